@@ -4,10 +4,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -20,7 +22,13 @@ import (
 	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
 )
 
-var version = "dev"
+// Build information injected through ldflags. Empty values are filled in from the
+// Go build information embedded in the binary.
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
 
 func main() {
 	// Parse command-line flags
@@ -28,40 +36,136 @@ func main() {
 	port := flag.Int("port", 8080, "Port to listen on (for http transport)")
 	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, error")
 	logFormat := flag.String("log-format", "text", "Log format: text, json")
+	requestLogFormat := flag.String("request-log-format", mcpserver.AccessLogFormatJSON, "Access log format for the http transport, written at info level whatever --log-level and --log-format: json, text, none")
+	logSampling := flag.Int("log-sampling", 0, "Log only 1 in N high-frequency debug messages (e.g. per extracted file); 0 or 1 disables sampling")
+	disableLocalInCluster := flag.Bool("disable-local-in-cluster", false, "Require an explicit kubeconfig for target clusters instead of falling back to the in-cluster config")
+	defaultExcludeNamespaces := flag.String("default-exclude-namespaces", "", "Comma-separated namespaces or glob patterns (e.g. kube-system,openshift-*) dropped from all_resources comparisons that do not set exclude_namespaces")
+	kubeconfigSecretNamespaces := flag.String("kubeconfig-secret-namespaces", "", "Comma-separated namespaces or glob patterns from which the kubeconfig_secret tool input may read Secrets; kubeconfig_secret is disabled when empty")
+	volatileFields := flag.String("volatile-fields", mcpserver.DefaultVolatileFields, "Comma-separated field paths (e.g. metadata.resourceVersion,status) whose diffs ignore_volatile_fields drops; \"*\" matches any single field")
+	sessionEventLogSize := flag.Int("session-event-log-size", 0, "Number of server-sent events kept in memory per MCP session of the http transport, served at /debug/sessions/{id}/events on --session-event-log-addr; 0 disables the log. The events include full tool results and the endpoint is not authenticated")
+	sessionEventLogAddr := flag.String("session-event-log-addr", "127.0.0.1:8081", "Address of the separate listener serving the session event log; it exposes full tool results without authentication to anyone who can reach it, so keep it on a loopback address unless access to it is otherwise restricted")
+	sessionEventLogRetention := flag.Duration("session-event-log-retention", mcpserver.DefaultSessionEventLogRetention, "How long the events of an MCP session are kept after its last event")
+	rdsConfigFile := flag.String("rds-config-file", os.Getenv("KUBE_COMPARE_MCP_RDS_CONFIG"), "YAML or JSON file of RDS types (type, imageBase, path, rhelVariants, minOCPVersion) merged with the built-in core, ran, and hub types; an entry for a built-in type replaces it. Defaults to KUBE_COMPARE_MCP_RDS_CONFIG")
+	dumpSchemas := flag.Bool("dump-schemas", false, "Print the input and output JSON schemas of every tool to stdout and exit instead of starting the server")
 	showVersion := flag.Bool("version", false, "Show version information")
 	flag.Parse()
 
+	mcpserver.SetBuildInfo(version, commit, buildDate)
+	buildInfo := mcpserver.GetBuildInfo()
+
 	if *showVersion {
-		fmt.Printf("kube-compare-mcp %s\n", version)
+		fmt.Printf("kube-compare-mcp %s\n", buildInfo.Version)
+		fmt.Printf("  commit:       %s\n", valueOrUnknown(buildInfo.GitCommit))
+		fmt.Printf("  build date:   %s\n", valueOrUnknown(buildInfo.BuildDate))
+		fmt.Printf("  go version:   %s\n", buildInfo.GoVersion)
+		fmt.Printf("  kube-compare: %s\n", buildInfo.KubeCompareVersion)
 		os.Exit(0)
 	}
 
 	// Initialize logger
-	logger := initLogger(*logLevel, *logFormat)
+	logger := initLogger(*logLevel, *logFormat, *logSampling)
 	slog.SetDefault(logger)
 
 	logger.Info("Starting kube-compare-mcp",
-		"version", version,
+		"version", buildInfo.Version,
+		"commit", buildInfo.GitCommit,
+		"buildDate", buildInfo.BuildDate,
 		"transport", *transport,
 		"logLevel", *logLevel,
+		"requestLogFormat", *requestLogFormat,
+		"logSampling", *logSampling,
+		"disableLocalInCluster", *disableLocalInCluster,
+		"defaultExcludeNamespaces", *defaultExcludeNamespaces,
+		"volatileFields", *volatileFields,
+		"rdsConfigFile", *rdsConfigFile,
+		"sessionEventLogSize", *sessionEventLogSize,
+		"sessionEventLogAddr", *sessionEventLogAddr,
 	)
 
+	volatileFieldPaths := mcpserver.ParseVolatileFields(*volatileFields)
+	if err := mcpserver.ValidateVolatileFields(volatileFieldPaths); err != nil {
+		logger.Error("Invalid --volatile-fields", "error", err)
+		os.Exit(1)
+	}
+
+	cosignPublicKey, err := mcpserver.LoadCosignPublicKeyFromEnv()
+	if err != nil {
+		logger.Error("Invalid KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY", "error", err)
+		os.Exit(1)
+	}
+
+	registryKeychain, err := mcpserver.LoadRegistryKeychainFromEnv()
+	if err != nil {
+		logger.Error("Invalid KUBE_COMPARE_MCP_PULL_SECRET", "error", err)
+		os.Exit(1)
+	}
+
+	var rdsConfigEntries []mcpserver.RDSConfigEntry
+	if *rdsConfigFile != "" {
+		entries, err := mcpserver.LoadRDSConfigFile(*rdsConfigFile)
+		if err != nil {
+			logger.Error("Invalid --rds-config-file", "error", err)
+			os.Exit(1)
+		}
+		rdsConfigEntries = entries
+
+		types := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			types = append(types, entry.Type)
+		}
+		logger.Info("Registered RDS types from config file", "path", *rdsConfigFile, "rdsTypes", types)
+	}
+
+	accessLogger, err := mcpserver.NewAccessLogger(*requestLogFormat, os.Stderr)
+	if err != nil {
+		logger.Error("Invalid --request-log-format", "error", err)
+		os.Exit(1)
+	}
+
+	mcpserver.SetDisableLocalInCluster(*disableLocalInCluster)
+	mcpserver.SetDefaultExcludeNamespaces(mcpserver.ParseNamespacePatterns(*defaultExcludeNamespaces))
+	mcpserver.SetKubeconfigSecretNamespaces(mcpserver.ParseNamespacePatterns(*kubeconfigSecretNamespaces))
+	mcpserver.SetVolatileFields(volatileFieldPaths)
+	mcpserver.SetRDSConfigs(rdsConfigEntries)
+	mcpserver.SetCosignPublicKey(cosignPublicKey)
+	mcpserver.SetRegistryKeychain(registryKeychain)
+
+	// Dump the schemas after the configuration is applied, since --rds-config-file
+	// extends the rds_type enums
+	if *dumpSchemas {
+		if err := mcpserver.DumpToolSchemas(context.Background(), os.Stdout, buildInfo.Version); err != nil {
+			logger.Error("Failed to dump tool schemas", "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Create the MCP server with build-time version
-	s := mcpserver.NewServer(version)
+	s := mcpserver.NewServer(buildInfo.Version)
 
 	switch *transport {
 	case "stdio":
 		runStdioServer(s, logger)
 	case "http":
-		runHTTPServer(s, *port, logger)
+		eventLog := mcpserver.NewSessionEventLog(*sessionEventLogSize, *sessionEventLogRetention)
+		runHTTPServer(s, *port, logger, accessLogger, eventLog, *sessionEventLogAddr)
 	default:
 		logger.Error("Unknown transport", "transport", *transport)
 		os.Exit(1)
 	}
 }
 
+// valueOrUnknown returns value, or "unknown" when it is empty.
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
 // initLogger creates a slog.Logger with the specified level and format.
-func initLogger(level, format string) *slog.Logger {
+// A sampling rate above 1 thins out high-frequency debug messages.
+func initLogger(level, format string, sampling int) *slog.Logger {
 	// Parse log level
 	var slogLevel slog.Level
 	switch strings.ToLower(level) {
@@ -89,6 +193,10 @@ func initLogger(level, format string) *slog.Logger {
 		handler = slog.NewTextHandler(os.Stderr, opts)
 	}
 
+	if sampling > 1 {
+		handler = mcpserver.NewSamplingHandler(handler, sampling, mcpserver.HighFrequencyLogMessages)
+	}
+
 	return slog.New(handler)
 }
 
@@ -101,8 +209,11 @@ func runStdioServer(s *mcp.Server, logger *slog.Logger) {
 	}
 }
 
-// runHTTPServer starts the server using Streamable HTTP transport
-func runHTTPServer(s *mcp.Server, port int, logger *slog.Logger) {
+// runHTTPServer starts the server using Streamable HTTP transport.
+// Requests are written to accessLogger unless it is nil, and the events streamed to
+// each session are recorded in eventLog unless it is nil and served on a separate
+// listener at eventLogAddr.
+func runHTTPServer(s *mcp.Server, port int, logger *slog.Logger, accessLogger *slog.Logger, eventLog *mcpserver.SessionEventLog, eventLogAddr string) {
 	addr := fmt.Sprintf(":%d", port)
 	logger.Info("Starting HTTP server",
 		"addr", addr,
@@ -114,18 +225,33 @@ func runHTTPServer(s *mcp.Server, port int, logger *slog.Logger) {
 	mux := http.NewServeMux()
 
 	// Health endpoint for Kubernetes liveness/readiness probes
+	healthBody, err := json.Marshal(struct {
+		Status string `json:"status"`
+		mcpserver.BuildInfo
+	}{Status: "ok", BuildInfo: mcpserver.GetBuildInfo()})
+	if err != nil {
+		healthBody = []byte(`{"status":"ok"}`)
+	}
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
+		_, _ = w.Write(healthBody)
 	})
 
 	// MCP endpoint handled by the Streamable HTTP handler
-	streamHandler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil)
+	streamHandler := eventLog.Middleware(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil))
 	mux.Handle("/mcp", streamHandler)
 	mux.Handle("/", streamHandler)
 
-	// Wrap with logging middleware
-	handler := loggingMiddleware(mux, logger)
+	// Admin endpoint for the events streamed to each session, when enabled. The events
+	// include tool results, so they are kept off the MCP listener
+	var adminSrv *http.Server
+	if eventLog != nil {
+		adminSrv = startSessionEventLogServer(eventLog, eventLogAddr, logger)
+	}
+
+	// Wrap with logging and access log middleware
+	handler := mcpserver.AccessLogMiddleware(loggingMiddleware(mux, logger), accessLogger)
 
 	srv := &http.Server{
 		Addr:              addr,
@@ -145,6 +271,11 @@ func runHTTPServer(s *mcp.Server, port int, logger *slog.Logger) {
 		logger.Info("Received shutdown signal", "signal", sig)
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
+		if adminSrv != nil {
+			if err := adminSrv.Shutdown(ctx); err != nil {
+				logger.Error("Error during session event log shutdown", "error", err)
+			}
+		}
 		if err := srv.Shutdown(ctx); err != nil {
 			logger.Error("Error during shutdown", "error", err)
 		}
@@ -157,14 +288,43 @@ func runHTTPServer(s *mcp.Server, port int, logger *slog.Logger) {
 	logger.Info("Server stopped")
 }
 
-// loggingMiddleware wraps an http.Handler with request logging and body size limits.
+// startSessionEventLogServer serves the session event log at addr, on a listener of its
+// own. It exits when addr cannot be listened on.
+func startSessionEventLogServer(eventLog *mcpserver.SessionEventLog, addr string, logger *slog.Logger) *http.Server {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Error("Invalid --session-event-log-addr", "error", err)
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(mcpserver.SessionEventLogPath, eventLog.Handler())
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 30 * time.Second,
+		ReadTimeout:       60 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	logger.Info("Session event log enabled",
+		"endpoint", fmt.Sprintf("http://%s%s{id}/events", listener.Addr(), mcpserver.SessionEventLogPath),
+	)
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Session event log server error", "error", err)
+		}
+	}()
+	return srv
+}
+
+// loggingMiddleware wraps an http.Handler with MCP request logging and body size limits.
+// Completed requests are written to the access log by mcpserver.AccessLogMiddleware.
 func loggingMiddleware(next http.Handler, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Limit request body size to 10MB to prevent DoS attacks
 		r.Body = http.MaxBytesReader(w, r.Body, 10*1024*1024)
 
-		start := time.Now()
-
 		// Log incoming MCP requests for observability
 		if r.Method == http.MethodPost && r.URL.Path == "/mcp" {
 			logger.Info("Incoming MCP request",
@@ -173,39 +333,6 @@ func loggingMiddleware(next http.Handler, logger *slog.Logger) http.Handler {
 			)
 		}
 
-		// Wrap response writer to capture status code
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
-
-		next.ServeHTTP(wrapped, r)
-
-		// Skip logging for health checks to reduce log noise
-		if r.URL.Path != "/health" {
-			logger.Debug("HTTP request",
-				"method", r.Method,
-				"path", r.URL.Path,
-				"status", wrapped.statusCode,
-				"duration", time.Since(start),
-				"remoteAddr", r.RemoteAddr,
-			)
-		}
+		next.ServeHTTP(w, r)
 	})
 }
-
-// responseWriter wraps http.ResponseWriter to capture the status code.
-// It implements http.Flusher to support HTTP streaming.
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
-
-// Flush implements http.Flusher interface for HTTP streaming support.
-func (rw *responseWriter) Flush() {
-	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
-		flusher.Flush()
-	}
-}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// AccessLogFormatJSON writes one JSON object per HTTP request.
+	AccessLogFormatJSON = "json"
+	// AccessLogFormatText writes one logfmt line per HTTP request.
+	AccessLogFormatText = "text"
+	// AccessLogFormatNone disables the access log.
+	AccessLogFormatNone = "none"
+
+	// RequestIDHeader carries the ID of an HTTP request. An ID sent by the client is
+	// kept; otherwise one is generated. Either way it is echoed in the response.
+	RequestIDHeader = "X-Request-ID"
+)
+
+// NewAccessLogger returns the logger HTTP requests are written to in format, at info
+// level whatever the application log level and format. It returns nil for
+// AccessLogFormatNone and an error for an unknown format.
+func NewAccessLogger(format string, w io.Writer) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	switch strings.ToLower(format) {
+	case AccessLogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case AccessLogFormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case AccessLogFormatNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown request log format %q; expected %s, %s, or %s",
+			format, AccessLogFormatJSON, AccessLogFormatText, AccessLogFormatNone)
+	}
+}
+
+// AccessLogMiddleware wraps next so every request except health checks is written to
+// accessLogger once it completes, with its method, path, status, duration, response
+// bytes, remote address, and request ID. A nil accessLogger returns next unchanged.
+func AccessLogMiddleware(next http.Handler, accessLogger *slog.Logger) http.Handler {
+	if accessLogger == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = generateRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		recorder := &accessLogResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Skip health checks to keep probes out of the access log
+		if r.URL.Path == "/health" {
+			return
+		}
+		accessLogger.Info("HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.statusCode,
+			"durationMs", time.Since(start).Milliseconds(),
+			"bytes", recorder.bytes,
+			"remoteAddr", r.RemoteAddr,
+			"requestID", requestID,
+		)
+	})
+}
+
+// accessLogResponseWriter wraps http.ResponseWriter to capture the status code and
+// the number of body bytes written. It implements http.Flusher to support HTTP streaming.
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rw *accessLogResponseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *accessLogResponseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher interface for HTTP streaming support.
+func (rw *accessLogResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped http.ResponseWriter for http.ResponseController.
+func (rw *accessLogResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
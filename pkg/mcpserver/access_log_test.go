@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
+)
+
+var _ = Describe("Access log", func() {
+	var (
+		buf     *bytes.Buffer
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		accessLogger, err := mcpserver.NewAccessLogger(mcpserver.AccessLogFormatJSON, buf)
+		Expect(err).NotTo(HaveOccurred())
+		handler = mcpserver.AccessLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte("hello"))
+		}), accessLogger)
+	})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("writes a JSON record at info level for a handled request", func() {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = "10.0.0.1:51234"
+		req.Header.Set(mcpserver.RequestIDHeader, "req-42")
+		rec := serve(req)
+		Expect(rec.Header().Get(mcpserver.RequestIDHeader)).To(Equal("req-42"))
+
+		var record map[string]any
+		Expect(json.Unmarshal(buf.Bytes(), &record)).To(Succeed())
+		Expect(record).To(HaveKeyWithValue("level", "INFO"))
+		Expect(record).To(HaveKeyWithValue("msg", "HTTP request"))
+		Expect(record).To(HaveKeyWithValue("method", "POST"))
+		Expect(record).To(HaveKeyWithValue("path", "/mcp"))
+		Expect(record).To(HaveKeyWithValue("status", BeNumerically("==", http.StatusOK)))
+		Expect(record).To(HaveKeyWithValue("bytes", BeNumerically("==", len("hello"))))
+		Expect(record).To(HaveKeyWithValue("remoteAddr", "10.0.0.1:51234"))
+		Expect(record).To(HaveKeyWithValue("requestID", "req-42"))
+		Expect(record).To(HaveKey("durationMs"))
+	})
+
+	It("records the status written by the handler and generates a request ID", func() {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/missing", nil))
+		Expect(rec.Header().Get(mcpserver.RequestIDHeader)).NotTo(BeEmpty())
+
+		var record map[string]any
+		Expect(json.Unmarshal(buf.Bytes(), &record)).To(Succeed())
+		Expect(record).To(HaveKeyWithValue("status", BeNumerically("==", http.StatusNotFound)))
+		Expect(record).To(HaveKeyWithValue("requestID", rec.Header().Get(mcpserver.RequestIDHeader)))
+	})
+
+	It("skips health checks", func() {
+		serve(httptest.NewRequest(http.MethodGet, "/health", nil))
+		Expect(buf.Len()).To(BeZero())
+	})
+
+	Describe("NewAccessLogger", func() {
+		It("disables the access log with none", func() {
+			accessLogger, err := mcpserver.NewAccessLogger(mcpserver.AccessLogFormatNone, buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessLogger).To(BeNil())
+
+			next := http.NewServeMux()
+			Expect(mcpserver.AccessLogMiddleware(next, accessLogger)).To(BeIdenticalTo(next))
+		})
+
+		It("rejects an unknown format", func() {
+			_, err := mcpserver.NewAccessLogger("xml", buf)
+			Expect(err).To(MatchError(ContainSubstring(`unknown request log format "xml"`)))
+		})
+	})
+})
@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+const (
+	// anonymizedTokenPrefix starts every token anonymize replaces a name with.
+	anonymizedTokenPrefix = "anon-"
+	// anonymizedTokenLength is the number of hex digits of a token. A token that
+	// collides with another name's is lengthened.
+	anonymizedTokenLength = 10
+	// redactedValue replaces the field values of diffs when redact_values is set.
+	redactedValue = "<redacted>"
+)
+
+// AnonymizationLegend maps the tokens anonymize put in a comparison result back to
+// the resource names and namespaces they replace. It is returned in its own content
+// block, for the operator to keep when sharing the anonymized result.
+type AnonymizationLegend struct {
+	Tokens map[string]string `json:"anonymization_legend"`
+}
+
+// content returns the MCP content block carrying the legend as JSON text. The block
+// is annotated for the user only, since the legend undoes the anonymization.
+func (l *AnonymizationLegend) content() (mcp.Content, error) {
+	legendJSON, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format anonymization legend: %w", err)
+	}
+	return &mcp.TextContent{
+		Text:        string(legendJSON),
+		Annotations: &mcp.Annotations{Audience: []mcp.Role{"user"}},
+	}, nil
+}
+
+// anonymizer replaces the resource names and namespaces of a comparison result with
+// tokens. Tokens are an HMAC of the name under a key generated for the anonymizer,
+// so a name maps to the same token throughout one result, but the tokens of two
+// results cannot be correlated or reversed without the legend.
+type anonymizer struct {
+	key []byte
+	// redactValues also replaces the field values of diffs with redactedValue
+	redactValues bool
+	// tokens maps each anonymized name to its token, and originals the reverse
+	tokens    map[string]string
+	originals map[string]string
+}
+
+// newAnonymizer returns an anonymizer with a new random key.
+func newAnonymizer(redactValues bool) (*anonymizer, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate anonymization key: %w", err)
+	}
+	return &anonymizer{
+		key:          key,
+		redactValues: redactValues,
+		tokens:       make(map[string]string),
+		originals:    make(map[string]string),
+	}, nil
+}
+
+// token returns the token for name, the same one each time it is asked for.
+func (a *anonymizer) token(name string) string {
+	if name == "" {
+		return ""
+	}
+	if token, ok := a.tokens[name]; ok {
+		return token
+	}
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(name))
+	sum := hex.EncodeToString(mac.Sum(nil))
+
+	token := anonymizedTokenPrefix + sum
+	for length := anonymizedTokenLength; length < len(sum); length++ {
+		candidate := anonymizedTokenPrefix + sum[:length]
+		if _, taken := a.originals[candidate]; !taken {
+			token = candidate
+			break
+		}
+	}
+	a.tokens[name] = token
+	a.originals[token] = name
+	return token
+}
+
+// crName returns crName, the apiVersion_kind_namespace_name of a CR as kube-compare
+// reports it, with its namespace and name replaced by tokens. A CR name that cannot
+// be parsed is replaced whole.
+func (a *anonymizer) crName(crName string) string {
+	gvk, namespace, name, err := parseCRName(crName)
+	if err != nil {
+		return a.token(crName)
+	}
+	parts := []string{gvk.GroupVersion().String(), gvk.Kind}
+	if namespace != "" {
+		parts = append(parts, a.token(namespace))
+	}
+	return strings.Join(append(parts, a.token(name)), compare.FieldSeparator)
+}
+
+// legend returns the tokens handed out so far with the names they replace.
+func (a *anonymizer) legend() *AnonymizationLegend {
+	legend := &AnonymizationLegend{Tokens: make(map[string]string, len(a.originals))}
+	for token, name := range a.originals {
+		legend.Tokens[token] = name
+	}
+	return legend
+}
+
+// anonymizeOutput replaces the namespaces and names of the CRs of output with
+// tokens, in the CR names and wherever they appear in the diffs, and redacts the
+// field values of the diffs if the anonymizer is set to. The structure of the output
+// and the fields of each diff are kept.
+func (a *anonymizer) anonymizeOutput(output *compare.Output) {
+	// Every name is given its token first, so the diffs of one CR can mention another
+	if output.Diffs != nil {
+		for i := range *output.Diffs {
+			diff := &(*output.Diffs)[i]
+			diff.CRName = a.crName(diff.CRName)
+		}
+	}
+	if output.Summary != nil {
+		for i, crName := range output.Summary.UnmatchedCRS {
+			output.Summary.UnmatchedCRS[i] = a.crName(crName)
+		}
+	}
+	if output.Diffs == nil {
+		return
+	}
+	for i := range *output.Diffs {
+		diff := &(*output.Diffs)[i]
+		if a.redactValues {
+			diff.DiffOutput = redactDiffValues(diff.DiffOutput)
+		}
+		diff.DiffOutput = a.replaceNames(diff.DiffOutput)
+	}
+}
+
+// replaceNames replaces each word of text that is an anonymized name with its token.
+// Words are runs of the characters of Kubernetes names, so a name is replaced where
+// it stands alone, such as in "name: web" or a path ending in "_web", but not inside
+// a longer name.
+func (a *anonymizer) replaceNames(text string) string {
+	var b strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := text[start:end]
+		if token, ok := a.tokens[word]; ok {
+			word = token
+		}
+		b.WriteString(word)
+		start = -1
+	}
+	for i := 0; i < len(text); i++ {
+		if isNameChar(text[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		b.WriteByte(text[i])
+	}
+	flush(len(text))
+	return b.String()
+}
+
+// isNameChar reports whether c can be part of a Kubernetes resource name.
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.'
+}
+
+// redactDiffValues replaces the values on the added, removed, and context lines of
+// a unified diff with redactedValue, keeping the field names and list structure.
+func redactDiffValues(diffOutput string) string {
+	lines := strings.Split(diffOutput, "\n")
+	for i, line := range lines {
+		if line == "" || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		switch line[0] {
+		case '+', '-', ' ':
+			lines[i] = line[:1] + redactYAMLValue(line[1:])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// redactYAMLValue replaces the value of a YAML line, "key: value" or "- value", with
+// redactedValue. A line that only opens a mapping or list, such as "key:", is kept,
+// and a line of a multi-line value is redacted whole.
+func redactYAMLValue(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if trimmed == "" {
+		return line
+	}
+	indent := line[:len(line)-len(trimmed)]
+	prefix := ""
+	for strings.HasPrefix(trimmed, "- ") {
+		prefix += "- "
+		trimmed = trimmed[2:]
+	}
+	if key, value, found := strings.Cut(trimmed, ": "); found && !strings.ContainsAny(key, " \"'") {
+		if strings.TrimSpace(value) == "" {
+			return line
+		}
+		return indent + prefix + key + ": " + redactedValue
+	}
+	if strings.HasSuffix(trimmed, ":") {
+		return line
+	}
+	return indent + prefix + redactedValue
+}
+
+// anonymizeSeverity replaces the CR names of severity with their anonymized names.
+func (a *anonymizer) anonymizeSeverity(severity *DiffSeverity) {
+	for i, crName := range severity.SpecDrift {
+		severity.SpecDrift[i] = a.crName(crName)
+	}
+	for i, crName := range severity.MetadataDrift {
+		severity.MetadataDrift[i] = a.crName(crName)
+	}
+}
+
+// anonymizeRemediation replaces the CR names of groups with their anonymized names.
+// The templates to create name reference files, not cluster resources, and are kept.
+func (a *anonymizer) anonymizeRemediation(groups *RemediationGroups) {
+	for i, crName := range groups.Update.Resources {
+		groups.Update.Resources[i] = a.crName(crName)
+	}
+	for i, crName := range groups.Delete.Resources {
+		groups.Delete.Resources[i] = a.crName(crName)
+	}
+}
+
+// anonymizeOwned replaces the CR names of report, and the names of the resources
+// they own, with their anonymized names.
+func (a *anonymizer) anonymizeOwned(report *OwnedResourcesReport) {
+	for i := range report.Resources {
+		report.Resources[i].CRName = a.crName(report.Resources[i].CRName)
+		report.Resources[i].Error = a.replaceNames(report.Resources[i].Error)
+		a.anonymizeOwnedResources(report.Resources[i].Owned)
+	}
+}
+
+// anonymizeOwnedResources replaces the names of owned and their children with tokens.
+func (a *anonymizer) anonymizeOwnedResources(owned []OwnedResource) {
+	for i := range owned {
+		owned[i].Name = a.token(owned[i].Name)
+		a.anonymizeOwnedResources(owned[i].Owned)
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// anonymizeTestDiff is a kube-compare diff of ConfigMap payments/billing-settings.
+const anonymizeTestDiff = `diff -u -N /tmp/MERGED-1/v1_configmap_payments_billing-settings /tmp/LIVE-2/v1_configmap_payments_billing-settings
+--- /tmp/MERGED-1/v1_configmap_payments_billing-settings
++++ /tmp/LIVE-2/v1_configmap_payments_billing-settings
+@@ -1,8 +1,8 @@
+ apiVersion: v1
+ data:
+-  endpoint: https://billing.internal
++  endpoint: https://billing.example.com
+ kind: ConfigMap
+ metadata:
+   name: billing-settings
+   namespace: payments
+`
+
+// newAnonymizeTestOutput returns kube-compare output with a drifted ConfigMap and a
+// matching Deployment in namespace payments, and an unmatched Secret.
+func newAnonymizeTestOutput() *compare.Output {
+	return &compare.Output{
+		Summary: &compare.Summary{
+			NumDiffCRs:   1,
+			TotalCRs:     2,
+			UnmatchedCRS: []string{"v1_Secret_payments_billing-token"},
+		},
+		Diffs: &[]compare.DiffSum{
+			{CRName: "v1_ConfigMap_payments_billing-settings", CorrelatedTemplate: "configmap.yaml", DiffOutput: anonymizeTestDiff},
+			{CRName: "apps/v1_Deployment_payments_billing", CorrelatedTemplate: "deployment.yaml"},
+		},
+	}
+}
+
+var _ = Describe("anonymize", func() {
+	var a *anonymizer
+
+	BeforeEach(func() {
+		var err error
+		a, err = newAnonymizer(false)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("maps the same name to the same token", func() {
+		token := a.token("payments")
+		Expect(token).To(HavePrefix(anonymizedTokenPrefix))
+		Expect(token).NotTo(ContainSubstring("payments"))
+		Expect(a.token("payments")).To(Equal(token))
+		Expect(a.token("billing")).NotTo(Equal(token))
+	})
+
+	It("gives the names of another result other tokens", func() {
+		other, err := newAnonymizer(false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other.token("payments")).NotTo(Equal(a.token("payments")))
+	})
+
+	It("replaces CR names, namespaces, and names in diffs, keeping the fields", func() {
+		output := newAnonymizeTestOutput()
+		a.anonymizeOutput(output)
+
+		namespace, configMap := a.token("payments"), a.token("billing-settings")
+		diffs := *output.Diffs
+		Expect(diffs[0].CRName).To(Equal("v1_ConfigMap_" + namespace + "_" + configMap))
+		Expect(diffs[1].CRName).To(Equal("apps/v1_Deployment_" + namespace + "_" + a.token("billing")))
+		Expect(output.Summary.UnmatchedCRS).To(Equal([]string{"v1_Secret_" + namespace + "_" + a.token("billing-token")}))
+
+		Expect(diffs[0].DiffOutput).NotTo(ContainSubstring("payments"))
+		Expect(diffs[0].DiffOutput).NotTo(ContainSubstring("billing-settings"))
+		Expect(diffs[0].DiffOutput).To(ContainSubstring("  name: " + configMap + "\n"))
+		Expect(diffs[0].DiffOutput).To(ContainSubstring("/v1_configmap_" + namespace + "_" + configMap))
+		// Field diffs are kept, and names only replaced where they stand alone
+		Expect(diffs[0].DiffOutput).To(ContainSubstring("+  endpoint: https://billing.example.com"))
+	})
+
+	It("keeps the mapping consistent across the comparisons of one result", func() {
+		first, second := newAnonymizeTestOutput(), newAnonymizeTestOutput()
+		a.anonymizeOutput(first)
+		a.anonymizeOutput(second)
+
+		Expect(*second.Diffs).To(Equal(*first.Diffs))
+		Expect(a.legend().Tokens).To(HaveLen(4))
+	})
+
+	It("returns a legend that maps every token back to its name", func() {
+		output := newAnonymizeTestOutput()
+		a.anonymizeOutput(output)
+
+		legend := a.legend()
+		Expect(legend.Tokens).To(HaveKeyWithValue(a.token("payments"), "payments"))
+		restored := (*output.Diffs)[0].DiffOutput
+		for token, name := range legend.Tokens {
+			restored = strings.ReplaceAll(restored, token, name)
+		}
+		Expect(restored).To(Equal(anonymizeTestDiff))
+
+		content, err := legend.content()
+		Expect(err).NotTo(HaveOccurred())
+		text := content.(*mcp.TextContent)
+		Expect(text.Annotations.Audience).To(Equal([]mcp.Role{"user"}))
+		Expect(text.Text).To(ContainSubstring(`"anonymization_legend"`))
+	})
+
+	It("redacts field values when asked to", func() {
+		redacting, err := newAnonymizer(true)
+		Expect(err).NotTo(HaveOccurred())
+		output := newAnonymizeTestOutput()
+		redacting.anonymizeOutput(output)
+
+		diff := (*output.Diffs)[0].DiffOutput
+		Expect(diff).To(ContainSubstring("-  endpoint: <redacted>\n+  endpoint: <redacted>\n"))
+		Expect(diff).To(ContainSubstring(" data:\n"))
+		Expect(diff).NotTo(ContainSubstring("billing.example.com"))
+		Expect(diff).To(ContainSubstring("--- /tmp/MERGED-1/v1_configmap_" + redacting.token("payments")))
+	})
+
+	It("redacts list items and keeps the keys that open a mapping", func() {
+		Expect(redactYAMLValue("    - 10.0.0.1")).To(Equal("    - <redacted>"))
+		Expect(redactYAMLValue("  - name: web")).To(Equal("  - name: <redacted>"))
+		Expect(redactYAMLValue("  labels:")).To(Equal("  labels:"))
+	})
+
+	It("anonymizes the severity and owned resources of the result", func() {
+		severity := &DiffSeverity{SpecDrift: []string{"v1_ConfigMap_payments_billing-settings"}, MetadataDrift: []string{}}
+		owned := &OwnedResourcesReport{Resources: []OwnedResources{{
+			CRName: "apps/v1_Deployment_payments_billing",
+			Owned:  []OwnedResource{{Kind: "ReplicaSet", Name: "billing-5d8f", Owned: []OwnedResource{{Kind: "Pod", Name: "billing-5d8f-a"}}}},
+		}}}
+
+		a.anonymizeSeverity(severity)
+		a.anonymizeOwned(owned)
+
+		Expect(severity.SpecDrift).To(Equal([]string{"v1_ConfigMap_" + a.token("payments") + "_" + a.token("billing-settings")}))
+		Expect(owned.Resources[0].CRName).To(Equal("apps/v1_Deployment_" + a.token("payments") + "_" + a.token("billing")))
+		Expect(owned.Resources[0].Owned[0].Name).To(Equal(a.token("billing-5d8f")))
+		Expect(owned.Resources[0].Owned[0].Owned[0].Name).To(Equal(a.token("billing-5d8f-a")))
+	})
+
+	It("renders the anonymized output in the requested format", func() {
+		output := newAnonymizeTestOutput()
+		a.anonymizeOutput(output)
+
+		rendered, err := renderCompareOutput(output, compare.Yaml)
+		Expect(err).NotTo(HaveOccurred())
+		var parsed compare.Output
+		Expect(sigsyaml.Unmarshal([]byte(rendered), &parsed)).To(Succeed())
+		Expect((*parsed.Diffs)[0].CRName).To(Equal("v1_ConfigMap_" + a.token("payments") + "_" + a.token("billing-settings")))
+		Expect(rendered).NotTo(ContainSubstring("payments"))
+	})
+
+	It("makes kube-compare output JSON", func() {
+		Expect(compareOutputFormat(&CompareArgs{OutputFormat: compare.Yaml, Anonymize: true})).To(Equal(compare.Json))
+	})
+
+	It("rejects redact_values without anonymize", func() {
+		result, _, err := HandleClusterDiff(context.Background(), nil, ClusterDiffInput{
+			Reference:    "https://example.com/metadata.yaml",
+			RedactValues: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("redact_values"))
+	})
+})
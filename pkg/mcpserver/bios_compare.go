@@ -5,21 +5,26 @@ package mcpserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/adrg/strutil"
 	"github.com/adrg/strutil/metrics"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.yaml.in/yaml/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/rest"
 	sigsyaml "sigs.k8s.io/yaml"
 )
 
@@ -34,9 +39,216 @@ const (
 	// (0.0-1.0) required to accept a reference  BIOS ConfigMap model match.
 	// Values below this threshold indicate the product name and label are too dissimilar.
 	minModelSimilarity = 0.7
+
+	// AmbiguousMatchError rejects a host when several reference ConfigMaps tie for the best score.
+	AmbiguousMatchError = "error"
+	// AmbiguousMatchFirst picks the lexicographically first of the tied reference ConfigMaps.
+	AmbiguousMatchFirst = "first"
+)
+
+// getAmbiguousMatchPolicy returns how ties between reference ConfigMaps are resolved.
+// Can be configured via KUBE_COMPARE_MCP_BIOS_AMBIGUOUS_MATCH environment variable
+// ("first" or "error"). Defaults to "first", which keeps resolving ties as before;
+// "error" surfaces misconfigured labels instead.
+func getAmbiguousMatchPolicy() string {
+	if val := os.Getenv("KUBE_COMPARE_MCP_BIOS_AMBIGUOUS_MATCH"); val == AmbiguousMatchError {
+		return AmbiguousMatchError
+	}
+	return AmbiguousMatchFirst
+}
+
+// parseReferenceNamespaces splits reference_source into the namespaces searched for
+// BIOS reference ConfigMaps, in order. Namespaces are comma-separated; blank and
+// repeated entries are dropped, and an empty reference_source searches
+// DefaultReferenceConfigNamespace.
+func parseReferenceNamespaces(referenceSource string) []string {
+	var namespaces []string
+	for _, namespace := range strings.Split(referenceSource, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace != "" && !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{DefaultReferenceConfigNamespace}
+	}
+	return namespaces
+}
+
+// getDefaultBMHNamespace returns the namespace compared when baremetal_bios_diff is
+// called without one. Can be configured via KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE
+// environment variable. Empty (the default) keeps the namespace required.
+func getDefaultBMHNamespace() string {
+	return os.Getenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE")
+}
+
+// Sources of the namespace a BIOS tool reads from, reported in its logs.
+const (
+	namespaceFromInput      = "input"
+	namespaceFromKubeconfig = "kubeconfig-context"
+	namespaceFromServer     = "server-default"
+)
+
+// resolveToolNamespace returns namespace when it is set, and otherwise the namespace
+// of the kubeconfig context the tool connects with, along with where the namespace
+// came from. It returns "" when neither is set.
+func resolveToolNamespace(namespace, kubeconfig, contextName string) (string, string) {
+	if namespace != "" {
+		return namespace, namespaceFromInput
+	}
+	if contextNamespace := KubeconfigContextNamespace(kubeconfig, contextName); contextNamespace != "" {
+		return contextNamespace, namespaceFromKubeconfig
+	}
+	return "", ""
+}
+
+// resolveBMHNamespace is resolveToolNamespace, falling back to the configured default
+// when neither the input nor the kubeconfig context sets a namespace.
+func resolveBMHNamespace(namespace, kubeconfig, contextName string) (string, string) {
+	if resolved, source := resolveToolNamespace(namespace, kubeconfig, contextName); resolved != "" {
+		return resolved, source
+	}
+	if defaultNamespace := getDefaultBMHNamespace(); defaultNamespace != "" {
+		return defaultNamespace, namespaceFromServer
+	}
+	return "", ""
+}
+
+// logResolvedNamespace logs the namespace a tool reads from when it was defaulted
+// rather than given in the input.
+func logResolvedNamespace(logger *slog.Logger, namespace, source string) {
+	if source != namespaceFromInput {
+		logger.Info("Using default namespace", "namespace", namespace, "namespaceSource", source)
+	}
+}
+
+const (
+	// MissingRoleWorker treats a BareMetalHost without a role as a worker.
+	MissingRoleWorker = "worker"
+	// MissingRoleError reports a BareMetalHost without a role as an error.
+	MissingRoleError = "error"
 )
 
-// GVRs for metal3 and related resources
+// getMissingRolePolicy returns how BareMetalHosts without a role annotation or node-role
+// label are handled. Can be configured via KUBE_COMPARE_MCP_BIOS_MISSING_ROLE environment
+// variable ("worker" or "error"). Defaults to "worker".
+func getMissingRolePolicy() string {
+	if val := os.Getenv("KUBE_COMPARE_MCP_BIOS_MISSING_ROLE"); val == MissingRoleError {
+		return MissingRoleError
+	}
+	return MissingRoleWorker
+}
+
+const (
+	// RoleSourceAnnotation is a role source read from a BareMetalHost annotation.
+	RoleSourceAnnotation = "annotation"
+	// RoleSourceLabel is a role source read from a BareMetalHost label.
+	RoleSourceLabel = "label"
+)
+
+// bmhRoleSource is a BareMetalHost annotation or label that may hold the host's role.
+type bmhRoleSource struct {
+	Kind string // RoleSourceAnnotation or RoleSourceLabel
+	Key  string
+}
+
+func (s bmhRoleSource) String() string {
+	return s.Key + " " + s.Kind
+}
+
+// lookup returns the value of the source on bmh, or "" when it is not set.
+func (s bmhRoleSource) lookup(bmh *unstructured.Unstructured) string {
+	if s.Kind == RoleSourceLabel {
+		return strings.TrimSpace(bmh.GetLabels()[s.Key])
+	}
+	return strings.TrimSpace(bmh.GetAnnotations()[s.Key])
+}
+
+// getBMHRoleSources returns the BareMetalHost annotations and labels read, in order, for
+// a host's role. Depending on the install method the role lives in different places, so
+// the list can be configured via KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES environment variable
+// as comma-separated annotation:<key> and label:<key> entries. Defaults to the
+// BMHRoleAnnotation annotation. An invalid entry is an error rather than ignored.
+func getBMHRoleSources() ([]bmhRoleSource, error) {
+	val := strings.TrimSpace(os.Getenv("KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES"))
+	if val == "" {
+		return []bmhRoleSource{{Kind: RoleSourceAnnotation, Key: BMHRoleAnnotation}}, nil
+	}
+
+	var sources []bmhRoleSource
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, key, found := strings.Cut(entry, ":")
+		if !found || (kind != RoleSourceAnnotation && kind != RoleSourceLabel) {
+			return nil, fmt.Errorf("KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES entry %q must be %s:<key> or %s:<key>",
+				entry, RoleSourceAnnotation, RoleSourceLabel)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES entry %q has an invalid key: %s",
+				entry, strings.Join(errs, "; "))
+		}
+		sources = append(sources, bmhRoleSource{Kind: kind, Key: key})
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES has no entries")
+	}
+	return sources, nil
+}
+
+const (
+	// DefaultVendorLabelKey is the default label key holding a reference ConfigMap's vendor.
+	DefaultVendorLabelKey = "bios-reference/vendor"
+	// DefaultModelLabelKey is the default label key holding a reference ConfigMap's model.
+	DefaultModelLabelKey = "bios-reference/model"
+	// DefaultRoleLabelKey is the default label key holding a reference ConfigMap's role.
+	DefaultRoleLabelKey = "bios-reference/role"
+)
+
+// referenceLabelKeys are the label keys that identify reference ConfigMaps by
+// vendor, model, and role.
+type referenceLabelKeys struct {
+	Vendor string
+	Model  string
+	Role   string
+}
+
+// getReferenceLabelKeys returns the label keys used to match reference ConfigMaps.
+// Each can be configured via the KUBE_COMPARE_MCP_BIOS_VENDOR_LABEL,
+// KUBE_COMPARE_MCP_BIOS_MODEL_LABEL, and KUBE_COMPARE_MCP_BIOS_ROLE_LABEL environment
+// variables to reuse an existing labeling scheme. A key that is not a valid label key
+// is an error rather than ignored, so a typo does not silently match nothing.
+func getReferenceLabelKeys() (referenceLabelKeys, error) {
+	keys := referenceLabelKeys{
+		Vendor: DefaultVendorLabelKey,
+		Model:  DefaultModelLabelKey,
+		Role:   DefaultRoleLabelKey,
+	}
+	for _, setting := range []struct {
+		env string
+		key *string
+	}{
+		{"KUBE_COMPARE_MCP_BIOS_VENDOR_LABEL", &keys.Vendor},
+		{"KUBE_COMPARE_MCP_BIOS_MODEL_LABEL", &keys.Model},
+		{"KUBE_COMPARE_MCP_BIOS_ROLE_LABEL", &keys.Role},
+	} {
+		val := strings.TrimSpace(os.Getenv(setting.env))
+		if val == "" {
+			continue
+		}
+		if errs := validation.IsQualifiedName(val); len(errs) > 0 {
+			return referenceLabelKeys{}, fmt.Errorf("%s=%q is not a valid label key: %s",
+				setting.env, val, strings.Join(errs, "; "))
+		}
+		*setting.key = val
+	}
+	return keys, nil
+}
+
+// GVRs for metal3 and related resources. The metal3 GVRs are defaults that
+// KUBE_COMPARE_MCP_METAL3_GVRS and discovery may override; see newMetal3Client.
 var (
 	bareMetalHostGVR = schema.GroupVersionResource{
 		Group:    "metal3.io",
@@ -67,6 +279,12 @@ var (
 		Version:  "v1",
 		Resource: "configmaps",
 	}
+
+	nodeGVR = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "nodes",
+	}
 )
 
 // BIOSDiffInput defines the typed input for the baremetal_bios_diff tool.
@@ -74,9 +292,9 @@ var (
 type BIOSDiffInput struct {
 	Kubeconfig        string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for the ACM hub cluster. If omitted, uses in-cluster config."`
 	Context           string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig."`
-	Namespace         string `json:"namespace" jsonschema:"Namespace on the hub cluster containing BareMetalHost resources to compare."`
+	Namespace         string `json:"namespace,omitempty" jsonschema:"Namespace on the hub cluster containing BareMetalHost resources to compare. Defaults to the namespace of the kubeconfig context, then to the server's default namespace."`
 	HostName          string `json:"host_name,omitempty" jsonschema:"Specific host to compare. Omit to compare all hosts in the namespace."`
-	ReferenceSource   string `json:"reference_source,omitempty" jsonschema:"Namespace containing BIOS reference ConfigMaps."`
+	ReferenceSource   string `json:"reference_source,omitempty" jsonschema:"Namespace containing BIOS reference ConfigMaps, or a comma-separated list of namespaces searched in order."`
 	ReferenceOverride string `json:"reference_override,omitempty" jsonschema:"Explicit ConfigMap name to use, bypassing auto-matching by server model."`
 	OutputFormat      string `json:"output_format,omitempty" jsonschema:"Output format for results."`
 }
@@ -94,16 +312,17 @@ type BIOSDiffResult struct {
 
 // HostBIOSResult contains the BIOS comparison result for a single host.
 type HostBIOSResult struct {
-	Name            string            `json:"Name"`
-	Namespace       string            `json:"Namespace"`
-	Role            string            `json:"Role"`
-	ServerModel     ServerModelInfo   `json:"ServerModel"`
-	Reference       string            `json:"Reference"`
-	ReferenceSource string            `json:"ReferenceSource,omitempty"`
-	BIOSVersion     BIOSVersionResult `json:"BIOSVersion"`
-	SettingsDiff    []BIOSSettingDiff `json:"SettingsDiff,omitempty"`
-	Compliant       bool              `json:"Compliant"`
-	Error           string            `json:"Error,omitempty"`
+	Name               string            `json:"Name"`
+	Namespace          string            `json:"Namespace"`
+	Role               string            `json:"Role"`
+	ServerModel        ServerModelInfo   `json:"ServerModel"`
+	Reference          string            `json:"Reference"`
+	ReferenceNamespace string            `json:"ReferenceNamespace,omitempty"`
+	ReferenceSource    string            `json:"ReferenceSource,omitempty"`
+	BIOSVersion        BIOSVersionResult `json:"BIOSVersion"`
+	SettingsDiff       []BIOSSettingDiff `json:"SettingsDiff,omitempty"`
+	Compliant          bool              `json:"Compliant"`
+	Error              string            `json:"Error,omitempty"`
 }
 
 const (
@@ -190,7 +409,7 @@ func HandleBIOSDiff(ctx context.Context, req *mcp.CallToolRequest, input BIOSDif
 
 	if err := ctx.Err(); err != nil {
 		logger.Warn("Request canceled", "error", err)
-		return newToolResultError(formatErrorForUser(ErrContextCanceled)), nil, nil
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
 	}
 
 	// Validate context requires kubeconfig
@@ -199,97 +418,44 @@ func HandleBIOSDiff(ctx context.Context, req *mcp.CallToolRequest, input BIOSDif
 			"'context' parameter requires 'kubeconfig' to also be provided",
 			"Provide a kubeconfig along with the context name")
 		logger.Debug("Validation failed", "error", err)
-		return newToolResultError(formatErrorForUser(err)), nil, nil
+		return newToolResultErrorFor(err), nil, nil
 	}
 
 	// Validate required fields
+	var namespaceSource string
+	input.Namespace, namespaceSource = resolveBMHNamespace(input.Namespace, input.Kubeconfig, input.Context)
 	if input.Namespace == "" {
 		err := NewValidationError("namespace",
 			"namespace is required",
-			"Provide the namespace on the hub cluster containing the BareMetalHost resources")
-		return newToolResultError(formatErrorForUser(err)), nil, nil
+			"Provide the namespace on the hub cluster containing the BareMetalHost resources, "+
+				"set a namespace on the kubeconfig context, or set KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE on the server")
+		return newToolResultErrorFor(err), nil, nil
 	}
+	logResolvedNamespace(logger, input.Namespace, namespaceSource)
 
 	// Set defaults
-	referenceSource := input.ReferenceSource
-	if referenceSource == "" {
-		referenceSource = DefaultReferenceConfigNamespace
-	}
+	referenceNamespaces := parseReferenceNamespaces(input.ReferenceSource)
 
 	logger.Debug("Parsed baremetal_bios_diff arguments",
 		"namespace", input.Namespace,
 		"hostName", input.HostName,
-		"referenceSource", referenceSource,
+		"referenceNamespaces", referenceNamespaces,
 		"hasKubeconfig", input.Kubeconfig != "",
 		"context", input.Context,
 	)
 
-	// Build REST config
-	var restConfig *rest.Config
-	var err error
-
-	if input.Kubeconfig != "" {
-		logger.Debug("Using provided kubeconfig for hub cluster connection",
-			"kubeconfigLength", len(input.Kubeconfig),
-		)
-
-		kubeconfigData, err := DecodeOrParseKubeconfig(input.Kubeconfig)
-		if err != nil {
-			logger.Debug("Kubeconfig parsing failed", "error", err)
-			return newToolResultError(formatErrorForUser(err)), nil, nil
-		}
-
-		restConfig, err = BuildSecureRestConfigFromBytes(kubeconfigData, input.Context)
-		if err != nil {
-			logger.Debug("Failed to build REST config from kubeconfig", "error", err)
-			return newToolResultError(formatErrorForUser(err)), nil, nil
-		}
-	} else {
-		logger.Debug("Using in-cluster config for hub cluster connection")
-		restConfig, err = rest.InClusterConfig()
-		if err != nil {
-			err = NewCompareError("cluster-config",
-				fmt.Errorf("failed to get in-cluster config: %w", err),
-				"No kubeconfig provided and in-cluster config not available. "+
-					"Provide a kubeconfig for the hub cluster.")
-			return newToolResultError(formatErrorForUser(err)), nil, nil
-		}
-	}
-
-	// Create dynamic client for hub cluster (target workload data only)
-	targetClient, err := dynamic.NewForConfig(restConfig)
+	targetClient, referenceClient, logger, err := buildBIOSClients(ctx, input.Kubeconfig, input.Context, referenceNamespaces, logger)
 	if err != nil {
-		err = NewCompareError("cluster-client",
-			fmt.Errorf("failed to create dynamic client: %w", err),
-			"Verify the kubeconfig is valid")
-		return newToolResultError(formatErrorForUser(err)), nil, nil
-	}
-
-	// Create reference client from in-cluster config (for reference ConfigMaps).
-	// Reference ConfigMaps are ONLY loaded from the MCP server cluster for security:
-	// the server operator controls the compliance baseline, not the user.
-	var referenceClient dynamic.Interface
-	inClusterConfig, inClusterErr := rest.InClusterConfig()
-	if inClusterErr != nil {
-		err = NewCompareError("reference-config",
-			fmt.Errorf("in-cluster config not available: %w", inClusterErr),
-			"The MCP server must run inside a Kubernetes cluster to access reference ConfigMaps. "+
-				"Deploy reference ConfigMaps to the MCP server cluster namespace '"+referenceSource+"'.")
-		return newToolResultError(formatErrorForUser(err)), nil, nil
-	}
-	referenceClient, err = dynamic.NewForConfig(inClusterConfig)
-	if err != nil {
-		err = NewCompareError("reference-client",
-			fmt.Errorf("failed to create reference client: %w", err),
-			"Unable to connect to the MCP server cluster for reference ConfigMaps")
-		return newToolResultError(formatErrorForUser(err)), nil, nil
+		return newToolResultErrorFor(err), nil, nil
 	}
-	logger.Debug("Reference client created from in-cluster config for secure ConfigMap lookup")
+
+	// Stream per-host results when the client sent a progress token
+	progress := newBIOSProgressReporter(req, logger)
 
 	// Run the comparison
-	result, err := runBIOSComparison(ctx, targetClient, referenceClient, input.Namespace, input.HostName, referenceSource, input.ReferenceOverride, logger)
+	result, err := runBIOSComparison(ctx, targetClient, referenceClient, input.Namespace, input.HostName, referenceNamespaces, input.ReferenceOverride, progress, logger)
 	if err != nil {
-		return newToolResultError(formatErrorForUser(err)), nil, nil
+		return newToolResultErrorFor(err), nil, nil
 	}
 
 	// Format output
@@ -316,17 +482,80 @@ func HandleBIOSDiff(ctx context.Context, req *mcp.CallToolRequest, input BIOSDif
 	return newToolResultText(string(outputBytes)), result, nil
 }
 
+// buildBIOSClients creates the dynamic clients used by the BIOS tools.
+// The target client reads workload data (BMH, HardwareData, HostFirmware*) from the
+// hub cluster described by kubeconfig, or from the in-cluster config when kubeconfig is empty.
+// The reference client always uses the in-cluster config: reference ConfigMaps are ONLY
+// loaded from the MCP server cluster for security, so the server operator controls the
+// compliance baseline, not the user. The returned logger is tagged with the hub
+// cluster identity.
+func buildBIOSClients(ctx context.Context, kubeconfig, contextName string, referenceNamespaces []string, logger *slog.Logger) (dynamic.Interface, dynamic.Interface, *slog.Logger, error) {
+	targetClient, logger, err := buildBIOSTargetClient(ctx, kubeconfig, contextName, logger)
+	if err != nil {
+		return nil, nil, logger, err
+	}
+
+	inClusterConfig, err := resolveInClusterConfig(ctx, "reference-config",
+		"The MCP server must run inside a Kubernetes cluster to access reference ConfigMaps. "+
+			"Deploy reference ConfigMaps to the MCP server cluster namespace '"+strings.Join(referenceNamespaces, ", ")+"'.")
+	if err != nil {
+		return nil, nil, logger, err
+	}
+	referenceClient, err := dynamic.NewForConfig(inClusterConfig)
+	if err != nil {
+		return nil, nil, logger, NewCompareError("reference-client",
+			fmt.Errorf("failed to create reference client: %w", err),
+			"Unable to connect to the MCP server cluster for reference ConfigMaps")
+	}
+	logger.Debug("Reference client created from in-cluster config for secure ConfigMap lookup")
+
+	return targetClient, referenceClient, logger, nil
+}
+
+// buildBIOSTargetClient creates the dynamic client for the hub cluster described by
+// kubeconfig, or for the in-cluster config when kubeconfig is empty. It also returns
+// logger tagged with the hub cluster identity.
+func buildBIOSTargetClient(ctx context.Context, kubeconfig, contextName string, logger *slog.Logger) (dynamic.Interface, *slog.Logger, error) {
+	restConfig, err := buildTargetRestConfig(ctx, kubeconfig, contextName,
+		"No kubeconfig provided: provide a kubeconfig for the hub cluster.", logger)
+	if err != nil {
+		return nil, logger, err
+	}
+	logger = withClusterIdentity(logger, restConfig)
+
+	targetClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, logger, NewCompareError("cluster-client",
+			fmt.Errorf("failed to create dynamic client: %w", err),
+			"Verify the kubeconfig is valid")
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, logger, NewCompareError("cluster-client",
+			fmt.Errorf("failed to create discovery client: %w", err),
+			"Verify the kubeconfig is valid")
+	}
+	metal3TargetClient, err := newMetal3Client(targetClient, discoveryClient, logger)
+	if err != nil {
+		return nil, logger, NewCompareError("metal3-gvrs", err,
+			"Fix KUBE_COMPARE_MCP_METAL3_GVRS on the MCP server")
+	}
+	return metal3TargetClient, logger, nil
+}
+
 // runBIOSComparison performs the actual BIOS comparison logic.
 // targetClient is used for reading workload data (BMH, HardwareData, HostFirmware*) from the hub cluster.
 // referenceClient is used for reading reference ConfigMaps from the MCP server cluster.
+// progress, when not nil, receives each host result as it completes and the summary last.
 func runBIOSComparison(
 	ctx context.Context,
 	targetClient dynamic.Interface,
 	referenceClient dynamic.Interface,
 	namespace string,
 	hostName string,
-	referenceSource string,
+	referenceNamespaces []string,
 	referenceOverride string,
+	progress *biosProgressReporter,
 	logger *slog.Logger,
 ) (*BIOSDiffResult, error) {
 	// Get BMH resources from target cluster
@@ -372,7 +601,7 @@ func runBIOSComparison(
 	}
 
 	for _, bmh := range bmhList.Items {
-		hostResult := compareBMHBIOS(ctx, targetClient, referenceClient, &bmh, referenceSource, referenceOverride, logger)
+		hostResult := compareBMHBIOS(ctx, targetClient, referenceClient, &bmh, referenceNamespaces, referenceOverride, logger)
 		result.Hosts = append(result.Hosts, hostResult)
 
 		switch {
@@ -383,8 +612,12 @@ func runBIOSComparison(
 		default:
 			result.Summary.NumDiffHosts++
 		}
+
+		progress.hostCompleted(ctx, hostResult, len(result.Hosts), len(bmhList.Items))
 	}
 
+	progress.finished(ctx, result.Summary)
+
 	return result, nil
 }
 
@@ -396,7 +629,7 @@ func compareBMHBIOS(
 	targetClient dynamic.Interface,
 	referenceClient dynamic.Interface,
 	bmh *unstructured.Unstructured,
-	refSourceNamespace string,
+	referenceNamespaces []string,
 	refOverride string,
 	logger *slog.Logger,
 ) HostBIOSResult {
@@ -408,30 +641,24 @@ func compareBMHBIOS(
 		Namespace: namespace,
 	}
 
-	// Get role from annotation
-	annotations := bmh.GetAnnotations()
-	role := annotations[BMHRoleAnnotation]
-	if role == "" {
-		role = "worker" // Default to worker if not specified
-		logger.Warn("No role annotation found, defaulting to worker", "bmh", name)
+	role, err := resolveBMHRole(ctx, targetClient, bmh, logger)
+	if err != nil {
+		result.Error = err.Error()
+		logger.Debug("Failed to resolve BMH role", "bmh", name, "error", err)
+		return result
 	}
 	result.Role = role
 
 	// Get HardwareData for server model from target cluster
-	hardwareData, err := targetClient.Resource(hardwareDataGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	serverModel, err := getServerModel(ctx, targetClient, namespace, name)
 	if err != nil {
-		result.Error = fmt.Sprintf("failed to get HardwareData: %v", err)
+		result.Error = err.Error()
 		logger.Debug("Failed to get HardwareData", "bmh", name, "error", err)
 		return result
 	}
-
-	manufacturer, _, _ := unstructured.NestedString(hardwareData.Object, "spec", "hardware", "systemVendor", "manufacturer")
-	productName, _, _ := unstructured.NestedString(hardwareData.Object, "spec", "hardware", "systemVendor", "productName")
-
-	result.ServerModel = ServerModelInfo{
-		Manufacturer: manufacturer,
-		ProductName:  productName,
-	}
+	result.ServerModel = serverModel
+	manufacturer := serverModel.Manufacturer
+	productName := serverModel.ProductName
 
 	// Get HostFirmwareComponents for BIOS version from target cluster
 	firmwareComponents, err := targetClient.Resource(hostFirmwareComponentsGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
@@ -458,7 +685,7 @@ func compareBMHBIOS(
 	var configMapName string
 
 	refConfigMap, configMapName, err = findReferenceConfigMap(
-		ctx, referenceClient, refSourceNamespace, refOverride,
+		ctx, referenceClient, referenceNamespaces, refOverride,
 		manufacturer, productName, role, logger,
 	)
 	if err != nil {
@@ -466,6 +693,7 @@ func compareBMHBIOS(
 		return result
 	}
 	result.Reference = configMapName
+	result.ReferenceNamespace = refConfigMap.GetNamespace()
 	result.ReferenceSource = ReferenceSourceMCPServer
 
 	// Extract reference values from ConfigMap
@@ -496,6 +724,102 @@ func compareBMHBIOS(
 	return result
 }
 
+// resolveBMHRole returns the node role of a BareMetalHost. The configured role sources
+// (by default the role annotation) are tried in order and the first one set wins;
+// otherwise, for a host that is a node of its own cluster (see bmhIsClusterNode), the
+// role is derived from the node-role.kubernetes.io/* labels of the Node with the same
+// name, if there is one. When neither is available the host defaults to worker, or
+// fails in strict mode (KUBE_COMPARE_MCP_BIOS_MISSING_ROLE=error).
+func resolveBMHRole(ctx context.Context, targetClient dynamic.Interface, bmh *unstructured.Unstructured, logger *slog.Logger) (string, error) {
+	sources, err := getBMHRoleSources()
+	if err != nil {
+		return "", err
+	}
+	for i, source := range sources {
+		if role := source.lookup(bmh); role != "" {
+			if i > 0 {
+				logger.Info("Using role from a later role source", "bmh", bmh.GetName(), "source", source.String(), "role", role)
+			}
+			return role, nil
+		}
+	}
+
+	if bmhIsClusterNode(bmh) {
+		if role := roleFromNodeLabels(ctx, targetClient, bmh.GetName(), logger); role != "" {
+			logger.Info("No role annotation found, using role from node labels", "bmh", bmh.GetName(), "role", role)
+			return role, nil
+		}
+	}
+
+	described := make([]string, len(sources))
+	for i, source := range sources {
+		described[i] = source.String()
+	}
+	if getMissingRolePolicy() == MissingRoleError {
+		return "", fmt.Errorf("BareMetalHost %s/%s has no %s and no matching Node with a node-role label; "+
+			"set the role so the correct reference ConfigMap is used",
+			bmh.GetNamespace(), bmh.GetName(), strings.Join(described, ", "))
+	}
+
+	logger.Warn("No role annotation found, defaulting to worker", "bmh", bmh.GetName(), "sources", described)
+	return "worker", nil
+}
+
+// machineAPINamespace is the namespace of the BareMetalHosts that back the nodes of the
+// cluster they are defined in.
+const machineAPINamespace = "openshift-machine-api"
+
+// bmhIsClusterNode reports whether bmh backs a node of the cluster it is defined in, so
+// a Node with its name there is its own: the host is in openshift-machine-api or is
+// consumed by a Machine API Machine. A host on a hub, such as one provisioned through
+// ZTP, backs a node of a spoke cluster, and a Node with the same name on the hub is
+// another host.
+func bmhIsClusterNode(bmh *unstructured.Unstructured) bool {
+	if bmh.GetNamespace() == machineAPINamespace {
+		return true
+	}
+	kind, _, _ := unstructured.NestedString(bmh.Object, "spec", "consumerRef", "kind")
+	return kind == "Machine"
+}
+
+// roleFromNodeLabels derives a role from the node-role.kubernetes.io/* labels of the named
+// Node. Control plane labels map to master, matching the role annotation values. It returns
+// "" when the Node cannot be read or has no role label.
+func roleFromNodeLabels(ctx context.Context, targetClient dynamic.Interface, nodeName string, logger *slog.Logger) string {
+	node, err := targetClient.Resource(nodeGVR).Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		logger.Debug("No node found to derive role from", "node", nodeName, "error", err)
+		return ""
+	}
+
+	labels := node.GetLabels()
+	for _, label := range []string{"node-role.kubernetes.io/master", "node-role.kubernetes.io/control-plane"} {
+		if _, ok := labels[label]; ok {
+			return "master"
+		}
+	}
+	if _, ok := labels["node-role.kubernetes.io/worker"]; ok {
+		return "worker"
+	}
+	return ""
+}
+
+// getServerModel reads the server manufacturer and product name from the host's HardwareData.
+func getServerModel(ctx context.Context, client dynamic.Interface, namespace, name string) (ServerModelInfo, error) {
+	hardwareData, err := client.Resource(hardwareDataGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return ServerModelInfo{}, fmt.Errorf("failed to get HardwareData: %w", err)
+	}
+
+	manufacturer, _, _ := unstructured.NestedString(hardwareData.Object, "spec", "hardware", "systemVendor", "manufacturer")
+	productName, _, _ := unstructured.NestedString(hardwareData.Object, "spec", "hardware", "systemVendor", "productName")
+
+	return ServerModelInfo{
+		Manufacturer: manufacturer,
+		ProductName:  productName,
+	}, nil
+}
+
 // extractBIOSVersion extracts the BIOS version from HostFirmwareComponents.
 func extractBIOSVersion(hfc *unstructured.Unstructured) string {
 	components, found, err := unstructured.NestedSlice(hfc.Object, "status", "components")
@@ -526,15 +850,18 @@ func extractBIOSSettings(hfs *unstructured.Unstructured) map[string]string {
 	return settings
 }
 
-// findReferenceConfigMap finds a reference ConfigMap from the MCP server cluster.
+// findReferenceConfigMap finds a reference ConfigMap from the MCP server cluster,
+// searching referenceNamespaces in order.
 // If explicitConfigMap is set, looks for that specific ConfigMap.
-// Otherwise, tries exact name match then label-based best match.
+// Otherwise, tries exact name match then label-based best match; the first namespace
+// holding the ConfigMap wins either named lookup, while label-based matching scores
+// the candidates of all namespaces together.
 // Reference ConfigMaps are only loaded from the MCP server cluster for security -
 // this ensures the server operator controls the compliance baseline, not the user.
 func findReferenceConfigMap(
 	ctx context.Context,
 	referenceClient dynamic.Interface,
-	referenceNamespace string,
+	referenceNamespaces []string,
 	explicitConfigMap string,
 	manufacturer string,
 	productName string,
@@ -542,35 +869,59 @@ func findReferenceConfigMap(
 	logger *slog.Logger,
 ) (*unstructured.Unstructured, string, error) {
 	if explicitConfigMap != "" {
-		refConfigMap, err := referenceClient.Resource(configMapGVR).Namespace(referenceNamespace).Get(ctx, explicitConfigMap, metav1.GetOptions{})
+		refConfigMap, err := getReferenceConfigMap(ctx, referenceClient, referenceNamespaces, explicitConfigMap)
 		if err != nil {
-			return nil, "", fmt.Errorf("reference override ConfigMap %q not found in namespace %q: %w", explicitConfigMap, referenceNamespace, err)
+			return nil, "", fmt.Errorf("reference override ConfigMap %q not found in namespace %q: %w",
+				explicitConfigMap, strings.Join(referenceNamespaces, ","), err)
 		}
-		logger.Info("Found reference ConfigMap on MCP server cluster", "configmap", explicitConfigMap, "namespace", referenceNamespace)
+		logger.Info("Found reference ConfigMap on MCP server cluster", "configmap", explicitConfigMap, "namespace", refConfigMap.GetNamespace())
 		return refConfigMap, explicitConfigMap, nil
 	}
 
 	// Auto-match: try exact name match first
 	configMapName := buildReferenceConfigMapName(manufacturer, productName, role)
-	refConfigMap, err := referenceClient.Resource(configMapGVR).Namespace(referenceNamespace).Get(ctx, configMapName, metav1.GetOptions{})
+	refConfigMap, err := getReferenceConfigMap(ctx, referenceClient, referenceNamespaces, configMapName)
 	if err == nil {
-		logger.Info("Found reference ConfigMap on MCP server cluster", "configmap", configMapName, "namespace", referenceNamespace)
+		logger.Info("Found reference ConfigMap on MCP server cluster", "configmap", configMapName, "namespace", refConfigMap.GetNamespace())
 		return refConfigMap, configMapName, nil
 	}
 
 	// Fall back to label-based best match
 	exactMatchName := configMapName
 	logger.Debug("Exact ConfigMap match not found, trying label-based match", "tried", exactMatchName)
-	refConfigMap, matchedName, err := findBestMatchConfigMap(ctx, referenceClient, referenceNamespace, manufacturer, productName, role, logger)
+	refConfigMap, matchedName, err := findBestMatchConfigMap(ctx, referenceClient, referenceNamespaces, manufacturer, productName, role, logger)
 	if err != nil {
 		return nil, "", fmt.Errorf("no matching reference ConfigMap found for vendor=%s role=%s (tried exact: %s) on MCP server cluster: %w",
 			manufacturer, role, exactMatchName, err)
 	}
 
-	logger.Info("Found reference ConfigMap on MCP server cluster", "configmap", matchedName, "namespace", referenceNamespace)
+	logger.Info("Found reference ConfigMap on MCP server cluster", "configmap", matchedName, "namespace", refConfigMap.GetNamespace())
 	return refConfigMap, matchedName, nil
 }
 
+// getReferenceConfigMap returns the ConfigMap called name from the first of
+// referenceNamespaces that has one. When none does, the error of the last lookup is
+// returned; any other lookup error ends the search.
+func getReferenceConfigMap(
+	ctx context.Context,
+	referenceClient dynamic.Interface,
+	referenceNamespaces []string,
+	name string,
+) (*unstructured.Unstructured, error) {
+	var err error
+	for _, namespace := range referenceNamespaces {
+		var refConfigMap *unstructured.Unstructured
+		refConfigMap, err = referenceClient.Resource(configMapGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			return refConfigMap, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
 // buildReferenceConfigMapName constructs the ConfigMap name from server info.
 // Format: bios-ref-<manufacturer>-<model>-<role>
 func buildReferenceConfigMapName(manufacturer, productName, role string) string {
@@ -580,76 +931,163 @@ func buildReferenceConfigMapName(manufacturer, productName, role string) string
 	return fmt.Sprintf("bios-ref-%s-%s-%s", mfr, model, role)
 }
 
-// findBestMatchConfigMap searches for a ConfigMap matching vendor, role, and model using labels.
-// Uses score-based matching to find the best model match.
+// scoredConfigMap is a reference ConfigMap candidate with its model similarity score.
+type scoredConfigMap struct {
+	configMap  *unstructured.Unstructured
+	modelLabel string
+	score      float64
+}
+
+// referenceLabelSelector builds the label selector used to list reference ConfigMaps
+// for a vendor and role. Both are normalized since labels can't contain spaces or special chars.
+func referenceLabelSelector(keys referenceLabelKeys, manufacturer, role string) string {
+	vendor := normalizeForK8sName(manufacturer, validation.DNS1123LabelMaxLength)
+	normalizedRole := normalizeForK8sName(role, validation.DNS1123LabelMaxLength)
+	return fmt.Sprintf("%s=%s,%s=%s", keys.Vendor, vendor, keys.Role, normalizedRole)
+}
+
+// scoreReferenceCandidates lists the ConfigMaps matching the vendor and role labels in
+// each of referenceNamespaces and scores the model label, modelLabelKey, of each one
+// against the product name. Candidates are returned in namespace order.
+func scoreReferenceCandidates(
+	ctx context.Context,
+	client dynamic.Interface,
+	referenceNamespaces []string,
+	labelSelector string,
+	modelLabelKey string,
+	productName string,
+	logger *slog.Logger,
+) ([]scoredConfigMap, error) {
+	var candidates []scoredConfigMap
+	for _, namespace := range referenceNamespaces {
+		configMaps, err := client.Resource(configMapGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ConfigMaps with selector %s in namespace %s: %w", labelSelector, namespace, err)
+		}
+
+		for i := range configMaps.Items {
+			cm := &configMaps.Items[i]
+			modelLabel := cm.GetLabels()[modelLabelKey]
+
+			score := scoreModelMatch(productName, modelLabel)
+			logger.Debug("Scoring ConfigMap",
+				"configmap", cm.GetName(),
+				"namespace", namespace,
+				"modelLabel", modelLabel,
+				"productName", productName,
+				"score", score,
+			)
+
+			candidates = append(candidates, scoredConfigMap{configMap: cm, modelLabel: modelLabel, score: score})
+		}
+	}
+
+	return candidates, nil
+}
+
+// bestCandidate returns the highest scoring candidate, or nil if there are none.
+// Ties are broken by ConfigMap name so the result does not depend on list order,
+// and between ConfigMaps of the same name by namespace order.
+func bestCandidate(candidates []scoredConfigMap) *scoredConfigMap {
+	var best *scoredConfigMap
+	for i := range candidates {
+		c := &candidates[i]
+		if best == nil || c.score > best.score ||
+			(c.score == best.score && c.configMap.GetName() < best.configMap.GetName()) {
+			best = c
+		}
+	}
+	return best
+}
+
+// tiedCandidates returns the sorted names of all candidates sharing the best score.
+// Names are qualified as namespace/name when the tied candidates span namespaces.
+func tiedCandidates(candidates []scoredConfigMap, best *scoredConfigMap) []string {
+	var tied []*unstructured.Unstructured
+	for _, c := range candidates {
+		if c.score == best.score {
+			tied = append(tied, c.configMap)
+		}
+	}
+	qualify := slices.ContainsFunc(tied, func(cm *unstructured.Unstructured) bool {
+		return cm.GetNamespace() != best.configMap.GetNamespace()
+	})
+
+	names := make([]string, 0, len(tied))
+	for _, cm := range tied {
+		if qualify {
+			names = append(names, cm.GetNamespace()+"/"+cm.GetName())
+		} else {
+			names = append(names, cm.GetName())
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// findBestMatchConfigMap searches referenceNamespaces for a ConfigMap matching vendor,
+// role, and model using labels. Uses score-based matching to find the best model match
+// among the candidates of all namespaces.
 // Returns the ConfigMap, its name, and any error.
 func findBestMatchConfigMap(
 	ctx context.Context,
 	client dynamic.Interface,
-	referenceNamespace string,
+	referenceNamespaces []string,
 	manufacturer string,
 	productName string,
 	role string,
 	logger *slog.Logger,
 ) (*unstructured.Unstructured, string, error) {
-	// Normalize vendor and role for label matching (labels can't contain spaces or special chars)
-	vendor := normalizeForK8sName(manufacturer, validation.DNS1123LabelMaxLength)
-	normalizedRole := normalizeForK8sName(role, validation.DNS1123LabelMaxLength)
-
-	// List ConfigMaps with matching vendor and role labels
-	labelSelector := fmt.Sprintf("bios-reference/vendor=%s,bios-reference/role=%s", vendor, normalizedRole)
-	configMaps, err := client.Resource(configMapGVR).Namespace(referenceNamespace).List(ctx, metav1.ListOptions{
-		LabelSelector: labelSelector,
-	})
+	keys, err := getReferenceLabelKeys()
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to list ConfigMaps with selector %s: %w", labelSelector, err)
+		return nil, "", err
+	}
+	labelSelector := referenceLabelSelector(keys, manufacturer, role)
+	candidates, err := scoreReferenceCandidates(ctx, client, referenceNamespaces, labelSelector, keys.Model, productName, logger)
+	if err != nil {
+		return nil, "", err
 	}
 
-	if len(configMaps.Items) == 0 {
+	best := bestCandidate(candidates)
+	if best == nil {
+		vendor := normalizeForK8sName(manufacturer, validation.DNS1123LabelMaxLength)
 		return nil, "", fmt.Errorf("no ConfigMaps found matching vendor=%s role=%s", vendor, role)
 	}
 
-	// Score each ConfigMap based on model match and pick the best one
-	var bestMatch *unstructured.Unstructured
-	var bestName string
-	bestScore := -1.0
-
-	for i := range configMaps.Items {
-		cm := &configMaps.Items[i]
-		labels := cm.GetLabels()
-		modelLabel := labels["bios-reference/model"]
-
-		score := scoreModelMatch(productName, modelLabel)
-		logger.Debug("Scoring ConfigMap",
-			"configmap", cm.GetName(),
-			"modelLabel", modelLabel,
-			"productName", productName,
-			"score", score,
+	if best.score < minModelSimilarity {
+		return nil, "", fmt.Errorf(
+			"no ConfigMap model label is similar enough to %q (best score: %.2f, threshold: %.2f)",
+			productName, best.score, minModelSimilarity,
 		)
-
-		if score > bestScore {
-			bestScore = score
-			bestMatch = cm
-			bestName = cm.GetName()
-		}
 	}
 
-	if bestScore < minModelSimilarity {
-		return nil, "", fmt.Errorf(
-			"no ConfigMap model label is similar enough to %q (best score: %.2f, threshold: %.2f)",
-			productName, bestScore, minModelSimilarity,
+	if tied := tiedCandidates(candidates, best); len(tied) > 1 {
+		if getAmbiguousMatchPolicy() == AmbiguousMatchError {
+			return nil, "", fmt.Errorf(
+				"ambiguous reference match: ConfigMaps %s all match %q with score %.2f; "+
+					"adjust their %s labels so only one matches, or use reference_override",
+				strings.Join(tied, ", "), productName, best.score, keys.Model,
+			)
+		}
+		logger.Warn("Multiple reference ConfigMaps tie for best match, using the first by name",
+			"configmaps", tied,
+			"selected", best.configMap.GetName(),
+			"score", best.score,
 		)
 	}
 
 	logger.Info("Found best matching reference ConfigMap via labels",
-		"configmap", bestName,
-		"vendor", vendor,
+		"configmap", best.configMap.GetName(),
+		"namespace", best.configMap.GetNamespace(),
+		"selector", labelSelector,
 		"role", role,
-		"score", bestScore,
-		"totalCandidates", len(configMaps.Items),
+		"score", best.score,
+		"totalCandidates", len(candidates),
 	)
 
-	return bestMatch, bestName, nil
+	return best.configMap, best.configMap.GetName(), nil
 }
 
 // scoreModelMatch calculates a similarity score between a product name and a model
@@ -693,9 +1131,82 @@ func normalizeForK8sName(s string, maxLen int) string {
 	return s
 }
 
-// parseSettingsYAML parses the settings YAML string from ConfigMap.
-// Format is simple key: value pairs, one per line.
+// parseSettingsYAML parses the settings string from a reference ConfigMap.
+// Settings authored as a YAML mapping, including quoted, multi-line, nested, and
+// anchored values, are read with a YAML parser; nested keys are joined with ".".
+// Anything else falls back to the simple format of one key: value pair per line.
 func parseSettingsYAML(settingsStr string) map[string]string {
+	if settings, ok := parseSettingsMapping(settingsStr); ok {
+		return settings
+	}
+	return parseSettingsLines(settingsStr)
+}
+
+// parseSettingsMapping parses settingsStr as a YAML mapping. Scalars keep their literal
+// text, so values such as Off or 010 are not turned into booleans or numbers, which
+// sigs.k8s.io/yaml's JSON conversion would do. It reports false if settingsStr is not
+// a YAML mapping of scalars and mappings.
+func parseSettingsMapping(settingsStr string) (map[string]string, bool) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(settingsStr), &doc); err != nil {
+		return nil, false
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 {
+		return nil, false
+	}
+
+	settings := make(map[string]string)
+	if !flattenSettingsNode(doc.Content[0], "", settings) {
+		return nil, false
+	}
+	return settings, true
+}
+
+// flattenSettingsNode adds the scalars under a mapping node to settings, prefixing
+// nested keys with their parent keys. Aliases are followed and merge keys (<<) are
+// expanded in place.
+func flattenSettingsNode(node *yaml.Node, prefix string, settings map[string]string) bool {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+
+		if key.Tag == "!!merge" {
+			if !flattenSettingsNode(value, prefix, settings) {
+				return false
+			}
+			continue
+		}
+
+		name := key.Value
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		switch value.Kind {
+		case yaml.ScalarNode:
+			settings[name] = strings.TrimSpace(value.Value)
+		case yaml.MappingNode:
+			if !flattenSettingsNode(value, name, settings) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseSettingsLines parses the simple settings format: key: value pairs, one per line.
+func parseSettingsLines(settingsStr string) map[string]string {
 	settings := make(map[string]string)
 	if settingsStr == "" {
 		return settings
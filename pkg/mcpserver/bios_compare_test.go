@@ -3,6 +3,7 @@
 package mcpserver
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
@@ -28,6 +29,7 @@ var biosTestGVRToListKind = map[schema.GroupVersionResource]string{
 	{Group: "metal3.io", Version: "v1alpha1", Resource: "hostfirmwarecomponents"}: "HostFirmwareComponentsList",
 	{Group: "metal3.io", Version: "v1alpha1", Resource: "hostfirmwaresettings"}:   "HostFirmwareSettingsList",
 	{Group: "", Version: "v1", Resource: "configmaps"}:                            "ConfigMapList",
+	{Group: "", Version: "v1", Resource: "nodes"}:                                 "NodeList",
 }
 
 func newBIOSTestFakeDynamicClient(objects ...runtime.Object) dynamic.Interface {
@@ -199,6 +201,47 @@ var _ = Describe("BIOSCompare", func() {
 			settings := parseSettingsYAML("")
 			Expect(settings).To(BeEmpty())
 		})
+
+		It("unquotes quoted values", func() {
+			settings := parseSettingsYAML("BootMode: \"Uefi\"\nLogicalProc: 'Enabled'")
+			Expect(settings).To(Equal(map[string]string{"BootMode": "Uefi", "LogicalProc": "Enabled"}))
+		})
+
+		It("keeps colons inside quoted values", func() {
+			settings := parseSettingsYAML("PxeServer: \"10.0.0.1:69\"\nURL: http://example.com:8080")
+			Expect(settings["PxeServer"]).To(Equal("10.0.0.1:69"))
+			Expect(settings["URL"]).To(Equal("http://example.com:8080"))
+		})
+
+		It("keeps the literal text of values YAML would convert", func() {
+			settings := parseSettingsYAML("TurboMode: Off\nSriovGlobalEnable: Yes\nBootOrder: 010")
+			Expect(settings).To(Equal(map[string]string{"TurboMode": "Off", "SriovGlobalEnable": "Yes", "BootOrder": "010"}))
+		})
+
+		It("reads multi-line and nested values", func() {
+			settings := parseSettingsYAML("Banner: >-\n  Managed by\n  ZTP\nProcessor:\n  CStates: Disabled\n  Turbo: Enabled\n")
+			Expect(settings).To(Equal(map[string]string{
+				"Banner":            "Managed by ZTP",
+				"Processor.CStates": "Disabled",
+				"Processor.Turbo":   "Enabled",
+			}))
+		})
+
+		It("follows anchors, aliases, and merge keys", func() {
+			settings := parseSettingsYAML("base: &base\n  BootMode: Uefi\nnode:\n  <<: *base\n  Turbo: Enabled\nalias: *base\n")
+			Expect(settings).To(HaveKeyWithValue("node.BootMode", "Uefi"))
+			Expect(settings).To(HaveKeyWithValue("node.Turbo", "Enabled"))
+			Expect(settings).To(HaveKeyWithValue("alias.BootMode", "Uefi"))
+		})
+
+		It("falls back to the legacy flat format when the input is not a YAML mapping", func() {
+			settings := parseSettingsYAML("ProcVirtualization: Enabled\nBootMode:UEFI\nBadLine\nTag: [x")
+			Expect(settings).To(Equal(map[string]string{
+				"ProcVirtualization": "Enabled",
+				"BootMode":           "UEFI",
+				"Tag":                "[x",
+			}))
+		})
 	})
 
 	Describe("compareBIOSSettings", func() {
@@ -337,7 +380,7 @@ var _ = Describe("BIOSCompare", func() {
 			targetClient := newBIOSTestFakeDynamicClient()
 			referenceClient := newBIOSTestFakeDynamicClient()
 
-			_, err := runBIOSComparison(ctx, targetClient, referenceClient, "test-ns", "", "reference-configs", "", discardLogger)
+			_, err := runBIOSComparison(ctx, targetClient, referenceClient, "test-ns", "", []string{"reference-configs"}, "", nil, discardLogger)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("no BareMetalHosts"))
 		})
@@ -346,9 +389,185 @@ var _ = Describe("BIOSCompare", func() {
 			targetClient := newBIOSTestFakeDynamicClient()
 			referenceClient := newBIOSTestFakeDynamicClient()
 
-			_, err := runBIOSComparison(ctx, targetClient, referenceClient, "test-ns", "nonexistent-host", "reference-configs", "", discardLogger)
+			_, err := runBIOSComparison(ctx, targetClient, referenceClient, "test-ns", "nonexistent-host", []string{"reference-configs"}, "", nil, discardLogger)
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("streams each host result before the final summary", func() {
+			targetClient := newBIOSTestFakeDynamicClient(
+				newTestBareMetalHost("node-0", "test-ns", "master"),
+				newTestBareMetalHost("node-1", "test-ns", "worker"),
+			)
+			referenceClient := newBIOSTestFakeDynamicClient()
+			recorder := &recordingNotifier{}
+			progress := &biosProgressReporter{notifier: recorder, token: "tok-1", logger: discardLogger}
+
+			result, err := runBIOSComparison(ctx, targetClient, referenceClient, "test-ns", "", []string{"reference-configs"}, "", progress, discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.sent).To(HaveLen(3))
+
+			for i, params := range recorder.sent {
+				Expect(params.ProgressToken).To(Equal("tok-1"))
+				Expect(params.Progress).To(BeEquivalentTo(min(i+1, 2)))
+				Expect(params.Total).To(BeEquivalentTo(2))
+			}
+
+			var names []string
+			for _, params := range recorder.sent[:2] {
+				var chunk BIOSProgressChunk
+				Expect(json.Unmarshal([]byte(params.Message), &chunk)).To(Succeed())
+				Expect(chunk.Host).NotTo(BeNil())
+				Expect(chunk.Summary).To(BeNil())
+				names = append(names, chunk.Host.Name)
+			}
+			Expect(names).To(ConsistOf("node-0", "node-1"))
+
+			var last BIOSProgressChunk
+			Expect(json.Unmarshal([]byte(recorder.sent[2].Message), &last)).To(Succeed())
+			Expect(last.Host).To(BeNil())
+			Expect(last.Summary).To(Equal(&result.Summary))
+		})
+
+		It("does not report progress without a reporter", func() {
+			Expect(newBIOSProgressReporter(nil, discardLogger)).To(BeNil())
+			Expect(newBIOSProgressReporter(&mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{}}, discardLogger)).To(BeNil())
+		})
+	})
+
+	Describe("resolveBMHRole", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_MISSING_ROLE", "")
+		})
+
+		It("uses the role annotation when set", func() {
+			client := newBIOSTestFakeDynamicClient(newTestNode("node-0", "node-role.kubernetes.io/worker"))
+			role, err := resolveBMHRole(ctx, client, newTestBareMetalHost("node-0", "spoke", "master"), discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role).To(Equal("master"))
+		})
+
+		DescribeTable("derives the role from node labels when the annotation is missing",
+			func(label, expected string) {
+				client := newBIOSTestFakeDynamicClient(newTestNode("node-0", label))
+				role, err := resolveBMHRole(ctx, client, newTestBareMetalHost("node-0", machineAPINamespace, ""), discardLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(role).To(Equal(expected))
+			},
+			Entry("control-plane", "node-role.kubernetes.io/control-plane", "master"),
+			Entry("master", "node-role.kubernetes.io/master", "master"),
+			Entry("worker", "node-role.kubernetes.io/worker", "worker"),
+		)
+
+		It("derives the role from node labels for a host consumed by a Machine", func() {
+			bmh := newTestBareMetalHost("node-0", "hosts", "")
+			Expect(unstructured.SetNestedField(bmh.Object, "Machine", "spec", "consumerRef", "kind")).To(Succeed())
+			client := newBIOSTestFakeDynamicClient(newTestNode("node-0", "node-role.kubernetes.io/control-plane"))
+
+			role, err := resolveBMHRole(ctx, client, bmh, discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role).To(Equal("master"))
+		})
+
+		It("ignores a Node with the name of a host that backs another cluster", func() {
+			client := newBIOSTestFakeDynamicClient(newTestNode("node-0", "node-role.kubernetes.io/control-plane"))
+			role, err := resolveBMHRole(ctx, client, newTestBareMetalHost("node-0", "spoke", ""), discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role).To(Equal("worker"))
+		})
+
+		It("defaults to worker when no role can be found", func() {
+			client := newBIOSTestFakeDynamicClient()
+			role, err := resolveBMHRole(ctx, client, newTestBareMetalHost("node-0", "spoke", ""), discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role).To(Equal("worker"))
+		})
+
+		Context("with configured role sources", func() {
+			BeforeEach(func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES",
+					"annotation:"+BMHRoleAnnotation+", label:ztp.example.com/role")
+			})
+
+			It("uses the first source that is set", func() {
+				bmh := newTestBareMetalHost("node-0", "spoke", "")
+				bmh.SetLabels(map[string]string{"ztp.example.com/role": "master"})
+				client := newBIOSTestFakeDynamicClient(newTestNode("node-0", "node-role.kubernetes.io/worker"))
+
+				role, err := resolveBMHRole(ctx, client, bmh, discardLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(role).To(Equal("master"))
+			})
+
+			It("prefers an earlier source", func() {
+				bmh := newTestBareMetalHost("node-0", "spoke", "worker")
+				bmh.SetLabels(map[string]string{"ztp.example.com/role": "master"})
+
+				role, err := resolveBMHRole(ctx, newBIOSTestFakeDynamicClient(), bmh, discardLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(role).To(Equal("worker"))
+			})
+
+			It("defaults to worker when no source is set", func() {
+				role, err := resolveBMHRole(ctx, newBIOSTestFakeDynamicClient(), newTestBareMetalHost("node-0", "spoke", ""), discardLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(role).To(Equal("worker"))
+			})
+
+			It("names every source in strict mode when none is set", func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_MISSING_ROLE", "error")
+
+				_, err := resolveBMHRole(ctx, newBIOSTestFakeDynamicClient(), newTestBareMetalHost("node-0", "spoke", ""), discardLogger)
+				Expect(err).To(MatchError(ContainSubstring(
+					"has no " + BMHRoleAnnotation + " annotation, ztp.example.com/role label")))
+			})
+		})
+
+		DescribeTable("rejects invalid role sources",
+			func(sources string) {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES", sources)
+				_, err := resolveBMHRole(ctx, newBIOSTestFakeDynamicClient(), newTestBareMetalHost("node-0", "spoke", "master"), discardLogger)
+				Expect(err).To(MatchError(ContainSubstring("KUBE_COMPARE_MCP_BIOS_ROLE_SOURCES")))
+			},
+			Entry("missing kind", "ztp.example.com/role"),
+			Entry("unknown kind", "field:spec.role"),
+			Entry("invalid key", "label:not a key"),
+			Entry("no entries", " , "),
+		)
+
+		Context("in strict mode", func() {
+			BeforeEach(func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_MISSING_ROLE", "error")
+			})
+
+			It("errors for a host that backs another cluster", func() {
+				client := newBIOSTestFakeDynamicClient(newTestNode("node-0", "node-role.kubernetes.io/control-plane"))
+				_, err := resolveBMHRole(ctx, client, newTestBareMetalHost("node-0", "spoke", ""), discardLogger)
+				Expect(err).To(MatchError(ContainSubstring("has no " + BMHRoleAnnotation + " annotation")))
+			})
+
+			It("errors when no role can be found", func() {
+				client := newBIOSTestFakeDynamicClient(newTestNode("node-0"))
+				_, err := resolveBMHRole(ctx, client, newTestBareMetalHost("node-0", "spoke", ""), discardLogger)
+				Expect(err).To(MatchError(ContainSubstring("has no " + BMHRoleAnnotation + " annotation")))
+			})
+
+			It("still derives the role from node labels", func() {
+				client := newBIOSTestFakeDynamicClient(newTestNode("node-0", "node-role.kubernetes.io/control-plane"))
+				role, err := resolveBMHRole(ctx, client, newTestBareMetalHost("node-0", machineAPINamespace, ""), discardLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(role).To(Equal("master"))
+			})
+
+			It("reports the error on the host result", func() {
+				client := newBIOSTestFakeDynamicClient()
+				result := compareBMHBIOS(ctx, client, client, newTestBareMetalHost("node-0", "spoke", ""), []string{"reference-configs"}, "", discardLogger)
+				Expect(result.Error).To(ContainSubstring("has no " + BMHRoleAnnotation + " annotation"))
+				Expect(result.Compliant).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("findBestMatchConfigMap", func() {
@@ -363,7 +582,7 @@ var _ = Describe("BIOSCompare", func() {
 				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
 			client := newBIOSTestFakeDynamicClient(cm)
 
-			result, name, err := findBestMatchConfigMap(ctx, client, "reference-configs", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			result, name, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(name).To(Equal("bios-ref-dell-poweredge-r750-master"))
 			Expect(result).NotTo(BeNil())
@@ -374,7 +593,7 @@ var _ = Describe("BIOSCompare", func() {
 				"hpe", "proliant-dl380", "master", "2.1.0", "")
 			client := newBIOSTestFakeDynamicClient(cm)
 
-			_, _, err := findBestMatchConfigMap(ctx, client, "reference-configs", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			_, _, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("no ConfigMaps found"))
 		})
@@ -384,7 +603,7 @@ var _ = Describe("BIOSCompare", func() {
 				"dell-inc", "completely-different-xyz", "master", "2.1.0", "")
 			client := newBIOSTestFakeDynamicClient(cm)
 
-			_, _, err := findBestMatchConfigMap(ctx, client, "reference-configs", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			_, _, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("similar enough"))
 		})
@@ -396,13 +615,202 @@ var _ = Describe("BIOSCompare", func() {
 				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
 			client := newBIOSTestFakeDynamicClient(cm1, cm2)
 
-			_, name, err := findBestMatchConfigMap(ctx, client, "reference-configs", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			_, name, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(name).To(Equal("bios-ref-dell-poweredge-r750-master"))
 		})
+
+		Context("when two ConfigMaps tie for the best score", func() {
+			var client dynamic.Interface
+
+			BeforeEach(func() {
+				cm1 := newTestReferenceConfigMap("dell-r750-master-b", "reference-configs",
+					"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+				cm2 := newTestReferenceConfigMap("dell-r750-master-a", "reference-configs",
+					"dell-inc", "poweredge-r750", "master", "2.2.0", "")
+				client = newBIOSTestFakeDynamicClient(cm1, cm2)
+			})
+
+			It("returns an ambiguous match error listing the tied ConfigMaps when configured", func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_AMBIGUOUS_MATCH", AmbiguousMatchError)
+
+				_, _, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+				Expect(err).To(MatchError(ContainSubstring("ambiguous reference match")))
+				Expect(err.Error()).To(ContainSubstring("dell-r750-master-a, dell-r750-master-b"))
+			})
+
+			It("picks the lexicographically first ConfigMap by default", func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_AMBIGUOUS_MATCH", "")
+
+				_, name, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(name).To(Equal("dell-r750-master-a"))
+			})
+		})
+
+		Context("with custom label keys", func() {
+			BeforeEach(func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_VENDOR_LABEL", "hardware.example.com/vendor")
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_MODEL_LABEL", "hardware.example.com/model")
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_ROLE_LABEL", "hardware.example.com/role")
+			})
+
+			It("matches ConfigMaps using the configured keys", func() {
+				cm := newTestReferenceConfigMap("bios-ref-dell-poweredge-r750-master", "reference-configs",
+					"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+				cm.SetLabels(map[string]string{
+					"hardware.example.com/vendor": "dell-inc",
+					"hardware.example.com/model":  "poweredge-r750",
+					"hardware.example.com/role":   "master",
+				})
+				client := newBIOSTestFakeDynamicClient(cm)
+
+				_, name, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(name).To(Equal("bios-ref-dell-poweredge-r750-master"))
+			})
+
+			It("ignores ConfigMaps labeled only with the default keys", func() {
+				cm := newTestReferenceConfigMap("bios-ref-dell-poweredge-r750-master", "reference-configs",
+					"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+				client := newBIOSTestFakeDynamicClient(cm)
+
+				_, _, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+				Expect(err).To(MatchError(ContainSubstring("no ConfigMaps found")))
+			})
+
+			It("rejects an invalid label key", func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_MODEL_LABEL", "not a label")
+				client := newBIOSTestFakeDynamicClient()
+
+				_, _, err := findBestMatchConfigMap(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+				Expect(err).To(MatchError(ContainSubstring("KUBE_COMPARE_MCP_BIOS_MODEL_LABEL")))
+			})
+		})
+	})
+
+	Describe("reference ConfigMaps in several namespaces", func() {
+		var (
+			ctx        context.Context
+			namespaces []string
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			namespaces = []string{"vendor-dell", "reference-configs"}
+		})
+
+		It("scores label candidates from every namespace together", func() {
+			cm1 := newTestReferenceConfigMap("dell-r740", "vendor-dell",
+				"dell-inc", "poweredge-r740", "master", "2.0.0", "")
+			cm2 := newTestReferenceConfigMap("dell-r750", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm1, cm2)
+
+			result, name, err := findBestMatchConfigMap(ctx, client, namespaces, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("dell-r750"))
+			Expect(result.GetNamespace()).To(Equal("reference-configs"))
+		})
+
+		It("qualifies tied ConfigMaps from different namespaces", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_AMBIGUOUS_MATCH", AmbiguousMatchError)
+			cm1 := newTestReferenceConfigMap("dell-r750", "vendor-dell",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			cm2 := newTestReferenceConfigMap("dell-r750", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.2.0", "")
+			client := newBIOSTestFakeDynamicClient(cm1, cm2)
+
+			_, _, err := findBestMatchConfigMap(ctx, client, namespaces, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(err).To(MatchError(ContainSubstring("reference-configs/dell-r750, vendor-dell/dell-r750")))
+		})
+
+		It("prefers the earlier namespace for tied ConfigMaps of the same name by default", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_AMBIGUOUS_MATCH", "")
+			cm1 := newTestReferenceConfigMap("dell-r750", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.2.0", "")
+			cm2 := newTestReferenceConfigMap("dell-r750", "vendor-dell",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm1, cm2)
+
+			result, _, err := findBestMatchConfigMap(ctx, client, namespaces, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.GetNamespace()).To(Equal("vendor-dell"))
+		})
+
+		It("uses the exact name match of the first namespace holding it", func() {
+			name := buildReferenceConfigMapName("Dell Inc.", "PowerEdge R750", "master")
+			cm1 := newTestReferenceConfigMap(name, "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			cm2 := newTestReferenceConfigMap(name, "vendor-dell",
+				"dell-inc", "poweredge-r750", "master", "2.2.0", "")
+			client := newBIOSTestFakeDynamicClient(cm1, cm2)
+
+			result, matched, err := findReferenceConfigMap(ctx, client, namespaces, "", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matched).To(Equal(name))
+			Expect(result.GetNamespace()).To(Equal("vendor-dell"))
+		})
+
+		It("finds an exact name match in a later namespace", func() {
+			name := buildReferenceConfigMapName("Dell Inc.", "PowerEdge R750", "master")
+			cm := newTestReferenceConfigMap(name, "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm)
+
+			result, _, err := findReferenceConfigMap(ctx, client, namespaces, "", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.GetNamespace()).To(Equal("reference-configs"))
+		})
+
+		It("searches every namespace for a reference override", func() {
+			cm := newTestReferenceConfigMap("custom-ref", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm)
+
+			result, _, err := findReferenceConfigMap(ctx, client, namespaces, "custom-ref", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.GetNamespace()).To(Equal("reference-configs"))
+
+			_, _, err = findReferenceConfigMap(ctx, client, namespaces, "missing-ref", "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(err).To(MatchError(ContainSubstring(`not found in namespace "vendor-dell,reference-configs"`)))
+		})
 	})
+
+	DescribeTable("parseReferenceNamespaces",
+		func(referenceSource string, expected []string) {
+			Expect(parseReferenceNamespaces(referenceSource)).To(Equal(expected))
+		},
+		Entry("defaults when empty", "", []string{DefaultReferenceConfigNamespace}),
+		Entry("a single namespace", "baselines", []string{"baselines"}),
+		Entry("a list in order", "vendor-dell, reference-configs", []string{"vendor-dell", "reference-configs"}),
+		Entry("without blank or repeated entries", "a,,b,a,", []string{"a", "b"}),
+	)
 })
 
+// newTestNode builds a Node carrying the given label keys.
+func newTestNode(name string, labelKeys ...string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Node"})
+	obj.SetName(name)
+	labels := make(map[string]string, len(labelKeys))
+	for _, key := range labelKeys {
+		labels[key] = ""
+	}
+	obj.SetLabels(labels)
+	return obj
+}
+
+// recordingNotifier records the progress notifications it is asked to send.
+type recordingNotifier struct {
+	sent []*mcp.ProgressNotificationParams
+}
+
+func (n *recordingNotifier) NotifyProgress(_ context.Context, params *mcp.ProgressNotificationParams) error {
+	n.sent = append(n.sent, params)
+	return nil
+}
+
 func newTestHostFirmwareComponents(name, namespace, biosVersion string) *unstructured.Unstructured {
 	obj := &unstructured.Unstructured{
 		Object: map[string]any{
@@ -499,6 +907,20 @@ var _ = Describe("BIOSDiffInputSchema", func() {
 		prop := schema.Properties["output_format"]
 		Expect(prop.Enum).To(ContainElements("json", "yaml"))
 	})
+
+	It("does not require namespace, which the kubeconfig context may set", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE", "")
+		schema := BIOSDiffInputSchema()
+		Expect(schema.Required).NotTo(ContainElement("namespace"))
+		Expect(schema.Properties["namespace"].Default).To(BeNil())
+	})
+
+	It("makes namespace optional and reports the configured default", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE", "spoke-1")
+		schema := BIOSDiffInputSchema()
+		Expect(schema.Required).NotTo(ContainElement("namespace"))
+		Expect(string(schema.Properties["namespace"].Default)).To(Equal(`"spoke-1"`))
+	})
 })
 
 var _ = Describe("BIOSDiffOutputSchema", func() {
@@ -648,6 +1070,7 @@ var _ = Describe("HandleBIOSDiff input validation", func() {
 	})
 
 	It("rejects empty namespace", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE", "")
 		input := BIOSDiffInput{
 			Namespace: "",
 		}
@@ -660,6 +1083,88 @@ var _ = Describe("HandleBIOSDiff input validation", func() {
 	})
 })
 
+// contextNamespaceKubeconfig is a kubeconfig whose current context sets the namespace
+// hub-hosts.
+const contextNamespaceKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: hub
+clusters:
+- name: hub
+  cluster:
+    server: https://api.hub.example.com:6443
+users:
+- name: admin
+  user:
+    token: test-token
+contexts:
+- name: hub
+  context:
+    cluster: hub
+    user: admin
+    namespace: hub-hosts
+`
+
+var _ = Describe("resolveBMHNamespace", func() {
+	It("applies the configured default when namespace is omitted", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE", "spoke-1")
+		namespace, source := resolveBMHNamespace("", "", "")
+		Expect(namespace).To(Equal("spoke-1"))
+		Expect(source).To(Equal(namespaceFromServer))
+	})
+
+	It("prefers an explicit namespace over the default", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE", "spoke-1")
+		namespace, source := resolveBMHNamespace("spoke-2", contextNamespaceKubeconfig, "")
+		Expect(namespace).To(Equal("spoke-2"))
+		Expect(source).To(Equal(namespaceFromInput))
+	})
+
+	It("prefers the kubeconfig context's namespace over the configured default", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE", "spoke-1")
+		namespace, source := resolveBMHNamespace("", contextNamespaceKubeconfig, "")
+		Expect(namespace).To(Equal("hub-hosts"))
+		Expect(source).To(Equal(namespaceFromKubeconfig))
+	})
+
+	It("returns empty when neither is set", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_DEFAULT_BMH_NAMESPACE", "")
+		namespace, _ := resolveBMHNamespace("", "", "")
+		Expect(namespace).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Kubeconfig context namespace", func() {
+	It("is used by baremetal_bios_explain_match when namespace is omitted", func() {
+		var logs bytes.Buffer
+		DeferCleanup(slog.SetDefault, slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+		// Validation reaches host_name, so the namespace was resolved
+		result, _, err := HandleBIOSExplainMatch(context.Background(), nil, BIOSExplainMatchInput{
+			Kubeconfig: contextNamespaceKubeconfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("host_name is required"))
+		Expect(logs.String()).To(ContainSubstring("namespace=hub-hosts namespaceSource=kubeconfig-context"))
+	})
+
+	It("is used by baremetal_host_firmware_settings when namespace is omitted", func() {
+		result, _, err := HandleHostFirmwareSettings(context.Background(), nil, HostFirmwareSettingsInput{
+			Kubeconfig: contextNamespaceKubeconfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("host_name is required"))
+	})
+
+	It("leaves namespace required without a kubeconfig context namespace", func() {
+		result, _, err := HandleHostFirmwareSettings(context.Background(), nil, HostFirmwareSettingsInput{HostName: "host-0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("namespace is required"))
+	})
+})
+
 var _ = Describe("Context cancellation", func() {
 	It("returns error when context is canceled", func() {
 		ctx, cancel := context.WithCancel(context.Background())
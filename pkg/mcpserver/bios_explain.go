@@ -0,0 +1,324 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// Reasons reported by the baremetal_bios_explain_match tool.
+const (
+	// MatchReasonExactName indicates a ConfigMap with the expected name was found.
+	MatchReasonExactName = "ExactNameMatch"
+	// MatchReasonLabelMatch indicates a ConfigMap was selected by labels and model similarity.
+	MatchReasonLabelMatch = "LabelMatch"
+	// MatchReasonNoVendorRoleMatch indicates no ConfigMap carries the host's vendor and role labels.
+	MatchReasonNoVendorRoleMatch = "NoVendorRoleMatch"
+	// MatchReasonBelowThreshold indicates candidates exist but no model label is similar enough.
+	MatchReasonBelowThreshold = "ModelSimilarityBelowThreshold"
+	// MatchReasonListFailed indicates the reference ConfigMaps could not be read or listed.
+	MatchReasonListFailed = "ReferenceListFailed"
+	// MatchReasonAmbiguous indicates several ConfigMaps tie for the best model similarity.
+	MatchReasonAmbiguous = "AmbiguousMatch"
+)
+
+// BIOSExplainMatchInput defines the typed input for the baremetal_bios_explain_match tool.
+type BIOSExplainMatchInput struct {
+	Kubeconfig      string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for the ACM hub cluster. If omitted, uses in-cluster config."`
+	Context         string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig."`
+	Namespace       string `json:"namespace,omitempty" jsonschema:"Namespace on the hub cluster containing the BareMetalHost. Defaults to the namespace of the kubeconfig context."`
+	HostName        string `json:"host_name" jsonschema:"BareMetalHost whose reference ConfigMap matching should be explained."`
+	ReferenceSource string `json:"reference_source,omitempty" jsonschema:"Namespace containing BIOS reference ConfigMaps, or a comma-separated list of namespaces searched in order."`
+}
+
+// BIOSMatchCandidate is a reference ConfigMap considered during label-based matching.
+type BIOSMatchCandidate struct {
+	Name       string  `json:"Name"`
+	Namespace  string  `json:"Namespace"`
+	ModelLabel string  `json:"ModelLabel"`
+	Score      float64 `json:"Score"`
+}
+
+// BIOSMatchExplanation is the structured response for the baremetal_bios_explain_match tool.
+// It reports every step of the reference ConfigMap matching pipeline for a single host.
+type BIOSMatchExplanation struct {
+	Name                string               `json:"Name"`
+	Namespace           string               `json:"Namespace"`
+	Role                string               `json:"Role"`
+	ServerModel         ServerModelInfo      `json:"ServerModel"`
+	NormalizedVendor    string               `json:"NormalizedVendor"`
+	NormalizedModel     string               `json:"NormalizedModel"`
+	NormalizedRole      string               `json:"NormalizedRole"`
+	ReferenceNamespaces []string             `json:"ReferenceNamespaces"`
+	ExactName           string               `json:"ExactName"`
+	ExactNameFound      bool                 `json:"ExactNameFound"`
+	LabelSelector       string               `json:"LabelSelector,omitempty"`
+	Candidates          []BIOSMatchCandidate `json:"Candidates,omitempty"`
+	TiedCandidates      []string             `json:"TiedCandidates,omitempty"`
+	Threshold           float64              `json:"Threshold"`
+	Matched             bool                 `json:"Matched"`
+	MatchedReference    string               `json:"MatchedReference,omitempty"`
+	MatchedNamespace    string               `json:"MatchedNamespace,omitempty"`
+	Reason              string               `json:"Reason"`
+	Message             string               `json:"Message"`
+}
+
+// BIOSExplainMatchTool returns the MCP tool definition for explaining BIOS reference matching.
+func BIOSExplainMatchTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:         "baremetal_bios_explain_match",
+		Title:        "BIOS Reference Match Explainer",
+		Description:  "Explain how a bare metal host is matched to a BIOS reference ConfigMap, and why matching failed when no reference is found.",
+		InputSchema:  BIOSExplainMatchInputSchema(),
+		OutputSchema: BIOSExplainMatchOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleBIOSExplainMatch is the MCP tool handler for the baremetal_bios_explain_match tool.
+func HandleBIOSExplainMatch(ctx context.Context, req *mcp.CallToolRequest, input BIOSExplainMatchInput) (toolResult *mcp.CallToolResult, explanation *BIOSMatchExplanation, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Info("Received tool request",
+		"tool", "baremetal_bios_explain_match",
+		"namespace", input.Namespace,
+		"hostName", input.HostName,
+		"referenceSource", input.ReferenceSource,
+		"hasKubeconfig", input.Kubeconfig != "",
+		"context", input.Context,
+	)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	// Validate context requires kubeconfig
+	if input.Context != "" && input.Kubeconfig == "" {
+		err := NewValidationError("context",
+			"'context' parameter requires 'kubeconfig' to also be provided",
+			"Provide a kubeconfig along with the context name")
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	// Validate required fields
+	var namespaceSource string
+	input.Namespace, namespaceSource = resolveToolNamespace(input.Namespace, input.Kubeconfig, input.Context)
+	if input.Namespace == "" {
+		err := NewValidationError("namespace",
+			"namespace is required",
+			"Provide the namespace on the hub cluster containing the BareMetalHost, or set a namespace on the kubeconfig context")
+		return newToolResultErrorFor(err), nil, nil
+	}
+	logResolvedNamespace(logger, input.Namespace, namespaceSource)
+	if input.HostName == "" {
+		err := NewValidationError("host_name",
+			"host_name is required",
+			"Provide the name of the BareMetalHost to explain")
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	referenceNamespaces := parseReferenceNamespaces(input.ReferenceSource)
+
+	targetClient, referenceClient, logger, err := buildBIOSClients(ctx, input.Kubeconfig, input.Context, referenceNamespaces, logger)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	result, err := explainBIOSMatch(ctx, targetClient, referenceClient, input.Namespace, input.HostName, referenceNamespaces, logger)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	logger.Info("BIOS match explanation completed",
+		"duration", time.Since(start),
+		"namespace", input.Namespace,
+		"hostName", input.HostName,
+		"matched", result.Matched,
+		"reason", result.Reason,
+	)
+
+	return newToolResultText(string(outputBytes)), result, nil
+}
+
+// explainBIOSMatch reads the host's role and server model from the hub cluster and
+// explains how it is matched against reference ConfigMaps on the MCP server cluster.
+func explainBIOSMatch(
+	ctx context.Context,
+	targetClient dynamic.Interface,
+	referenceClient dynamic.Interface,
+	namespace string,
+	hostName string,
+	referenceNamespaces []string,
+	logger *slog.Logger,
+) (*BIOSMatchExplanation, error) {
+	bmh, err := targetClient.Resource(bareMetalHostGVR).Namespace(namespace).Get(ctx, hostName, metav1.GetOptions{})
+	if err != nil {
+		return nil, NewCompareError("get-bmh",
+			fmt.Errorf("failed to get BareMetalHost %s/%s: %w", namespace, hostName, err),
+			"Verify the host name and namespace are correct")
+	}
+
+	serverModel, err := getServerModel(ctx, targetClient, namespace, hostName)
+	if err != nil {
+		return nil, NewCompareError("get-hardware-data", err,
+			"The host's HardwareData is required to determine its server model. Verify the host has been inspected.")
+	}
+
+	role, err := resolveBMHRole(ctx, targetClient, bmh, logger)
+	if err != nil {
+		return nil, NewCompareError("resolve-role", err,
+			"Set the role annotation on the BareMetalHost, or unset KUBE_COMPARE_MCP_BIOS_MISSING_ROLE to default to worker")
+	}
+
+	explanation := explainReferenceMatch(ctx, referenceClient, referenceNamespaces,
+		serverModel.Manufacturer, serverModel.ProductName, role, logger)
+	explanation.Name = hostName
+	explanation.Namespace = namespace
+	return explanation, nil
+}
+
+// explainReferenceMatch runs the same matching pipeline as findReferenceConfigMap
+// (exact name, then label selector with model similarity) across referenceNamespaces
+// and records each step.
+func explainReferenceMatch(
+	ctx context.Context,
+	referenceClient dynamic.Interface,
+	referenceNamespaces []string,
+	manufacturer string,
+	productName string,
+	role string,
+	logger *slog.Logger,
+) *BIOSMatchExplanation {
+	explanation := &BIOSMatchExplanation{
+		Role: role,
+		ServerModel: ServerModelInfo{
+			Manufacturer: manufacturer,
+			ProductName:  productName,
+		},
+		NormalizedVendor:    normalizeForK8sName(manufacturer, validation.DNS1123LabelMaxLength),
+		NormalizedModel:     normalizeForK8sName(productName, validation.DNS1123LabelMaxLength),
+		NormalizedRole:      normalizeForK8sName(role, validation.DNS1123LabelMaxLength),
+		ReferenceNamespaces: referenceNamespaces,
+		ExactName:           buildReferenceConfigMapName(manufacturer, productName, role),
+		Threshold:           minModelSimilarity,
+	}
+
+	refConfigMap, err := getReferenceConfigMap(ctx, referenceClient, referenceNamespaces, explanation.ExactName)
+	switch {
+	case err == nil:
+		explanation.ExactNameFound = true
+		explanation.Matched = true
+		explanation.MatchedReference = explanation.ExactName
+		explanation.MatchedNamespace = refConfigMap.GetNamespace()
+		explanation.Reason = MatchReasonExactName
+		explanation.Message = fmt.Sprintf("ConfigMap %q exists in namespace %q and is used directly.",
+			explanation.ExactName, explanation.MatchedNamespace)
+		return explanation
+	case !apierrors.IsNotFound(err):
+		explanation.Reason = MatchReasonListFailed
+		explanation.Message = err.Error()
+		return explanation
+	}
+
+	keys, err := getReferenceLabelKeys()
+	if err != nil {
+		explanation.Reason = MatchReasonListFailed
+		explanation.Message = err.Error()
+		return explanation
+	}
+	explanation.LabelSelector = referenceLabelSelector(keys, manufacturer, role)
+	candidates, err := scoreReferenceCandidates(ctx, referenceClient, referenceNamespaces, explanation.LabelSelector, keys.Model, productName, logger)
+	if err != nil {
+		explanation.Reason = MatchReasonListFailed
+		explanation.Message = err.Error()
+		return explanation
+	}
+
+	for _, c := range candidates {
+		explanation.Candidates = append(explanation.Candidates, BIOSMatchCandidate{
+			Name:       c.configMap.GetName(),
+			Namespace:  c.configMap.GetNamespace(),
+			ModelLabel: c.modelLabel,
+			Score:      c.score,
+		})
+	}
+
+	best := bestCandidate(candidates)
+	switch {
+	case best == nil:
+		explanation.Reason = MatchReasonNoVendorRoleMatch
+		explanation.Message = fmt.Sprintf(
+			"No ConfigMap named %q and no ConfigMap labeled with %s in namespace %q. "+
+				"Check that the %s and %s labels match the normalized vendor and role.",
+			explanation.ExactName, explanation.LabelSelector, strings.Join(referenceNamespaces, ","), keys.Vendor, keys.Role)
+	case best.score < minModelSimilarity:
+		explanation.Reason = MatchReasonBelowThreshold
+		explanation.Message = fmt.Sprintf(
+			"Found %d ConfigMap(s) for the vendor and role, but the best model label %q scored %.2f against %q, below the threshold of %.2f. "+
+				"Check the %s label.",
+			len(candidates), best.modelLabel, best.score, productName, minModelSimilarity, keys.Model)
+	default:
+		if tied := tiedCandidates(candidates, best); len(tied) > 1 {
+			explanation.TiedCandidates = tied
+			if getAmbiguousMatchPolicy() == AmbiguousMatchError {
+				explanation.Reason = MatchReasonAmbiguous
+				explanation.Message = fmt.Sprintf(
+					"ConfigMaps %s tie with model similarity %.2f. "+
+						"Adjust their %s labels so only one matches, or use reference_override.",
+					strings.Join(tied, ", "), best.score, keys.Model)
+				return explanation
+			}
+		}
+
+		explanation.Matched = true
+		explanation.MatchedReference = best.configMap.GetName()
+		explanation.MatchedNamespace = best.configMap.GetNamespace()
+		explanation.Reason = MatchReasonLabelMatch
+		explanation.Message = fmt.Sprintf("ConfigMap %q matched by labels with model similarity %.2f.",
+			explanation.MatchedReference, best.score)
+		if len(explanation.TiedCandidates) > 1 {
+			explanation.Message += fmt.Sprintf(" It was chosen by name from tied ConfigMaps %s.",
+				strings.Join(explanation.TiedCandidates, ", "))
+		}
+	}
+
+	return explanation
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("BIOSExplainMatch", func() {
+
+	Describe("BIOSExplainMatchTool", func() {
+		var tool = BIOSExplainMatchTool()
+
+		It("has the correct name", func() {
+			Expect(tool.Name).To(Equal("baremetal_bios_explain_match"))
+		})
+
+		It("has input and output schemas", func() {
+			Expect(tool.InputSchema).NotTo(BeNil())
+			Expect(tool.OutputSchema).NotTo(BeNil())
+		})
+	})
+
+	Describe("explainReferenceMatch", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("reports an exact name match", func() {
+			cm := newTestReferenceConfigMap("bios-ref-dell-inc-poweredge-r750-master", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm)
+
+			explanation := explainReferenceMatch(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(explanation.Matched).To(BeTrue())
+			Expect(explanation.ExactNameFound).To(BeTrue())
+			Expect(explanation.Reason).To(Equal(MatchReasonExactName))
+			Expect(explanation.MatchedReference).To(Equal("bios-ref-dell-inc-poweredge-r750-master"))
+		})
+
+		It("reports a failed exact name lookup instead of treating it as not found", func() {
+			client := newBIOSTestFakeDynamicClient().(*dynamicfake.FakeDynamicClient)
+			client.PrependReactor("get", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "", errors.New("denied"))
+			})
+
+			explanation := explainReferenceMatch(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(explanation.Matched).To(BeFalse())
+			Expect(explanation.ExactNameFound).To(BeFalse())
+			Expect(explanation.Reason).To(Equal(MatchReasonListFailed))
+			Expect(explanation.Message).To(ContainSubstring("forbidden"))
+			Expect(explanation.Candidates).To(BeEmpty())
+		})
+
+		It("reports a label match with candidate scores", func() {
+			cm1 := newTestReferenceConfigMap("dell-r740", "reference-configs",
+				"dell-inc", "poweredge-r740", "master", "2.0.0", "")
+			cm2 := newTestReferenceConfigMap("dell-r750", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm1, cm2)
+
+			explanation := explainReferenceMatch(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(explanation.Matched).To(BeTrue())
+			Expect(explanation.Reason).To(Equal(MatchReasonLabelMatch))
+			Expect(explanation.MatchedReference).To(Equal("dell-r750"))
+			Expect(explanation.Candidates).To(HaveLen(2))
+		})
+
+		It("reports no vendor/role match with the selector used", func() {
+			cm := newTestReferenceConfigMap("bios-ref-hpe-proliant-master", "reference-configs",
+				"hpe", "proliant-dl380", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm)
+
+			explanation := explainReferenceMatch(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(explanation.Matched).To(BeFalse())
+			Expect(explanation.Reason).To(Equal(MatchReasonNoVendorRoleMatch))
+			Expect(explanation.ExactName).To(Equal("bios-ref-dell-inc-poweredge-r750-master"))
+			Expect(explanation.ExactNameFound).To(BeFalse())
+			Expect(explanation.NormalizedVendor).To(Equal("dell-inc"))
+			Expect(explanation.NormalizedModel).To(Equal("poweredge-r750"))
+			Expect(explanation.NormalizedRole).To(Equal("master"))
+			Expect(explanation.LabelSelector).To(Equal("bios-reference/vendor=dell-inc,bios-reference/role=master"))
+			Expect(explanation.Candidates).To(BeEmpty())
+		})
+
+		It("reports model similarity below threshold with candidate scores", func() {
+			cm := newTestReferenceConfigMap("bios-ref-dell-different-model-master", "reference-configs",
+				"dell-inc", "completely-different-xyz", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm)
+
+			explanation := explainReferenceMatch(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(explanation.Matched).To(BeFalse())
+			Expect(explanation.Reason).To(Equal(MatchReasonBelowThreshold))
+			Expect(explanation.Threshold).To(Equal(minModelSimilarity))
+			Expect(explanation.Candidates).To(HaveLen(1))
+			Expect(explanation.Candidates[0].Name).To(Equal("bios-ref-dell-different-model-master"))
+			Expect(explanation.Candidates[0].ModelLabel).To(Equal("completely-different-xyz"))
+			Expect(explanation.Candidates[0].Score).To(BeNumerically("<", minModelSimilarity))
+			Expect(explanation.Message).To(ContainSubstring("below the threshold"))
+		})
+
+		It("reports an ambiguous match when ConfigMaps tie for the best score", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_BIOS_AMBIGUOUS_MATCH", AmbiguousMatchError)
+			cm1 := newTestReferenceConfigMap("dell-r750-b", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			cm2 := newTestReferenceConfigMap("dell-r750-a", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm1, cm2)
+
+			explanation := explainReferenceMatch(ctx, client, []string{"reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(explanation.Matched).To(BeFalse())
+			Expect(explanation.Reason).To(Equal(MatchReasonAmbiguous))
+			Expect(explanation.TiedCandidates).To(Equal([]string{"dell-r750-a", "dell-r750-b"}))
+		})
+
+		It("reports the namespace of a label match found in a later namespace", func() {
+			cm := newTestReferenceConfigMap("dell-r750", "reference-configs",
+				"dell-inc", "poweredge-r750", "master", "2.1.0", "")
+			client := newBIOSTestFakeDynamicClient(cm)
+
+			explanation := explainReferenceMatch(ctx, client, []string{"vendor-dell", "reference-configs"}, "Dell Inc.", "PowerEdge R750", "master", discardLogger)
+			Expect(explanation.Matched).To(BeTrue())
+			Expect(explanation.MatchedNamespace).To(Equal("reference-configs"))
+			Expect(explanation.Candidates).To(ConsistOf(HaveField("Namespace", "reference-configs")))
+		})
+	})
+
+	Describe("explainBIOSMatch", func() {
+		It("resolves role and server model from the hub cluster", func() {
+			// Objects are created through their GVRs since the fake client cannot
+			// pluralize the singular "hardwaredata" resource from the kind.
+			targetClient := newBIOSTestFakeDynamicClient()
+			_, err := targetClient.Resource(bareMetalHostGVR).Namespace("spoke").Create(context.Background(),
+				newTestBareMetalHost("node-0", "spoke", "master"), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = targetClient.Resource(hardwareDataGVR).Namespace("spoke").Create(context.Background(),
+				newTestHardwareData("node-0", "spoke", "Dell Inc.", "PowerEdge R750"), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			referenceClient := newBIOSTestFakeDynamicClient()
+
+			explanation, err := explainBIOSMatch(context.Background(), targetClient, referenceClient, "spoke", "node-0", []string{"reference-configs"}, discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(explanation.Name).To(Equal("node-0"))
+			Expect(explanation.Namespace).To(Equal("spoke"))
+			Expect(explanation.Role).To(Equal("master"))
+			Expect(explanation.ServerModel.ProductName).To(Equal("PowerEdge R750"))
+			Expect(explanation.Reason).To(Equal(MatchReasonNoVendorRoleMatch))
+		})
+
+		It("returns an error when the host does not exist", func() {
+			client := newBIOSTestFakeDynamicClient()
+			_, err := explainBIOSMatch(context.Background(), client, client, "spoke", "missing", []string{"reference-configs"}, discardLogger)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("HandleBIOSExplainMatch input validation", func() {
+		It("rejects a missing host name", func() {
+			result, _, err := HandleBIOSExplainMatch(context.Background(), nil, BIOSExplainMatchInput{Namespace: "spoke"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			Expect(ok).To(BeTrue())
+			Expect(textContent.Text).To(ContainSubstring("host_name"))
+		})
+	})
+})
+
+func newTestBareMetalHost(name, namespace, role string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+				"annotations": map[string]any{
+					BMHRoleAnnotation: role,
+				},
+			},
+		},
+	}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "metal3.io",
+		Version: "v1alpha1",
+		Kind:    "BareMetalHost",
+	})
+	return obj
+}
+
+func newTestHardwareData(name, namespace, manufacturer, productName string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]any{
+				"hardware": map[string]any{
+					"systemVendor": map[string]any{
+						"manufacturer": manufacturer,
+						"productName":  productName,
+					},
+				},
+			},
+		},
+	}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "metal3.io",
+		Version: "v1alpha1",
+		Kind:    "HardwareData",
+	})
+	return obj
+}
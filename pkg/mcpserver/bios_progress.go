@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressNotifier sends MCP progress notifications. *mcp.ServerSession implements it.
+type progressNotifier interface {
+	NotifyProgress(ctx context.Context, params *mcp.ProgressNotificationParams) error
+}
+
+// BIOSProgressChunk is the JSON payload carried in the message of each progress
+// notification sent by baremetal_bios_diff. Exactly one of Host or Summary is set;
+// the Summary chunk is always sent last.
+type BIOSProgressChunk struct {
+	Host    *HostBIOSResult  `json:"Host,omitempty"`
+	Summary *BIOSDiffSummary `json:"Summary,omitempty"`
+}
+
+// biosProgressReporter streams per-host BIOS results as MCP progress notifications.
+// A nil reporter is valid and reports nothing.
+type biosProgressReporter struct {
+	notifier progressNotifier
+	token    any
+	logger   *slog.Logger
+}
+
+// newBIOSProgressReporter returns a reporter for req, or nil when the client did not
+// ask for progress by sending a progress token.
+func newBIOSProgressReporter(req *mcp.CallToolRequest, logger *slog.Logger) *biosProgressReporter {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+	return &biosProgressReporter{notifier: req.Session, token: token, logger: logger}
+}
+
+// hostCompleted reports the result of one host. completed counts the hosts done so far.
+func (r *biosProgressReporter) hostCompleted(ctx context.Context, host HostBIOSResult, completed, total int) {
+	if r == nil {
+		return
+	}
+	r.send(ctx, BIOSProgressChunk{Host: &host}, completed, total)
+}
+
+// finished reports the aggregated summary once every host has been compared.
+func (r *biosProgressReporter) finished(ctx context.Context, summary BIOSDiffSummary) {
+	if r == nil {
+		return
+	}
+	r.send(ctx, BIOSProgressChunk{Summary: &summary}, summary.TotalHosts, summary.TotalHosts)
+}
+
+// send delivers a chunk. Failures are logged and otherwise ignored, since the
+// complete result is still returned in the tool response.
+func (r *biosProgressReporter) send(ctx context.Context, chunk BIOSProgressChunk, completed, total int) {
+	message, err := json.Marshal(chunk)
+	if err != nil {
+		r.logger.Warn("Failed to encode BIOS progress", "error", err)
+		return
+	}
+
+	if err := r.notifier.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: r.token,
+		Message:       string(message),
+		Progress:      float64(completed),
+		Total:         float64(total),
+	}); err != nil {
+		r.logger.Debug("Failed to send BIOS progress notification", "error", err)
+	}
+}
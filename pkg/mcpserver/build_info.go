@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// kubeCompareModulePath is the module path of the kube-compare library.
+	kubeCompareModulePath = "github.com/openshift/kube-compare"
+	// unknownVersion is reported for a module whose version is not in the build info.
+	unknownVersion = "unknown"
+)
+
+// BuildInfo describes the build of the running server.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+	// KubeCompareVersion is the version of the kube-compare library the server was built with
+	KubeCompareVersion string `json:"kube_compare_version"`
+	// Modified is set when the binary was built from a working tree with uncommitted changes
+	Modified bool `json:"modified,omitempty"`
+}
+
+var (
+	buildInfoMu sync.RWMutex
+	buildInfo   BuildInfo
+)
+
+// SetBuildInfo records the version, git commit, and build date injected at build time
+// through ldflags. Empty values are filled in from the Go build information.
+func SetBuildInfo(version, gitCommit, buildDate string) {
+	buildInfoMu.Lock()
+	defer buildInfoMu.Unlock()
+	buildInfo = BuildInfo{Version: version, GitCommit: gitCommit, BuildDate: buildDate}
+}
+
+// GetBuildInfo returns the build information of the running server. Values not set
+// through SetBuildInfo are read from runtime/debug.ReadBuildInfo.
+func GetBuildInfo() BuildInfo {
+	buildInfoMu.RLock()
+	info := buildInfo
+	buildInfoMu.RUnlock()
+
+	goInfo, _ := debug.ReadBuildInfo()
+	return completeBuildInfo(info, goInfo)
+}
+
+// completeBuildInfo fills the empty fields of info from goInfo, which may be nil.
+// The version "dev" is the unset default of main.version and is replaced as well.
+func completeBuildInfo(info BuildInfo, goInfo *debug.BuildInfo) BuildInfo {
+	if goInfo != nil {
+		if (info.Version == "" || info.Version == "dev") && goInfo.Main.Version != "" && goInfo.Main.Version != "(devel)" {
+			info.Version = goInfo.Main.Version
+		}
+		for _, setting := range goInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+		info.GoVersion = goInfo.GoVersion
+	}
+	info.KubeCompareVersion = moduleVersion(goInfo, kubeCompareModulePath)
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.GoVersion == "" {
+		info.GoVersion = runtime.Version()
+	}
+	return info
+}
+
+// KubeCompareVersion returns the version of the kube-compare library the server was
+// built with, or "unknown" when the binary carries no build information.
+func KubeCompareVersion() string {
+	goInfo, _ := debug.ReadBuildInfo()
+	return moduleVersion(goInfo, kubeCompareModulePath)
+}
+
+// moduleVersion returns the version of the dependency at path in goInfo, which may be
+// nil, or "unknown" when it is not listed. A replaced module reports the version of its
+// replacement, which is the code that was built.
+func moduleVersion(goInfo *debug.BuildInfo, path string) string {
+	if goInfo == nil {
+		return unknownVersion
+	}
+	for _, dep := range goInfo.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		if dep.Version != "" {
+			return dep.Version
+		}
+	}
+	return unknownVersion
+}
+
+// ServerBuildInfoInput defines the typed input for the kube_compare_server_build_info tool.
+type ServerBuildInfoInput struct{}
+
+// ServerBuildInfoTool returns the MCP tool definition for reporting the server build.
+func ServerBuildInfoTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:         "kube_compare_server_build_info",
+		Title:        "Server Build Info",
+		Description:  "Report the version, git commit, build date, Go version, and kube-compare library version of the running kube-compare-mcp server.",
+		OutputSchema: ServerBuildInfoOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(false),
+		},
+	}
+}
+
+// HandleServerBuildInfo is the MCP tool handler for the kube_compare_server_build_info tool.
+func HandleServerBuildInfo(_ context.Context, _ *mcp.CallToolRequest, _ ServerBuildInfoInput) (*mcp.CallToolResult, *BuildInfo, error) {
+	slog.Default().Debug("Received tool request", "tool", "kube_compare_server_build_info")
+
+	info := GetBuildInfo()
+	outputBytes, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+	return newToolResultText(string(outputBytes)), &info, nil
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Build info", func() {
+	goInfo := &debug.BuildInfo{
+		GoVersion: "go1.24.4",
+		Main:      debug.Module{Path: "github.com/sakhoury/kube-compare-mcp", Version: "v0.5.0"},
+		Deps: []*debug.Module{
+			{Path: "github.com/modelcontextprotocol/go-sdk", Version: "v1.6.0"},
+			{Path: "github.com/openshift/kube-compare", Version: "v0.12.0"},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "3f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39"},
+			{Key: "vcs.time", Value: "2026-10-01T12:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	Describe("completeBuildInfo", func() {
+		It("populates every field from the Go build info when no ldflags were set", func() {
+			info := completeBuildInfo(BuildInfo{Version: "dev"}, goInfo)
+
+			Expect(info).To(Equal(BuildInfo{
+				Version:            "v0.5.0",
+				GitCommit:          "3f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39",
+				BuildDate:          "2026-10-01T12:00:00Z",
+				GoVersion:          "go1.24.4",
+				KubeCompareVersion: "v0.12.0",
+				Modified:           true,
+			}))
+		})
+
+		It("keeps values set through ldflags", func() {
+			info := completeBuildInfo(BuildInfo{Version: "v1.0.0", GitCommit: "abc123", BuildDate: "2026-10-18T09:00:00Z"}, goInfo)
+
+			Expect(info.Version).To(Equal("v1.0.0"))
+			Expect(info.GitCommit).To(Equal("abc123"))
+			Expect(info.BuildDate).To(Equal("2026-10-18T09:00:00Z"))
+		})
+
+		It("keeps dev for a development build", func() {
+			devel := &debug.BuildInfo{GoVersion: "go1.24.4", Main: debug.Module{Version: "(devel)"}}
+
+			Expect(completeBuildInfo(BuildInfo{Version: "dev"}, devel).Version).To(Equal("dev"))
+		})
+
+		It("falls back to the runtime Go version without build info", func() {
+			info := completeBuildInfo(BuildInfo{}, nil)
+
+			Expect(info.Version).To(Equal("dev"))
+			Expect(info.GitCommit).To(BeEmpty())
+			Expect(info.GoVersion).To(Equal(runtime.Version()))
+			Expect(info.KubeCompareVersion).To(Equal("unknown"))
+		})
+	})
+
+	Describe("moduleVersion", func() {
+		It("reports the version of a replaced module's replacement", func() {
+			replaced := &debug.BuildInfo{Deps: []*debug.Module{{
+				Path:    "github.com/openshift/kube-compare",
+				Version: "v0.12.0",
+				Replace: &debug.Module{Path: "github.com/example/kube-compare", Version: "v0.12.1-fix"},
+			}}}
+
+			Expect(moduleVersion(replaced, kubeCompareModulePath)).To(Equal("v0.12.1-fix"))
+		})
+
+		It("reports unknown for a module that is not a dependency", func() {
+			Expect(moduleVersion(goInfo, "github.com/example/missing")).To(Equal("unknown"))
+		})
+
+		It("discovers the kube-compare version of the running binary", func() {
+			goInfo, ok := debug.ReadBuildInfo()
+			if !ok {
+				Expect(KubeCompareVersion()).To(Equal("unknown"))
+				return
+			}
+			Expect(KubeCompareVersion()).To(Equal(moduleVersion(goInfo, kubeCompareModulePath)))
+			Expect(KubeCompareVersion()).NotTo(BeEmpty())
+		})
+	})
+
+	Describe("HandleServerBuildInfo", func() {
+		AfterEach(func() {
+			SetBuildInfo("", "", "")
+		})
+
+		It("returns the build info set at startup", func() {
+			SetBuildInfo("v1.2.3", "abc123", "2026-10-18T09:00:00Z")
+
+			result, info, err := HandleServerBuildInfo(context.Background(), nil, ServerBuildInfoInput{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+			Expect(info.Version).To(Equal("v1.2.3"))
+			Expect(info.GitCommit).To(Equal("abc123"))
+			Expect(info.GoVersion).NotTo(BeEmpty())
+
+			var decoded BuildInfo
+			Expect(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &decoded)).To(Succeed())
+			Expect(decoded).To(Equal(*info))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/openshift/kube-compare/pkg/compare"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// liveObjectGetter fetches the live cluster object for a kube-compare CR name.
+type liveObjectGetter func(ctx context.Context, crName string) (*unstructured.Unstructured, error)
+
+// ParseChangedSince parses a changed_since value, either a positive Go duration
+// relative to now (e.g. "1h") or an RFC 3339 timestamp.
+func ParseChangedSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, NewValidationError("changed_since",
+				fmt.Sprintf("duration must be positive, got %q", value),
+				"Use a duration such as '1h' or '30m'")
+		}
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, NewValidationError("changed_since",
+			fmt.Sprintf("%q is neither a duration nor an RFC 3339 timestamp", value),
+			"Use a duration such as '1h' or a timestamp such as '2025-01-02T15:04:05Z'")
+	}
+	return t, nil
+}
+
+// LastChangeTime estimates when obj last changed: the latest of its creationTimestamp
+// and the times recorded in its managedFields. managedFields times only move when a
+// manager writes fields it owns, so this is a heuristic rather than an audit record.
+func LastChangeTime(obj *unstructured.Unstructured) time.Time {
+	last := obj.GetCreationTimestamp().Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(last) {
+			last = entry.Time.Time
+		}
+	}
+	return last
+}
+
+// parseCRName splits a kube-compare CR name (apiVersion_kind[_namespace]_name) into
+// its group/version/kind, namespace, and name.
+func parseCRName(crName string) (schema.GroupVersionKind, string, string, error) {
+	parts := strings.Split(crName, compare.FieldSeparator)
+	var namespace, name string
+	switch len(parts) {
+	case 3:
+		name = parts[2]
+	case 4:
+		namespace, name = parts[2], parts[3]
+	default:
+		return schema.GroupVersionKind{}, "", "", fmt.Errorf("unexpected CR name %q", crName)
+	}
+
+	gv, err := schema.ParseGroupVersion(parts[0])
+	if err != nil {
+		return schema.GroupVersionKind{}, "", "", fmt.Errorf("unexpected API version in CR name %q: %w", crName, err)
+	}
+	return gv.WithKind(parts[1]), namespace, name, nil
+}
+
+// newFactoryObjectGetter returns a liveObjectGetter that reads objects from the
+// cluster the factory is configured for.
+func newFactoryObjectGetter(factory kcmdutil.Factory) (liveObjectGetter, error) {
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REST mapper: %w", err)
+	}
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return func(ctx context.Context, crName string) (*unstructured.Unstructured, error) {
+		gvk, namespace, name, err := parseCRName(crName)
+		if err != nil {
+			return nil, err
+		}
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map %s: %w", gvk, err)
+		}
+		return client.Resource(mapping.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	}, nil
+}
+
+// FilterOutputChangedSince keeps only the compared CRs whose live object changed at or
+// after since, and updates the CR counts in the summary to match. CRs whose live object
+// cannot be read are kept, since they cannot be shown to be unchanged. Missing templates
+// and validation issues have no live object and are left as reported.
+func FilterOutputChangedSince(ctx context.Context, output *compare.Output, since time.Time, getObject liveObjectGetter) {
+	logger := slog.Default()
+
+	changed := make(map[string]bool)
+	changedSince := func(crName string) bool {
+		if result, ok := changed[crName]; ok {
+			return result
+		}
+		obj, err := getObject(ctx, crName)
+		if err != nil {
+			logger.Debug("Keeping CR whose live object could not be read", "cr", crName, "error", err)
+			changed[crName] = true
+			return true
+		}
+		changed[crName] = !LastChangeTime(obj).Before(since)
+		return changed[crName]
+	}
+
+	if output.Diffs != nil {
+		kept := make([]compare.DiffSum, 0, len(*output.Diffs))
+		for _, diff := range *output.Diffs {
+			if changedSince(diff.CRName) {
+				kept = append(kept, diff)
+			}
+		}
+		output.Diffs = &kept
+	}
+
+	if output.Summary == nil {
+		return
+	}
+
+	unmatched := make([]string, 0, len(output.Summary.UnmatchedCRS))
+	for _, crName := range output.Summary.UnmatchedCRS {
+		if changedSince(crName) {
+			unmatched = append(unmatched, crName)
+		}
+	}
+	output.Summary.UnmatchedCRS = unmatched
+	recountSummaryCRs(output)
+}
+
+// recountSummaryCRs updates the CR counts in the summary of output to match its
+// remaining diffs, after some were filtered out. As in kube-compare, TotalCRs counts
+// the CRs matched to a template, so unmatched CRs are not included.
+func recountSummaryCRs(output *compare.Output) {
+	output.Summary.NumDiffCRs, output.Summary.PatchedCRs, output.Summary.TotalCRs = 0, 0, 0
+	if output.Diffs != nil {
+		for _, diff := range *output.Diffs {
+			output.Summary.TotalCRs++
+			if diff.HasDiff() {
+				output.Summary.NumDiffCRs++
+			}
+			if diff.WasPatched() {
+				output.Summary.PatchedCRs++
+			}
+		}
+	}
+}
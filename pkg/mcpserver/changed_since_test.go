@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("changed_since", func() {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// newLiveObject builds a live object created at created and last written by a
+	// manager at each of updates.
+	newLiveObject := func(created time.Time, updates ...time.Time) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetCreationTimestamp(metav1.NewTime(created))
+		var fields []metav1.ManagedFieldsEntry
+		for _, t := range updates {
+			mt := metav1.NewTime(t)
+			fields = append(fields, metav1.ManagedFieldsEntry{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, Time: &mt})
+		}
+		obj.SetManagedFields(fields)
+		return obj
+	}
+
+	Describe("ParseChangedSince", func() {
+		It("subtracts a duration from now", func() {
+			since, err := ParseChangedSince("1h", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(since).To(Equal(now.Add(-time.Hour)))
+		})
+
+		It("accepts an RFC 3339 timestamp", func() {
+			since, err := ParseChangedSince("2025-06-01T10:30:00Z", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(since).To(Equal(time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)))
+		})
+
+		DescribeTable("rejects invalid values",
+			func(value string) {
+				_, err := ParseChangedSince(value, now)
+				var valErr *ValidationError
+				Expect(errors.As(err, &valErr)).To(BeTrue())
+				Expect(valErr.Field).To(Equal("changed_since"))
+			},
+			Entry("negative duration", "-1h"),
+			Entry("zero duration", "0s"),
+			Entry("date without time", "2025-06-01"),
+			Entry("free text", "yesterday"),
+		)
+	})
+
+	Describe("LastChangeTime", func() {
+		It("uses the creation timestamp when there are no managed fields", func() {
+			created := now.Add(-48 * time.Hour)
+			Expect(LastChangeTime(newLiveObject(created))).To(BeTemporally("==", created))
+		})
+
+		It("uses the latest managed fields time", func() {
+			created := now.Add(-48 * time.Hour)
+			obj := newLiveObject(created, now.Add(-3*time.Hour), now.Add(-10*time.Minute), now.Add(-24*time.Hour))
+			Expect(LastChangeTime(obj)).To(BeTemporally("==", now.Add(-10*time.Minute)))
+		})
+	})
+
+	Describe("parseCRName", func() {
+		It("parses namespaced and cluster-scoped names", func() {
+			gvk, namespace, name, err := parseCRName("apps/v1_Deployment_openshift-dns_dns-default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gvk.Group).To(Equal("apps"))
+			Expect(gvk.Kind).To(Equal("Deployment"))
+			Expect(namespace).To(Equal("openshift-dns"))
+			Expect(name).To(Equal("dns-default"))
+
+			gvk, namespace, name, err = parseCRName("v1_Namespace_openshift-dns")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gvk.Group).To(BeEmpty())
+			Expect(gvk.Version).To(Equal("v1"))
+			Expect(namespace).To(BeEmpty())
+			Expect(name).To(Equal("openshift-dns"))
+		})
+
+		It("rejects malformed names", func() {
+			_, _, _, err := parseCRName("Deployment")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("FilterOutputChangedSince", func() {
+		var (
+			since   time.Time
+			live    map[string]*unstructured.Unstructured
+			getLive liveObjectGetter
+			output  *compare.Output
+		)
+
+		BeforeEach(func() {
+			since = now.Add(-time.Hour)
+			old := now.Add(-30 * 24 * time.Hour)
+			live = map[string]*unstructured.Unstructured{
+				"v1_ConfigMap_ns_recently-updated": newLiveObject(old, now.Add(-5*time.Minute)),
+				"v1_ConfigMap_ns_recently-created": newLiveObject(now.Add(-20 * time.Minute)),
+				"v1_ConfigMap_ns_stale":            newLiveObject(old, now.Add(-2*time.Hour)),
+				"v1_ConfigMap_ns_stale-in-sync":    newLiveObject(old),
+				"v1_Secret_ns_recent-unmatched":    newLiveObject(old, now.Add(-time.Minute)),
+				"v1_Secret_ns_stale-unmatched":     newLiveObject(old),
+			}
+			getLive = func(_ context.Context, crName string) (*unstructured.Unstructured, error) {
+				obj, ok := live[crName]
+				if !ok {
+					return nil, errors.New("not found")
+				}
+				return obj, nil
+			}
+
+			diffs := []compare.DiffSum{
+				{CRName: "v1_ConfigMap_ns_recently-updated", DiffOutput: "-a\n+b"},
+				{CRName: "v1_ConfigMap_ns_recently-created", CorrelatedTemplate: "cm.yaml"},
+				{CRName: "v1_ConfigMap_ns_stale", DiffOutput: "-c\n+d"},
+				{CRName: "v1_ConfigMap_ns_stale-in-sync"},
+				{CRName: "v1_ConfigMap_ns_deleted", DiffOutput: "-e\n+f"},
+			}
+			output = &compare.Output{
+				Summary: &compare.Summary{
+					NumDiffCRs:   3,
+					TotalCRs:     5,
+					NumMissing:   1,
+					UnmatchedCRS: []string{"v1_Secret_ns_recent-unmatched", "v1_Secret_ns_stale-unmatched"},
+				},
+				Diffs: &diffs,
+			}
+		})
+
+		It("keeps only CRs whose live object changed in the window", func() {
+			FilterOutputChangedSince(context.Background(), output, since, getLive)
+
+			var names []string
+			for _, diff := range *output.Diffs {
+				names = append(names, diff.CRName)
+			}
+			Expect(names).To(ConsistOf(
+				"v1_ConfigMap_ns_recently-updated",
+				"v1_ConfigMap_ns_recently-created",
+				// Kept because its live object could not be read
+				"v1_ConfigMap_ns_deleted",
+			))
+			Expect(output.Summary.UnmatchedCRS).To(ConsistOf("v1_Secret_ns_recent-unmatched"))
+		})
+
+		It("recounts the summary for the remaining CRs", func() {
+			FilterOutputChangedSince(context.Background(), output, since, getLive)
+
+			Expect(output.Summary.NumDiffCRs).To(Equal(2))
+			Expect(output.Summary.TotalCRs).To(Equal(3))
+			Expect(output.Summary.NumMissing).To(Equal(1))
+		})
+
+		It("keeps everything when all objects changed in the window", func() {
+			FilterOutputChangedSince(context.Background(), output, now.Add(-365*24*time.Hour), getLive)
+
+			Expect(*output.Diffs).To(HaveLen(5))
+			Expect(output.Summary.NumDiffCRs).To(Equal(3))
+		})
+	})
+})
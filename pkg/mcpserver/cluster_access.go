@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ClusterAccessInput defines the typed input for the kube_compare_check_cluster_access tool.
+type ClusterAccessInput struct {
+	Kubeconfig string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for the cluster to check. If omitted, uses in-cluster config."`
+	Context    string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig."`
+
+	KubeconfigSecret string `json:"kubeconfig_secret,omitempty" jsonschema:"Secret holding the kubeconfig, as namespace/name or namespace/name/key (key defaults to kubeconfig). Read by the MCP server from its own cluster; must be enabled by the server. Use instead of kubeconfig."`
+}
+
+// AccessCheck is the outcome of one permission check.
+type AccessCheck struct {
+	Verb     string `json:"verb"`
+	Group    string `json:"group"`
+	Resource string `json:"resource"`
+	UsedBy   string `json:"used_by"`
+	Allowed  bool   `json:"allowed"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ClusterAccessResult is the structured response for the kube_compare_check_cluster_access tool.
+type ClusterAccessResult struct {
+	ServerVersion string        `json:"server_version"`
+	AllAllowed    bool          `json:"all_allowed"`
+	Checks        []AccessCheck `json:"checks"`
+}
+
+// clusterAccessChecks are the permissions the other tools need on a target cluster.
+var clusterAccessChecks = []AccessCheck{
+	{Verb: "get", Group: "config.openshift.io", Resource: "clusterversions", UsedBy: "kube_compare_resolve_rds, kube_compare_validate_rds"},
+	{Verb: "list", Group: "metal3.io", Resource: "baremetalhosts", UsedBy: "baremetal_bios_diff, baremetal_bios_explain_match"},
+	{Verb: "get", Group: "metal3.io", Resource: "hostfirmwaresettings", UsedBy: "baremetal_bios_diff, baremetal_host_firmware_settings"},
+}
+
+// ClusterAccessTool returns the MCP tool definition for checking cluster access.
+func ClusterAccessTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:  "kube_compare_check_cluster_access",
+		Title: "Check Cluster Access",
+		Description: "Check that a kubeconfig can reach a cluster and has the permissions the other tools need. " +
+			"Reports the server version and whether each permission is allowed or denied.",
+		InputSchema:  ClusterAccessInputSchema(),
+		OutputSchema: ClusterAccessOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleClusterAccess is the MCP tool handler for the kube_compare_check_cluster_access tool.
+func HandleClusterAccess(ctx context.Context, req *mcp.CallToolRequest, input ClusterAccessInput) (toolResult *mcp.CallToolResult, result *ClusterAccessResult, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Info("Received tool request",
+		"tool", "kube_compare_check_cluster_access",
+		"hasKubeconfig", input.Kubeconfig != "",
+		"context", input.Context,
+	)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	resolvedKubeconfig, err := resolveKubeconfigInput(ctx, input.Kubeconfig, input.KubeconfigSecret)
+	if err != nil {
+		logger.Debug("Kubeconfig Secret lookup failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+	input.Kubeconfig = resolvedKubeconfig
+
+	// Validate context requires kubeconfig
+	if input.Context != "" && input.Kubeconfig == "" {
+		err := NewValidationError("context",
+			"'context' parameter requires 'kubeconfig' to also be provided",
+			"Provide a kubeconfig along with the context name")
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	restConfig, err := buildTargetRestConfig(ctx, input.Kubeconfig, input.Context,
+		"No kubeconfig provided: provide a kubeconfig for the cluster to check.", logger)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+	logger = withClusterIdentity(logger, restConfig)
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		err = NewCompareError("cluster-client",
+			fmt.Errorf("failed to create client: %w", err),
+			"Verify the kubeconfig is valid")
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	result, err = checkClusterAccess(ctx, clientset)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	logger.Info("Cluster access checked",
+		"duration", time.Since(start),
+		"serverVersion", result.ServerVersion,
+		"allAllowed", result.AllAllowed,
+	)
+
+	return newToolResultText(string(outputBytes)), result, nil
+}
+
+// checkClusterAccess reads the server version and checks each of clusterAccessChecks.
+// An unreachable cluster is an error; a check that cannot be evaluated is reported as
+// denied with the reason.
+func checkClusterAccess(ctx context.Context, clientset kubernetes.Interface) (*ClusterAccessResult, error) {
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return nil, NewCompareError("cluster-access",
+			fmt.Errorf("%w: %w", ErrClusterConnection, err),
+			"Verify the API server URL in the kubeconfig is reachable and its credentials are valid")
+	}
+
+	result := &ClusterAccessResult{
+		ServerVersion: version.GitVersion,
+		AllAllowed:    true,
+		Checks:        make([]AccessCheck, 0, len(clusterAccessChecks)),
+	}
+	for _, check := range clusterAccessChecks {
+		check.Allowed, check.Reason = CheckAccess(ctx, clientset, check.Verb, check.Group, check.Resource)
+		result.AllAllowed = result.AllAllowed && check.Allowed
+		result.Checks = append(result.Checks, check)
+	}
+	return result, nil
+}
+
+// CheckAccess asks the API server whether the client's user may perform verb on
+// resource in group across all namespaces. The reason explains a denial, or the error
+// when the review itself failed.
+func CheckAccess(ctx context.Context, clientset kubernetes.Interface, verb, group, resource string) (bool, string) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     verb,
+				Group:    group,
+				Resource: resource,
+			},
+		},
+	}
+	response, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Sprintf("access review failed: %v", err)
+	}
+	if !response.Status.Allowed && response.Status.Reason == "" {
+		return false, "denied"
+	}
+	return response.Status.Allowed, response.Status.Reason
+}
+
+// buildTargetRestConfig builds the REST config for the target cluster described by
+// kubeconfig, or the in-cluster config when kubeconfig is empty. purpose explains how
+// to fix a missing in-cluster config.
+func buildTargetRestConfig(ctx context.Context, kubeconfig, contextName, purpose string, logger *slog.Logger) (*rest.Config, error) {
+	if kubeconfig == "" {
+		logger.Debug("Using in-cluster config for target cluster connection")
+		return resolveTargetInClusterConfig(ctx, "cluster-config", purpose)
+	}
+
+	logger.Debug("Using provided kubeconfig for target cluster connection",
+		"kubeconfigLength", len(kubeconfig),
+	)
+
+	kubeconfigData, err := DecodeOrParseKubeconfig(kubeconfig)
+	if err != nil {
+		logger.Debug("Kubeconfig parsing failed", "error", err)
+		return nil, err
+	}
+
+	restConfig, err := BuildSecureRestConfigFromBytes(kubeconfigData, contextName)
+	if err != nil {
+		logger.Debug("Failed to build REST config from kubeconfig", "error", err)
+		return nil, err
+	}
+	return restConfig, nil
+}
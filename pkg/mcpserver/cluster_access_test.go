@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newAccessTestClientset returns a fake clientset whose access reviews allow only the
+// resources in allowed and fail for the resources in failing.
+func newAccessTestClientset(allowed map[string]bool, failing map[string]bool) *fake.Clientset {
+	clientset := fake.NewClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.31.4"}
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		resource := review.Spec.ResourceAttributes.Resource
+		if failing[resource] {
+			return true, nil, errors.New("the server is currently unable to handle the request")
+		}
+		review.Status.Allowed = allowed[resource]
+		if !review.Status.Allowed {
+			review.Status.Reason = "RBAC: no matching rule"
+		}
+		return true, review, nil
+	})
+	return clientset
+}
+
+var _ = Describe("Cluster access", func() {
+
+	Describe("ClusterAccessTool", func() {
+		It("has the correct name and schemas", func() {
+			tool := ClusterAccessTool()
+			Expect(tool.Name).To(Equal("kube_compare_check_cluster_access"))
+			Expect(tool.InputSchema).NotTo(BeNil())
+			Expect(tool.OutputSchema).NotTo(BeNil())
+		})
+	})
+
+	Describe("checkClusterAccess", func() {
+		It("reports each permission as allowed or denied", func() {
+			clientset := newAccessTestClientset(map[string]bool{
+				"clusterversions": true,
+				"baremetalhosts":  true,
+			}, nil)
+
+			result, err := checkClusterAccess(context.Background(), clientset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.ServerVersion).To(Equal("v1.31.4"))
+			Expect(result.AllAllowed).To(BeFalse())
+			Expect(result.Checks).To(HaveLen(len(clusterAccessChecks)))
+
+			allowed := make(map[string]bool)
+			for _, check := range result.Checks {
+				allowed[check.Resource] = check.Allowed
+				if !check.Allowed {
+					Expect(check.Reason).To(Equal("RBAC: no matching rule"))
+				}
+			}
+			Expect(allowed).To(Equal(map[string]bool{
+				"clusterversions":      true,
+				"baremetalhosts":       true,
+				"hostfirmwaresettings": false,
+			}))
+		})
+
+		It("reports all allowed when every check passes", func() {
+			clientset := newAccessTestClientset(map[string]bool{
+				"clusterversions":      true,
+				"baremetalhosts":       true,
+				"hostfirmwaresettings": true,
+			}, nil)
+
+			result, err := checkClusterAccess(context.Background(), clientset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.AllAllowed).To(BeTrue())
+		})
+
+		It("reports a failed access review as denied with the error", func() {
+			clientset := newAccessTestClientset(map[string]bool{
+				"clusterversions": true,
+				"baremetalhosts":  true,
+			}, map[string]bool{"hostfirmwaresettings": true})
+
+			result, err := checkClusterAccess(context.Background(), clientset)
+			Expect(err).NotTo(HaveOccurred())
+			last := result.Checks[len(result.Checks)-1]
+			Expect(last.Resource).To(Equal("hostfirmwaresettings"))
+			Expect(last.Allowed).To(BeFalse())
+			Expect(last.Reason).To(ContainSubstring("access review failed"))
+		})
+	})
+
+	Describe("HandleClusterAccess", func() {
+		It("requires a kubeconfig when a context is given", func() {
+			result, _, err := HandleClusterAccess(context.Background(), &mcp.CallToolRequest{},
+				ClusterAccessInput{Context: "spoke"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("requires an explicit kubeconfig when in-cluster access is disabled", func() {
+			SetDisableLocalInCluster(true)
+			DeferCleanup(func() { SetDisableLocalInCluster(false) })
+
+			result, _, err := HandleClusterAccess(context.Background(), &mcp.CallToolRequest{}, ClusterAccessInput{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("explicit kubeconfig required"))
+		})
+	})
+})
@@ -14,18 +14,22 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/doyensec/safeurl"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/openshift/kube-compare/pkg/compare"
+	"golang.org/x/sync/errgroup"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/rest"
@@ -48,10 +52,133 @@ type ClusterDiffInput struct {
 	AllResources bool   `json:"all_resources,omitempty" jsonschema:"Compare all resources of types mentioned in the reference"`
 	Kubeconfig   string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for connecting to a remote cluster. If omitted, uses in-cluster config."`
 	Context      string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig"`
+	ChangedSince string `json:"changed_since,omitempty" jsonschema:"Only report CRs whose live object changed within this window: a duration such as '1h' or an RFC 3339 timestamp. Change times are estimated from creationTimestamp and managedFields."`
+
+	IncludeReferenceMetadata bool `json:"include_reference_metadata,omitempty" jsonschema:"Also return the reference metadata.yaml the comparison ran against, with its SHA-256 and image digest. Large metadata is returned as an embedded resource."`
+
+	ExcludeNamespaces []string `json:"exclude_namespaces,omitempty" jsonschema:"Drop CRs in these namespaces from the result. Entries are namespace names or glob patterns such as 'openshift-*'. With all_resources, the server's default exclusions apply when omitted; pass an empty list to disable them."`
+	Profile           string   `json:"profile,omitempty" jsonschema:"Name of a server-side comparison profile whose options are used as defaults. Options set in this call take precedence."`
+
+	FailOnDiff bool `json:"fail_on_diff,omitempty" jsonschema:"Mark the result as an error when differences are found, including when kube-compare reports differences without detailed output. The comparison output is still returned."`
+
+	ReferenceTimeout string `json:"reference_timeout,omitempty" jsonschema:"Limit on the combined time to validate, pull, and extract the reference, as a duration such as '90s' or '5m' (at most 30m). Independent of the overall tool timeout."`
+
+	KubeconfigSecret string `json:"kubeconfig_secret,omitempty" jsonschema:"Secret holding the kubeconfig, as namespace/name or namespace/name/key (key defaults to kubeconfig). Read by the MCP server from its own cluster; must be enabled by the server. Use instead of kubeconfig."`
+
+	IncludeCommandEquivalent bool `json:"include_command_equivalent,omitempty" jsonschema:"Also return the kube-compare CLI command equivalent to the comparison, with the kubeconfig redacted. Filters the server applies to the output, such as changed_since and exclude_namespaces, have no CLI equivalent and are not included."`
+
+	IgnoreVolatileFields bool `json:"ignore_volatile_fields,omitempty" jsonschema:"Drop diffs that only change volatile fields, such as metadata.resourceVersion, metadata.managedFields, and status. The fields are configured on the server. A CR whose only diffs are dropped is reported as matching."`
+
+	IncludeReferenceCoverage bool `json:"include_reference_coverage,omitempty" jsonschema:"Also return the templates the reference declares, with the part, component, kind, and name of each, and the distinct resource kinds they cover. Explains which cluster resources the comparison can see."`
+
+	Platform string `json:"platform,omitempty" jsonschema:"Platform to pull when the container:// or oci-layout:// reference is a multi-platform image, as os/arch or os/arch/variant (default linux/amd64)."`
+
+	ClassifyMetadataDiffs bool `json:"classify_metadata_diffs,omitempty" jsonschema:"Also classify each differing CR as spec drift, or as lower-severity metadata drift when its diffs only change metadata.labels or metadata.annotations, so spec drift can be triaged first. Returned as severity in the structured result and the summary."`
+
+	FieldManager string `json:"field_manager,omitempty" jsonschema:"Only report diffs in fields this field manager owns according to the live object's managedFields, such as argocd-controller, so fields set by other managers do not show as drift. A CR whose only diffs are dropped is reported as matching."`
+
+	Verbosity string `json:"verbosity,omitempty" jsonschema:"Detail of the comparison output: terse lists only the CRs that differ with the summary counts, normal (the default) adds their field diffs, and full also includes managedFields in the diffs. Ignored with output_format summary."`
+
+	IncludeOwned bool `json:"include_owned,omitempty" jsonschema:"Also report the ReplicaSets, Jobs, and Pods that the compared CRs own through ownerReferences, following up to three levels, with whether each is ready. Owned resources are not compared with the reference."`
+
+	Anonymize bool `json:"anonymize,omitempty" jsonschema:"Replace the names and namespaces of the cluster's resources in the result with tokens, so it can be shared. A name maps to the same token throughout the result. The legend mapping tokens back to names is returned in a separate content block for the operator to keep."`
+
+	RedactValues bool `json:"redact_values,omitempty" jsonschema:"With anonymize, also replace the field values of the diffs with <redacted>, keeping the field names. Requires anonymize."`
+
+	GroupByRemediation bool `json:"group_by_remediation,omitempty" jsonschema:"Also group the drift by remediation action, with a count for each: reference templates with no matching resource to create, CRs whose fields drifted to update, and cluster CRs no template matched to consider deleting. Returned as remediation in the structured result and the summary."`
+}
+
+// OutputFormatSummary is the output_format that returns only the compliance verdict.
+const OutputFormatSummary = "summary"
+
+// CompareSummary is the compact result returned for output_format "summary".
+type CompareSummary struct {
+	Compliant  bool               `json:"compliant"`
+	NumDiffs   int                `json:"num_diffs"`
+	Reference  string             `json:"reference"`
+	Components []ComponentSummary `json:"components,omitempty"`
+	Warning    string             `json:"warning,omitempty"`
+	// SuppressedCRs is the number of CRs dropped by exclude_namespaces
+	SuppressedCRs int `json:"suppressed_crs,omitempty"`
+	// Severity is set when classify_metadata_diffs is
+	Severity *DiffSeverity `json:"severity,omitempty"`
+	// Remediation is set when group_by_remediation is
+	Remediation *RemediationGroups `json:"remediation,omitempty"`
+}
+
+// ClusterDiffOutput is the structured compliance verdict returned alongside the text
+// content of a comparison against a single reference. Its fields are unset when the
+// comparison fails, the verdict fields are unset when the reference is a directory,
+// and num_diffs is unset when kube-compare reports differences without JSON output
+// to count them from.
+type ClusterDiffOutput struct {
+	Compliant *bool `json:"compliant,omitempty"`
+	NumDiffs  *int  `json:"num_diffs,omitempty"`
+	// Counts is set when the output is JSON
+	Counts *CompareCounts `json:"counts,omitempty"`
+	// Severity is set when classify_metadata_diffs is
+	Severity *DiffSeverity `json:"severity,omitempty"`
+	// Remediation is set when group_by_remediation is
+	Remediation *RemediationGroups `json:"remediation,omitempty"`
+	// KubeCompareVersion is the version of the kube-compare library that ran the comparison
+	KubeCompareVersion string `json:"kube_compare_version,omitempty"`
+}
+
+// CompareCounts is the raw counts of the kube-compare summary.
+type CompareCounts struct {
+	// TotalCRs is the number of cluster CRs matched to a reference template
+	TotalCRs int `json:"total_crs"`
+	// NumDiffCRs is the number of matched CRs that differ from their template
+	NumDiffCRs int `json:"num_diff_crs"`
+	// NumMissing is the number of required templates without a matching CR
+	NumMissing int `json:"num_missing"`
+	// NumUnmatched is the number of cluster CRs no template matched
+	NumUnmatched int `json:"num_unmatched"`
+	// PatchedCRs is the number of CRs whose diff was patched away by a user override
+	PatchedCRs int `json:"patched_crs"`
+}
+
+// newCompareCounts returns the counts of summary.
+func newCompareCounts(summary *compare.Summary) *CompareCounts {
+	return &CompareCounts{
+		TotalCRs:     summary.TotalCRs,
+		NumDiffCRs:   summary.NumDiffCRs,
+		NumMissing:   summary.NumMissing,
+		NumUnmatched: len(summary.UnmatchedCRS),
+		PatchedCRs:   summary.PatchedCRs,
+	}
 }
 
-// ClusterDiffOutput is an empty output struct (tool returns text content).
-type ClusterDiffOutput struct{}
+// clusterDiffOutput returns the structured verdict of run and the version of the
+// kube-compare library that produced it.
+func (r *compareRun) clusterDiffOutput() ClusterDiffOutput {
+	output := r.verdict()
+	output.KubeCompareVersion = KubeCompareVersion()
+	return output
+}
+
+// verdict returns the compliance fields of the structured verdict of run. The
+// kube-compare summary, when the output is JSON, takes precedence over the outcome,
+// since filters such as exclude_namespaces may have dropped every diff kube-compare
+// reported.
+func (r *compareRun) verdict() ClusterDiffOutput {
+	if r.summary != nil {
+		compliant := r.summary.NumDiffCRs == 0 && r.summary.NumMissing == 0
+		return ClusterDiffOutput{
+			Compliant:   &compliant,
+			NumDiffs:    &r.summary.NumDiffCRs,
+			Counts:      newCompareCounts(r.summary),
+			Severity:    r.severity,
+			Remediation: r.remediation,
+		}
+	}
+	compliant := r.outcome == CompareOutcomeNoDifferences
+	if !compliant {
+		return ClusterDiffOutput{Compliant: &compliant}
+	}
+	numDiffs := 0
+	return ClusterDiffOutput{Compliant: &compliant, NumDiffs: &numDiffs}
+}
 
 // ClusterDiffTool returns the MCP tool definition for cluster-compare.
 func ClusterDiffTool() *mcp.Tool {
@@ -103,6 +230,12 @@ func getImagePullTimeout() time.Duration {
 type CompareService struct {
 	HTTPClient HTTPDoer
 	Registry   RegistryClient
+	// Verifier verifies image signatures. When nil, a verifier for the key set
+	// by SetCosignPublicKey is used, if any.
+	Verifier ImageVerifier
+	// Keychain supplies the credentials of image pulls. When nil, the keychain
+	// set by SetRegistryKeychain is used.
+	Keychain authn.Keychain
 }
 
 // NewCompareService creates a new CompareService with default implementations.
@@ -120,8 +253,12 @@ func NewCompareService() *CompareService {
 		}).
 		Build()
 
+	// safeurl checks the address it dials, so the client must not use a proxy: the
+	// proxy would be checked instead of the reference's host.
+	client := safeurl.Client(cfg)
+
 	return &CompareService{
-		HTTPClient: safeurl.Client(cfg),
+		HTTPClient: client,
 		Registry:   DefaultRegistry,
 	}
 }
@@ -151,9 +288,16 @@ func HandleClusterDiff(ctx context.Context, req *mcp.CallToolRequest, input Clus
 
 	if err := ctx.Err(); err != nil {
 		logger.Warn("Request canceled", "error", err)
-		return newToolResultError(formatErrorForUser(ErrContextCanceled)), ClusterDiffOutput{}, nil
+		return newToolResultErrorFor(ErrContextCanceled), ClusterDiffOutput{}, nil
 	}
 
+	resolvedKubeconfig, err := resolveKubeconfigInput(ctx, input.Kubeconfig, input.KubeconfigSecret)
+	if err != nil {
+		logger.Debug("Kubeconfig Secret lookup failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+	input.Kubeconfig = resolvedKubeconfig
+
 	// Convert typed input to CompareArgs
 	args := &CompareArgs{
 		Reference:    input.Reference,
@@ -161,6 +305,40 @@ func HandleClusterDiff(ctx context.Context, req *mcp.CallToolRequest, input Clus
 		AllResources: input.AllResources,
 		Kubeconfig:   input.Kubeconfig,
 		Context:      input.Context,
+
+		IncludeReferenceMetadata: input.IncludeReferenceMetadata,
+		FailOnDiff:               input.FailOnDiff,
+		IncludeCommandEquivalent: input.IncludeCommandEquivalent,
+		IgnoreVolatileFields:     input.IgnoreVolatileFields,
+		IncludeReferenceCoverage: input.IncludeReferenceCoverage,
+		ClassifyMetadataDiffs:    input.ClassifyMetadataDiffs,
+		FieldManager:             strings.TrimSpace(input.FieldManager),
+		IncludeOwned:             input.IncludeOwned,
+		Anonymize:                input.Anonymize,
+		RedactValues:             input.RedactValues,
+		GroupByRemediation:       input.GroupByRemediation,
+	}
+
+	if err := validateReferenceNotEmpty(args.Reference); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+
+	if args.RedactValues && !args.Anonymize {
+		err := NewValidationError("redact_values",
+			"'redact_values' requires 'anonymize' to also be set",
+			"Set anonymize to true to redact field values along with resource names")
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+	if args.Anonymize {
+		// One anonymizer for the whole call, so a directory reference's comparisons
+		// share their tokens
+		if args.anonymizer, err = newAnonymizer(args.RedactValues); err != nil {
+			err = NewCompareError("anonymize", err, "Could not initialize anonymization; retry the request")
+			logger.Error("Anonymizer initialization failed", "error", err)
+			return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+		}
 	}
 
 	// Validate context requires kubeconfig
@@ -169,8 +347,63 @@ func HandleClusterDiff(ctx context.Context, req *mcp.CallToolRequest, input Clus
 			"'context' parameter requires 'kubeconfig' to also be provided",
 			"Provide a base64-encoded kubeconfig along with the context name")
 		logger.Debug("Validation failed", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ClusterDiffOutput{}, nil
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+
+	// Fail before fetching the reference when the comparison could not reach a cluster
+	if args.Kubeconfig == "" {
+		if err := requireExplicitKubeconfig("cluster-config"); err != nil {
+			logger.Debug("Validation failed", "error", err)
+			return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+		}
+	}
+
+	if err := validateNamespacePatterns(input.ExcludeNamespaces); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
 	}
+	args.ExcludeNamespaces = input.ExcludeNamespaces
+
+	if input.Profile != "" {
+		profile, err := loadCompareProfile(input.Profile)
+		if err != nil {
+			logger.Debug("Profile lookup failed", "error", err)
+			return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+		}
+		profile.applyTo(args)
+	}
+
+	if args.ExcludeNamespaces == nil && args.AllResources {
+		args.ExcludeNamespaces = getDefaultExcludeNamespaces()
+	}
+
+	args.Verbosity, err = parseVerbosity(input.Verbosity, args.OutputFormat)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+
+	if input.ChangedSince != "" {
+		changedSince, err := ParseChangedSince(input.ChangedSince, time.Now())
+		if err != nil {
+			logger.Debug("Validation failed", "error", err)
+			return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+		}
+		args.ChangedSince = changedSince
+	}
+
+	args.ReferenceTimeout, err = parseReferenceTimeout(input.ReferenceTimeout)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+
+	platform, err := parsePlatform(input.Platform)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+	args.Platform = platform.String()
 
 	logger.Debug("Parsed compare arguments",
 		"reference", args.Reference,
@@ -178,16 +411,43 @@ func HandleClusterDiff(ctx context.Context, req *mcp.CallToolRequest, input Clus
 		"allResources", args.AllResources,
 		"hasKubeconfig", args.Kubeconfig != "",
 		"context", args.Context,
+		"changedSince", args.ChangedSince,
+		"excludeNamespaces", args.ExcludeNamespaces,
+		"profile", input.Profile,
+		"failOnDiff", args.FailOnDiff,
+		"referenceTimeout", args.ReferenceTimeout,
+		"ignoreVolatileFields", args.IgnoreVolatileFields,
+		"includeReferenceCoverage", args.IncludeReferenceCoverage,
+		"classifyMetadataDiffs", args.ClassifyMetadataDiffs,
+		"fieldManager", args.FieldManager,
+		"verbosity", args.Verbosity,
+		"includeOwned", args.IncludeOwned,
+		"anonymize", args.Anonymize,
+		"redactValues", args.RedactValues,
+		"groupByRemediation", args.GroupByRemediation,
+		"platform", args.Platform,
 	)
 
-	if err := validateReference(ctx, args); err != nil {
+	startReferenceAcquisition(args)
+	refCtx, cancel := referenceAcquisitionContext(ctx, args)
+	err = validateReference(refCtx, args)
+	if err != nil && referenceTimedOut(ctx, refCtx) {
+		err = newReferenceTimeoutError(args.ReferenceTimeout)
+	}
+	cancel()
+	if err != nil {
 		logger.Debug("Reference validation failed", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ClusterDiffOutput{}, nil
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+
+	if isDirectoryReference(args.Reference) {
+		return handleClusterDiffDirectory(ctx, args, logger, start)
 	}
 
 	logger.Info("Starting cluster comparison", "reference", args.Reference)
-	output, err := RunCompare(ctx, args)
+	run, err := runCompare(ctx, args)
 	duration := time.Since(start)
+	logger = withClusterID(logger, args.clusterID)
 
 	if err != nil {
 		logger.Error("Comparison failed",
@@ -195,16 +455,86 @@ func HandleClusterDiff(ctx context.Context, req *mcp.CallToolRequest, input Clus
 			"duration", duration,
 			"reference", args.Reference,
 		)
-		return newToolResultError(formatErrorForUser(err)), ClusterDiffOutput{}, nil
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
 	}
 
 	logger.Info("Comparison completed",
 		"duration", duration,
 		"reference", args.Reference,
-		"outputLength", len(output),
+		"outputLength", len(run.output),
+		"suppressedCRs", run.suppressedCRs,
 	)
 
-	return newToolResultText(output), ClusterDiffOutput{}, nil
+	toolResult, err = compareRunResult(run, args)
+	if err != nil {
+		err = NewCompareError("format", err, "The comparison completed but its accompanying content could not be formatted")
+		logger.Error("Formatting comparison result failed", "error", err)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+	return toolResult, run.clusterDiffOutput(), nil
+}
+
+// compareRunResult returns the tool result of run: its output, marked as an error
+// when args.FailOnDiff is set and run is not compliant once filtered, followed by the
+// content that accompanies it.
+func compareRunResult(run *compareRun, args *CompareArgs) (*mcp.CallToolResult, error) {
+	toolResult := newToolResultText(run.output)
+	if args.FailOnDiff && !*run.verdict().Compliant {
+		// The output is still returned so the caller can see what differs
+		toolResult.IsError = true
+	}
+	if err := appendCompareRunContent(toolResult, run, args); err != nil {
+		return nil, err
+	}
+	return toolResult, nil
+}
+
+// appendCompareRunContent appends the content that accompanies a comparison's output:
+// a note on CRs dropped by exclude_namespaces, and the reference metadata, the
+// equivalent kube-compare command, the reference coverage, the owned resources, and
+// the anonymization legend, if requested.
+func appendCompareRunContent(toolResult *mcp.CallToolResult, run *compareRun, args *CompareArgs) error {
+	if run.suppressedCRs > 0 && args.OutputFormat != OutputFormatSummary {
+		// The summary carries the count itself; other formats cannot, so note it separately
+		toolResult.Content = append(toolResult.Content, &mcp.TextContent{
+			Text: fmt.Sprintf("Suppressed %d CRs in excluded namespaces (%s).",
+				run.suppressedCRs, strings.Join(args.ExcludeNamespaces, ", ")),
+		})
+	}
+	if run.referenceMetadata != nil {
+		content, err := run.referenceMetadata.content()
+		if err != nil {
+			return err
+		}
+		toolResult.Content = append(toolResult.Content, content...)
+	}
+	if run.commandEquivalent != "" {
+		toolResult.Content = append(toolResult.Content, &mcp.TextContent{
+			Text: "Equivalent kube-compare command: " + run.commandEquivalent,
+		})
+	}
+	if run.coverage != nil {
+		content, err := run.coverage.content()
+		if err != nil {
+			return err
+		}
+		toolResult.Content = append(toolResult.Content, content)
+	}
+	if run.owned != nil {
+		content, err := run.owned.content()
+		if err != nil {
+			return err
+		}
+		toolResult.Content = append(toolResult.Content, content)
+	}
+	if args.anonymizer != nil {
+		content, err := args.anonymizer.legend().content()
+		if err != nil {
+			return err
+		}
+		toolResult.Content = append(toolResult.Content, content)
+	}
+	return nil
 }
 
 // ExtractArguments safely extracts the arguments map from the MCP request.
@@ -228,12 +558,74 @@ type CompareArgs struct {
 	Reference    string
 	OutputFormat string
 	AllResources bool
-	Kubeconfig   string // Base64-encoded kubeconfig content (optional)
-	Context      string // Kubernetes context name to use (optional)
+	Kubeconfig   string    // Base64-encoded kubeconfig content (optional)
+	Context      string    // Kubernetes context name to use (optional)
+	ChangedSince time.Time // Only report CRs changed at or after this time (optional)
+
+	// IncludeReferenceMetadata records the reference metadata.yaml in the result
+	IncludeReferenceMetadata bool
+	// ExcludeNamespaces drops CRs in matching namespaces from the result (optional)
+	ExcludeNamespaces []string
+	// FailOnDiff marks the tool result as an error when differences are found
+	FailOnDiff bool
+	// ReferenceTimeout bounds the combined reference validation, pull, and
+	// extraction time (optional)
+	ReferenceTimeout time.Duration
+	// IncludeCommandEquivalent records the equivalent kube-compare command in the result
+	IncludeCommandEquivalent bool
+	// IgnoreVolatileFields drops diffs that only change the server's volatile fields
+	IgnoreVolatileFields bool
+	// IncludeReferenceCoverage records the reference's templates and kinds in the result
+	IncludeReferenceCoverage bool
+	// Platform selects the image of a multi-platform container:// or oci-layout://
+	// reference, such as linux/arm64 (optional, DefaultPlatform when empty)
+	Platform string
+	// ClassifyMetadataDiffs records the spec or metadata severity of each differing CR
+	ClassifyMetadataDiffs bool
+	// FieldManager drops diffs in fields this manager does not own on the live object
+	// (optional)
+	FieldManager string
+	// Verbosity is the detail of the output: VerbosityTerse, VerbosityNormal, or
+	// VerbosityFull (optional, VerbosityNormal when empty)
+	Verbosity string
+	// IncludeOwned records the resources the compared CRs own and their readiness
+	IncludeOwned bool
+	// Anonymize replaces the names and namespaces of the cluster's resources in the
+	// result with tokens
+	Anonymize bool
+	// RedactValues also replaces the field values of the diffs when Anonymize is set
+	RedactValues bool
+	// GroupByRemediation records the resources to create, update, and delete
+	GroupByRemediation bool
+
+	// image is the already pulled image of a container:// reference, so several
+	// comparisons against one image pull it once (optional)
+	image *pulledImage
+	// verifiedDigest is the digest whose signature validateReference verified, so
+	// the image is pulled by it without verifying again (optional)
+	verifiedDigest string
+	// anonymizer is the anonymizer of args.Anonymize, shared by the comparisons of one
+	// call. runCompare creates it when unset.
+	anonymizer *anonymizer
+	// clusterID is set by runCompare to the identity of the cluster it connects to,
+	// including when the comparison then fails
+	clusterID string
+	// referenceDeadline is when the ReferenceTimeout budget runs out, once started
+	referenceDeadline time.Time
+}
+
+// pulledImage is a container image pulled from its registry, with its digest.
+type pulledImage struct {
+	img    v1.Image
+	digest string
 }
 
 // validateReference validates the reference configuration path/URL.
 func validateReference(ctx context.Context, args *CompareArgs) error {
+	if err := validateReferenceNotEmpty(args.Reference); err != nil {
+		return err
+	}
+
 	refType := ClassifyReference(args.Reference)
 
 	switch refType {
@@ -251,7 +643,15 @@ func validateReference(ctx context.Context, args *CompareArgs) error {
 		return validateHTTPReference(ctx, args.Reference)
 
 	case ReferenceTypeOCI:
-		return validateOCIReference(ctx, args.Reference)
+		digest, err := validateOCIReference(ctx, args.Reference)
+		if err != nil {
+			return err
+		}
+		args.verifiedDigest = digest
+		return nil
+
+	case ReferenceTypeLocalImage:
+		return validateLocalImageReference(args.Reference)
 
 	default:
 		return NewValidationError("reference",
@@ -260,12 +660,62 @@ func validateReference(ctx context.Context, args *CompareArgs) error {
 	}
 }
 
+// maxConcurrentReferenceValidations bounds how many references validateReferences
+// checks at once.
+const maxConcurrentReferenceValidations = 4
+
+// validateReferences validates refs concurrently, at most
+// maxConcurrentReferenceValidations at a time. Unlike validateReference it does not
+// stop at the first invalid reference: every failure is returned in a
+// ReferencesValidationError, in the order of refs. It returns the verified digest of
+// each container:// reference, in the order of refs, "" for the others.
+func validateReferences(ctx context.Context, refs []string) ([]string, error) {
+	errs := make([]error, len(refs))
+	digests := make([]string, len(refs))
+
+	var group errgroup.Group
+	group.SetLimit(maxConcurrentReferenceValidations)
+	for i, ref := range refs {
+		group.Go(func() error {
+			args := &CompareArgs{Reference: ref}
+			errs[i] = validateReference(ctx, args)
+			digests[i] = args.verifiedDigest
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	var failures []ReferenceValidationFailure
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, ReferenceValidationFailure{Reference: refs[i], Err: err})
+		}
+	}
+	if len(failures) == 0 {
+		return digests, nil
+	}
+	return nil, &ReferencesValidationError{Total: len(refs), Failures: failures}
+}
+
+// validateReferenceNotEmpty rejects a missing reference. It runs before
+// classification, which would otherwise treat an empty string as a local path.
+func validateReferenceNotEmpty(ref string) error {
+	if strings.TrimSpace(ref) == "" {
+		return NewValidationError("reference",
+			"reference is required and cannot be empty",
+			"Provide an HTTP/HTTPS URL or container:// image reference, "+
+				"e.g. container://registry/image:tag:/path/to/metadata.yaml")
+	}
+	return nil
+}
+
 type ReferenceType int
 
 const (
 	ReferenceTypeLocal ReferenceType = iota
 	ReferenceTypeHTTP
 	ReferenceTypeOCI
+	ReferenceTypeLocalImage
 )
 
 // ClassifyReference determines the type of reference from the input string.
@@ -276,6 +726,9 @@ func ClassifyReference(ref string) ReferenceType {
 	if strings.HasPrefix(ref, "container://") {
 		return ReferenceTypeOCI
 	}
+	if strings.HasPrefix(ref, ociLayoutPrefix) || strings.HasPrefix(ref, ociArchivePrefix) {
+		return ReferenceTypeLocalImage
+	}
 	return ReferenceTypeLocal
 }
 
@@ -360,9 +813,8 @@ func (s *CompareService) ValidateHTTPReference(ctx context.Context, refURL strin
 	if resp.StatusCode >= 400 {
 		statusText := http.StatusText(resp.StatusCode)
 
-		// Read up to 1KB of response body for error context
-		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		bodyHint := strings.TrimSpace(string(bodyBytes))
+		// Read the start of the response body for error context
+		bodyHint := readErrorBody(validateCtx, resp.Body)
 
 		switch resp.StatusCode {
 		case http.StatusNotFound:
@@ -409,25 +861,35 @@ func getOCIValidationTimeout() time.Duration {
 	return defaultOCIValidationTimeout
 }
 
-func validateOCIReference(ctx context.Context, ref string) error {
-	return defaultCompareService.ValidateOCIReference(ctx, ref)
+// validateOCIReference validates ref and returns the verified digest of its image, or
+// "" when signature verification is disabled.
+func validateOCIReference(ctx context.Context, ref string) (string, error) {
+	return defaultCompareService.validateOCIReference(ctx, ref)
 }
 
 // ValidateOCIReference validates that an OCI container image exists using the injected registry client.
 func (s *CompareService) ValidateOCIReference(ctx context.Context, ref string) error {
+	_, err := s.validateOCIReference(ctx, ref)
+	return err
+}
+
+// validateOCIReference validates that the image of ref exists and, when signature
+// verification is enabled, that it is signed. It returns the verified digest, or ""
+// when signature verification is disabled.
+func (s *CompareService) validateOCIReference(ctx context.Context, ref string) (string, error) {
 	logger := slog.Default()
 	logger.Debug("Validating OCI reference", "ref", ref)
 
 	imageRef, filePath, err := ParseContainerReference(ref)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	logger.Debug("Parsed container reference", "image", imageRef, "path", filePath)
 
 	_, err = name.ParseReference(imageRef)
 	if err != nil {
-		return NewValidationError("reference",
+		return "", NewValidationError("reference",
 			fmt.Sprintf("invalid container image reference '%s': %v", imageRef, err),
 			"Use format: container://registry/image:tag:/path/to/metadata.yaml")
 	}
@@ -438,37 +900,44 @@ func (s *CompareService) ValidateOCIReference(ctx context.Context, ref string) e
 	err = s.Registry.HeadImage(validateCtx, imageRef)
 	if err != nil {
 		if ctx.Err() != nil {
-			return NewCompareError("validate", ErrContextCanceled, "The validation was canceled")
+			return "", NewCompareError("validate", ErrContextCanceled, "The validation was canceled")
 		}
 
 		// Check for common error patterns
 		errStr := err.Error()
 		if strings.Contains(errStr, "MANIFEST_UNKNOWN") || strings.Contains(errStr, "NAME_UNKNOWN") {
-			return NewCompareError("validate",
+			return "", NewCompareError("validate",
 				fmt.Errorf("%w: %s", ErrOCIImageNotFound, imageRef),
 				fmt.Sprintf("The container image '%s' was not found. Verify the image name, tag, and registry.", imageRef))
 		}
 		if strings.Contains(errStr, "UNAUTHORIZED") || strings.Contains(errStr, "DENIED") {
-			return NewCompareError("validate",
+			return "", NewCompareError("validate",
 				fmt.Errorf("%w: authentication failed for %s", ErrRemoteUnreachable, imageRef),
 				"Access denied to the container registry. The image may require authentication or be in a private repository.")
 		}
 		if strings.Contains(errStr, "no such host") || strings.Contains(errStr, "connection refused") {
-			return NewCompareError("validate",
+			return "", NewCompareError("validate",
 				fmt.Errorf("%w: cannot reach registry for %s", ErrRemoteUnreachable, imageRef),
 				"Could not connect to the container registry. Verify the registry URL and network connectivity.")
 		}
 
-		return NewCompareError("validate",
+		return "", NewCompareError("validate",
 			fmt.Errorf("%w: %w", ErrRemoteUnreachable, err),
 			fmt.Sprintf("Failed to validate container image '%s'. Verify the image reference is correct.", imageRef))
 	}
 
+	digest, err := s.VerifyImageSignature(validateCtx, imageRef)
+	if err != nil {
+		return "", err
+	}
+
 	logger.Debug("OCI reference validated successfully", "image", imageRef)
-	return nil
+	return digest, nil
 }
 
 // ParseContainerReference parses a container:// reference into image and file path.
+// The image may omit its tag, as in container://quay.io/org/image:/path, in which case
+// the returned image is tagged latest.
 func ParseContainerReference(ref string) (imageRef, filePath string, err error) {
 	const prefix = "container://"
 	if !strings.HasPrefix(ref, prefix) {
@@ -479,31 +948,10 @@ func ParseContainerReference(ref string) (imageRef, filePath string, err error)
 
 	remainder := strings.TrimPrefix(ref, prefix)
 
-	// Find the :/ pattern that indicates the start of the file path.
-	// The format is: image:tag:/path or image:/path (using latest tag).
-	pathSepIdx := -1
-	for i := 0; i < len(remainder)-1; i++ {
-		if remainder[i] != ':' || remainder[i+1] != '/' {
-			continue
-		}
-		// Check if this could be a port number or tag
-		// If there's already been a : before, this is likely the path separator
-		prevColonIdx := strings.LastIndex(remainder[:i], ":")
-		if prevColonIdx >= 0 {
-			// There was a previous colon (likely for tag), so this :/ is the path
-			pathSepIdx = i
-			break
-		}
-		// Check if the part before : looks like a tag (no slashes after it)
-		afterColon := remainder[i+1:]
-		nextSlash := strings.Index(afterColon, "/")
-		if nextSlash == 0 {
-			// Immediately followed by /, this is the path separator
-			pathSepIdx = i
-			break
-		}
-	}
-
+	// The file path starts at the first ":/". An image reference never contains one:
+	// a registry port is followed by digits and a tag or digest cannot contain "/".
+	// The format is: image:tag:/path, image@digest:/path, or image:/path (using latest tag).
+	pathSepIdx := strings.Index(remainder, ":/")
 	if pathSepIdx == -1 {
 		return "", "", NewValidationError("reference",
 			"missing file path in container reference",
@@ -524,8 +972,38 @@ func ParseContainerReference(ref string) (imageRef, filePath string, err error)
 			"missing or invalid file path",
 			"Specify the path to metadata.yaml within the container image")
 	}
+	if err := validateReferenceFilePath(filePath); err != nil {
+		return "", "", err
+	}
 
-	return imageRef, filePath, nil
+	return withDefaultTag(imageRef), filePath, nil
+}
+
+// referenceGlobChars are the characters that make a path a glob pattern.
+const referenceGlobChars = "*?["
+
+// validateReferenceFilePath rejects a file path within a reference image that is a glob
+// pattern, such as /refs/*.yaml. The path is read literally, so a glob would otherwise
+// fail later with a file-not-found error that does not say why.
+func validateReferenceFilePath(filePath string) error {
+	if !strings.ContainsAny(filePath, referenceGlobChars) {
+		return nil
+	}
+	return NewValidationError("reference",
+		fmt.Sprintf("glob patterns are not supported in the reference file path %q", filePath),
+		"Point the reference at the reference's metadata.yaml itself, "+
+			"such as container://registry/image:tag:/path/to/metadata.yaml")
+}
+
+// withDefaultTag tags imageRef latest when it has neither a tag nor a digest, so that
+// the same image is always named the same way.
+func withDefaultTag(imageRef string) string {
+	// A colon before the last "/" belongs to the registry host's port
+	lastComponent := imageRef[strings.LastIndex(imageRef, "/")+1:]
+	if strings.ContainsAny(lastComponent, ":@") {
+		return imageRef
+	}
+	return imageRef + ":latest"
 }
 
 // processTarEntry handles extracting a single tar entry to the destination directory.
@@ -578,65 +1056,160 @@ func processTarEntry(header *tar.Header, tr *tar.Reader, destPath string, logger
 	}
 }
 
+// maxTargetCandidates caps how many alternative paths are reported when the target is missing.
+const maxTargetCandidates = 10
+
 // extractContainerReference extracts files from a container image to a local directory.
-func extractContainerReference(ctx context.Context, imageRef, targetPath, destDir string) (string, error) {
+// It returns the local path of targetPath and the digest of the pulled image. platform
+// selects the image of a multi-platform image, DefaultPlatform when empty. The image is
+// pulled as pullContainerImage pulls it.
+func (s *CompareService) extractContainerReference(ctx context.Context, imageRef, platform, verifiedDigest, targetPath, destDir string) (string, string, error) {
 	logger := slog.Default()
-	logger.Debug("Extracting container reference", "image", imageRef, "targetPath", targetPath)
+	logger.Debug("Extracting container reference", "image", imageRef, "platform", platform, "targetPath", targetPath)
 
-	ref, err := name.ParseReference(imageRef)
+	img, digest, release, err := s.pullContainerImage(ctx, imageRef, platform, verifiedDigest)
+	if err != nil {
+		return "", "", err
+	}
+	defer release()
+
+	extractedPath, err := extractImageFiles(ctx, img, imageRef, targetPath, destDir)
+	if err != nil {
+		return "", "", err
+	}
+	return extractedPath, digest, nil
+}
+
+// pullContainerImage pulls imageRef from its registry and returns the image and its
+// digest. The digest is the verified digest when signature verification is enabled:
+// verifiedDigest when the caller already verified imageRef, or the digest s verifies.
+// When imageRef is a multi-platform image, the image for platform is pulled, or for
+// DefaultPlatform when platform is empty. The registry is authenticated to with the
+// credentials of s.Keychain, or of the keychain set by SetRegistryKeychain when it is nil.
+//
+// The layers of the image are fetched as they are read, under the image pull timeout,
+// so the image is only readable until the returned release function is called.
+func (s *CompareService) pullContainerImage(ctx context.Context, imageRef, platform, verifiedDigest string) (_ v1.Image, _ string, release context.CancelFunc, err error) {
+	logger := slog.Default()
+
+	ref, err := parseImageReference(imageRef)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("invalid image reference '%s': %w", imageRef, err)
+	}
+	wantPlatform, err := parsePlatform(platform)
 	if err != nil {
-		return "", fmt.Errorf("invalid image reference '%s': %w", imageRef, err)
+		return nil, "", nil, err
+	}
+
+	// Pull by the verified digest, so the tag cannot be moved to an unsigned image
+	// between verification and pull
+	digest := verifiedDigest
+	if digest == "" {
+		digest, err = s.VerifyImageSignature(ctx, imageRef)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+	if digest != "" {
+		ref = ref.Context().Digest(digest)
 	}
 
 	pullTimeout := getImagePullTimeout()
 	pullCtx, cancel := context.WithTimeout(ctx, pullTimeout)
-	defer cancel()
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
 
-	logger.Debug("Pulling container image", "image", imageRef, "timeout", pullTimeout)
+	logger.Debug("Pulling container image", "image", imageRef, "platform", wantPlatform, "timeout", pullTimeout)
 
-	img, err := remote.Image(ref,
-		remote.WithContext(pullCtx),
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
-	)
+	desc, err := remote.Get(ref, append(registryOptions(s.Keychain), remote.WithContext(pullCtx))...)
 	if err != nil {
 		if pullCtx.Err() != nil {
-			return "", fmt.Errorf("image pull timed out after %v for '%s': %w", pullTimeout, imageRef, err)
+			return nil, "", nil, fmt.Errorf("image pull timed out after %v for '%s': %w", pullTimeout, imageRef, err)
 		}
-		return "", fmt.Errorf("failed to pull image '%s': %w", imageRef, err)
+		return nil, "", nil, fmt.Errorf("failed to pull image '%s': %w", imageRef, err)
+	}
+	img, err := resolvePlatformImage(desc, imageRef, wantPlatform)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	logger.Debug("Image pulled successfully", "image", imageRef)
 
-	reader := mutate.Extract(img)
+	if digest == "" {
+		imageDigest, err := img.Digest()
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to read digest of image '%s': %w", imageRef, err)
+		}
+		digest = imageDigest.String()
+	}
+	return img, digest, cancel, nil
+}
+
+// walkImageFiles calls visit for each entry of the flattened image filesystem, with
+// the entry's path relative to the image root. visit may read the entry's content
+// from tr.
+func walkImageFiles(ctx context.Context, img v1.Image, visit func(header *tar.Header, fileName string, tr *tar.Reader) error) error {
+	// Layers declared as uncompressed may still be gzip-compressed
+	reader := mutate.Extract(gzipSniffingImage{Image: img})
 	defer reader.Close()
 
 	tr := tar.NewReader(reader)
-
-	// Normalize target path and extract files matching the target directory
-	targetPath = strings.TrimPrefix(targetPath, "/")
-	targetDir := filepath.Dir(targetPath)
-	extractedFiles := 0
 	for {
 		// Check for context cancellation to avoid wasting resources if client disconnected
 		select {
 		case <-ctx.Done():
-			return "", fmt.Errorf("extraction canceled: %w", ctx.Err())
+			return fmt.Errorf("extraction canceled: %w", ctx.Err())
 		default:
 		}
 
 		header, err := tr.Next()
 		if errors.Is(err, io.EOF) {
-			break
+			// A layer that fails to read still ends the archive cleanly, and the
+			// failure is only reported by reading past its end
+			if _, err := io.Copy(io.Discard, reader); err != nil {
+				return fmt.Errorf("error reading image layers: %w", err)
+			}
+			return nil
 		}
 		if err != nil {
-			return "", fmt.Errorf("error reading tar: %w", err)
+			return fmt.Errorf("error reading tar: %w", err)
 		}
 
 		fileName := strings.TrimPrefix(header.Name, "./")
 		fileName = strings.TrimPrefix(fileName, "/")
 
+		if err := visit(header, fileName, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// extractImageFiles extracts the directory containing targetPath from the flattened
+// image filesystem into destDir and returns the local path of the target file.
+// imageName is used for logging only.
+func extractImageFiles(ctx context.Context, img v1.Image, imageName, targetPath, destDir string) (string, error) {
+	logger := slog.Default()
+
+	// Normalize target path and extract files matching the target directory
+	targetPath = strings.TrimPrefix(targetPath, "/")
+	targetDir := filepath.Dir(targetPath)
+	extractedFiles := 0
+
+	// Record same-named files near the target so a wrong path can suggest alternatives
+	targetBase := filepath.Base(targetPath)
+	searchRoot := filepath.Dir(targetDir)
+	var candidates []string
+	err := walkImageFiles(ctx, img, func(header *tar.Header, fileName string, tr *tar.Reader) error {
+		if header.Typeflag == tar.TypeReg && filepath.Base(fileName) == targetBase &&
+			underSearchRoot(fileName, searchRoot) && len(candidates) < maxTargetCandidates {
+			candidates = append(candidates, "/"+fileName)
+		}
+
 		if !strings.HasPrefix(fileName, targetDir) {
-			continue
+			return nil
 		}
 
 		destPath := filepath.Join(destDir, fileName)
@@ -646,37 +1219,143 @@ func extractContainerReference(ctx context.Context, imageRef, targetPath, destDi
 		cleanBase := filepath.Clean(destDir) + string(filepath.Separator)
 		if !strings.HasPrefix(cleanDest, cleanBase) && cleanDest != filepath.Clean(destDir) {
 			logger.Warn("Skipping path traversal attempt", "path", header.Name, "resolved", cleanDest)
-			continue
+			return nil
 		}
 
 		filesAdded, err := processTarEntry(header, tr, destPath, logger)
 		if err != nil {
-			return "", err
+			return err
 		}
 		extractedFiles += filesAdded
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	logger.Info("Container extraction complete", "image", imageRef, "filesExtracted", extractedFiles)
+	logger.Info("Container extraction complete", "image", imageName, "filesExtracted", extractedFiles)
 
 	extractedPath := filepath.Join(destDir, targetPath)
-	if _, err := os.Stat(extractedPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("target file not found in container image: %s", targetPath)
+	if err := validateExtractedTarget(extractedPath, targetPath); err != nil {
+		var notFound *TargetNotFoundError
+		if errors.As(err, &notFound) {
+			notFound.Candidates = candidates
+		}
+		return "", err
 	}
 
 	return extractedPath, nil
 }
 
+// underSearchRoot reports whether fileName lies under searchRoot, where "." is the image root.
+func underSearchRoot(fileName, searchRoot string) bool {
+	return searchRoot == "." || strings.HasPrefix(fileName, searchRoot+"/")
+}
+
+// binarySniffSize is how much of an extracted target is checked for binary content.
+const binarySniffSize = 64 * 1024
+
+// validateExtractedTarget checks that the extracted target is a regular, non-empty text
+// file so a wrong metadata path fails here rather than later inside kube-compare.
+func validateExtractedTarget(extractedPath, targetPath string) error {
+	info, err := os.Stat(extractedPath)
+	if os.IsNotExist(err) {
+		return &TargetNotFoundError{TargetPath: "/" + targetPath}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat extracted target /%s: %w", targetPath, err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("expected a file at /%s but found a directory; the path should point to metadata.yaml", targetPath)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("expected a regular file at /%s but found %s", targetPath, info.Mode().Type())
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("target file /%s in container image is empty", targetPath)
+	}
+
+	f, err := os.Open(extractedPath)
+	if err != nil {
+		return fmt.Errorf("failed to read extracted target /%s: %w", targetPath, err)
+	}
+	defer f.Close()
+	sample, err := io.ReadAll(io.LimitReader(f, binarySniffSize))
+	if err != nil {
+		return fmt.Errorf("failed to read extracted target /%s: %w", targetPath, err)
+	}
+	if isBinaryContent(sample, info.Size() > int64(len(sample))) {
+		return fmt.Errorf("expected YAML text at /%s but found binary content; the path should point to metadata.yaml", targetPath)
+	}
+
+	return nil
+}
+
+// isBinaryContent reports whether data contains a null byte or is not valid UTF-8.
+// truncated means data is only the start of the content, so a multi-byte character
+// cut off at its end is not counted as invalid.
+func isBinaryContent(data []byte, truncated bool) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(data[i]) {
+				if !utf8.FullRune(data[i:]) {
+					data = data[:i]
+				}
+				break
+			}
+		}
+	}
+	return !utf8.Valid(data)
+}
+
 // RunCompare executes the kube-compare operation and returns the result.
 func RunCompare(ctx context.Context, args *CompareArgs) (string, error) {
+	run, err := runCompare(ctx, args)
+	if run == nil {
+		return "", err
+	}
+	return run.output, err
+}
+
+// compareRun is the result of a kube-compare operation.
+type compareRun struct {
+	output string
+	// summary is the kube-compare summary when the output is JSON (the json and
+	// summary formats), and nil otherwise
+	summary *compare.Summary
+	// referenceMetadata is set when args.IncludeReferenceMetadata is
+	referenceMetadata *ReferenceMetadata
+	// suppressedCRs is the number of CRs dropped by args.ExcludeNamespaces
+	suppressedCRs int
+	// outcome is whether kube-compare reported differences
+	outcome CompareOutcome
+	// commandEquivalent is set when args.IncludeCommandEquivalent is
+	commandEquivalent string
+	// coverage is set when args.IncludeReferenceCoverage is
+	coverage *ReferenceCoverage
+	// severity is set when args.ClassifyMetadataDiffs is and kube-compare produced output
+	severity *DiffSeverity
+	// owned is set when args.IncludeOwned is and kube-compare produced output
+	owned *OwnedResourcesReport
+	// remediation is set when args.GroupByRemediation is and kube-compare produced output
+	remediation *RemediationGroups
+}
+
+// runCompare executes the kube-compare operation and returns the result.
+func runCompare(ctx context.Context, args *CompareArgs) (*compareRun, error) {
 	logger := slog.Default()
 
 	if err := ctx.Err(); err != nil {
-		return "", NewCompareError("run", ErrContextCanceled, "The operation was canceled before comparison started")
+		return nil, NewCompareError("run", ErrContextCanceled, "The operation was canceled before comparison started")
 	}
 
 	tmpDir, err := os.MkdirTemp("", "kube-compare-mcp")
 	if err != nil {
-		return "", NewCompareError("initialize",
+		return nil, NewCompareError("initialize",
 			fmt.Errorf("failed to create temp directory: %w", err),
 			"Check that the system temp directory is writable")
 	}
@@ -690,35 +1369,86 @@ func RunCompare(ctx context.Context, args *CompareArgs) (string, error) {
 		}
 	}()
 
+	// Pulling and extracting the reference draws on the reference_timeout budget
+	startReferenceAcquisition(args)
+	refCtx, cancelRef := referenceAcquisitionContext(ctx, args)
+	defer cancelRef()
+
+	// Image extractions are throttled separately from the rest of the request
+	releaseExtraction, err := acquireExtractionSlot(ctx, refCtx, args)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseExtraction()
+
 	// Handle container:// references by extracting them locally
 	referenceConfig := args.Reference
+	var imageDigest string
 	if ClassifyReference(args.Reference) == ReferenceTypeOCI {
 		logger.Info("Extracting container reference using go-containerregistry")
 
 		imageRef, filePath, err := ParseContainerReference(args.Reference)
 		if err != nil {
-			return "", NewCompareError("initialize", err, "Failed to parse container reference")
+			return nil, NewCompareError("initialize", err, "Failed to parse container reference")
 		}
 
 		// Extract the container image to the temp directory
 		extractDir := filepath.Join(tmpDir, "extracted")
 		if err := os.MkdirAll(extractDir, DirectoryPermissions); err != nil {
-			return "", NewCompareError("initialize",
+			return nil, NewCompareError("initialize",
 				fmt.Errorf("failed to create extraction directory: %w", err),
 				"Check filesystem permissions")
 		}
 
-		extractedPath, err := extractContainerReference(ctx, imageRef, filePath, extractDir)
+		var extractedPath, digest string
+		if args.image != nil {
+			digest = args.image.digest
+			extractedPath, err = extractImageFiles(refCtx, args.image.img, imageRef, filePath, extractDir)
+		} else {
+			extractedPath, digest, err = defaultCompareService.extractContainerReference(refCtx, imageRef, args.Platform, args.verifiedDigest, filePath, extractDir)
+		}
 		if err != nil {
-			return "", NewCompareError("initialize",
+			if referenceTimedOut(ctx, refCtx) {
+				return nil, newReferenceTimeoutError(args.ReferenceTimeout)
+			}
+			return nil, NewCompareError("initialize",
 				fmt.Errorf("failed to extract container reference: %w", err),
 				"Verify the container image and path are correct. Check registry authentication if needed.")
 		}
 
-		logger.Info("Container reference extracted", "extractedPath", extractedPath)
+		logger.Info("Container reference extracted", "extractedPath", extractedPath, "digest", digest)
 		referenceConfig = extractedPath
+		imageDigest = digest
 	}
 
+	// Handle oci-layout:// and oci-archive:// references by extracting from the local image
+	if ClassifyReference(args.Reference) == ReferenceTypeLocalImage {
+		if err := validateLocalImageReference(args.Reference); err != nil {
+			return nil, err
+		}
+
+		extractDir := filepath.Join(tmpDir, "extracted")
+		if err := os.MkdirAll(extractDir, DirectoryPermissions); err != nil {
+			return nil, NewCompareError("initialize",
+				fmt.Errorf("failed to create extraction directory: %w", err),
+				"Check filesystem permissions")
+		}
+
+		extractedPath, err := extractLocalImageReference(refCtx, args.Reference, args.Platform, extractDir)
+		if err != nil {
+			if referenceTimedOut(ctx, refCtx) {
+				return nil, newReferenceTimeoutError(args.ReferenceTimeout)
+			}
+			return nil, NewCompareError("initialize",
+				fmt.Errorf("failed to extract local image reference: %w", err),
+				"Verify the image layout or archive path and the metadata path within the image are correct.")
+		}
+
+		logger.Info("Local image reference extracted", "extractedPath", extractedPath)
+		referenceConfig = extractedPath
+	}
+	releaseExtraction()
+
 	var outBuf, errBuf bytes.Buffer
 	ioStreams := genericiooptions.IOStreams{
 		In:     os.Stdin,
@@ -726,61 +1456,343 @@ func RunCompare(ctx context.Context, args *CompareArgs) (string, error) {
 		ErrOut: &errBuf,
 	}
 
-	opts := compare.NewOptions(ioStreams)
+	opts, factory, err := buildCompareOptions(args, referenceConfig, tmpDir, ioStreams)
+	if err != nil {
+		return nil, err
+	}
+
+	if restConfig, err := factory.ToRESTConfig(); err == nil {
+		args.clusterID = clusterIdentity(restConfig)
+		logger = withClusterID(logger, args.clusterID)
+	}
+
+	if err := preflightClusterConnection(factory); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Complete(factory, nil, nil); err != nil {
+		return nil, referenceCompleteError(ctx, args, referenceConfig, err, errBuf.String())
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, NewCompareError("run", ErrContextCanceled, "The operation was canceled during initialization")
+	}
+
+	runErr := opts.Run()
+	output := outBuf.String()
+	errOutput := errBuf.String()
+
+	processed, err := ProcessCompareRun(output, errOutput, runErr)
+	if err != nil {
+		return nil, err
+	}
+	result := processed.Output
+
+	run := &compareRun{outcome: processed.Outcome}
+	if args.IncludeCommandEquivalent {
+		run.commandEquivalent = compareCommandEquivalent(args)
+	}
+	if args.IncludeReferenceMetadata {
+		data, err := defaultCompareService.readReferenceMetadata(ctx, referenceConfig)
+		if err != nil {
+			return nil, NewCompareError("reference-metadata", err, "The comparison completed but the reference metadata could not be read")
+		}
+		run.referenceMetadata, err = newReferenceMetadata(args.Reference, imageDigest, data)
+		if err != nil {
+			return nil, NewCompareError("reference-metadata", err, "The comparison completed but the reference metadata could not be parsed")
+		}
+	}
+	if args.IncludeReferenceCoverage {
+		run.coverage, err = defaultCompareService.loadReferenceCoverage(ctx, args.Reference, referenceConfig)
+		if err != nil {
+			return nil, NewCompareError("reference-coverage", err, "The comparison completed but the reference templates could not be read")
+		}
+	}
+
+	if !processed.DetailAvailable {
+		// There is no kube-compare output to filter or summarize
+		run.output = result
+		return run, nil
+	}
+
+	// The output is parsed once; the filters change it in place and the requested
+	// format is rendered once they ran
+	filtered := filtersCompareOutput(args) && output != ""
+	var parsed *compare.Output
+	if filtered || args.OutputFormat == OutputFormatSummary {
+		parsed, err = parseCompareOutput(output)
+		if err != nil {
+			return nil, NewCompareError("compare", err, "The comparison completed but its output could not be parsed")
+		}
+	} else if compareOutputFormat(args) == compare.Json {
+		// Only the counts are read, so output that is not JSON just has none
+		parsed, _ = parseCompareOutput(output)
+	}
+
+	if filtered {
+		if err := filterCompareOutput(ctx, args, parsed, factory, run); err != nil {
+			return nil, err
+		}
+	}
+
+	if parsed != nil {
+		run.summary = parsed.Summary
+	}
+	switch {
+	case !filtered || args.OutputFormat == OutputFormatSummary:
+		// The output is returned as kube-compare rendered it, or summarized below
+	case args.Verbosity == VerbosityTerse:
+		result, err = terseCompareOutput(parsed, args.OutputFormat)
+		if err != nil {
+			return nil, NewCompareError("verbosity", err, "The comparison completed but its output could not be reduced to verbosity terse")
+		}
+	default:
+		result, err = renderCompareOutput(parsed, args.OutputFormat)
+		if err != nil {
+			return nil, NewCompareError("compare", err, "The comparison completed but its filtered output could not be rendered")
+		}
+	}
+
+	if args.OutputFormat != OutputFormatSummary {
+		run.output = result
+		return run, nil
+	}
+
+	summary, err := summarizeCompareOutput(parsed, args.Reference)
+	if err != nil {
+		return nil, NewCompareError("compare", err, "The comparison completed but its output could not be summarized")
+	}
+	summary.SuppressedCRs = run.suppressedCRs
+	summary.Severity = run.severity
+	summary.Remediation = run.remediation
+	summary.Components = summarizeReferenceComponents(ctx, parsed, referenceConfig)
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format summary: %w", err)
+	}
+	run.output = string(summaryJSON)
+	return run, nil
+}
+
+// filterCompareOutput applies the filters and reports args enables to the parsed
+// kube-compare output, in place, and records their reports in run.
+func filterCompareOutput(ctx context.Context, args *CompareArgs, output *compare.Output, factory kcmdutil.Factory, run *compareRun) error {
+	if len(args.ExcludeNamespaces) > 0 {
+		run.suppressedCRs = FilterOutputExcludeNamespaces(output, args.ExcludeNamespaces)
+	}
+
+	if args.IgnoreVolatileFields {
+		FilterOutputVolatileFields(output, getVolatileFields())
+	}
+
+	if args.FieldManager != "" {
+		getObject, err := newFactoryObjectGetter(factory)
+		if err != nil {
+			return NewCompareError("field-manager", err, "Could not read live objects to apply field_manager")
+		}
+		FilterOutputFieldManager(ctx, output, args.FieldManager, getObject)
+	}
+
+	if !args.ChangedSince.IsZero() {
+		getObject, err := newFactoryObjectGetter(factory)
+		if err != nil {
+			return NewCompareError("changed-since", err, "Could not read live objects to apply changed_since")
+		}
+		FilterOutputChangedSince(ctx, output, args.ChangedSince, getObject)
+	}
+
+	if args.IncludeOwned {
+		client, err := factory.DynamicClient()
+		if err != nil {
+			return NewCompareError("include-owned", fmt.Errorf("failed to create dynamic client: %w", err), "Could not read live objects to apply include_owned")
+		}
+		run.owned = ReportOwnedResources(ctx, output, newOwnedResourceLister(client))
+	}
+
+	if args.ClassifyMetadataDiffs {
+		run.severity = ClassifyDiffSeverity(output)
+	}
+
+	if args.GroupByRemediation {
+		run.remediation = GroupDiffsByRemediation(output)
+	}
+
+	if args.Anonymize {
+		if args.anonymizer == nil {
+			var err error
+			if args.anonymizer, err = newAnonymizer(args.RedactValues); err != nil {
+				return NewCompareError("anonymize", err, "Could not initialize anonymization; retry the request")
+			}
+		}
+		args.anonymizer.anonymizeOutput(output)
+		if run.severity != nil {
+			args.anonymizer.anonymizeSeverity(run.severity)
+		}
+		if run.owned != nil {
+			args.anonymizer.anonymizeOwned(run.owned)
+		}
+		if run.remediation != nil {
+			args.anonymizer.anonymizeRemediation(run.remediation)
+		}
+	}
+	return nil
+}
+
+// parseCompareOutput parses kube-compare JSON output.
+func parseCompareOutput(jsonOutput string) (*compare.Output, error) {
+	var parsed compare.Output
+	// Decode only the first JSON value; warnings may follow the JSON document
+	if err := json.NewDecoder(strings.NewReader(jsonOutput)).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse comparison output: %w", err)
+	}
+	return &parsed, nil
+}
+
+// buildCompareOptions creates the kube-compare options and the kubectl factory for a
+// comparison against the cluster described by args. referenceConfig is the local or
+// remote metadata.yaml to compare against. The caller completes and runs the options.
+func buildCompareOptions(args *CompareArgs, referenceConfig, tmpDir string, streams genericiooptions.IOStreams) (*compare.Options, kcmdutil.Factory, error) {
+	logger := slog.Default()
+
+	opts := compare.NewOptions(streams)
 	opts.ReferenceConfig = referenceConfig
-	opts.OutputFormat = args.OutputFormat
+	opts.OutputFormat = compareOutputFormat(args)
+	opts.ShowManagedFields = args.Verbosity == VerbosityFull
 	opts.TmpDir = tmpDir
 
-	var configFlags *genericclioptions.ConfigFlags
+	configFlags := genericclioptions.NewConfigFlags(true)
 	if args.Kubeconfig != "" {
 		logger.Info("Using provided kubeconfig for cluster connection")
 
 		// Use DecodeOrParseKubeconfig to support both raw YAML and base64-encoded kubeconfig
 		kubeconfigData, err := DecodeOrParseKubeconfig(args.Kubeconfig)
 		if err != nil {
-			return "", err
+			return nil, nil, err
 		}
 
 		restConfig, err := BuildSecureRestConfigFromBytes(kubeconfigData, args.Context)
 		if err != nil {
-			return "", err
-		}
-
-		configFlags = genericclioptions.NewConfigFlags(true)
-		configFlags.WithWrapConfigFn(func(config *rest.Config) *rest.Config {
-			config.Host = restConfig.Host
-			config.TLSClientConfig = restConfig.TLSClientConfig
-			config.BearerToken = restConfig.BearerToken
-			config.BearerTokenFile = restConfig.BearerTokenFile
-			config.Username = restConfig.Username
-			config.Password = restConfig.Password
-			config.Impersonate = restConfig.Impersonate
-			config.CertData = restConfig.CertData
-			config.KeyData = restConfig.KeyData
-			config.CAData = restConfig.CAData
-			return config
-		})
+			return nil, nil, err
+		}
+
+		configFlags.WithWrapConfigFn(wrapWithRestConfig(restConfig))
 	} else {
+		if err := requireExplicitKubeconfig("cluster-config"); err != nil {
+			return nil, nil, err
+		}
 		logger.Debug("Using default cluster credentials")
-		configFlags = genericclioptions.NewConfigFlags(true)
 	}
-	factory := kcmdutil.NewFactory(configFlags)
 
-	if err := opts.Complete(factory, nil, nil); err != nil {
-		errOutput := errBuf.String()
-		details := BuildErrorDetails(err, errOutput)
-		return "", NewCompareError("initialize", err, details)
+	return opts, kcmdutil.NewFactory(configFlags), nil
+}
+
+// compareOutputFormat returns the output format kube-compare runs with for args.
+func compareOutputFormat(args *CompareArgs) string {
+	if args.OutputFormat == OutputFormatSummary || filtersCompareOutput(args) {
+		// The summary and the filtered output are derived from the JSON output
+		return compare.Json
 	}
+	return args.OutputFormat
+}
 
-	if err := ctx.Err(); err != nil {
-		return "", NewCompareError("run", ErrContextCanceled, "The operation was canceled during initialization")
+// filtersCompareOutput reports whether args enables a step that reads kube-compare's
+// JSON output after it ran: the changed_since, exclude_namespaces,
+// ignore_volatile_fields, and field_manager filters, include_owned,
+// classify_metadata_diffs, group_by_remediation, anonymize, or terse output.
+func filtersCompareOutput(args *CompareArgs) bool {
+	return !args.ChangedSince.IsZero() || len(args.ExcludeNamespaces) > 0 || args.IgnoreVolatileFields ||
+		args.FieldManager != "" || args.IncludeOwned || args.ClassifyMetadataDiffs || args.GroupByRemediation ||
+		args.Anonymize || args.Verbosity == VerbosityTerse
+}
+
+// renderCompareOutput renders parsed kube-compare output in format. The summary is
+// built from JSON, so it is rendered as JSON.
+func renderCompareOutput(output *compare.Output, format string) (string, error) {
+	if format == OutputFormatSummary {
+		format = compare.Json
 	}
+	var buf bytes.Buffer
+	if _, err := output.Print(format, &buf, false); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
 
-	runErr := opts.Run()
-	output := outBuf.String()
-	errOutput := errBuf.String()
+// compareCommandEquivalent returns the kube-compare CLI command equivalent to the
+// comparison described by args. The kubeconfig is never included, only a placeholder
+// for it. Filters the server applies to kube-compare's output afterwards have no CLI
+// flag and are left out.
+func compareCommandEquivalent(args *CompareArgs) string {
+	parts := []string{"kubectl", "cluster-compare", "-r", shellQuote(args.Reference)}
+	if format := compareOutputFormat(args); format != "" {
+		parts = append(parts, "-o", shellQuote(format))
+	}
+	if args.AllResources {
+		parts = append(parts, "--all-resources")
+	}
+	if args.Verbosity == VerbosityFull {
+		parts = append(parts, "--show-managed-fields")
+	}
+	if args.Kubeconfig != "" {
+		parts = append(parts, "--kubeconfig", shellQuote("<redacted>"))
+		if args.Context != "" {
+			parts = append(parts, "--context", shellQuote(args.Context))
+		}
+	}
+	return strings.Join(parts, " ")
+}
 
-	return ProcessCompareResult(output, errOutput, runErr)
+// shellSafeRegex matches words that need no quoting in a POSIX shell.
+var shellSafeRegex = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s for a POSIX shell, leaving it as is when it needs no quoting.
+func shellQuote(s string) string {
+	if shellSafeRegex.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// wrapWithRestConfig returns a WrapConfigFn that replaces the connection and credential
+// fields of the config kubectl loads with those of restConfig. Exec and auth provider
+// plugins are deliberately not copied; BuildSecureRestConfigFromBytes rejects them.
+func wrapWithRestConfig(restConfig *rest.Config) func(*rest.Config) *rest.Config {
+	return func(config *rest.Config) *rest.Config {
+		config.Host = restConfig.Host
+		// TLSClientConfig carries CertData, KeyData, CAData, and ServerName
+		config.TLSClientConfig = restConfig.TLSClientConfig
+		config.BearerToken = restConfig.BearerToken
+		config.BearerTokenFile = restConfig.BearerTokenFile
+		config.Username = restConfig.Username
+		config.Password = restConfig.Password
+		config.Impersonate = restConfig.Impersonate
+		config.Proxy = restConfig.Proxy
+		return config
+	}
+}
+
+// SummarizeCompareOutput builds a CompareSummary from kube-compare JSON output.
+// A cluster is compliant when no CRs differ from the reference and no required
+// templates are missing.
+func SummarizeCompareOutput(output, reference string) (*CompareSummary, error) {
+	parsed, err := parseCompareOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeCompareOutput(parsed, reference)
+}
+
+// summarizeCompareOutput builds a CompareSummary from parsed kube-compare output.
+func summarizeCompareOutput(parsed *compare.Output, reference string) (*CompareSummary, error) {
+	if parsed.Summary == nil {
+		return nil, errors.New("comparison output has no summary")
+	}
+
+	return &CompareSummary{
+		Compliant: parsed.Summary.NumDiffCRs == 0 && parsed.Summary.NumMissing == 0,
+		NumDiffs:  parsed.Summary.NumDiffCRs,
+		Reference: reference,
+	}, nil
 }
 
 // BuildErrorDetails creates a helpful error message based on the error and context.
@@ -814,24 +1826,71 @@ func BuildErrorDetails(err error, errOutput string) string {
 	return details.String()
 }
 
+// CompareOutcome classifies the result of a kube-compare run.
+type CompareOutcome string
+
+const (
+	// CompareOutcomeNoDifferences means the cluster matched the reference.
+	CompareOutcomeNoDifferences CompareOutcome = "NoDifferences"
+	// CompareOutcomeDifferencesFound means kube-compare reported differences.
+	CompareOutcomeDifferencesFound CompareOutcome = "DifferencesFound"
+)
+
+// CompareResult is a processed kube-compare run.
+type CompareResult struct {
+	Outcome CompareOutcome
+	// Output is the kube-compare output, or a message when it produced none
+	Output string
+	// DetailAvailable is false when kube-compare produced no output to report
+	DetailAvailable bool
+}
+
+// emptyDifferencesOutput is returned when kube-compare reports differences but
+// produces no output. It is JSON so that callers can read the outcome.
+const emptyDifferencesOutput = `{"outcome":"DifferencesFound","detail_available":false,` +
+	`"message":"Differences were found but no detailed output was generated."}`
+
 // ProcessCompareResult handles the comparison result and formats the output.
 func ProcessCompareResult(output, errOutput string, runErr error) (string, error) {
+	result, err := ProcessCompareRun(output, errOutput, runErr)
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// ProcessCompareRun classifies a kube-compare run from its output and error.
+func ProcessCompareRun(output, errOutput string, runErr error) (*CompareResult, error) {
+	outcome := CompareOutcomeNoDifferences
+	if IsDifferencesFoundError(runErr) {
+		outcome = CompareOutcomeDifferencesFound
+	}
+
 	if output != "" {
 		if runErr != nil && !IsDifferencesFoundError(runErr) {
-			return fmt.Sprintf("%s\n\nWarning: Comparison completed with errors: %v", output, runErr), nil
+			output = fmt.Sprintf("%s\n\nWarning: Comparison completed with errors: %v", output, runErr)
 		}
-		return output, nil
+		return &CompareResult{Outcome: outcome, Output: output, DetailAvailable: true}, nil
 	}
 
 	if runErr != nil {
 		if IsDifferencesFoundError(runErr) {
-			return "Differences were found but no detailed output was generated.", nil
+			// kube-compare writes the differences it finds, so this usually means the
+			// output options did not match what it could render
+			slog.Default().Warn("Comparison reported differences without any output",
+				"error", runErr,
+				"errOutput", errOutput,
+			)
+			return &CompareResult{Outcome: outcome, Output: emptyDifferencesOutput}, nil
 		}
 		details := BuildErrorDetails(runErr, errOutput)
-		return "", NewCompareError("compare", runErr, details)
+		return nil, NewCompareError("compare", runErr, details)
 	}
 
-	return "No differences found between the cluster configuration and reference.", nil
+	return &CompareResult{
+		Outcome: outcome,
+		Output:  "No differences found between the cluster configuration and reference.",
+	}, nil
 }
 
 // IsDifferencesFoundError checks if the error indicates differences were found (not a failure).
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+var _ = Describe("clusterDiffOutput", func() {
+	It("marks a comparison without differences as compliant with zero diffs", func() {
+		processed, err := ProcessCompareRun("", "", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed.Output).To(ContainSubstring("No differences found"))
+
+		output := (&compareRun{outcome: processed.Outcome, output: processed.Output}).clusterDiffOutput()
+		Expect(output.Compliant).To(HaveValue(BeTrue()))
+		Expect(output.NumDiffs).To(HaveValue(BeZero()))
+		Expect(output.KubeCompareVersion).To(Equal(KubeCompareVersion()))
+	})
+
+	It("counts the diffs of the kube-compare summary", func() {
+		run := &compareRun{
+			outcome: CompareOutcomeDifferencesFound,
+			summary: &compare.Summary{NumDiffCRs: 3},
+		}
+		output := run.clusterDiffOutput()
+		Expect(output.Compliant).To(HaveValue(BeFalse()))
+		Expect(output.NumDiffs).To(HaveValue(Equal(3)))
+	})
+
+	It("is not compliant when templates are missing", func() {
+		run := &compareRun{
+			outcome: CompareOutcomeNoDifferences,
+			summary: &compare.Summary{NumMissing: 1},
+		}
+		Expect(run.clusterDiffOutput().Compliant).To(HaveValue(BeFalse()))
+	})
+
+	It("reports the raw counts of kube-compare JSON output", func() {
+		output := `{"Summary":{"ValidationIssuses":{},"NumMissing":1,` +
+			`"UnmatchedCRS":["v1_ConfigMap_default_extra","v1_Secret_default_other"],` +
+			`"NumDiffCRs":2,"TotalCRs":7,"MetadataHash":"abc","patchedCRs":1},"Diffs":[]}`
+		parsed, err := parseCompareOutput(output)
+		Expect(err).NotTo(HaveOccurred())
+		run := &compareRun{outcome: CompareOutcomeDifferencesFound, output: output, summary: parsed.Summary}
+
+		Expect(run.clusterDiffOutput().Counts).To(Equal(&CompareCounts{
+			TotalCRs:     7,
+			NumDiffCRs:   2,
+			NumMissing:   1,
+			NumUnmatched: 2,
+			PatchedCRs:   1,
+		}))
+	})
+
+	It("leaves the counts unset without JSON output", func() {
+		run := &compareRun{outcome: CompareOutcomeDifferencesFound, output: "Summary\nCRs with diffs: 2/7\n"}
+		Expect(run.clusterDiffOutput().Counts).To(BeNil())
+	})
+
+	It("leaves num_diffs unset when differences are reported without output", func() {
+		processed, err := ProcessCompareRun("", "", errors.New("there are differences"))
+		Expect(err).NotTo(HaveOccurred())
+
+		output := (&compareRun{outcome: processed.Outcome, output: processed.Output}).clusterDiffOutput()
+		Expect(output.Compliant).To(HaveValue(BeFalse()))
+		Expect(output.NumDiffs).To(BeNil())
+	})
+})
+
+var _ = Describe("compareRunResult", func() {
+	var output string
+
+	BeforeEach(func() {
+		diffs := []compare.DiffSum{
+			{CRName: "v1_ConfigMap_openshift-monitoring_cluster-monitoring-config", DiffOutput: "-a\n+b"},
+			{CRName: "v1_ConfigMap_openshift-sriov_sriov-config", DiffOutput: "-c\n+d"},
+		}
+		data, err := json.Marshal(&compare.Output{
+			Summary: &compare.Summary{NumDiffCRs: 2, TotalCRs: 5},
+			Diffs:   &diffs,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		output = string(data)
+	})
+
+	filteredRun := func(patterns []string) *compareRun {
+		parsed, err := parseCompareOutput(output)
+		Expect(err).NotTo(HaveOccurred())
+		suppressed := FilterOutputExcludeNamespaces(parsed, patterns)
+		filtered, err := renderCompareOutput(parsed, compare.Json)
+		Expect(err).NotTo(HaveOccurred())
+		return &compareRun{
+			outcome:       CompareOutcomeDifferencesFound,
+			output:        filtered,
+			summary:       parsed.Summary,
+			suppressedCRs: suppressed,
+		}
+	}
+
+	It("does not fail on diff when the filters dropped every diff", func() {
+		args := &CompareArgs{FailOnDiff: true, OutputFormat: compare.Json, ExcludeNamespaces: []string{"openshift-*"}}
+		result, err := compareRunResult(filteredRun(args.ExcludeNamespaces), args)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+	})
+
+	It("fails on diff when diffs remain after filtering", func() {
+		args := &CompareArgs{FailOnDiff: true, OutputFormat: compare.Json, ExcludeNamespaces: []string{"kube-system"}}
+		result, err := compareRunResult(filteredRun(args.ExcludeNamespaces), args)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeTrue())
+	})
+})
+
+var _ = Describe("parseCompareOutput", func() {
+	It("parses JSON output followed by warnings", func() {
+		parsed, err := parseCompareOutput(`{"Summary":{"NumDiffCRs":1,"TotalCRs":1},"Diffs":[]}` + "\nwarning: something")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.Summary.NumDiffCRs).To(Equal(1))
+	})
+
+	It("rejects output that is not JSON", func() {
+		_, err := parseCompareOutput("Summary\nCRs with diffs: 2/7\n")
+		Expect(err).To(MatchError(ContainSubstring("failed to parse comparison output")))
+	})
+})
+
+var _ = Describe("renderCompareOutput", func() {
+	var output *compare.Output
+
+	BeforeEach(func() {
+		diffs := []compare.DiffSum{{DiffOutput: "-a\n+b", CorrelatedTemplate: "cm.yaml", CRName: "v1_ConfigMap_default_app"}}
+		output = &compare.Output{Summary: &compare.Summary{NumDiffCRs: 1, TotalCRs: 1}, Diffs: &diffs}
+	})
+
+	It("renders JSON for JSON and summary output", func() {
+		for _, format := range []string{compare.Json, OutputFormatSummary} {
+			rendered, err := renderCompareOutput(output, format)
+			Expect(err).NotTo(HaveOccurred())
+			parsed, err := parseCompareOutput(rendered)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(output))
+		}
+	})
+
+	It("renders the requested format", func() {
+		rendered, err := renderCompareOutput(output, compare.Yaml)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rendered).To(ContainSubstring("CRName: v1_ConfigMap_default_app"))
+	})
+})
@@ -4,11 +4,13 @@ package mcpserver_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
@@ -210,6 +212,50 @@ var _ = Describe("CompareHandler", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(strings.ToLower(err.Error())).To(ContainSubstring("denied"))
 		})
+
+		Context("with signature verification enabled", func() {
+			var mockVerifier *MockImageVerifier
+
+			BeforeEach(func() {
+				mockVerifier = NewMockImageVerifier(ctrl)
+				service.Verifier = mockVerifier
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), "quay.io/test:v1").
+					Return(nil)
+			})
+
+			It("accepts a signed image", func() {
+				mockVerifier.EXPECT().
+					Verify(gomock.Any(), "quay.io/test:v1").
+					Return("sha256:"+strings.Repeat("a", 64), nil)
+
+				err := service.ValidateOCIReference(context.Background(), "container://quay.io/test:v1:/path")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("rejects an image whose signature does not verify", func() {
+				mockVerifier.EXPECT().
+					Verify(gomock.Any(), "quay.io/test:v1").
+					Return("", errors.New("no signature found"))
+
+				err := service.ValidateOCIReference(context.Background(), "container://quay.io/test:v1:/path")
+				var secErr *mcpserver.SecurityError
+				Expect(errors.As(err, &secErr)).To(BeTrue())
+				Expect(secErr.Code).To(Equal("image-signature-invalid"))
+				Expect(secErr.Message).To(ContainSubstring("no signature found"))
+			})
+		})
+
+		It("does not verify signatures by default", func() {
+			mockRegistry.EXPECT().
+				HeadImage(gomock.Any(), "quay.io/test:v1").
+				Return(nil)
+
+			digest, err := service.VerifyImageSignature(context.Background(), "quay.io/test:v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(BeEmpty())
+			Expect(service.ValidateOCIReference(context.Background(), "container://quay.io/test:v1:/path")).To(Succeed())
+		})
 	})
 
 	Describe("IsDifferencesFoundError", func() {
@@ -309,6 +355,25 @@ var _ = Describe("CompareHandler", func() {
 		})
 	})
 
+	Describe("HandleClusterDiff with an empty reference", func() {
+		DescribeTable("reports the missing reference instead of a local path",
+			func(reference string) {
+				result, _, err := mcpserver.HandleClusterDiff(context.Background(), nil, mcpserver.ClusterDiffInput{
+					Reference: reference,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeTrue())
+
+				text := result.Content[0].(*mcp.TextContent).Text
+				Expect(text).To(ContainSubstring("validation error for 'reference'"))
+				Expect(text).To(ContainSubstring("reference is required and cannot be empty"))
+				Expect(text).NotTo(ContainSubstring("Local filesystem paths are not supported"))
+			},
+			Entry("empty", ""),
+			Entry("whitespace only", "  \t\n"),
+		)
+	})
+
 	Describe("ClusterDiffTool", func() {
 		var tool = mcpserver.ClusterDiffTool()
 
@@ -363,6 +428,54 @@ var _ = Describe("CompareHandler additional tests", func() {
 				"container://quay.io/test:v1:path/file",
 				"", "", true),
 		)
+
+		DescribeTable("references without a tag default to latest",
+			func(ref string, wantImage, wantPath string) {
+				image, path, err := mcpserver.ParseContainerReference(ref)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(image).To(Equal(wantImage))
+				Expect(path).To(Equal(wantPath))
+			},
+			Entry("no tag",
+				"container://quay.io/org/image:/path",
+				"quay.io/org/image:latest", "/path"),
+			Entry("no tag with a nested path",
+				"container://quay.io/org/image:/usr/share/refs/metadata.yaml",
+				"quay.io/org/image:latest", "/usr/share/refs/metadata.yaml"),
+			Entry("no tag with a registry port",
+				"container://registry.local:5000/org/image:/path",
+				"registry.local:5000/org/image:latest", "/path"),
+			Entry("tag with a registry port",
+				"container://registry.local:5000/org/image:v1:/path",
+				"registry.local:5000/org/image:v1", "/path"),
+			Entry("digest with a registry port",
+				"container://registry.local:5000/org/image@sha256:abc123:/path",
+				"registry.local:5000/org/image@sha256:abc123", "/path"),
+			Entry("no registry or tag",
+				"container://image:/path",
+				"image:latest", "/path"),
+			Entry("a path containing a colon",
+				"container://quay.io/org/image:v1:/path/a:/b",
+				"quay.io/org/image:v1", "/path/a:/b"),
+		)
+
+		It("rejects a reference with a path but no image", func() {
+			_, _, err := mcpserver.ParseContainerReference("container://:/path")
+			Expect(err).To(HaveOccurred())
+		})
+
+		DescribeTable("rejects a glob in the file path",
+			func(ref string) {
+				_, _, err := mcpserver.ParseContainerReference(ref)
+				var validationErr *mcpserver.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Message).To(ContainSubstring("glob patterns are not supported"))
+				Expect(validationErr.Hint).To(ContainSubstring("metadata.yaml"))
+			},
+			Entry("a wildcard file name", "container://quay.io/org/image:v1:/refs/*.yaml"),
+			Entry("a single-character wildcard", "container://quay.io/org/image:v1:/refs/metadata.ya?l"),
+			Entry("a character class", "container://quay.io/org/image:v1:/refs/[a-z]/metadata.yaml"),
+		)
 	})
 
 	Describe("ProcessCompareResult additional tests", func() {
@@ -379,4 +492,101 @@ var _ = Describe("CompareHandler additional tests", func() {
 			Expect(result).To(ContainSubstring("No differences"))
 		})
 	})
+
+	Describe("ProcessCompareRun", func() {
+		differencesErr := errors.New("there are differences between the cluster CRs and the reference CRs")
+
+		It("reports differences without output as a structured outcome", func() {
+			result, err := mcpserver.ProcessCompareRun("", "", differencesErr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Outcome).To(Equal(mcpserver.CompareOutcomeDifferencesFound))
+			Expect(result.DetailAvailable).To(BeFalse())
+
+			var output map[string]any
+			Expect(json.Unmarshal([]byte(result.Output), &output)).To(Succeed())
+			Expect(output).To(HaveKeyWithValue("outcome", "DifferencesFound"))
+			Expect(output).To(HaveKeyWithValue("detail_available", false))
+		})
+
+		It("returns the same structured output through ProcessCompareResult", func() {
+			output, err := mcpserver.ProcessCompareResult("", "", differencesErr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring(`"outcome":"DifferencesFound"`))
+		})
+
+		It("reports differences with output as detailed", func() {
+			result, err := mcpserver.ProcessCompareRun(`{"Summary":{"NumDiffCRs":1}}`, "", differencesErr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Outcome).To(Equal(mcpserver.CompareOutcomeDifferencesFound))
+			Expect(result.DetailAvailable).To(BeTrue())
+			Expect(result.Output).To(Equal(`{"Summary":{"NumDiffCRs":1}}`))
+		})
+
+		It("reports a clean comparison as no differences", func() {
+			result, err := mcpserver.ProcessCompareRun(`{"Summary":{"NumDiffCRs":0}}`, "", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Outcome).To(Equal(mcpserver.CompareOutcomeNoDifferences))
+		})
+
+		It("returns other failures as errors", func() {
+			_, err := mcpserver.ProcessCompareRun("", "boom", errors.New("compare failed"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("fail_on_diff input", func() {
+		It("is accepted by kube_compare_cluster_diff", func() {
+			Expect(mcpserver.ClusterDiffInputSchema().Properties).To(HaveKey("fail_on_diff"))
+		})
+	})
+
+	Describe("SummarizeCompareOutput", func() {
+		const reference = "container://quay.io/org/refs:v1:/metadata.yaml"
+
+		const matchingOutput = `{
+  "Summary": {"ValidationIssuses": {}, "NumMissing": 0, "UnmatchedCRS": [], "NumDiffCRs": 0, "TotalCRs": 12},
+  "Diffs": [{"CRName": "apps/v1_Deployment_default_my-app", "CorrelatedTemplate": "deployment.yaml", "DiffOutput": ""}]
+}`
+
+		const driftingOutput = `{
+  "Summary": {"ValidationIssuses": {}, "NumMissing": 0, "UnmatchedCRS": [], "NumDiffCRs": 2, "TotalCRs": 12},
+  "Diffs": [{"CRName": "apps/v1_Deployment_default_my-app", "CorrelatedTemplate": "deployment.yaml", "DiffOutput": "--- reference\n+++ cluster\n"}]
+}`
+
+		DescribeTable("computes the verdict",
+			func(output string, compliant bool, numDiffs int) {
+				summary, err := mcpserver.SummarizeCompareOutput(output, reference)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.Compliant).To(Equal(compliant))
+				Expect(summary.NumDiffs).To(Equal(numDiffs))
+				Expect(summary.Reference).To(Equal(reference))
+
+				data, err := json.Marshal(summary)
+				Expect(err).NotTo(HaveOccurred())
+				var fields map[string]any
+				Expect(json.Unmarshal(data, &fields)).To(Succeed())
+				Expect(fields).To(HaveLen(3))
+				Expect(fields).To(HaveKey("compliant"))
+				Expect(fields).To(HaveKey("num_diffs"))
+				Expect(fields).To(HaveKey("reference"))
+				Expect(string(data)).NotTo(ContainSubstring("DiffOutput"))
+			},
+			Entry("matching cluster", matchingOutput, true, 0),
+			Entry("drifting cluster", driftingOutput, false, 2),
+			Entry("missing required templates",
+				`{"Summary": {"NumMissing": 1, "NumDiffCRs": 0}, "Diffs": []}`, false, 0),
+			Entry("output followed by a warning",
+				driftingOutput+"\n\nWarning: Comparison completed with errors: boom", false, 2),
+		)
+
+		It("rejects output that is not kube-compare JSON", func() {
+			_, err := mcpserver.SummarizeCompareOutput("No differences found", reference)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects output without a summary", func() {
+			_, err := mcpserver.SummarizeCompareOutput(`{"Diffs": []}`, reference)
+			Expect(err).To(MatchError(ContainSubstring("no summary")))
+		})
+	})
 })
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// clusterLogKey is the log attribute holding the target cluster identity.
+const clusterLogKey = "cluster"
+
+// clusterIdentity returns a stable identifier for the cluster config connects to: a
+// short hash of its API server host. The same cluster gets the same identifier
+// whichever kubeconfig, context, or user reaches it, and neither the host nor any
+// credential appears in it. It returns "" when config has no host.
+func clusterIdentity(config *rest.Config) string {
+	if config == nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(config.Host)), "/")
+	if host == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(host))
+	return "cluster-" + hex.EncodeToString(sum[:6])
+}
+
+// withClusterIdentity returns logger with the identity of the cluster config connects
+// to, so that the remaining log lines of a request name the cluster it touched.
+func withClusterIdentity(logger *slog.Logger, config *rest.Config) *slog.Logger {
+	return withClusterID(logger, clusterIdentity(config))
+}
+
+// withClusterID returns logger with the cluster identifier id, or logger itself when
+// id is empty.
+func withClusterID(logger *slog.Logger, id string) *slog.Logger {
+	if id == "" {
+		return logger
+	}
+	return logger.With(clusterLogKey, id)
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Cluster identity", func() {
+	Describe("clusterIdentity", func() {
+		It("is stable for the same API server", func() {
+			id := clusterIdentity(&rest.Config{Host: "https://api.spoke1.example.com:6443"})
+
+			Expect(id).To(MatchRegexp(`^cluster-[0-9a-f]{12}$`))
+			Expect(clusterIdentity(&rest.Config{Host: "https://API.spoke1.example.com:6443/", BearerToken: "other"})).To(Equal(id))
+		})
+
+		It("differs between API servers", func() {
+			Expect(clusterIdentity(&rest.Config{Host: "https://api.spoke1.example.com:6443"})).
+				NotTo(Equal(clusterIdentity(&rest.Config{Host: "https://api.spoke2.example.com:6443"})))
+		})
+
+		It("does not reveal the host", func() {
+			Expect(clusterIdentity(&rest.Config{Host: "https://api.spoke1.example.com:6443"})).NotTo(ContainSubstring("spoke1"))
+		})
+
+		It("is empty without a host", func() {
+			Expect(clusterIdentity(nil)).To(BeEmpty())
+			Expect(clusterIdentity(&rest.Config{})).To(BeEmpty())
+		})
+	})
+
+	Describe("request logging", func() {
+		It("tags the log lines of a request using a provided kubeconfig with the cluster identity", func() {
+			var logs bytes.Buffer
+			original := slog.Default()
+			slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+			DeferCleanup(func() { slog.SetDefault(original) })
+
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/version" {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"gitVersion":"v1.31.4"}`))
+					return
+				}
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			DeferCleanup(server.Close)
+			kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+users:
+- name: test
+  user:
+    token: secret-token-value
+`, server.URL)
+
+			result, _, err := HandleClusterAccess(context.Background(), &mcp.CallToolRequest{},
+				ClusterAccessInput{Kubeconfig: kubeconfig})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+
+			var checked map[string]any
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				var record map[string]any
+				Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+				if record["msg"] == "Cluster access checked" {
+					checked = record
+				}
+			}
+			Expect(checked).NotTo(BeNil())
+			Expect(checked).To(HaveKeyWithValue(clusterLogKey, clusterIdentity(&rest.Config{Host: server.URL})))
+			Expect(checked).To(HaveKey("requestID"))
+			Expect(logs.String()).NotTo(ContainSubstring("secret-token-value"))
+		})
+	})
+})
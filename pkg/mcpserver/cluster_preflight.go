@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// preflightClusterConnection checks that the cluster the factory is configured for
+// accepts its credentials before the comparison starts, so an expired token is
+// reported as such rather than as a failure partway through the comparison.
+func preflightClusterConnection(factory kcmdutil.Factory) error {
+	restConfig, err := factory.ToRESTConfig()
+	if err != nil {
+		return NewCompareError("cluster-connection",
+			fmt.Errorf("%w: %w", ErrClusterConnection, err),
+			"Verify that the server has access to the cluster via in-cluster config or KUBECONFIG.")
+	}
+	client, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return NewCompareError("cluster-connection",
+			fmt.Errorf("%w: %w", ErrClusterConnection, err),
+			"Verify the kubeconfig is valid")
+	}
+	return checkServerVersion(client)
+}
+
+// checkServerVersion reads the server version and classifies a failure.
+func checkServerVersion(client discovery.ServerVersionInterface) error {
+	if _, err := client.ServerVersion(); err != nil {
+		return classifyClusterAuthError(err)
+	}
+	return nil
+}
+
+// classifyClusterAuthError turns an API error into a CompareError that tells rejected
+// credentials (HTTP 401, typically an expired or revoked bearer token) apart from
+// valid credentials that lack permission (HTTP 403). Other errors are reported as a
+// connection failure.
+func classifyClusterAuthError(err error) error {
+	switch {
+	case apierrors.IsUnauthorized(err):
+		return NewCompareError("cluster-connection",
+			fmt.Errorf("%w: credentials rejected: %w", ErrClusterConnection, err),
+			"The API server rejected the kubeconfig's credentials (HTTP 401). Bearer tokens expire: "+
+				"generate a new token, for example with 'oc create token <service-account>' or 'oc whoami -t', "+
+				"update the kubeconfig, and retry.")
+	case apierrors.IsForbidden(err):
+		return NewCompareError("cluster-connection",
+			fmt.Errorf("%w: access denied: %w", ErrClusterConnection, err),
+			"The API server accepted the credentials but RBAC denied the request (HTTP 403). "+
+				"Grant the user or service account read access to the cluster; kube_compare_check_cluster_access lists the permissions the tools need.")
+	default:
+		return NewCompareError("cluster-connection",
+			fmt.Errorf("%w: %w", ErrClusterConnection, err),
+			BuildErrorDetails(err, ""))
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Cluster connection preflight", func() {
+	Describe("classifyClusterAuthError", func() {
+		It("reports a 401 as rejected credentials with a token hint", func() {
+			err := classifyClusterAuthError(apierrors.NewUnauthorized("token has expired"))
+
+			Expect(errors.Is(err, ErrClusterConnection)).To(BeTrue())
+			Expect(apierrors.IsUnauthorized(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("credentials rejected"))
+			Expect(err.Error()).To(ContainSubstring("HTTP 401"))
+			Expect(err.Error()).To(ContainSubstring("oc create token"))
+		})
+
+		It("reports a 403 as an RBAC denial rather than an expired token", func() {
+			err := classifyClusterAuthError(apierrors.NewForbidden(
+				schema.GroupResource{}, "", errors.New("user cannot get path /version")))
+
+			Expect(errors.Is(err, ErrClusterConnection)).To(BeTrue())
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("access denied"))
+			Expect(err.Error()).To(ContainSubstring("HTTP 403"))
+			Expect(err.Error()).NotTo(ContainSubstring("oc create token"))
+		})
+
+		It("reports other failures as a connection failure", func() {
+			err := classifyClusterAuthError(errors.New("dial tcp 10.0.0.1:6443: connect: connection refused"))
+
+			Expect(errors.Is(err, ErrClusterConnection)).To(BeTrue())
+			Expect(err.Error()).NotTo(ContainSubstring("HTTP 401"))
+			Expect(err.Error()).NotTo(ContainSubstring("HTTP 403"))
+		})
+	})
+
+	Describe("checkServerVersion", func() {
+		serverReturning := func(status int) discovery.ServerVersionInterface {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					_, _ = w.Write([]byte(`{"major":"1","minor":"31","gitVersion":"v1.31.0"}`))
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			client, err := discovery.NewDiscoveryClientForConfig(&rest.Config{Host: server.URL, BearerToken: "expired"})
+			Expect(err).NotTo(HaveOccurred())
+			return client
+		}
+
+		It("succeeds when the server accepts the credentials", func() {
+			Expect(checkServerVersion(serverReturning(http.StatusOK))).To(Succeed())
+		})
+
+		It("classifies a 401 response as rejected credentials", func() {
+			err := checkServerVersion(serverReturning(http.StatusUnauthorized))
+			Expect(apierrors.IsUnauthorized(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("credentials rejected"))
+		})
+
+		It("classifies a 403 response as denied access", func() {
+			err := checkServerVersion(serverReturning(http.StatusForbidden))
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("access denied"))
+		})
+	})
+})
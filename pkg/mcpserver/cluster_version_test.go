@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// newTestClusterVersion builds a ClusterVersion with the given desired version and
+// status.history entries, newest first, each given as a version and state pair.
+func newTestClusterVersion(desired string, history ...[2]string) *unstructured.Unstructured {
+	entries := make([]any, 0, len(history))
+	for _, h := range history {
+		entries = append(entries, map[string]any{"version": h[0], "state": h[1]})
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "config.openshift.io/v1",
+		"kind":       "ClusterVersion",
+		"metadata":   map[string]any{"name": "version"},
+		"status": map[string]any{
+			"desired": map[string]any{"version": desired},
+			"history": entries,
+		},
+	}}
+}
+
+var _ = Describe("DefaultClusterClient.GetClusterVersion", func() {
+	getClusterVersion := func(cv *unstructured.Unstructured) (*ClusterVersionInfo, error) {
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				{Group: "config.openshift.io", Version: "v1", Resource: "clusterversions"}: "ClusterVersionList",
+			}, cv)
+		return (&DefaultClusterClient{client: client}).GetClusterVersion(context.Background())
+	}
+
+	It("reports the desired version when the latest update completed", func() {
+		info, err := getClusterVersion(newTestClusterVersion("4.18.5",
+			[2]string{"4.18.5", "Completed"},
+			[2]string{"4.18.2", "Completed"},
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Upgrading).To(BeFalse())
+		Expect(info.Completed).To(Equal("4.18.5"))
+		Expect(info.Current()).To(Equal("4.18.5"))
+	})
+
+	It("reports the last completed version during an upgrade", func() {
+		info, err := getClusterVersion(newTestClusterVersion("4.19.1",
+			[2]string{"4.19.1", "Partial"},
+			[2]string{"4.18.5", "Completed"},
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Upgrading).To(BeTrue())
+		Expect(info.Desired).To(Equal("4.19.1"))
+		Expect(info.Completed).To(Equal("4.18.5"))
+		Expect(info.Current()).To(Equal("4.18.5"))
+	})
+
+	It("skips superseded partial updates when looking for the completed version", func() {
+		info, err := getClusterVersion(newTestClusterVersion("4.19.2",
+			[2]string{"4.19.2", "Partial"},
+			[2]string{"4.19.1", "Partial"},
+			[2]string{"4.18.5", "Completed"},
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Upgrading).To(BeTrue())
+		Expect(info.Current()).To(Equal("4.18.5"))
+	})
+
+	It("does not report an upgrade while the cluster is still installing", func() {
+		info, err := getClusterVersion(newTestClusterVersion("4.18.5",
+			[2]string{"4.18.5", "Partial"},
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Upgrading).To(BeFalse())
+		Expect(info.Current()).To(Equal("4.18.5"))
+	})
+
+	It("falls back to the desired version without history", func() {
+		info, err := getClusterVersion(newTestClusterVersion("4.18.5"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Upgrading).To(BeFalse())
+		Expect(info.Current()).To(Equal("4.18.5"))
+	})
+
+	It("reads status.desired when it is a string", func() {
+		cv := newTestClusterVersion("", [2]string{"4.18.5", "Completed"})
+		Expect(unstructured.SetNestedField(cv.Object, "4.18.5", "status", "desired")).To(Succeed())
+		info, err := getClusterVersion(cv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Current()).To(Equal("4.18.5"))
+	})
+
+	It("falls back to the latest completed history entry without a desired version", func() {
+		cv := newTestClusterVersion("",
+			[2]string{"4.19.1", "Partial"},
+			[2]string{"4.18.5", "Completed"},
+			[2]string{"4.18.2", "Completed"},
+		)
+		unstructured.RemoveNestedField(cv.Object, "status", "desired")
+		info, err := getClusterVersion(cv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Upgrading).To(BeFalse())
+		Expect(info.Current()).To(Equal("4.18.5"))
+	})
+
+	It("falls back to status.version without a desired version or history", func() {
+		cv := newTestClusterVersion("")
+		unstructured.RemoveNestedField(cv.Object, "status", "desired")
+		Expect(unstructured.SetNestedField(cv.Object, "4.17.9", "status", "version")).To(Succeed())
+		info, err := getClusterVersion(cv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Current()).To(Equal("4.17.9"))
+	})
+
+	It("errors when no version is set anywhere", func() {
+		cv := newTestClusterVersion("4.18.5", [2]string{"4.18.5", "Partial"})
+		unstructured.RemoveNestedField(cv.Object, "status", "desired")
+		_, err := getClusterVersion(cv)
+		Expect(err).To(MatchError(ContainSubstring("version not found")))
+		Expect(err).To(MatchError(ContainSubstring("status.version")))
+	})
+})
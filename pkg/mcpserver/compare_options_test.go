@@ -0,0 +1,276 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("buildCompareOptions", func() {
+	var (
+		certData = []byte("test-client-cert")
+		keyData  = []byte("test-client-key")
+		caData   = []byte("test-ca-data")
+	)
+
+	kubeconfig := func() string {
+		b64 := base64.StdEncoding.EncodeToString
+		return `
+apiVersion: v1
+kind: Config
+current-context: test-context
+clusters:
+- name: test-cluster
+  cluster:
+    server: https://192.168.1.100:6443
+    certificate-authority-data: ` + b64(caData) + `
+users:
+- name: test-user
+  user:
+    token: test-token-12345
+    client-certificate-data: ` + b64(certData) + `
+    client-key-data: ` + b64(keyData) + `
+    as: impersonated-user
+contexts:
+- name: test-context
+  context:
+    cluster: test-cluster
+    user: test-user
+`
+	}
+
+	It("configures the factory with the credentials from the kubeconfig", func() {
+		args := &CompareArgs{Kubeconfig: kubeconfig(), OutputFormat: "yaml"}
+
+		opts, factory, err := buildCompareOptions(args, "/tmp/ref/metadata.yaml", "/tmp/work", genericiooptions.NewTestIOStreamsDiscard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.ReferenceConfig).To(Equal("/tmp/ref/metadata.yaml"))
+		Expect(opts.OutputFormat).To(Equal("yaml"))
+		Expect(opts.TmpDir).To(Equal("/tmp/work"))
+
+		config, err := factory.ToRESTConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Host).To(Equal("https://192.168.1.100:6443"))
+		Expect(config.CertData).To(Equal(certData))
+		Expect(config.KeyData).To(Equal(keyData))
+		Expect(config.CAData).To(Equal(caData))
+		Expect(config.BearerToken).To(Equal("test-token-12345"))
+		Expect(config.Impersonate.UserName).To(Equal("impersonated-user"))
+	})
+
+	It("requests JSON from kube-compare for derived output", func() {
+		opts, _, err := buildCompareOptions(&CompareArgs{OutputFormat: OutputFormatSummary}, "", "", genericiooptions.NewTestIOStreamsDiscard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.OutputFormat).To(Equal("json"))
+
+		opts, _, err = buildCompareOptions(&CompareArgs{OutputFormat: "junit", ChangedSince: time.Now()}, "", "", genericiooptions.NewTestIOStreamsDiscard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.OutputFormat).To(Equal("json"))
+	})
+
+	It("rejects kubeconfigs with exec authentication", func() {
+		execKubeconfig := `
+apiVersion: v1
+kind: Config
+current-context: c
+clusters:
+- name: c
+  cluster:
+    server: https://192.168.1.100:6443
+users:
+- name: u
+  user:
+    exec:
+      command: /bin/true
+      apiVersion: client.authentication.k8s.io/v1beta1
+contexts:
+- name: c
+  context:
+    cluster: c
+    user: u
+`
+		_, _, err := buildCompareOptions(&CompareArgs{Kubeconfig: execKubeconfig}, "", "", genericiooptions.NewTestIOStreamsDiscard())
+		var secErr *SecurityError
+		Expect(errors.As(err, &secErr)).To(BeTrue())
+		Expect(secErr.Code).To(Equal("exec-auth-blocked"))
+	})
+})
+
+var _ = Describe("compareCommandEquivalent", func() {
+	It("reflects the reference, output format, and all_resources without the kubeconfig", func() {
+		kubeconfig := "apiVersion: v1\nkind: Config\nusers:\n- name: u\n  user:\n    token: secret-token-value\n"
+		command := compareCommandEquivalent(&CompareArgs{
+			Reference:    "container://quay.io/org/refs:v1:/reference/metadata.yaml",
+			OutputFormat: "yaml",
+			AllResources: true,
+			Kubeconfig:   kubeconfig,
+			Context:      "spoke 1",
+		})
+
+		Expect(command).To(Equal("kubectl cluster-compare -r container://quay.io/org/refs:v1:/reference/metadata.yaml " +
+			"-o yaml --all-resources --kubeconfig '<redacted>' --context 'spoke 1'"))
+		Expect(command).NotTo(ContainSubstring("secret-token-value"))
+	})
+
+	It("uses the format kube-compare runs with and omits unset flags", func() {
+		command := compareCommandEquivalent(&CompareArgs{
+			Reference:    "https://example.com/it's/metadata.yaml",
+			OutputFormat: OutputFormatSummary,
+		})
+
+		Expect(command).To(Equal(`kubectl cluster-compare -r 'https://example.com/it'\''s/metadata.yaml' -o json`))
+	})
+})
+
+var _ = Describe("wrapWithRestConfig", func() {
+	It("copies TLS, token, basic auth, and impersonation fields", func() {
+		source := &rest.Config{
+			Host:            "https://api.example.com:6443",
+			BearerToken:     "token",
+			BearerTokenFile: "/var/run/token",
+			Username:        "user",
+			Password:        "password",
+			Impersonate:     rest.ImpersonationConfig{UserName: "admin", Groups: []string{"system:masters"}},
+			TLSClientConfig: rest.TLSClientConfig{
+				ServerName: "api.example.com",
+				CertData:   []byte("cert"),
+				KeyData:    []byte("key"),
+				CAData:     []byte("ca"),
+			},
+		}
+
+		wrapped := wrapWithRestConfig(source)(&rest.Config{
+			Host:        "https://loaded.example.com",
+			BearerToken: "loaded-token",
+			QPS:         50,
+		})
+
+		Expect(wrapped.Host).To(Equal(source.Host))
+		Expect(wrapped.TLSClientConfig).To(Equal(source.TLSClientConfig))
+		Expect(wrapped.CertData).To(Equal([]byte("cert")))
+		Expect(wrapped.KeyData).To(Equal([]byte("key")))
+		Expect(wrapped.CAData).To(Equal([]byte("ca")))
+		Expect(wrapped.BearerToken).To(Equal("token"))
+		Expect(wrapped.BearerTokenFile).To(Equal("/var/run/token"))
+		Expect(wrapped.Username).To(Equal("user"))
+		Expect(wrapped.Password).To(Equal("password"))
+		Expect(wrapped.Impersonate).To(Equal(source.Impersonate))
+		// Settings that are not credentials are kept
+		Expect(wrapped.QPS).To(BeEquivalentTo(50))
+	})
+})
+
+var _ = Describe("validateReference", func() {
+	DescribeTable("rejects a missing reference before classifying it",
+		func(reference string) {
+			err := validateReference(context.Background(), &CompareArgs{Reference: reference})
+
+			var valErr *ValidationError
+			Expect(errors.As(err, &valErr)).To(BeTrue())
+			Expect(valErr.Field).To(Equal("reference"))
+			Expect(valErr.Message).To(ContainSubstring("required"))
+			Expect(valErr.Hint).To(ContainSubstring("container://"))
+			Expect(errors.Is(err, ErrLocalPathNotSupported)).To(BeFalse())
+		},
+		Entry("empty", ""),
+		Entry("whitespace only", " \t "),
+	)
+})
+
+var _ = Describe("validateReferences", func() {
+	var (
+		server   *httptest.Server
+		inFlight atomic.Int32
+		maxSeen  atomic.Int32
+	)
+
+	BeforeEach(func() {
+		inFlight.Store(0)
+		maxSeen.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxSeen.Load()
+				if current <= seen || maxSeen.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			if strings.HasPrefix(r.URL.Path, "/missing") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(server.Close)
+
+		original := defaultCompareService
+		defaultCompareService = &CompareService{HTTPClient: server.Client(), Registry: DefaultRegistry}
+		DeferCleanup(func() { defaultCompareService = original })
+	})
+
+	It("returns nil when every reference is reachable", func() {
+		refs := []string{server.URL + "/core/metadata.yaml", server.URL + "/ran/metadata.yaml"}
+
+		_, err := validateReferences(context.Background(), refs)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("reports every invalid reference, attributed to its reference", func() {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		refs := []string{
+			server.URL + "/core/metadata.yaml",
+			server.URL + "/missing/metadata.yaml",
+			closed.URL + "/hub/metadata.yaml",
+			"/local/metadata.yaml",
+		}
+		_, err := validateReferences(context.Background(), refs)
+
+		var refsErr *ReferencesValidationError
+		Expect(errors.As(err, &refsErr)).To(BeTrue())
+		Expect(refsErr.Total).To(Equal(4))
+		Expect(refsErr.Failures).To(HaveLen(3))
+		Expect(refsErr.Failures[0].Reference).To(Equal(refs[1]))
+		Expect(refsErr.Failures[0].Err.Error()).To(ContainSubstring("404"))
+		Expect(refsErr.Failures[1].Reference).To(Equal(refs[2]))
+		Expect(errors.Is(refsErr.Failures[1].Err, ErrRemoteUnreachable)).To(BeTrue())
+		Expect(refsErr.Failures[2].Reference).To(Equal(refs[3]))
+		Expect(errors.Is(refsErr.Failures[2].Err, ErrLocalPathNotSupported)).To(BeTrue())
+
+		message := formatErrorForUser(err)
+		Expect(message).To(HavePrefix("3 of 4 references failed validation"))
+		for _, ref := range refs[1:] {
+			Expect(message).To(ContainSubstring(ref))
+		}
+		Expect(message).NotTo(ContainSubstring(refs[0] + ":"))
+	})
+
+	It("validates references concurrently up to the limit", func() {
+		refs := make([]string, 3*maxConcurrentReferenceValidations)
+		for i := range refs {
+			refs[i] = fmt.Sprintf("%s/ref-%d/metadata.yaml", server.URL, i)
+		}
+
+		_, err := validateReferences(context.Background(), refs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(maxSeen.Load()).To(BeNumerically(">", 1))
+		Expect(maxSeen.Load()).To(BeNumerically("<=", maxConcurrentReferenceValidations))
+	})
+})
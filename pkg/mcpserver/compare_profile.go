@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// CompareProfile is a named set of default comparison options. A tool call selects a
+// profile with its profile input; options the call sets explicitly take precedence.
+type CompareProfile struct {
+	OutputFormat             string   `json:"output_format,omitempty"`
+	AllResources             bool     `json:"all_resources,omitempty"`
+	ExcludeNamespaces        []string `json:"exclude_namespaces,omitempty"`
+	IncludeReferenceMetadata bool     `json:"include_reference_metadata,omitempty"`
+}
+
+// compareProfilesFile is the layout of the file named by KUBE_COMPARE_MCP_PROFILES_FILE.
+type compareProfilesFile struct {
+	Profiles map[string]CompareProfile `json:"profiles"`
+}
+
+// compareOutputFormats are the output_format values a profile may set.
+var compareOutputFormats = []string{"json", "yaml", "junit", OutputFormatSummary}
+
+// getProfilesFile returns the path of the YAML file defining comparison profiles.
+// Can be configured via KUBE_COMPARE_MCP_PROFILES_FILE environment variable.
+func getProfilesFile() string {
+	return os.Getenv("KUBE_COMPARE_MCP_PROFILES_FILE")
+}
+
+// loadCompareProfile reads the profile called name from the profiles file. The file
+// is read on every call so that changes to a mounted ConfigMap apply without a restart.
+func loadCompareProfile(name string) (*CompareProfile, error) {
+	path := getProfilesFile()
+	if path == "" {
+		return nil, NewValidationError("profile",
+			fmt.Sprintf("profile %q was requested but no profiles are configured", name),
+			"Set KUBE_COMPARE_MCP_PROFILES_FILE on the server, or omit profile")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewCompareError("profile",
+			fmt.Errorf("failed to read profiles file: %w", err),
+			"Verify that KUBE_COMPARE_MCP_PROFILES_FILE points to a readable file")
+	}
+	var file compareProfilesFile
+	if err := sigsyaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, NewCompareError("profile",
+			fmt.Errorf("failed to parse profiles file: %w", err),
+			"The profiles file must contain a 'profiles' map of profile names to comparison options")
+	}
+
+	profile, ok := file.Profiles[name]
+	if !ok {
+		return nil, NewValidationError("profile",
+			fmt.Sprintf("unknown profile %q", name),
+			"Available profiles: "+strings.Join(slices.Sorted(maps.Keys(file.Profiles)), ", "))
+	}
+	if profile.OutputFormat != "" && !slices.Contains(compareOutputFormats, profile.OutputFormat) {
+		return nil, NewCompareError("profile",
+			fmt.Errorf("profile %q has invalid output_format %q", name, profile.OutputFormat),
+			"Use one of: "+strings.Join(compareOutputFormats, ", "))
+	}
+	if err := validateNamespacePatterns(profile.ExcludeNamespaces); err != nil {
+		return nil, NewCompareError("profile",
+			fmt.Errorf("profile %q: %w", name, err), "")
+	}
+	return &profile, nil
+}
+
+// applyTo fills in the options of args that the tool call left unset. Booleans can
+// only be turned on by a profile, since an unset boolean input reads as false.
+func (p *CompareProfile) applyTo(args *CompareArgs) {
+	if args.OutputFormat == "" {
+		args.OutputFormat = p.OutputFormat
+	}
+	args.AllResources = args.AllResources || p.AllResources
+	if args.ExcludeNamespaces == nil {
+		args.ExcludeNamespaces = p.ExcludeNamespaces
+	}
+	args.IncludeReferenceMetadata = args.IncludeReferenceMetadata || p.IncludeReferenceMetadata
+}
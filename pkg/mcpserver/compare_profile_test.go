@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const testProfiles = `profiles:
+  noisy-cluster:
+    output_format: summary
+    all_resources: true
+    exclude_namespaces: [kube-system, "openshift-*"]
+  audit:
+    output_format: json
+    include_reference_metadata: true
+`
+
+var _ = Describe("Comparison profiles", func() {
+	writeProfiles := func(content string) {
+		path := filepath.Join(GinkgoT().TempDir(), "profiles.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_PROFILES_FILE", path)
+	}
+
+	Describe("loadCompareProfile", func() {
+		BeforeEach(func() {
+			writeProfiles(testProfiles)
+		})
+
+		It("reads the named profile", func() {
+			profile, err := loadCompareProfile("noisy-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(profile).To(Equal(&CompareProfile{
+				OutputFormat:      OutputFormatSummary,
+				AllResources:      true,
+				ExcludeNamespaces: []string{"kube-system", "openshift-*"},
+			}))
+		})
+
+		It("lists the available profiles for an unknown name", func() {
+			_, err := loadCompareProfile("missing")
+			Expect(err).To(MatchError(ContainSubstring(`unknown profile "missing"`)))
+			Expect(err).To(MatchError(ContainSubstring("Available profiles: audit, noisy-cluster")))
+		})
+
+		It("errors when no profiles file is configured", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_PROFILES_FILE", "")
+			_, err := loadCompareProfile("audit")
+			Expect(err).To(MatchError(ContainSubstring("no profiles are configured")))
+		})
+
+		It("rejects unknown options", func() {
+			writeProfiles("profiles:\n  typo:\n    ignore_missing: true\n")
+			_, err := loadCompareProfile("typo")
+			Expect(err).To(MatchError(ContainSubstring("failed to parse profiles file")))
+		})
+
+		It("rejects an invalid output format", func() {
+			writeProfiles("profiles:\n  bad:\n    output_format: html\n")
+			_, err := loadCompareProfile("bad")
+			Expect(err).To(MatchError(ContainSubstring(`invalid output_format "html"`)))
+		})
+	})
+
+	Describe("applyTo", func() {
+		var profile *CompareProfile
+
+		BeforeEach(func() {
+			profile = &CompareProfile{
+				OutputFormat:             OutputFormatSummary,
+				AllResources:             true,
+				ExcludeNamespaces:        []string{"kube-system"},
+				IncludeReferenceMetadata: true,
+			}
+		})
+
+		It("fills in the options the call left unset", func() {
+			args := &CompareArgs{}
+			profile.applyTo(args)
+			Expect(args.OutputFormat).To(Equal(OutputFormatSummary))
+			Expect(args.AllResources).To(BeTrue())
+			Expect(args.ExcludeNamespaces).To(Equal([]string{"kube-system"}))
+			Expect(args.IncludeReferenceMetadata).To(BeTrue())
+		})
+
+		It("lets explicit inputs override the profile", func() {
+			args := &CompareArgs{OutputFormat: "yaml", ExcludeNamespaces: []string{}}
+			profile.applyTo(args)
+			Expect(args.OutputFormat).To(Equal("yaml"))
+			Expect(args.ExcludeNamespaces).To(BeEmpty())
+			Expect(args.ExcludeNamespaces).NotTo(BeNil())
+		})
+	})
+
+	Describe("HandleClusterDiff", func() {
+		It("reports an unknown profile", func() {
+			writeProfiles(testProfiles)
+			result, _, err := HandleClusterDiff(context.Background(), nil, ClusterDiffInput{
+				Reference: "https://example.com/metadata.yaml",
+				Profile:   "missing",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("unknown profile"))
+		})
+	})
+})
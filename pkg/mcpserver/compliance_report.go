@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxConcurrentComplianceChecks bounds how many compliance domains are checked at once.
+const maxConcurrentComplianceChecks = 2
+
+const (
+	// ComplianceDomainRDS is the section comparing the cluster against a Telco RDS.
+	ComplianceDomainRDS = "rds"
+	// ComplianceDomainBIOS is the section comparing bare metal hosts against BIOS references.
+	ComplianceDomainBIOS = "bios"
+)
+
+const (
+	// ComplianceStatusCompliant is a domain, or a report, with no drift found.
+	ComplianceStatusCompliant = "compliant"
+	// ComplianceStatusNonCompliant is a domain, or a report, with drift found.
+	ComplianceStatusNonCompliant = "non_compliant"
+	// ComplianceStatusError is a domain whose check failed.
+	ComplianceStatusError = "error"
+	// ComplianceStatusIncomplete is a report with no drift found in the domains that
+	// were checked, while at least one domain failed.
+	ComplianceStatusIncomplete = "incomplete"
+)
+
+// ComplianceReportInput defines the typed input for the cluster_compliance_report tool.
+type ComplianceReportInput struct {
+	Kubeconfig    string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for the cluster to report on. If omitted, uses in-cluster config."`
+	Context       string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig."`
+	RDSType       string `json:"rds_type,omitempty" jsonschema:"RDS type to compare the cluster against: core, ran, or hub. Omit to skip the RDS section."`
+	BIOSNamespace string `json:"bios_namespace,omitempty" jsonschema:"Namespace containing the BareMetalHost resources to compare against BIOS references. Omit to skip the BIOS section."`
+}
+
+// ComplianceSection is the outcome of one compliance domain of the report.
+type ComplianceSection struct {
+	Domain string `json:"domain"`
+	Status string `json:"status"`
+	// Result is the domain's own result: the kube_compare_validate_rds summary for rds,
+	// and the baremetal_bios_diff result for bios
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ComplianceReport is the structured response for the cluster_compliance_report tool.
+type ComplianceReport struct {
+	Verdict  string              `json:"verdict"`
+	Sections []ComplianceSection `json:"sections"`
+}
+
+// complianceCheck checks one compliance domain. run reports whether the domain is
+// compliant along with the result to return in its section.
+type complianceCheck struct {
+	domain string
+	run    func(ctx context.Context) (bool, any, error)
+}
+
+// ComplianceReportTool returns the MCP tool definition for the unified compliance report.
+func ComplianceReportTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:  "cluster_compliance_report",
+		Title: "Cluster Compliance Report",
+		Description: "Run the RDS and BIOS compliance checks that apply to a cluster concurrently and return one report " +
+			"with a section per domain and an overall verdict. A failed domain is reported in its section " +
+			"without failing the others.",
+		InputSchema:  ComplianceReportInputSchema(),
+		OutputSchema: ComplianceReportOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleComplianceReport is the MCP tool handler for the cluster_compliance_report tool.
+func HandleComplianceReport(ctx context.Context, req *mcp.CallToolRequest, input ComplianceReportInput) (toolResult *mcp.CallToolResult, report *ComplianceReport, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Info("Received tool request",
+		"tool", "cluster_compliance_report",
+		"rdsType", input.RDSType,
+		"biosNamespace", input.BIOSNamespace,
+		"hasKubeconfig", input.Kubeconfig != "",
+		"context", input.Context,
+	)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	// Validate context requires kubeconfig
+	if input.Context != "" && input.Kubeconfig == "" {
+		err := NewValidationError("context",
+			"'context' parameter requires 'kubeconfig' to also be provided",
+			"Provide a kubeconfig along with the context name")
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	checks, err := complianceChecks(input, logger)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	report = runComplianceChecks(ctx, checks, logger)
+
+	outputBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	logger.Info("Compliance report completed",
+		"duration", time.Since(start),
+		"domains", len(report.Sections),
+		"verdict", report.Verdict,
+	)
+
+	return newToolResultText(string(outputBytes)), report, nil
+}
+
+// complianceChecks returns the checks for the domains input asks for, in report order.
+// It is an error to ask for none.
+func complianceChecks(input ComplianceReportInput, logger *slog.Logger) ([]complianceCheck, error) {
+	var checks []complianceCheck
+
+	if input.RDSType != "" {
+		rdsTypes, err := selectRDSTypes(input.RDSType, nil)
+		if err != nil {
+			return nil, err
+		}
+		kubeconfigData, err := DecodeOrParseKubeconfig(input.Kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		var kubeconfig string
+		if kubeconfigData != nil {
+			kubeconfig = base64.StdEncoding.EncodeToString(kubeconfigData)
+		}
+		checks = append(checks, complianceCheck{
+			domain: ComplianceDomainRDS,
+			run: func(ctx context.Context) (bool, any, error) {
+				return checkRDSCompliance(ctx, kubeconfig, input.Context, rdsTypes[0], logger.With("domain", ComplianceDomainRDS))
+			},
+		})
+	}
+
+	if input.BIOSNamespace != "" {
+		checks = append(checks, complianceCheck{
+			domain: ComplianceDomainBIOS,
+			run: func(ctx context.Context) (bool, any, error) {
+				return checkBIOSCompliance(ctx, input.Kubeconfig, input.Context, input.BIOSNamespace, logger.With("domain", ComplianceDomainBIOS))
+			},
+		})
+	}
+
+	if len(checks) == 0 {
+		return nil, NewValidationError("rds_type",
+			"no compliance domain to check",
+			"Provide rds_type to compare against an RDS, bios_namespace to compare BIOS settings, or both")
+	}
+	return checks, nil
+}
+
+// runComplianceChecks runs checks concurrently, at most maxConcurrentComplianceChecks
+// at a time, and assembles their sections, in the order of checks, into a report. A
+// check that fails or panics is reported in its own section.
+func runComplianceChecks(ctx context.Context, checks []complianceCheck, logger *slog.Logger) *ComplianceReport {
+	sections := make([]ComplianceSection, len(checks))
+
+	var group errgroup.Group
+	group.SetLimit(maxConcurrentComplianceChecks)
+	for i, check := range checks {
+		group.Go(func() error {
+			sections[i] = runComplianceCheck(ctx, check, logger)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return &ComplianceReport{
+		Verdict:  complianceVerdict(sections),
+		Sections: sections,
+	}
+}
+
+// runComplianceCheck runs one check and returns its section.
+func runComplianceCheck(ctx context.Context, check complianceCheck, logger *slog.Logger) (section ComplianceSection) {
+	section.Domain = check.domain
+
+	// A panic in a check goroutine would otherwise take down the server
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Panic recovered in compliance check",
+				"domain", check.domain,
+				"panic", r,
+				"stackTrace", string(debug.Stack()),
+			)
+			section.Status = ComplianceStatusError
+			section.Result = nil
+			section.Error = fmt.Sprintf("Internal error: %v", r)
+		}
+	}()
+
+	compliant, result, err := check.run(ctx)
+	if err != nil {
+		logger.Debug("Compliance check failed", "domain", check.domain, "error", err)
+		section.Status = ComplianceStatusError
+		section.Error = formatErrorForUser(err)
+		return section
+	}
+
+	section.Result = result
+	section.Status = ComplianceStatusNonCompliant
+	if compliant {
+		section.Status = ComplianceStatusCompliant
+	}
+	return section
+}
+
+// complianceVerdict returns the overall verdict of sections: non-compliant when any
+// domain has drift, incomplete when none does but a domain failed, and compliant
+// otherwise.
+func complianceVerdict(sections []ComplianceSection) string {
+	verdict := ComplianceStatusCompliant
+	for _, section := range sections {
+		switch section.Status {
+		case ComplianceStatusNonCompliant:
+			return ComplianceStatusNonCompliant
+		case ComplianceStatusError:
+			verdict = ComplianceStatusIncomplete
+		}
+	}
+	return verdict
+}
+
+// checkRDSCompliance compares the cluster against the RDS of rdsType, as
+// kube_compare_validate_rds does in summary mode. kubeconfig is base64-encoded.
+func checkRDSCompliance(ctx context.Context, kubeconfig, contextName, rdsType string, logger *slog.Logger) (bool, any, error) {
+	// The comparison always runs against a cluster
+	if kubeconfig == "" {
+		if err := requireExplicitKubeconfig("cluster-config"); err != nil {
+			return false, nil, err
+		}
+	}
+
+	rdsArgs := &ResolveRDSArgs{
+		Kubeconfig: kubeconfig,
+		Context:    contextName,
+	}
+	compareArgs := &CompareArgs{
+		OutputFormat: OutputFormatSummary,
+		Kubeconfig:   kubeconfig,
+		Context:      contextName,
+	}
+	results, _, err := validateRDSTypes(ctx, rdsArgs, []string{rdsType}, compareArgs, logger)
+	if err != nil {
+		return false, nil, err
+	}
+	result := results[rdsType]
+
+	var summary CompareSummary
+	if err := json.Unmarshal(result.Comparison, &summary); err != nil {
+		return false, nil, fmt.Errorf("failed to parse comparison summary: %w", err)
+	}
+	return summary.Compliant, result, nil
+}
+
+// checkBIOSCompliance compares every BareMetalHost in namespace against its BIOS
+// reference, as baremetal_bios_diff does. The domain is compliant when no host
+// differs from its reference and every host could be compared.
+func checkBIOSCompliance(ctx context.Context, kubeconfig, contextName, namespace string, logger *slog.Logger) (bool, any, error) {
+	referenceNamespaces := parseReferenceNamespaces("")
+	targetClient, referenceClient, logger, err := buildBIOSClients(ctx, kubeconfig, contextName, referenceNamespaces, logger)
+	if err != nil {
+		return false, nil, err
+	}
+
+	result, err := runBIOSComparison(ctx, targetClient, referenceClient, namespace, "", referenceNamespaces, "", nil, logger)
+	if err != nil {
+		return false, nil, err
+	}
+	return result.Summary.NumDiffHosts == 0 && result.Summary.ErrorHosts == 0, result, nil
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fixedComplianceCheck returns a check of domain that reports compliant, result, and err.
+func fixedComplianceCheck(domain string, compliant bool, result any, err error) complianceCheck {
+	return complianceCheck{
+		domain: domain,
+		run: func(context.Context) (bool, any, error) {
+			return compliant, result, err
+		},
+	}
+}
+
+var _ = Describe("Compliance report", func() {
+	Describe("ComplianceReportTool", func() {
+		It("has the correct name and schemas", func() {
+			tool := ComplianceReportTool()
+			Expect(tool.Name).To(Equal("cluster_compliance_report"))
+			Expect(tool.InputSchema).NotTo(BeNil())
+			Expect(tool.OutputSchema).NotTo(BeNil())
+		})
+	})
+
+	Describe("runComplianceChecks", func() {
+		rdsSummary := &CompareSummary{Compliant: true, Reference: "container://rds:core"}
+		biosResult := &BIOSDiffResult{Namespace: "site-1", Summary: BIOSDiffSummary{TotalHosts: 2, CompliantHosts: 1, NumDiffHosts: 1}}
+
+		It("returns a section per domain, in order, and a compliant verdict", func() {
+			report := runComplianceChecks(context.Background(), []complianceCheck{
+				fixedComplianceCheck(ComplianceDomainRDS, true, rdsSummary, nil),
+				fixedComplianceCheck(ComplianceDomainBIOS, true, &BIOSDiffResult{Namespace: "site-1"}, nil),
+			}, discardLogger)
+
+			Expect(report.Verdict).To(Equal(ComplianceStatusCompliant))
+			Expect(report.Sections).To(HaveLen(2))
+			Expect(report.Sections[0].Domain).To(Equal(ComplianceDomainRDS))
+			Expect(report.Sections[0].Status).To(Equal(ComplianceStatusCompliant))
+			Expect(report.Sections[0].Result).To(BeIdenticalTo(rdsSummary))
+			Expect(report.Sections[1].Domain).To(Equal(ComplianceDomainBIOS))
+		})
+
+		It("is non-compliant when any domain has drift, even if another failed", func() {
+			report := runComplianceChecks(context.Background(), []complianceCheck{
+				fixedComplianceCheck(ComplianceDomainRDS, false, nil, errors.New("registry unreachable")),
+				fixedComplianceCheck(ComplianceDomainBIOS, false, biosResult, nil),
+			}, discardLogger)
+
+			Expect(report.Verdict).To(Equal(ComplianceStatusNonCompliant))
+			Expect(report.Sections[0].Status).To(Equal(ComplianceStatusError))
+			Expect(report.Sections[0].Error).To(ContainSubstring("registry unreachable"))
+			Expect(report.Sections[0].Result).To(BeNil())
+			Expect(report.Sections[1].Status).To(Equal(ComplianceStatusNonCompliant))
+			Expect(report.Sections[1].Result).To(BeIdenticalTo(biosResult))
+		})
+
+		It("is incomplete when no domain has drift but one failed", func() {
+			report := runComplianceChecks(context.Background(), []complianceCheck{
+				fixedComplianceCheck(ComplianceDomainRDS, true, rdsSummary, nil),
+				fixedComplianceCheck(ComplianceDomainBIOS, false, nil, NewValidationError("namespace", "no BareMetalHosts", "")),
+			}, discardLogger)
+
+			Expect(report.Verdict).To(Equal(ComplianceStatusIncomplete))
+			Expect(report.Sections[1].Error).To(ContainSubstring("no BareMetalHosts"))
+		})
+
+		It("reports a panicking check in its section", func() {
+			report := runComplianceChecks(context.Background(), []complianceCheck{
+				{domain: ComplianceDomainRDS, run: func(context.Context) (bool, any, error) { panic("boom") }},
+			}, discardLogger)
+
+			Expect(report.Verdict).To(Equal(ComplianceStatusIncomplete))
+			Expect(report.Sections[0].Status).To(Equal(ComplianceStatusError))
+			Expect(report.Sections[0].Error).To(ContainSubstring("boom"))
+		})
+
+		It("runs at most maxConcurrentComplianceChecks checks at once", func() {
+			var running, peak atomic.Int32
+			check := complianceCheck{
+				domain: ComplianceDomainRDS,
+				run: func(context.Context) (bool, any, error) {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						current := peak.Load()
+						if n <= current || peak.CompareAndSwap(current, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					return true, nil, nil
+				},
+			}
+
+			report := runComplianceChecks(context.Background(), []complianceCheck{check, check, check, check, check}, discardLogger)
+			Expect(report.Sections).To(HaveLen(5))
+			Expect(peak.Load()).To(BeNumerically("<=", maxConcurrentComplianceChecks))
+		})
+
+		It("encodes the unified report", func() {
+			report := runComplianceChecks(context.Background(), []complianceCheck{
+				fixedComplianceCheck(ComplianceDomainBIOS, false, biosResult, nil),
+			}, discardLogger)
+
+			data, err := json.Marshal(report)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{
+				"verdict": "non_compliant",
+				"sections": [{
+					"domain": "bios",
+					"status": "non_compliant",
+					"result": {
+						"Namespace": "site-1",
+						"Hosts": null,
+						"Summary": {"TotalHosts": 2, "CompliantHosts": 1, "NumDiffHosts": 1, "ErrorHosts": 0}
+					}
+				}]
+			}`))
+		})
+	})
+
+	Describe("complianceChecks", func() {
+		It("checks only the domains that were asked for", func() {
+			checks, err := complianceChecks(ComplianceReportInput{BIOSNamespace: "site-1"}, discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checks).To(HaveLen(1))
+			Expect(checks[0].domain).To(Equal(ComplianceDomainBIOS))
+		})
+
+		It("rejects an unknown rds_type", func() {
+			_, err := complianceChecks(ComplianceReportInput{RDSType: "edge"}, discardLogger)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("HandleComplianceReport", func() {
+		It("fails when no domain is asked for", func() {
+			result, report, err := HandleComplianceReport(context.Background(), &mcp.CallToolRequest{}, ComplianceReportInput{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(BeNil())
+			Expect(result.IsError).To(BeTrue())
+			Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("no compliance domain to check"))
+		})
+	})
+})
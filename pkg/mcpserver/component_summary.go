@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/openshift/kube-compare/pkg/compare"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// maxReferenceMetadataSize bounds how much of a reference metadata.yaml is read.
+const maxReferenceMetadataSize = 10 << 20
+
+// ComponentSummary is the compliance of one reference component in a CompareSummary.
+type ComponentSummary struct {
+	Part       string   `json:"part"`
+	Component  string   `json:"component"`
+	Compliant  bool     `json:"compliant"`
+	NumDiffs   int      `json:"num_diffs"`
+	NumMissing int      `json:"num_missing"`
+	DriftedCRs []string `json:"drifted_crs,omitempty"`
+}
+
+// componentKey identifies a component within a reference part.
+type componentKey struct {
+	part      string
+	component string
+}
+
+// referenceComponents lists a reference's components in metadata order and maps
+// each template path to the component that declares it.
+type referenceComponents struct {
+	components []componentKey
+	templates  map[string]componentKey
+}
+
+// referenceMetadata is the part of a kube-compare metadata.yaml that assigns
+// templates to components and lists the other files the reference reads. It covers
+// both the v1 and v2 formats.
+type referenceMetadata struct {
+	Parts []struct {
+		Name       string               `json:"name"`
+		Components []referenceComponent `json:"components"`
+	} `json:"parts"`
+	TemplateFunctionFiles []string `json:"templateFunctionFiles"`
+}
+
+// referenceComponent is a component of a reference part and the templates it declares.
+type referenceComponent struct {
+	Name string `json:"name"`
+	// v1
+	RequiredTemplates []referenceTemplatePath `json:"requiredTemplates"`
+	OptionalTemplates []referenceTemplatePath `json:"optionalTemplates"`
+	// v2
+	AllOf       []referenceTemplatePath `json:"allOf"`
+	AnyOf       []referenceTemplatePath `json:"anyOf"`
+	OneOf       []referenceTemplatePath `json:"oneOf"`
+	NoneOf      []referenceTemplatePath `json:"noneOf"`
+	AnyOneOf    []referenceTemplatePath `json:"anyOneOf"`
+	AllOrNoneOf []referenceTemplatePath `json:"allOrNoneOf"`
+}
+
+// templates returns the templates the component declares, in metadata order.
+func (c *referenceComponent) templates() []referenceTemplatePath {
+	var templates []referenceTemplatePath
+	for _, group := range [][]referenceTemplatePath{
+		c.RequiredTemplates, c.OptionalTemplates,
+		c.AllOf, c.AnyOf, c.OneOf,
+		c.NoneOf, c.AnyOneOf, c.AllOrNoneOf,
+	} {
+		templates = append(templates, group...)
+	}
+	return templates
+}
+
+type referenceTemplatePath struct {
+	Path string `json:"path"`
+}
+
+// parseReferenceComponents reads the part and component of every template declared
+// in a kube-compare metadata.yaml.
+func parseReferenceComponents(data []byte) (*referenceComponents, error) {
+	var metadata referenceMetadata
+	if err := sigsyaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse reference metadata: %w", err)
+	}
+
+	refComponents := &referenceComponents{templates: make(map[string]componentKey)}
+	for _, part := range metadata.Parts {
+		for _, component := range part.Components {
+			key := componentKey{part: part.Name, component: component.Name}
+			refComponents.components = append(refComponents.components, key)
+
+			for _, template := range component.templates() {
+				refComponents.templates[template.Path] = key
+			}
+		}
+	}
+	return refComponents, nil
+}
+
+// loadReferenceComponents reads referenceConfig, a local metadata.yaml path or an
+// HTTP/HTTPS URL, and returns its components.
+func (s *CompareService) loadReferenceComponents(ctx context.Context, referenceConfig string) (*referenceComponents, error) {
+	data, err := s.readReferenceMetadata(ctx, referenceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return parseReferenceComponents(data)
+}
+
+// readReferenceMetadata returns the content of referenceConfig, a local metadata.yaml
+// path or an HTTP/HTTPS URL, up to maxReferenceMetadataSize bytes.
+func (s *CompareService) readReferenceMetadata(ctx context.Context, referenceConfig string) ([]byte, error) {
+	return s.readReferenceFile(ctx, referenceConfig, "reference metadata")
+}
+
+// readReferenceFile returns the content of location, a local path or an HTTP/HTTPS
+// URL, up to maxReferenceMetadataSize bytes. what names the file in errors.
+func (s *CompareService) readReferenceFile(ctx context.Context, location, what string) ([]byte, error) {
+	if ClassifyReference(location) != ReferenceTypeHTTP {
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", what, err)
+		}
+		if len(data) > maxReferenceMetadataSize {
+			return nil, fmt.Errorf("%s exceeds %d bytes", what, maxReferenceMetadataSize)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference URL: %w", err)
+	}
+	req.Header.Set("User-Agent", "kube-compare-mcp/1.0")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", what, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReferenceMetadataSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", what, err)
+	}
+	return data, nil
+}
+
+// summarizeComponents groups kube-compare output by the reference component each
+// compared CR correlates to. Every component of the reference is listed, in metadata
+// order, so compliant components appear alongside drifted ones. Missing templates come
+// from the part and component grouping of the output's validation issues. Drifted CRs
+// whose template is not declared in the metadata are grouped under an empty part and
+// component.
+func summarizeComponents(output *compare.Output, refComponents *referenceComponents) []ComponentSummary {
+	summaries := make(map[componentKey]*ComponentSummary)
+	var order []componentKey
+	summaryFor := func(key componentKey) *ComponentSummary {
+		if summary, ok := summaries[key]; ok {
+			return summary
+		}
+		summary := &ComponentSummary{Part: key.part, Component: key.component}
+		summaries[key] = summary
+		order = append(order, key)
+		return summary
+	}
+
+	if refComponents != nil {
+		for _, key := range refComponents.components {
+			summaryFor(key)
+		}
+	}
+
+	if output.Diffs != nil {
+		for _, diff := range *output.Diffs {
+			if !diff.HasDiff() {
+				continue
+			}
+			var key componentKey
+			if refComponents != nil {
+				key = refComponents.templates[diff.CorrelatedTemplate]
+			}
+			summary := summaryFor(key)
+			summary.NumDiffs++
+			summary.DriftedCRs = append(summary.DriftedCRs, diff.CRName)
+		}
+	}
+
+	if output.Summary != nil {
+		// Sorted so components missing from the metadata are listed in a stable order
+		for _, part := range slices.Sorted(maps.Keys(output.Summary.ValidationIssues)) {
+			components := output.Summary.ValidationIssues[part]
+			for _, component := range slices.Sorted(maps.Keys(components)) {
+				summaryFor(componentKey{part: part, component: component}).NumMissing += len(components[component].CRs)
+			}
+		}
+	}
+
+	result := make([]ComponentSummary, 0, len(order))
+	for _, key := range order {
+		summary := summaries[key]
+		summary.Compliant = summary.NumDiffs == 0 && summary.NumMissing == 0
+		result = append(result, *summary)
+	}
+	return result
+}
+
+// summarizeReferenceComponents groups parsed kube-compare output by the components of
+// the reference at referenceConfig. The grouping is best effort: when the metadata
+// cannot be read, drifted CRs are still reported under an empty component.
+func summarizeReferenceComponents(ctx context.Context, output *compare.Output, referenceConfig string) []ComponentSummary {
+	refComponents, err := defaultCompareService.loadReferenceComponents(ctx, referenceConfig)
+	if err != nil {
+		slog.Default().Warn("Could not read reference components, grouping drifted CRs without them",
+			"reference", referenceConfig,
+			"error", err,
+		)
+	}
+	return summarizeComponents(output, refComponents)
+}
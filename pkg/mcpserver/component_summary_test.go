@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+const componentTestMetadataV2 = `apiVersion: v2
+parts:
+  - name: Networking
+    components:
+      - name: SriovOperator
+        allOf:
+          - path: networking/sriov-subscription.yaml
+          - path: networking/sriov-operatorconfig.yaml
+      - name: Multus
+        anyOf:
+          - path: networking/net-attach-def.yaml
+  - name: Storage
+    components:
+      - name: LocalStorage
+        allOf:
+          - path: storage/lso-subscription.yaml
+          - path: storage/localvolume.yaml
+`
+
+const componentTestMetadataV1 = `parts:
+  - name: Networking
+    components:
+      - name: SriovOperator
+        type: Required
+        requiredTemplates:
+          - path: networking/sriov-subscription.yaml
+        optionalTemplates:
+          - path: networking/sriov-operatorconfig.yaml
+`
+
+// doerFunc adapts a function to the HTTPDoer interface.
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var _ = Describe("Component summaries", func() {
+
+	Describe("parseReferenceComponents", func() {
+		It("maps v2 templates to their part and component", func() {
+			refComponents, err := parseReferenceComponents([]byte(componentTestMetadataV2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refComponents.components).To(Equal([]componentKey{
+				{part: "Networking", component: "SriovOperator"},
+				{part: "Networking", component: "Multus"},
+				{part: "Storage", component: "LocalStorage"},
+			}))
+			Expect(refComponents.templates).To(HaveKeyWithValue("networking/net-attach-def.yaml",
+				componentKey{part: "Networking", component: "Multus"}))
+			Expect(refComponents.templates).To(HaveKeyWithValue("storage/localvolume.yaml",
+				componentKey{part: "Storage", component: "LocalStorage"}))
+		})
+
+		It("maps v1 required and optional templates", func() {
+			refComponents, err := parseReferenceComponents([]byte(componentTestMetadataV1))
+			Expect(err).NotTo(HaveOccurred())
+			key := componentKey{part: "Networking", component: "SriovOperator"}
+			Expect(refComponents.templates).To(HaveKeyWithValue("networking/sriov-subscription.yaml", key))
+			Expect(refComponents.templates).To(HaveKeyWithValue("networking/sriov-operatorconfig.yaml", key))
+		})
+
+		It("rejects metadata that is not YAML", func() {
+			_, err := parseReferenceComponents([]byte("parts: ["))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("summarizeComponents", func() {
+		var (
+			refComponents *referenceComponents
+			output        *compare.Output
+		)
+
+		BeforeEach(func() {
+			var err error
+			refComponents, err = parseReferenceComponents([]byte(componentTestMetadataV2))
+			Expect(err).NotTo(HaveOccurred())
+
+			diffs := []compare.DiffSum{
+				{CRName: "operators.coreos.com/v1alpha1_Subscription_openshift-sriov-network-operator_sriov", CorrelatedTemplate: "networking/sriov-subscription.yaml", DiffOutput: "-channel: stable"},
+				{CRName: "sriovnetwork.openshift.io/v1_SriovOperatorConfig_openshift-sriov-network-operator_default", CorrelatedTemplate: "networking/sriov-operatorconfig.yaml", DiffOutput: "-enableInjector: true"},
+				{CRName: "k8s.cni.cncf.io/v1_NetworkAttachmentDefinition_default_net1", CorrelatedTemplate: "networking/net-attach-def.yaml"},
+				{CRName: "operators.coreos.com/v1alpha1_Subscription_openshift-local-storage_lso", CorrelatedTemplate: "storage/lso-subscription.yaml", DiffOutput: "-source: redhat-operators"},
+			}
+			output = &compare.Output{
+				Summary: &compare.Summary{
+					ValidationIssues: map[string]map[string]compare.ValidationIssue{
+						"Storage": {"LocalStorage": {Msg: "Missing CRs", CRs: []string{"storage/localvolume.yaml"}}},
+					},
+				},
+				Diffs: &diffs,
+			}
+		})
+
+		It("groups drifted CRs under their originating component", func() {
+			summaries := summarizeComponents(output, refComponents)
+			Expect(summaries).To(Equal([]ComponentSummary{
+				{
+					Part: "Networking", Component: "SriovOperator", NumDiffs: 2,
+					DriftedCRs: []string{
+						"operators.coreos.com/v1alpha1_Subscription_openshift-sriov-network-operator_sriov",
+						"sriovnetwork.openshift.io/v1_SriovOperatorConfig_openshift-sriov-network-operator_default",
+					},
+				},
+				{Part: "Networking", Component: "Multus", Compliant: true},
+				{
+					Part: "Storage", Component: "LocalStorage", NumDiffs: 1, NumMissing: 1,
+					DriftedCRs: []string{"operators.coreos.com/v1alpha1_Subscription_openshift-local-storage_lso"},
+				},
+			}))
+		})
+
+		It("groups drifted CRs with an unknown template under an empty component", func() {
+			*output.Diffs = append(*output.Diffs, compare.DiffSum{
+				CRName: "v1_ConfigMap_default_extra", CorrelatedTemplate: "other/cm.yaml", DiffOutput: "-data",
+			})
+
+			summaries := summarizeComponents(output, refComponents)
+			Expect(summaries).To(ContainElement(ComponentSummary{
+				NumDiffs: 1, DriftedCRs: []string{"v1_ConfigMap_default_extra"},
+			}))
+		})
+
+		It("groups by validation issues alone without reference metadata", func() {
+			summaries := summarizeComponents(output, nil)
+			Expect(summaries).To(HaveLen(2))
+			Expect(summaries[0].Component).To(BeEmpty())
+			Expect(summaries[0].NumDiffs).To(Equal(3))
+			Expect(summaries[1]).To(Equal(ComponentSummary{Part: "Storage", Component: "LocalStorage", NumMissing: 1}))
+		})
+	})
+
+	Describe("loadReferenceComponents", func() {
+		It("reads local metadata", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "metadata.yaml")
+			Expect(os.WriteFile(path, []byte(componentTestMetadataV2), 0o600)).To(Succeed())
+
+			refComponents, err := (&CompareService{}).loadReferenceComponents(context.Background(), path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refComponents.components).To(HaveLen(3))
+		})
+
+		It("fetches remote metadata with the service HTTP client", func() {
+			service := &CompareService{HTTPClient: doerFunc(func(req *http.Request) (*http.Response, error) {
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.String()).To(Equal("https://example.com/ref/metadata.yaml"))
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(componentTestMetadataV2))}, nil
+			})}
+
+			refComponents, err := service.loadReferenceComponents(context.Background(), "https://example.com/ref/metadata.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refComponents.components).To(HaveLen(3))
+		})
+
+		It("reports HTTP errors", func() {
+			service := &CompareService{HTTPClient: doerFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+			})}
+
+			_, err := service.loadReferenceComponents(context.Background(), "https://example.com/ref/metadata.yaml")
+			Expect(err).To(MatchError(ContainSubstring("HTTP 404")))
+		})
+	})
+
+	Describe("summarizeReferenceComponents", func() {
+		It("groups the output by the components of the reference metadata", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "metadata.yaml")
+			Expect(os.WriteFile(path, []byte(componentTestMetadataV2), 0o600)).To(Succeed())
+
+			diffs := []compare.DiffSum{
+				{CRName: "operators.coreos.com/v1alpha1_Subscription_openshift-local-storage_lso", CorrelatedTemplate: "storage/lso-subscription.yaml", DiffOutput: "-source"},
+			}
+			summaries := summarizeReferenceComponents(context.Background(), &compare.Output{Summary: &compare.Summary{}, Diffs: &diffs}, path)
+			Expect(summaries).To(HaveLen(3))
+			Expect(summaries[2].Component).To(Equal("LocalStorage"))
+			Expect(summaries[2].Compliant).To(BeFalse())
+			Expect(summaries[0].Compliant).To(BeTrue())
+		})
+	})
+})
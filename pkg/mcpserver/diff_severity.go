@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+// metadataDriftFields are the field paths whose diffs classify_metadata_diffs reports
+// as metadata drift rather than spec drift. Controllers commonly add labels and
+// annotations, so these diffs are rarely the drift a user is looking for.
+var metadataDriftFields = []string{"metadata.labels", "metadata.annotations"}
+
+// DiffSeverity splits the CRs that differ from the reference by how much their diffs
+// matter. It is returned when classify_metadata_diffs is set.
+type DiffSeverity struct {
+	// SpecDrift lists the CRs with a diff outside metadata.labels and metadata.annotations
+	SpecDrift []string `json:"spec_drift"`
+	// MetadataDrift lists the lower-severity CRs whose diffs only change
+	// metadata.labels or metadata.annotations
+	MetadataDrift []string `json:"metadata_drift"`
+}
+
+// ClassifyDiffSeverity sorts each CR of output that differs from the reference into
+// spec or metadata drift. A CR is metadata drift only when the path of every changed
+// line of its diff can be read from the diff and lies under metadataDriftFields; any
+// other diff, including one that cannot be placed, is spec drift.
+func ClassifyDiffSeverity(output *compare.Output) *DiffSeverity {
+	severity := &DiffSeverity{SpecDrift: []string{}, MetadataDrift: []string{}}
+	if output.Diffs == nil {
+		return severity
+	}
+	for _, diff := range *output.Diffs {
+		if !diff.HasDiff() {
+			continue
+		}
+		if diffOnlyChangesFields(diff.DiffOutput, metadataDriftFields) {
+			severity.MetadataDrift = append(severity.MetadataDrift, diff.CRName)
+		} else {
+			severity.SpecDrift = append(severity.SpecDrift, diff.CRName)
+		}
+	}
+	return severity
+}
+
+// diffOnlyChangesFields reports whether every hunk of a unified diff only changes
+// fields under one of patterns. Output that is not a unified diff never does.
+func diffOnlyChangesFields(diffOutput string, patterns []string) bool {
+	_, hunks := splitDiffHunks(diffOutput)
+	if len(hunks) == 0 {
+		return false
+	}
+	for _, hunk := range hunks {
+		if !hunkOnlyChangesFields(hunk[1:], patterns) {
+			return false
+		}
+	}
+	return true
+}
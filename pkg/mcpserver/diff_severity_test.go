@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+// annotationHunk only changes an annotation added by a controller.
+const annotationHunk = "@@ -1,8 +1,9 @@\n" +
+	" apiVersion: v1\n" +
+	" kind: ConfigMap\n" +
+	" metadata:\n" +
+	"   annotations:\n" +
+	"     owner: platform\n" +
+	"+    kubectl.kubernetes.io/last-applied-configuration: '{}'\n" +
+	"   labels:\n" +
+	"-    app: cm\n" +
+	"+    app: cm-v2\n"
+
+var _ = Describe("classify_metadata_diffs", func() {
+	newOutput := func(diffs ...compare.DiffSum) *compare.Output {
+		return &compare.Output{
+			Summary: &compare.Summary{NumDiffCRs: len(diffs), TotalCRs: len(diffs) + 1},
+			Diffs:   &diffs,
+		}
+	}
+
+	Describe("ClassifyDiffSeverity", func() {
+		It("separates annotation-only drift from spec drift", func() {
+			output := newOutput(
+				compare.DiffSum{CRName: "v1_ConfigMap_ns_annotated", DiffOutput: volatileDiffHeader + annotationHunk},
+				compare.DiffSum{CRName: "apps/v1_Deployment_ns_app", DiffOutput: volatileDiffHeader + driftHunk},
+				compare.DiffSum{CRName: "v1_ConfigMap_ns_same"},
+			)
+
+			severity := ClassifyDiffSeverity(output)
+			Expect(severity.MetadataDrift).To(Equal([]string{"v1_ConfigMap_ns_annotated"}))
+			Expect(severity.SpecDrift).To(Equal([]string{"apps/v1_Deployment_ns_app"}))
+		})
+
+		It("treats a diff with metadata and spec hunks as spec drift", func() {
+			output := newOutput(compare.DiffSum{CRName: "cm", DiffOutput: volatileDiffHeader + annotationHunk + driftHunk})
+			Expect(ClassifyDiffSeverity(output).SpecDrift).To(Equal([]string{"cm"}))
+		})
+
+		It("treats a diff that cannot be placed as spec drift", func() {
+			output := newOutput(
+				compare.DiffSum{CRName: "unplaced", DiffOutput: volatileDiffHeader + unplacedHunk},
+				compare.DiffSum{CRName: "not-unified", DiffOutput: "-a\n+b"},
+			)
+			Expect(ClassifyDiffSeverity(output).SpecDrift).To(Equal([]string{"unplaced", "not-unified"}))
+		})
+
+		It("returns empty lists when nothing differs", func() {
+			severity := ClassifyDiffSeverity(&compare.Output{})
+			Expect(severity.SpecDrift).To(BeEmpty())
+			Expect(severity.MetadataDrift).To(BeEmpty())
+		})
+	})
+
+	It("adds the severity to the structured result", func() {
+		severity := &DiffSeverity{SpecDrift: []string{"drifted"}, MetadataDrift: []string{}}
+		run := &compareRun{
+			outcome:  CompareOutcomeDifferencesFound,
+			summary:  &compare.Summary{NumDiffCRs: 1},
+			severity: severity,
+		}
+		Expect(run.clusterDiffOutput().Severity).To(Equal(severity))
+	})
+
+	It("makes kube-compare output JSON", func() {
+		Expect(compareOutputFormat(&CompareArgs{OutputFormat: compare.Yaml, ClassifyMetadataDiffs: true})).To(Equal(compare.Json))
+	})
+})
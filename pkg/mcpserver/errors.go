@@ -5,6 +5,7 @@ package mcpserver
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Error types for categorizing different failure modes
@@ -25,15 +26,27 @@ var (
 	// ErrOCIImageNotFound indicates the container image was not found
 	ErrOCIImageNotFound = errors.New("container image not found")
 
+	// ErrPlatformNotFound indicates a multi-platform image has no image for the requested platform
+	ErrPlatformNotFound = errors.New("platform not found in image")
+
 	// ErrClusterConnection indicates a failure to connect to the Kubernetes cluster
 	ErrClusterConnection = errors.New("cluster connection failed")
 
+	// ErrMetal3ResourceNotServed indicates the cluster serves a metal3 resource the BIOS tools read at no version
+	ErrMetal3ResourceNotServed = errors.New("metal3 resource not served by the cluster")
+
 	// ErrComparisonFailed indicates the comparison operation failed
 	ErrComparisonFailed = errors.New("comparison failed")
 
 	// ErrContextCanceled indicates the operation was canceled
 	ErrContextCanceled = errors.New("operation canceled")
 
+	// ErrReferenceTimeout indicates acquiring the reference took longer than reference_timeout
+	ErrReferenceTimeout = errors.New("reference acquisition timed out")
+
+	// ErrExtractionQueueTimeout indicates no image extraction slot freed up in time
+	ErrExtractionQueueTimeout = errors.New("timed out waiting for an image extraction slot")
+
 	// ErrSecurityViolation indicates a security policy was violated
 	ErrSecurityViolation = errors.New("security policy violation")
 
@@ -42,6 +55,22 @@ var (
 
 	// ErrAuthProviderBlocked indicates auth provider plugins were blocked for security
 	ErrAuthProviderBlocked = errors.New("auth provider plugins are not allowed")
+
+	// ErrNotInCluster indicates in-cluster config was requested outside a Kubernetes pod
+	ErrNotInCluster = errors.New("not running inside a Kubernetes pod")
+
+	// ErrServiceAccountTokenMissing indicates the pod's service account token could not be read
+	ErrServiceAccountTokenMissing = errors.New("service account token not found")
+
+	// ErrServiceAccountCAMissing indicates the pod's service account CA certificate could not be read
+	ErrServiceAccountCAMissing = errors.New("service account CA certificate not found")
+
+	// ErrExplicitKubeconfigRequired indicates no kubeconfig was provided while implicit
+	// in-cluster access to the target cluster is disabled
+	ErrExplicitKubeconfigRequired = errors.New("explicit kubeconfig required: implicit in-cluster config is disabled")
+
+	// ErrClusterRateLimited indicates the cluster API kept rejecting requests with HTTP 429
+	ErrClusterRateLimited = errors.New("cluster API is rate-limiting requests")
 )
 
 // CompareError provides detailed error information for comparison failures.
@@ -71,6 +100,17 @@ func NewCompareError(op string, err error, details string) *CompareError {
 	}
 }
 
+// TargetNotFoundError indicates the requested file was not found in a container image.
+// Candidates lists files with the same name found near the expected location.
+type TargetNotFoundError struct {
+	TargetPath string   // Absolute path of the missing file inside the image
+	Candidates []string // Same-named files found under the parent of the expected directory
+}
+
+func (e *TargetNotFoundError) Error() string {
+	return fmt.Sprintf("target file not found in container image: %s", e.TargetPath)
+}
+
 // ValidationError provides detailed error information for argument validation failures.
 type ValidationError struct {
 	Field   string // Field that failed validation
@@ -121,6 +161,92 @@ func NewSecurityError(code, message, hint string) *SecurityError {
 	}
 }
 
+// ReferenceValidationFailure is the validation failure of one reference.
+type ReferenceValidationFailure struct {
+	Reference string // The reference that failed validation
+	Err       error  // Why it failed
+}
+
+// ReferencesValidationError reports every reference that failed validation when
+// several references are validated together.
+type ReferencesValidationError struct {
+	Total    int                          // Number of references validated
+	Failures []ReferenceValidationFailure // Failed references, in the order they were given
+}
+
+func (e *ReferencesValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d references failed validation:", len(e.Failures), e.Total)
+	for _, failure := range e.Failures {
+		fmt.Fprintf(&b, "\n- %s: %s", failure.Reference, FormatErrorForUser(failure.Err))
+	}
+	return b.String()
+}
+
+func (e *ReferencesValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// ToolErrorMetaKey is the _meta key under which error tool results carry their
+// ToolErrorData.
+const ToolErrorMetaKey = "kube-compare-mcp/error"
+
+// Codes of ToolErrorData for errors that carry no code of their own.
+const (
+	ToolErrorCodeCompare           = "compare-failed"
+	ToolErrorCodeValidation        = "invalid-argument"
+	ToolErrorCodeReferencesInvalid = "references-invalid"
+)
+
+// ToolErrorData is the structured form of an error tool result, so that clients can
+// branch on the failure without matching the message text.
+type ToolErrorData struct {
+	Code  string `json:"code"`            // SecurityError code, or one of the ToolErrorCode constants
+	Op    string `json:"op,omitempty"`    // Operation that failed, for comparison failures
+	Field string `json:"field,omitempty"` // Field that failed validation, for validation failures
+	Hint  string `json:"hint,omitempty"`  // Suggestion for fixing the error
+}
+
+// StructuredError returns the structured form of err, or nil when err is not one
+// of the package's error types.
+func StructuredError(err error) *ToolErrorData {
+	if err == nil {
+		return nil
+	}
+
+	// Checked in the same order as FormatErrorForUser, so that the data describes
+	// the same error as the message.
+	var refsErr *ReferencesValidationError
+	if errors.As(err, &refsErr) {
+		return &ToolErrorData{Code: ToolErrorCodeReferencesInvalid}
+	}
+
+	if rateErr := rateLimitError(err); rateErr != nil {
+		return &ToolErrorData{Code: ToolErrorCodeCompare, Op: rateErr.Op, Hint: rateErr.Details}
+	}
+
+	var compErr *CompareError
+	if errors.As(err, &compErr) {
+		return &ToolErrorData{Code: ToolErrorCodeCompare, Op: compErr.Op, Hint: compErr.Details}
+	}
+
+	var valErr *ValidationError
+	if errors.As(err, &valErr) {
+		return &ToolErrorData{Code: ToolErrorCodeValidation, Field: valErr.Field, Hint: valErr.Hint}
+	}
+
+	var secErr *SecurityError
+	if errors.As(err, &secErr) {
+		return &ToolErrorData{Code: secErr.Code, Hint: secErr.Hint}
+	}
+
+	return nil
+}
+
 // formatErrorForUser is the internal unexported version for use within the package.
 func formatErrorForUser(err error) string {
 	return FormatErrorForUser(err)
@@ -132,7 +258,18 @@ func FormatErrorForUser(err error) string {
 		return ""
 	}
 
-	// Check for specific error types
+	// Check for specific error types. ReferencesValidationError comes first because
+	// it wraps the errors below and would otherwise be reported as its first failure.
+	var refsErr *ReferencesValidationError
+	if errors.As(err, &refsErr) {
+		return refsErr.Error()
+	}
+
+	// A rate-limited API call is reported as such whatever operation it failed in
+	if rateErr := rateLimitError(err); rateErr != nil {
+		return rateErr.Error()
+	}
+
 	var compErr *CompareError
 	if errors.As(err, &compErr) {
 		return compErr.Error()
@@ -192,6 +329,26 @@ func FormatErrorForUser(err error) string {
 			"Please use token, client certificate, or OIDC authentication instead."
 	}
 
+	if errors.Is(err, ErrNotInCluster) {
+		return "In-cluster config is not available because the server is not running inside a Kubernetes pod. " +
+			"Please provide a kubeconfig."
+	}
+
+	if errors.Is(err, ErrExplicitKubeconfigRequired) {
+		return "An explicit kubeconfig is required because the server does not use its in-cluster config for target clusters. " +
+			"Please provide a kubeconfig."
+	}
+
+	if errors.Is(err, ErrServiceAccountTokenMissing) {
+		return "The service account token could not be read. " +
+			"Please verify the pod mounts its service account token."
+	}
+
+	if errors.Is(err, ErrServiceAccountCAMissing) {
+		return "The service account CA certificate could not be read. " +
+			"Please verify the pod mounts its service account CA certificate."
+	}
+
 	// Default: return the error as-is
 	return err.Error()
 }
@@ -4,6 +4,7 @@ package mcpserver_test
 
 import (
 	"errors"
+	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -101,6 +102,41 @@ var _ = Describe("Errors", func() {
 		})
 	})
 
+	Describe("StructuredError", func() {
+		It("carries the operation and details of a CompareError", func() {
+			err := fmt.Errorf("wrapped: %w",
+				mcpserver.NewCompareError("reference-timeout", errors.New("too slow"), "Increase reference_timeout"))
+			data := mcpserver.StructuredError(err)
+			Expect(data).To(Equal(&mcpserver.ToolErrorData{
+				Code: mcpserver.ToolErrorCodeCompare,
+				Op:   "reference-timeout",
+				Hint: "Increase reference_timeout",
+			}))
+		})
+
+		It("carries the field and hint of a ValidationError", func() {
+			data := mcpserver.StructuredError(mcpserver.NewValidationError("ocp_version", "invalid", "Use 4.18"))
+			Expect(data).To(Equal(&mcpserver.ToolErrorData{
+				Code:  mcpserver.ToolErrorCodeValidation,
+				Field: "ocp_version",
+				Hint:  "Use 4.18",
+			}))
+		})
+
+		It("carries the code and hint of a SecurityError", func() {
+			data := mcpserver.StructuredError(mcpserver.NewSecurityError("exec-auth-blocked", "blocked", "Use a token"))
+			Expect(data).To(Equal(&mcpserver.ToolErrorData{
+				Code: "exec-auth-blocked",
+				Hint: "Use a token",
+			}))
+		})
+
+		It("returns nil for other errors", func() {
+			Expect(mcpserver.StructuredError(nil)).To(BeNil())
+			Expect(mcpserver.StructuredError(mcpserver.ErrContextCanceled)).To(BeNil())
+		})
+	})
+
 	Describe("Error variables", func() {
 		It("defines all expected sentinel errors", func() {
 			Expect(mcpserver.ErrInvalidArguments).NotTo(BeNil())
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+var (
+	defaultExcludeNamespacesMu sync.RWMutex
+	defaultExcludeNamespaces   []string
+)
+
+// SetDefaultExcludeNamespaces sets the namespace patterns excluded from all_resources
+// comparisons that do not pass exclude_namespaces themselves.
+func SetDefaultExcludeNamespaces(patterns []string) {
+	defaultExcludeNamespacesMu.Lock()
+	defer defaultExcludeNamespacesMu.Unlock()
+	defaultExcludeNamespaces = patterns
+}
+
+// getDefaultExcludeNamespaces returns the patterns set by SetDefaultExcludeNamespaces.
+func getDefaultExcludeNamespaces() []string {
+	defaultExcludeNamespacesMu.RLock()
+	defer defaultExcludeNamespacesMu.RUnlock()
+	return defaultExcludeNamespaces
+}
+
+// ParseNamespacePatterns splits a comma-separated list of namespace patterns,
+// dropping empty entries.
+func ParseNamespacePatterns(value string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// validateNamespacePatterns checks that every exclude_namespaces entry is a valid pattern.
+func validateNamespacePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return NewValidationError("exclude_namespaces",
+				fmt.Sprintf("invalid namespace pattern %q", pattern),
+				"Use namespace names or glob patterns such as 'openshift-*'")
+		}
+	}
+	return nil
+}
+
+// namespaceExcluded reports whether namespace matches one of patterns. Cluster-scoped
+// CRs have no namespace and are never excluded.
+func namespaceExcluded(namespace string, patterns []string) bool {
+	if namespace == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// crNamespaceExcluded reports whether the kube-compare CR name is in an excluded namespace.
+func crNamespaceExcluded(crName string, patterns []string) bool {
+	_, namespace, _, err := parseCRName(crName)
+	if err != nil {
+		return false
+	}
+	return namespaceExcluded(namespace, patterns)
+}
+
+// FilterOutputExcludeNamespaces drops the compared and unmatched CRs whose namespace
+// matches one of patterns, updates the CR counts in the summary to match, and returns
+// how many CRs were dropped. Patterns are namespace names or path.Match globs such as
+// "openshift-*". Missing templates have no namespace and are left as reported.
+func FilterOutputExcludeNamespaces(output *compare.Output, patterns []string) int {
+	suppressed := 0
+
+	if output.Diffs != nil {
+		kept := make([]compare.DiffSum, 0, len(*output.Diffs))
+		for _, diff := range *output.Diffs {
+			if crNamespaceExcluded(diff.CRName, patterns) {
+				suppressed++
+				continue
+			}
+			kept = append(kept, diff)
+		}
+		output.Diffs = &kept
+	}
+
+	if output.Summary == nil {
+		return suppressed
+	}
+
+	unmatched := make([]string, 0, len(output.Summary.UnmatchedCRS))
+	for _, crName := range output.Summary.UnmatchedCRS {
+		if crNamespaceExcluded(crName, patterns) {
+			suppressed++
+			continue
+		}
+		unmatched = append(unmatched, crName)
+	}
+	output.Summary.UnmatchedCRS = unmatched
+	recountSummaryCRs(output)
+
+	return suppressed
+}
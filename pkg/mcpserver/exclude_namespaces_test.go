@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+var _ = Describe("exclude_namespaces", func() {
+
+	Describe("FilterOutputExcludeNamespaces", func() {
+		var output *compare.Output
+
+		BeforeEach(func() {
+			diffs := []compare.DiffSum{
+				{CRName: "v1_ConfigMap_kube-system_coredns", DiffOutput: "-a\n+b"},
+				{CRName: "v1_ConfigMap_openshift-monitoring_cluster-monitoring-config", DiffOutput: "-c\n+d"},
+				{CRName: "v1_ConfigMap_openshift-monitoring_in-sync"},
+				{CRName: "v1_ConfigMap_telco-app_app-config", DiffOutput: "-e\n+f"},
+				{CRName: "v1_Namespace_openshift-sriov", DiffOutput: "-g\n+h"},
+			}
+			output = &compare.Output{
+				Summary: &compare.Summary{
+					NumDiffCRs:   4,
+					TotalCRs:     5,
+					NumMissing:   1,
+					UnmatchedCRS: []string{"v1_Secret_openshift-config_pull-secret", "v1_Secret_telco-app_creds"},
+				},
+				Diffs: &diffs,
+			}
+		})
+
+		It("drops only the CRs in excluded namespaces", func() {
+			suppressed := FilterOutputExcludeNamespaces(output, []string{"kube-system", "openshift-*"})
+			Expect(suppressed).To(Equal(4))
+
+			var names []string
+			for _, diff := range *output.Diffs {
+				names = append(names, diff.CRName)
+			}
+			Expect(names).To(ConsistOf(
+				"v1_ConfigMap_telco-app_app-config",
+				// Cluster-scoped CRs have no namespace to exclude
+				"v1_Namespace_openshift-sriov",
+			))
+			Expect(output.Summary.UnmatchedCRS).To(ConsistOf("v1_Secret_telco-app_creds"))
+		})
+
+		It("recounts the summary for the remaining CRs", func() {
+			FilterOutputExcludeNamespaces(output, []string{"kube-system", "openshift-*"})
+
+			Expect(output.Summary.NumDiffCRs).To(Equal(2))
+			Expect(output.Summary.TotalCRs).To(Equal(2))
+			Expect(output.Summary.NumMissing).To(Equal(1))
+		})
+
+		It("keeps everything when no namespace matches", func() {
+			Expect(FilterOutputExcludeNamespaces(output, []string{"other"})).To(BeZero())
+			Expect(*output.Diffs).To(HaveLen(5))
+			Expect(output.Summary.UnmatchedCRS).To(HaveLen(2))
+		})
+	})
+
+	Describe("ParseNamespacePatterns", func() {
+		It("splits and trims a comma-separated list", func() {
+			Expect(ParseNamespacePatterns(" kube-system, openshift-* ,,")).To(Equal([]string{"kube-system", "openshift-*"}))
+		})
+
+		It("returns nil for an empty value", func() {
+			Expect(ParseNamespacePatterns("")).To(BeNil())
+		})
+	})
+
+	Describe("HandleClusterDiff", func() {
+		It("rejects an invalid namespace pattern", func() {
+			result, _, err := HandleClusterDiff(context.Background(), nil, ClusterDiffInput{
+				Reference:         "https://example.com/metadata.yaml",
+				ExcludeNamespaces: []string{"openshift-["},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("exclude_namespaces"))
+		})
+	})
+})
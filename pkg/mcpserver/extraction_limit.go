@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxConcurrentExtractions is the number of image extractions that may run
+	// at once unless KUBE_COMPARE_MCP_MAX_CONCURRENT_EXTRACTIONS says otherwise.
+	DefaultMaxConcurrentExtractions = 4
+	// DefaultExtractionQueueTimeout is how long an extraction waits for a free slot
+	// unless KUBE_COMPARE_MCP_EXTRACTION_QUEUE_TIMEOUT says otherwise.
+	DefaultExtractionQueueTimeout = 2 * time.Minute
+)
+
+// getMaxConcurrentExtractions returns the number of image extractions that may run at once.
+// Can be configured via KUBE_COMPARE_MCP_MAX_CONCURRENT_EXTRACTIONS environment variable.
+func getMaxConcurrentExtractions() int {
+	if envVal := os.Getenv("KUBE_COMPARE_MCP_MAX_CONCURRENT_EXTRACTIONS"); envVal != "" {
+		if limit, err := strconv.Atoi(envVal); err == nil && limit > 0 {
+			return limit
+		}
+		slog.Default().Warn("Invalid KUBE_COMPARE_MCP_MAX_CONCURRENT_EXTRACTIONS, using default",
+			"value", envVal,
+			"default", DefaultMaxConcurrentExtractions,
+		)
+	}
+	return DefaultMaxConcurrentExtractions
+}
+
+// getExtractionQueueTimeout returns how long an extraction waits for a free slot.
+// Can be configured via KUBE_COMPARE_MCP_EXTRACTION_QUEUE_TIMEOUT environment variable (duration string).
+func getExtractionQueueTimeout() time.Duration {
+	if envVal := os.Getenv("KUBE_COMPARE_MCP_EXTRACTION_QUEUE_TIMEOUT"); envVal != "" {
+		if duration, err := time.ParseDuration(envVal); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return DefaultExtractionQueueTimeout
+}
+
+// extractionLimiter bounds how many image extractions run at once. Pulling and
+// unpacking an image is heavy on bandwidth and disk, unlike validating a reference,
+// so only extractions take a slot.
+type extractionLimiter struct {
+	slots chan struct{}
+}
+
+// newExtractionLimiter returns a limiter allowing limit extractions at once.
+func newExtractionLimiter(limit int) *extractionLimiter {
+	return &extractionLimiter{slots: make(chan struct{}, limit)}
+}
+
+var defaultExtractionLimiter = newExtractionLimiter(getMaxConcurrentExtractions())
+
+// acquire waits for a free slot, for at most timeout, and returns the function that
+// frees it. The returned function may be called more than once.
+func (l *extractionLimiter) acquire(ctx context.Context, timeout time.Duration) (func(), error) {
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaseFunc(), nil
+	default:
+	}
+
+	slog.Default().Debug("Waiting for an image extraction slot",
+		"running", len(l.slots),
+		"limit", cap(l.slots),
+		"timeout", timeout,
+	)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaseFunc(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: %d image extractions were still running after %v",
+			ErrExtractionQueueTimeout, cap(l.slots), timeout)
+	}
+}
+
+func (l *extractionLimiter) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.slots })
+	}
+}
+
+// acquireExtractionSlot waits for a slot to extract the reference of args, if it is an
+// image, and returns the function that frees it. refCtx is the reference acquisition
+// context derived from ctx, so the wait draws on the reference_timeout budget.
+func acquireExtractionSlot(ctx, refCtx context.Context, args *CompareArgs) (func(), error) {
+	switch ClassifyReference(args.Reference) {
+	case ReferenceTypeOCI, ReferenceTypeLocalImage:
+	default:
+		return func() {}, nil
+	}
+
+	release, err := defaultExtractionLimiter.acquire(refCtx, getExtractionQueueTimeout())
+	if err == nil {
+		return release, nil
+	}
+	if referenceTimedOut(ctx, refCtx) {
+		return nil, newReferenceTimeoutError(args.ReferenceTimeout)
+	}
+	if ctx.Err() != nil {
+		return nil, NewCompareError("run", ErrContextCanceled,
+			"The operation was canceled while waiting to extract the reference")
+	}
+	return nil, NewCompareError("extraction-queue", err,
+		"The server is busy extracting other images. Retry later, or raise KUBE_COMPARE_MCP_MAX_CONCURRENT_EXTRACTIONS")
+}
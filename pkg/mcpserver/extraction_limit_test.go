@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("extraction_limit", func() {
+	useLimiter := func(limit int) *extractionLimiter {
+		original := defaultExtractionLimiter
+		defaultExtractionLimiter = newExtractionLimiter(limit)
+		DeferCleanup(func() { defaultExtractionLimiter = original })
+		return defaultExtractionLimiter
+	}
+
+	DescribeTable("getMaxConcurrentExtractions",
+		func(value string, expected int) {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_MAX_CONCURRENT_EXTRACTIONS", value)
+			Expect(getMaxConcurrentExtractions()).To(Equal(expected))
+		},
+		Entry("unset", "", DefaultMaxConcurrentExtractions),
+		Entry("a positive limit", "2", 2),
+		Entry("zero", "0", DefaultMaxConcurrentExtractions),
+		Entry("not a number", "many", DefaultMaxConcurrentExtractions),
+	)
+
+	It("runs at most the configured number of extractions at once", func() {
+		limiter := useLimiter(2)
+		var running, peak atomic.Int32
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				release, err := limiter.acquire(context.Background(), time.Minute)
+				Expect(err).NotTo(HaveOccurred())
+				defer release()
+
+				current := running.Add(1)
+				for {
+					highest := peak.Load()
+					if current <= highest || peak.CompareAndSwap(highest, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+			}()
+		}
+		wg.Wait()
+
+		Expect(peak.Load()).To(Equal(int32(2)))
+	})
+
+	It("frees a slot once however often it is released", func() {
+		limiter := useLimiter(1)
+		release, err := limiter.acquire(context.Background(), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		release()
+		release()
+
+		next, err := limiter.acquire(context.Background(), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		defer next()
+		Expect(limiter.slots).To(HaveLen(1))
+	})
+
+	It("fails an extraction that waits longer than the queue timeout", func() {
+		limiter := useLimiter(1)
+		release, err := limiter.acquire(context.Background(), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		defer release()
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_EXTRACTION_QUEUE_TIMEOUT", "20ms")
+
+		args := &CompareArgs{
+			Reference:    "container://quay.io/org/refs:v1:/reference/metadata.yaml",
+			OutputFormat: "json",
+			image: &pulledImage{
+				img:    newTestReferenceImage(map[string]string{"reference/metadata.yaml": "apiVersion: v2\nparts: []\n"}),
+				digest: "sha256:test",
+			},
+		}
+		_, err = runCompare(context.Background(), args)
+
+		Expect(errors.Is(err, ErrExtractionQueueTimeout)).To(BeTrue())
+		var compareErr *CompareError
+		Expect(errors.As(err, &compareErr)).To(BeTrue())
+		Expect(compareErr.Op).To(Equal("extraction-queue"))
+	})
+
+	It("reports a reference_timeout that runs out in the queue as a reference timeout", func() {
+		limiter := useLimiter(1)
+		release, err := limiter.acquire(context.Background(), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		defer release()
+
+		args := &CompareArgs{
+			Reference:        "container://quay.io/org/refs:v1:/reference/metadata.yaml",
+			ReferenceTimeout: 20 * time.Millisecond,
+		}
+		startReferenceAcquisition(args)
+		refCtx, cancel := referenceAcquisitionContext(context.Background(), args)
+		defer cancel()
+
+		_, err = acquireExtractionSlot(context.Background(), refCtx, args)
+		Expect(errors.Is(err, ErrReferenceTimeout)).To(BeTrue())
+	})
+
+	It("does not throttle references that are not images", func() {
+		limiter := useLimiter(1)
+		release, err := limiter.acquire(context.Background(), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		defer release()
+
+		args := &CompareArgs{Reference: "https://example.com/reference/metadata.yaml"}
+		releaseHTTP, err := acquireExtractionSlot(context.Background(), context.Background(), args)
+		Expect(err).NotTo(HaveOccurred())
+		releaseHTTP()
+		Expect(limiter.slots).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/openshift/kube-compare/pkg/compare"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ManagedFieldPaths returns the field paths of obj that fieldManager owns according to
+// its managedFields, across every operation the manager recorded. Each path is the
+// list of keys down to an owned field. List items, whether keyed by k:, v:, or i:, add
+// no key of their own, matching how fields are placed in kube-compare diffs, so a
+// field owned in one item of a list counts as owned in all of them.
+func ManagedFieldPaths(obj *unstructured.Unstructured, fieldManager string) ([][]string, error) {
+	var paths [][]string
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, fmt.Errorf("failed to parse managedFields of manager %q: %w", fieldManager, err)
+		}
+		paths = appendOwnedFieldPaths(paths, nil, fields)
+	}
+	return paths, nil
+}
+
+// appendOwnedFieldPaths appends the paths of the leaves of a FieldsV1 set, below
+// prefix, to paths. The "." entry only marks that the enclosing field is itself owned,
+// so a field whose only child is "." is a leaf.
+func appendOwnedFieldPaths(paths [][]string, prefix []string, fields map[string]any) [][]string {
+	for key, value := range fields {
+		if key == "." {
+			continue
+		}
+		path := prefix
+		if name, ok := strings.CutPrefix(key, "f:"); ok {
+			path = append(append([]string(nil), prefix...), name)
+		}
+		children, _ := value.(map[string]any)
+		if _, marked := children["."]; len(children) == 0 || (marked && len(children) == 1) {
+			if len(path) > 0 {
+				paths = append(paths, path)
+			}
+			continue
+		}
+		paths = appendOwnedFieldPaths(paths, path, children)
+	}
+	return paths
+}
+
+// pathUnderOwnedFields reports whether the field path lies under one of owned.
+func pathUnderOwnedFields(path []string, owned [][]string) bool {
+	for _, prefix := range owned {
+		if len(prefix) <= len(path) && slices.Equal(prefix, path[:len(prefix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterOutputFieldManager drops the hunks of each diff that only change fields the
+// live object's fieldManager does not own, updates the CR counts in the summary to
+// match, and returns how many CRs no longer differ. As with ignore_volatile_fields, a
+// hunk is dropped only when the path of every changed line in it can be read from the
+// hunk itself. CRs whose live object cannot be read are kept as reported.
+func FilterOutputFieldManager(ctx context.Context, output *compare.Output, fieldManager string, getObject liveObjectGetter) int {
+	if output.Diffs == nil {
+		return 0
+	}
+	logger := slog.Default()
+
+	cleared := 0
+	for i := range *output.Diffs {
+		diff := &(*output.Diffs)[i]
+		if !diff.HasDiff() {
+			continue
+		}
+		obj, err := getObject(ctx, diff.CRName)
+		if err != nil {
+			logger.Debug("Keeping CR whose live object could not be read", "cr", diff.CRName, "error", err)
+			continue
+		}
+		owned, err := ManagedFieldPaths(obj, fieldManager)
+		if err != nil {
+			logger.Debug("Keeping CR whose managedFields could not be read", "cr", diff.CRName, "error", err)
+			continue
+		}
+		diff.DiffOutput = filterDiffUnownedFields(diff.DiffOutput, owned)
+		if !diff.HasDiff() {
+			cleared++
+		}
+	}
+
+	if output.Summary != nil {
+		recountSummaryCRs(output)
+	}
+	return cleared
+}
+
+// filterDiffUnownedFields drops the hunks of a unified diff that only change fields
+// outside owned. It returns "" when no hunk is left.
+func filterDiffUnownedFields(diffOutput string, owned [][]string) string {
+	header, hunks := splitDiffHunks(diffOutput)
+	if len(hunks) == 0 {
+		// Not a unified diff, so there is nothing to place
+		return diffOutput
+	}
+
+	unowned := func(path []string) bool {
+		return path != nil && !pathUnderOwnedFields(path, owned)
+	}
+	var kept []string
+	for _, hunk := range hunks {
+		if !hunkOnlyChangesPaths(hunk[1:], unowned) {
+			kept = append(kept, hunk...)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(header, "") + strings.Join(kept, "")
+}
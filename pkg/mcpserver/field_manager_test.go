@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// lastAppliedHunk only changes an annotation owned by kubectl.
+const lastAppliedHunk = "@@ -1,6 +1,7 @@\n" +
+	" apiVersion: apps/v1\n" +
+	" kind: Deployment\n" +
+	" metadata:\n" +
+	"   annotations:\n" +
+	"+    kubectl.kubernetes.io/last-applied-configuration: '{}'\n" +
+	"   labels:\n"
+
+// newManagedTestObject returns a live Deployment whose replicas and app label are owned
+// by argocd-controller and whose last-applied annotation is owned by kubectl.
+func newManagedTestObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("ns")
+	obj.SetName("app")
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:   "argocd-controller",
+			Operation: metav1.ManagedFieldsOperationApply,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{".":{},"f:app":{}}},` +
+				`"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{".":{},"f:image":{}}}}}}}`)},
+		},
+		{
+			Manager:   "kubectl-client-side-apply",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{".":{},` +
+				`"f:kubectl.kubernetes.io/last-applied-configuration":{}}}}`)},
+		},
+	})
+	return obj
+}
+
+var _ = Describe("field_manager", func() {
+	Describe("ManagedFieldPaths", func() {
+		It("returns the fields owned by the manager", func() {
+			paths, err := ManagedFieldPaths(newManagedTestObject(), "argocd-controller")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(ConsistOf(
+				[]string{"metadata", "labels", "app"},
+				[]string{"spec", "replicas"},
+				[]string{"spec", "template", "spec", "containers", "image"},
+			))
+		})
+
+		It("returns no fields for a manager without managedFields", func() {
+			paths, err := ManagedFieldPaths(newManagedTestObject(), "helm")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(BeEmpty())
+		})
+	})
+
+	Describe("filterDiffUnownedFields", func() {
+		var argoFields, kubectlFields [][]string
+
+		BeforeEach(func() {
+			var err error
+			argoFields, err = ManagedFieldPaths(newManagedTestObject(), "argocd-controller")
+			Expect(err).NotTo(HaveOccurred())
+			kubectlFields, err = ManagedFieldPaths(newManagedTestObject(), "kubectl-client-side-apply")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("compares only the fields of the selected manager", func() {
+			diff := volatileDiffHeader + lastAppliedHunk + driftHunk
+			Expect(filterDiffUnownedFields(diff, argoFields)).To(Equal(volatileDiffHeader + driftHunk))
+			Expect(filterDiffUnownedFields(diff, kubectlFields)).To(Equal(volatileDiffHeader + lastAppliedHunk))
+		})
+
+		It("keeps a hunk whose changed fields cannot be placed", func() {
+			diff := volatileDiffHeader + unplacedHunk
+			Expect(filterDiffUnownedFields(diff, argoFields)).To(Equal(diff))
+		})
+
+		It("leaves output that is not a unified diff as is", func() {
+			Expect(filterDiffUnownedFields("-a\n+b", argoFields)).To(Equal("-a\n+b"))
+		})
+	})
+
+	Describe("FilterOutputFieldManager", func() {
+		var getObject liveObjectGetter
+
+		BeforeEach(func() {
+			getObject = func(_ context.Context, crName string) (*unstructured.Unstructured, error) {
+				if crName == "apps/v1_Deployment_ns_app" {
+					return newManagedTestObject(), nil
+				}
+				return nil, errors.New("not found")
+			}
+		})
+
+		newOutput := func() *compare.Output {
+			diffs := []compare.DiffSum{
+				{CRName: "apps/v1_Deployment_ns_app", DiffOutput: volatileDiffHeader + lastAppliedHunk},
+				{CRName: "apps/v1_Deployment_ns_unreadable", DiffOutput: volatileDiffHeader + lastAppliedHunk},
+			}
+			return &compare.Output{
+				Summary: &compare.Summary{NumDiffCRs: 2, TotalCRs: 2},
+				Diffs:   &diffs,
+			}
+		}
+
+		It("clears CRs whose diffs are all in fields of other managers", func() {
+			output := newOutput()
+			Expect(FilterOutputFieldManager(context.Background(), output, "argocd-controller", getObject)).To(Equal(1))
+			Expect((*output.Diffs)[0].HasDiff()).To(BeFalse())
+			Expect((*output.Diffs)[1].HasDiff()).To(BeTrue())
+			Expect(output.Summary.NumDiffCRs).To(Equal(1))
+		})
+	})
+
+	It("makes kube-compare output JSON", func() {
+		Expect(compareOutputFormat(&CompareArgs{OutputFormat: compare.Yaml, FieldManager: "argocd-controller"})).To(Equal(compare.Json))
+	})
+})
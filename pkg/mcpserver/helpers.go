@@ -29,6 +29,18 @@ func newToolResultError(errMsg string) *mcp.CallToolResult {
 	}
 }
 
+// newToolResultErrorFor creates an error tool result for err. Besides the
+// user-facing message, the result carries the structured form of err, when it has
+// one, under ToolErrorMetaKey in _meta. The SDK fills StructuredContent from the
+// tool's typed output, so the error data cannot go there.
+func newToolResultErrorFor(err error) *mcp.CallToolResult {
+	result := newToolResultError(formatErrorForUser(err))
+	if data := StructuredError(err); data != nil {
+		result.Meta = mcp.Meta{ToolErrorMetaKey: data}
+	}
+	return result
+}
+
 // ptrBool returns a pointer to a bool value, used for optional annotation fields.
 func ptrBool(b bool) *bool {
 	return &b
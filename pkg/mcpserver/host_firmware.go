@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// HostFirmwareSettingsInput defines the typed input for the baremetal_host_firmware_settings tool.
+type HostFirmwareSettingsInput struct {
+	Kubeconfig     string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for the ACM hub cluster. If omitted, uses in-cluster config."`
+	Context        string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig."`
+	Namespace      string `json:"namespace,omitempty" jsonschema:"Namespace on the hub cluster containing the HostFirmwareSettings. Defaults to the namespace of the kubeconfig context."`
+	HostName       string `json:"host_name" jsonschema:"BareMetalHost whose HostFirmwareSettings should be returned."`
+	IncludePending bool   `json:"include_pending,omitempty" jsonschema:"Also return the pending settings requested in spec.settings. Default: false."`
+}
+
+// HostFirmwareSettingsResult is the structured response for the baremetal_host_firmware_settings tool.
+type HostFirmwareSettingsResult struct {
+	Name            string            `json:"Name"`
+	Namespace       string            `json:"Namespace"`
+	Settings        map[string]string `json:"Settings"`
+	PendingSettings map[string]string `json:"PendingSettings,omitempty"`
+	YAML            string            `json:"YAML"`
+}
+
+// HostFirmwareSettingsTool returns the MCP tool definition for fetching live BIOS settings.
+func HostFirmwareSettingsTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:  "baremetal_host_firmware_settings",
+		Title: "Host Firmware Settings",
+		Description: "Fetch the live HostFirmwareSettings of a bare metal host as YAML, along with the current " +
+			"BIOS settings from status.settings. Use after baremetal_bios_diff reports drift to craft a fix.",
+		InputSchema:  HostFirmwareSettingsInputSchema(),
+		OutputSchema: HostFirmwareSettingsOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleHostFirmwareSettings is the MCP tool handler for the baremetal_host_firmware_settings tool.
+func HandleHostFirmwareSettings(ctx context.Context, req *mcp.CallToolRequest, input HostFirmwareSettingsInput) (toolResult *mcp.CallToolResult, result *HostFirmwareSettingsResult, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Info("Received tool request",
+		"tool", "baremetal_host_firmware_settings",
+		"namespace", input.Namespace,
+		"hostName", input.HostName,
+		"includePending", input.IncludePending,
+		"hasKubeconfig", input.Kubeconfig != "",
+		"context", input.Context,
+	)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	// Validate context requires kubeconfig
+	if input.Context != "" && input.Kubeconfig == "" {
+		err := NewValidationError("context",
+			"'context' parameter requires 'kubeconfig' to also be provided",
+			"Provide a kubeconfig along with the context name")
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	// Validate required fields
+	var namespaceSource string
+	input.Namespace, namespaceSource = resolveToolNamespace(input.Namespace, input.Kubeconfig, input.Context)
+	if input.Namespace == "" {
+		err := NewValidationError("namespace",
+			"namespace is required",
+			"Provide the namespace on the hub cluster containing the HostFirmwareSettings, or set a namespace on the kubeconfig context")
+		return newToolResultErrorFor(err), nil, nil
+	}
+	logResolvedNamespace(logger, input.Namespace, namespaceSource)
+	if input.HostName == "" {
+		err := NewValidationError("host_name",
+			"host_name is required",
+			"Provide the name of the BareMetalHost whose settings should be returned")
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	targetClient, logger, err := buildBIOSTargetClient(ctx, input.Kubeconfig, input.Context, logger)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	result, err = getHostFirmwareSettings(ctx, targetClient, input.Namespace, input.HostName, input.IncludePending)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	logger.Info("Host firmware settings fetched",
+		"duration", time.Since(start),
+		"namespace", input.Namespace,
+		"hostName", input.HostName,
+		"settings", len(result.Settings),
+		"pendingSettings", len(result.PendingSettings),
+	)
+
+	return newToolResultText(string(outputBytes)), result, nil
+}
+
+// getHostFirmwareSettings reads the host's HostFirmwareSettings from the hub cluster and
+// returns its current settings, optionally its pending settings, and the object as YAML.
+func getHostFirmwareSettings(
+	ctx context.Context,
+	targetClient dynamic.Interface,
+	namespace string,
+	hostName string,
+	includePending bool,
+) (*HostFirmwareSettingsResult, error) {
+	hfs, err := targetClient.Resource(hostFirmwareSettingsGVR).Namespace(namespace).Get(ctx, hostName, metav1.GetOptions{})
+	if err != nil {
+		return nil, NewCompareError("get-hfs",
+			fmt.Errorf("failed to get HostFirmwareSettings %s/%s: %w", namespace, hostName, err),
+			"Verify the host name and namespace are correct and that the host has been inspected")
+	}
+
+	result := &HostFirmwareSettingsResult{
+		Name:      hostName,
+		Namespace: namespace,
+		Settings:  extractBIOSSettings(hfs),
+	}
+	if includePending {
+		result.PendingSettings = extractPendingBIOSSettings(hfs)
+	}
+
+	clean := cleanHostFirmwareSettings(hfs)
+	if !includePending {
+		// Leave the pending settings out of the YAML too, so it matches the request
+		unstructured.RemoveNestedField(clean.Object, "spec", "settings")
+		if spec, _, _ := unstructured.NestedMap(clean.Object, "spec"); len(spec) == 0 {
+			unstructured.RemoveNestedField(clean.Object, "spec")
+		}
+	}
+	yamlBytes, err := sigsyaml.Marshal(clean.Object)
+	if err != nil {
+		return nil, NewCompareError("format-hfs", err, "")
+	}
+	result.YAML = string(yamlBytes)
+
+	return result, nil
+}
+
+// extractPendingBIOSSettings extracts the settings requested in HostFirmwareSettings
+// spec.settings. Values may be strings or integers and are returned as strings.
+func extractPendingBIOSSettings(hfs *unstructured.Unstructured) map[string]string {
+	pending, found, err := unstructured.NestedMap(hfs.Object, "spec", "settings")
+	if err != nil || !found {
+		return make(map[string]string)
+	}
+	settings := make(map[string]string, len(pending))
+	for name, value := range pending {
+		settings[name] = fmt.Sprint(value)
+	}
+	return settings
+}
+
+// cleanHostFirmwareSettings returns a copy of hfs without the server-managed metadata
+// that is noise when reading or re-applying the settings.
+func cleanHostFirmwareSettings(hfs *unstructured.Unstructured) *unstructured.Unstructured {
+	clean := hfs.DeepCopy()
+	clean.SetManagedFields(nil)
+	clean.SetResourceVersion("")
+	clean.SetUID("")
+	clean.SetGeneration(0)
+	clean.SetCreationTimestamp(metav1.Time{})
+	return clean
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+var _ = Describe("HostFirmwareSettings", func() {
+
+	Describe("HostFirmwareSettingsTool", func() {
+		var tool = HostFirmwareSettingsTool()
+
+		It("has the correct name", func() {
+			Expect(tool.Name).To(Equal("baremetal_host_firmware_settings"))
+		})
+
+		It("has input and output schemas", func() {
+			Expect(tool.InputSchema).NotTo(BeNil())
+			Expect(tool.OutputSchema).NotTo(BeNil())
+		})
+	})
+
+	Describe("getHostFirmwareSettings", func() {
+		var (
+			ctx    context.Context
+			hfs    *unstructured.Unstructured
+			client dynamic.Interface
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			hfs = newTestHostFirmwareSettings("node-0", "spoke", map[string]string{
+				"ProcTurboMode":     "Enabled",
+				"SriovGlobalEnable": "Enabled",
+			})
+			Expect(unstructured.SetNestedMap(hfs.Object, map[string]any{
+				"ProcTurboMode": "Disabled",
+				"BootDelay":     int64(5),
+			}, "spec", "settings")).To(Succeed())
+			hfs.SetUID("4f3c2b1a")
+			hfs.SetResourceVersion("12345")
+			hfs.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "baremetal-operator"}})
+
+			// Created through the GVR since the fake client cannot pluralize the kind
+			client = newBIOSTestFakeDynamicClient()
+			_, err := client.Resource(hostFirmwareSettingsGVR).Namespace("spoke").Create(ctx, hfs, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the current settings and clean YAML", func() {
+			result, err := getHostFirmwareSettings(ctx, client, "spoke", "node-0", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Name).To(Equal("node-0"))
+			Expect(result.Namespace).To(Equal("spoke"))
+			Expect(result.Settings).To(Equal(map[string]string{
+				"ProcTurboMode":     "Enabled",
+				"SriovGlobalEnable": "Enabled",
+			}))
+			Expect(result.PendingSettings).To(BeNil())
+
+			var parsed map[string]any
+			Expect(sigsyaml.Unmarshal([]byte(result.YAML), &parsed)).To(Succeed())
+			Expect(parsed).To(HaveKeyWithValue("kind", "HostFirmwareSettings"))
+			Expect(parsed).To(HaveKeyWithValue("status", HaveKeyWithValue("settings",
+				HaveKeyWithValue("ProcTurboMode", "Enabled"))))
+			Expect(parsed).NotTo(HaveKey("spec"))
+
+			metadata := parsed["metadata"].(map[string]any)
+			Expect(metadata).To(HaveKeyWithValue("name", "node-0"))
+			Expect(metadata).NotTo(HaveKey("uid"))
+			Expect(metadata).NotTo(HaveKey("resourceVersion"))
+			Expect(metadata).NotTo(HaveKey("managedFields"))
+		})
+
+		It("includes the pending settings when requested", func() {
+			result, err := getHostFirmwareSettings(ctx, client, "spoke", "node-0", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.PendingSettings).To(Equal(map[string]string{
+				"ProcTurboMode": "Disabled",
+				"BootDelay":     "5",
+			}))
+			Expect(result.YAML).To(ContainSubstring("BootDelay: 5"))
+		})
+
+		It("returns an error when the host has no HostFirmwareSettings", func() {
+			_, err := getHostFirmwareSettings(ctx, client, "spoke", "node-1", false)
+			Expect(err).To(MatchError(ContainSubstring("failed to get HostFirmwareSettings spoke/node-1")))
+		})
+	})
+
+	Describe("HandleHostFirmwareSettings", func() {
+		It("requires a namespace", func() {
+			result, _, err := HandleHostFirmwareSettings(context.Background(), &mcp.CallToolRequest{},
+				HostFirmwareSettingsInput{HostName: "node-0"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+
+		It("requires a host name", func() {
+			result, _, err := HandleHostFirmwareSettings(context.Background(), &mcp.CallToolRequest{},
+				HostFirmwareSettingsInput{Namespace: "spoke"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+	})
+})
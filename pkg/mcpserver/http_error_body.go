@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHTTPErrorBodyLimit   = 1024
+	defaultHTTPErrorBodyTimeout = 2 * time.Second
+)
+
+// getHTTPErrorBodyLimit returns the most bytes of an HTTP error response read for
+// context. Can be configured via KUBE_COMPARE_MCP_HTTP_ERROR_BODY_LIMIT environment
+// variable (in bytes).
+func getHTTPErrorBodyLimit() int64 {
+	if val := os.Getenv("KUBE_COMPARE_MCP_HTTP_ERROR_BODY_LIMIT"); val != "" {
+		if limit, err := strconv.ParseInt(val, 10, 64); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return defaultHTTPErrorBodyLimit
+}
+
+// getHTTPErrorBodyTimeout returns how long reading an HTTP error response may take.
+// Can be configured via KUBE_COMPARE_MCP_HTTP_ERROR_BODY_TIMEOUT environment variable.
+func getHTTPErrorBodyTimeout() time.Duration {
+	if val := os.Getenv("KUBE_COMPARE_MCP_HTTP_ERROR_BODY_TIMEOUT"); val != "" {
+		if duration, err := time.ParseDuration(val); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return defaultHTTPErrorBodyTimeout
+}
+
+// readErrorBody reads the start of an HTTP error response for context, at most
+// getHTTPErrorBodyLimit bytes and for at most getHTTPErrorBodyTimeout or until ctx
+// is done. When time runs out, body is closed and what was read so far is returned,
+// so a server trickling its error body cannot hold the request.
+func readErrorBody(ctx context.Context, body io.ReadCloser) string {
+	ctx, cancel := context.WithTimeout(ctx, getHTTPErrorBodyTimeout())
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		read []byte
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		limited := io.LimitReader(body, getHTTPErrorBodyLimit())
+		buf := make([]byte, 256)
+		for {
+			n, err := limited.Read(buf)
+			mu.Lock()
+			read = append(read, buf[:n]...)
+			mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Closing the body ends the pending read
+		_ = body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return strings.TrimSpace(string(read))
+}
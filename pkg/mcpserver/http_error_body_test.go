@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// getErrorResponse sends a GET to the handler of a test server and returns its response.
+func getErrorResponse(handler http.HandlerFunc) *http.Response {
+	server := httptest.NewServer(handler)
+	DeferCleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	Expect(err).NotTo(HaveOccurred())
+	resp, err := http.DefaultClient.Do(req)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(resp.Body.Close)
+	Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+	return resp
+}
+
+var _ = Describe("readErrorBody", func() {
+	It("reads a short error body", func() {
+		resp := getErrorResponse(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "  backend unavailable  ", http.StatusInternalServerError)
+		})
+
+		Expect(readErrorBody(context.Background(), resp.Body)).To(Equal("backend unavailable"))
+	})
+
+	It("reads at most the configured number of bytes", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_HTTP_ERROR_BODY_LIMIT", "100")
+		resp := getErrorResponse(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, strings.Repeat("x", 4096), http.StatusInternalServerError)
+		})
+
+		Expect(readErrorBody(context.Background(), resp.Body)).To(HaveLen(100))
+	})
+
+	It("stops reading a trickled body at the read deadline", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_HTTP_ERROR_BODY_TIMEOUT", "200ms")
+		resp := getErrorResponse(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.(http.Flusher).Flush()
+			ticker := time.NewTicker(20 * time.Millisecond)
+			defer ticker.Stop()
+			for range 500 {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-ticker.C:
+				}
+				_, _ = w.Write([]byte("x"))
+				w.(http.Flusher).Flush()
+			}
+		})
+
+		start := time.Now()
+		body := readErrorBody(context.Background(), resp.Body)
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		Expect(body).NotTo(BeEmpty())
+		Expect(len(body)).To(BeNumerically("<", defaultHTTPErrorBodyLimit))
+		Expect(strings.Trim(body, "x")).To(BeEmpty())
+	})
+
+	It("stops reading when the request's context is done", func() {
+		resp := getErrorResponse(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		Expect(readErrorBody(ctx, resp.Body)).To(BeEmpty())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+})
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// gzipMagic is the header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipSniffingImage wraps an image so that the uncompressed stream of each layer is
+// gunzipped when it is still gzip-compressed. Hand-built images sometimes declare
+// gzip-compressed layers as uncompressed tar, which go-containerregistry then passes
+// through as is and the tar reader cannot read.
+type gzipSniffingImage struct {
+	v1.Image
+}
+
+// Layers returns the image layers, each wrapped in a gzipSniffingLayer.
+func (i gzipSniffingImage) Layers() ([]v1.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	wrapped := make([]v1.Layer, len(layers))
+	for idx, layer := range layers {
+		wrapped[idx] = gzipSniffingLayer{Layer: layer}
+	}
+	return wrapped, nil
+}
+
+// gzipSniffingLayer is a layer whose Uncompressed stream is gunzipped when it starts
+// with the gzip magic number, whatever the declared media type.
+type gzipSniffingLayer struct {
+	v1.Layer
+}
+
+// Uncompressed returns the layer's uncompressed tar stream.
+func (l gzipSniffingLayer) Uncompressed() (io.ReadCloser, error) {
+	rc, err := l.Layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	return gunzipIfCompressed(rc)
+}
+
+// gunzipIfCompressed returns rc, gunzipped when its first bytes are the gzip magic
+// number. Closing the result closes rc.
+func gunzipIfCompressed(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	header, err := br.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		_ = rc.Close()
+		return nil, fmt.Errorf("failed to read layer: %w", err)
+	}
+	if !bytes.Equal(header, gzipMagic) {
+		return &layerReadCloser{Reader: br, closers: []io.Closer{rc}}, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		_ = rc.Close()
+		return nil, fmt.Errorf("failed to read gzip-compressed layer: %w", err)
+	}
+	return &layerReadCloser{Reader: gz, closers: []io.Closer{gz, rc}}, nil
+}
+
+// layerReadCloser reads from Reader and closes each of closers in order.
+type layerReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *layerReadCloser) Close() error {
+	var errs []error
+	for _, closer := range r.closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
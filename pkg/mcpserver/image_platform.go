@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// DefaultPlatform is the platform pulled from a multi-platform image when the request
+// does not set one.
+const DefaultPlatform = "linux/amd64"
+
+// parsePlatform parses a platform input such as linux/arm64 or linux/arm/v7. An empty
+// value selects DefaultPlatform.
+func parsePlatform(value string) (*v1.Platform, error) {
+	if value == "" {
+		value = DefaultPlatform
+	}
+	platform, err := v1.ParsePlatform(value)
+	if err != nil || platform.OS == "" || platform.Architecture == "" {
+		return nil, NewValidationError("platform",
+			fmt.Sprintf("invalid platform %q", value),
+			"Use os/arch or os/arch/variant, such as linux/amd64 or linux/arm64")
+	}
+	return platform, nil
+}
+
+// resolvePlatformImage returns the image desc describes. When desc is a multi-platform
+// image index, the image for platform is selected from it, and an error listing the
+// platforms the index does offer is returned when it has none for platform.
+func resolvePlatformImage(desc *remote.Descriptor, imageRef string, platform *v1.Platform) (v1.Image, error) {
+	if !desc.MediaType.IsIndex() {
+		return desc.Image()
+	}
+
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image index of '%s': %w", imageRef, err)
+	}
+	return selectPlatformImage(index, imageRef, platform)
+}
+
+// selectPlatformImage returns the image for platform from a multi-platform image index,
+// or an error listing the platforms the index does offer when it has none for platform.
+func selectPlatformImage(index v1.ImageIndex, imageRef string, platform *v1.Platform) (v1.Image, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image index of '%s': %w", imageRef, err)
+	}
+
+	var available []string
+	for _, entry := range manifest.Manifests {
+		if entry.Platform == nil || !entry.MediaType.IsImage() {
+			continue
+		}
+		if entry.Platform.Satisfies(*platform) {
+			return index.Image(entry.Digest)
+		}
+		available = append(available, entry.Platform.String())
+	}
+	if len(available) == 0 {
+		return nil, fmt.Errorf("%w: image index '%s' declares no platforms", ErrPlatformNotFound, imageRef)
+	}
+	return nil, fmt.Errorf("%w: image '%s' has no %s image; it provides %s",
+		ErrPlatformNotFound, imageRef, platform, strings.Join(available, ", "))
+}
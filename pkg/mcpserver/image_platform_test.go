@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("image_platform", func() {
+	DescribeTable("parsePlatform",
+		func(value, expected string, valid bool) {
+			platform, err := parsePlatform(value)
+			if !valid {
+				var valErr *ValidationError
+				Expect(errors.As(err, &valErr)).To(BeTrue())
+				Expect(valErr.Field).To(Equal("platform"))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(platform.String()).To(Equal(expected))
+		},
+		Entry("unset", "", DefaultPlatform, true),
+		Entry("os and architecture", "linux/arm64", "linux/arm64", true),
+		Entry("with a variant", "linux/arm/v7", "linux/arm/v7", true),
+		Entry("architecture only", "arm64", "", false),
+		Entry("empty architecture", "linux/", "", false),
+	)
+
+	Describe("pullContainerImage", func() {
+		var (
+			registryHost string
+			images       map[string]v1.Image
+		)
+
+		parseRef := func(repo string) name.Reference {
+			ref, err := name.ParseReference(registryHost + "/" + repo)
+			Expect(err).NotTo(HaveOccurred())
+			return ref
+		}
+
+		digestOf := func(img v1.Image) string {
+			digest, err := img.Digest()
+			Expect(err).NotTo(HaveOccurred())
+			return digest.String()
+		}
+
+		BeforeEach(func() {
+			server := httptest.NewServer(registry.New())
+			DeferCleanup(server.Close)
+			u, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			registryHost = u.Host
+
+			images = map[string]v1.Image{}
+			var adds []mutate.IndexAddendum
+			for _, platform := range []v1.Platform{
+				{OS: "linux", Architecture: "amd64"},
+				{OS: "linux", Architecture: "arm64"},
+			} {
+				img := newTestReferenceImage(map[string]string{
+					"reference/metadata.yaml": "apiVersion: v2\n# " + platform.Architecture + "\n",
+				})
+				images[platform.String()] = img
+				adds = append(adds, mutate.IndexAddendum{
+					Add:        img,
+					Descriptor: v1.Descriptor{Platform: &platform},
+				})
+			}
+			Expect(remote.WriteIndex(parseRef("org/refs:multi"), mutate.AppendManifests(empty.Index, adds...))).To(Succeed())
+		})
+
+		It("pulls the default platform from a multi-platform image", func() {
+			img, digest, _, err := (&CompareService{}).pullContainerImage(context.Background(), registryHost+"/org/refs:multi", "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(Equal(digestOf(images["linux/amd64"])))
+			Expect(digestOf(img)).To(Equal(digest))
+		})
+
+		It("reads the layers of the pulled image until it is released", func() {
+			img, _, release, err := (&CompareService{}).pullContainerImage(context.Background(), registryHost+"/org/refs:multi", "", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			path, err := extractImageFiles(context.Background(), img, "test", "/reference/metadata.yaml", GinkgoT().TempDir())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(path)).To(Equal([]byte("apiVersion: v2\n# amd64\n")))
+
+			release()
+			_, err = extractImageFiles(context.Background(), img, "test", "/reference/metadata.yaml", GinkgoT().TempDir())
+			Expect(err).To(MatchError(ContainSubstring("error reading image layers")))
+		})
+
+		It("pulls the requested platform from a multi-platform image", func() {
+			_, digest, _, err := (&CompareService{}).pullContainerImage(context.Background(), registryHost+"/org/refs:multi", "linux/arm64", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(Equal(digestOf(images["linux/arm64"])))
+		})
+
+		It("names the available platforms when the requested one is missing", func() {
+			_, _, _, err := (&CompareService{}).pullContainerImage(context.Background(), registryHost+"/org/refs:multi", "linux/s390x", "")
+			Expect(errors.Is(err, ErrPlatformNotFound)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("has no linux/s390x image"))
+			Expect(err.Error()).To(ContainSubstring("linux/amd64, linux/arm64"))
+		})
+
+		It("pulls a single-platform image as is", func() {
+			single := images["linux/arm64"]
+			ref := parseRef("org/refs:single")
+			Expect(remote.Write(ref, single)).To(Succeed())
+
+			_, digest, _, err := (&CompareService{}).pullContainerImage(context.Background(), ref.String(), "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(Equal(digestOf(single)))
+		})
+	})
+})
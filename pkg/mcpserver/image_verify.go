@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const (
+	// cosignSignatureAnnotation holds the base64 signature of a cosign signature layer.
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	// maxSignaturePayloadSize bounds how much of a signature layer is read.
+	maxSignaturePayloadSize = 1 << 20
+)
+
+// getCosignPublicKeyPath returns the path of the PEM public key used to verify image
+// signatures. Can be configured via KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY environment variable.
+// Empty (the default) disables signature verification.
+func getCosignPublicKeyPath() string {
+	return os.Getenv("KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY")
+}
+
+var (
+	cosignPublicKeyMu sync.RWMutex
+	cosignPublicKey   crypto.PublicKey
+)
+
+// LoadCosignPublicKeyFromEnv reads and parses the PEM public key configured through
+// KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY. It returns nil when none is configured.
+func LoadCosignPublicKeyFromEnv() (crypto.PublicKey, error) {
+	keyPath := getCosignPublicKeyPath()
+	if keyPath == "" {
+		return nil, nil
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cosign public key: %w", err)
+	}
+	return parseCosignPublicKey(keyPEM)
+}
+
+// SetCosignPublicKey sets the public key image signatures are verified with, as
+// returned by LoadCosignPublicKeyFromEnv. A nil key disables signature verification.
+func SetCosignPublicKey(key crypto.PublicKey) {
+	cosignPublicKeyMu.Lock()
+	defer cosignPublicKeyMu.Unlock()
+	cosignPublicKey = key
+}
+
+// getCosignPublicKey returns the key set by SetCosignPublicKey.
+func getCosignPublicKey() crypto.PublicKey {
+	cosignPublicKeyMu.RLock()
+	defer cosignPublicKeyMu.RUnlock()
+	return cosignPublicKey
+}
+
+// newConfiguredImageVerifier returns a verifier for the key set by SetCosignPublicKey,
+// fetching signatures with the credentials of keychain, or nil when signature
+// verification is disabled.
+func newConfiguredImageVerifier(keychain authn.Keychain) ImageVerifier {
+	key := getCosignPublicKey()
+	if key == nil {
+		return nil
+	}
+	return &CosignVerifier{PublicKey: key, Options: registryOptions(keychain)}
+}
+
+// CosignVerifier verifies cosign key-based signatures stored in the registry next to
+// the image, under the sha256-<digest>.sig tag.
+type CosignVerifier struct {
+	PublicKey crypto.PublicKey
+	Options   []remote.Option
+}
+
+// NewCosignVerifier returns a CosignVerifier for a PEM-encoded ECDSA, RSA, or Ed25519 public key.
+func NewCosignVerifier(keyPEM []byte) (*CosignVerifier, error) {
+	key, err := parseCosignPublicKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &CosignVerifier{
+		PublicKey: key,
+		Options:   registryOptions(authn.DefaultKeychain),
+	}, nil
+}
+
+// parseCosignPublicKey parses a PEM-encoded ECDSA, RSA, or Ed25519 public key.
+func parseCosignPublicKey(keyPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("cosign public key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cosign public key: %w", err)
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported cosign public key type %T", key)
+	}
+	return key, nil
+}
+
+// cosignPayload is the part of the cosign simple signing payload that binds a signature to an image.
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify resolves imageRef to a digest and checks that at least one signature
+// for that digest verifies with the public key. It returns the verified digest.
+func (v *CosignVerifier) Verify(ctx context.Context, imageRef string) (string, error) {
+	ref, err := parseImageReference(imageRef)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", imageRef, err)
+	}
+
+	opts := append([]remote.Option{remote.WithContext(ctx)}, v.Options...)
+
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest for %q: %w", imageRef, err)
+	}
+	digest := desc.Digest.String()
+
+	sigRef := ref.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".sig")
+	sigImage, err := remote.Image(sigRef, opts...)
+	if err != nil {
+		return "", fmt.Errorf("no signature found for %s@%s: %w", ref.Context(), digest, err)
+	}
+
+	manifest, err := sigImage.Manifest()
+	if err != nil {
+		return "", fmt.Errorf("failed to read signature manifest for %s@%s: %w", ref.Context(), digest, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		if err := v.verifyLayer(sigImage, layer, digest); err != nil {
+			slog.Default().Debug("Image signature did not verify", "image", imageRef, "layer", layer.Digest, "error", err)
+			continue
+		}
+		return digest, nil
+	}
+
+	return "", fmt.Errorf("no signature for %s@%s verified with the configured public key", ref.Context(), digest)
+}
+
+// verifyLayer checks one signature layer against the public key and the image digest.
+func (v *CosignVerifier) verifyLayer(sigImage v1.Image, desc v1.Descriptor, digest string) error {
+	encoded, ok := desc.Annotations[cosignSignatureAnnotation]
+	if !ok {
+		return errors.New("layer has no signature annotation")
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	layer, err := sigImage.LayerByDigest(desc.Digest)
+	if err != nil {
+		return err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(rc, maxSignaturePayloadSize))
+	if err != nil {
+		return fmt.Errorf("failed to read signature payload: %w", err)
+	}
+
+	if err := verifySignature(v.PublicKey, payload, signature); err != nil {
+		return err
+	}
+
+	var p cosignPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid signature payload: %w", err)
+	}
+	if p.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("signature is for %s, not %s", p.Critical.Image.DockerManifestDigest, digest)
+	}
+
+	return nil
+}
+
+// verifySignature checks signature over payload with key.
+func verifySignature(key crypto.PublicKey, payload, signature []byte) error {
+	hash := sha256.Sum256(payload)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, hash[:], signature) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, signature) {
+			return errors.New("invalid Ed25519 signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// imageVerifier returns the injected verifier, or the one configured through
+// SetCosignPublicKey. A nil verifier means signature verification is disabled.
+func (s *CompareService) imageVerifier() ImageVerifier {
+	if s.Verifier != nil {
+		return s.Verifier
+	}
+	return newConfiguredImageVerifier(s.Keychain)
+}
+
+// VerifyImageSignature verifies imageRef when signature verification is enabled and
+// returns the verified digest, or "" when verification is disabled.
+func (s *CompareService) VerifyImageSignature(ctx context.Context, imageRef string) (string, error) {
+	verifier := s.imageVerifier()
+	if verifier == nil {
+		return "", nil
+	}
+
+	digest, err := verifier.Verify(ctx, imageRef)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", NewCompareError("verify-signature", ErrContextCanceled, "The signature verification was canceled")
+		}
+		return "", NewSecurityError("image-signature-invalid",
+			fmt.Sprintf("signature verification failed for image '%s': %v", imageRef, err),
+			"Only images signed with the configured cosign key can be used. Sign the image with 'cosign sign --key' or use a signed image.")
+	}
+
+	slog.Default().Debug("Image signature verified", "image", imageRef, "digest", digest)
+	return digest, nil
+}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CosignVerifier", func() {
+	var (
+		server   *httptest.Server
+		imageRef string
+		digest   v1.Hash
+		key      *ecdsa.PrivateKey
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(registry.New())
+		DeferCleanup(server.Close)
+
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		imageRef = u.Host + "/openshift4/telco-core-rds:v4.18"
+
+		img := newTestReferenceImage(map[string]string{"reference/metadata.yaml": "apiVersion: v2\n"})
+		ref, err := name.ParseReference(imageRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remote.Write(ref, img)).To(Succeed())
+		digest, err = img.Digest()
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// sign pushes a cosign signature for the test image made with signer over a
+	// payload naming signedDigest.
+	sign := func(signer *ecdsa.PrivateKey, signedDigest string) {
+		payload := []byte(fmt.Sprintf(
+			`{"critical":{"identity":{"docker-reference":"%s"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`,
+			strings.Split(imageRef, ":v")[0], signedDigest))
+		hash := sha256.Sum256(payload)
+		signature, err := ecdsa.SignASN1(rand.Reader, signer, hash[:])
+		Expect(err).NotTo(HaveOccurred())
+
+		layer := static.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json")
+		sigImage, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+			Layer:       layer,
+			Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ref, err := name.ParseReference(imageRef)
+		Expect(err).NotTo(HaveOccurred())
+		sigTag := ref.Context().Tag(strings.Replace(digest.String(), ":", "-", 1) + ".sig")
+		Expect(remote.Write(sigTag, sigImage)).To(Succeed())
+	}
+
+	newVerifier := func() *CosignVerifier {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		verifier, err := NewCosignVerifier(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		Expect(err).NotTo(HaveOccurred())
+		// The test registry is plain HTTP on loopback
+		verifier.Options = nil
+		return verifier
+	}
+
+	It("returns the digest of an image signed with the key", func() {
+		sign(key, digest.String())
+
+		verified, err := newVerifier().Verify(context.Background(), imageRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verified).To(Equal(digest.String()))
+	})
+
+	It("rejects an unsigned image", func() {
+		_, err := newVerifier().Verify(context.Background(), imageRef)
+		Expect(err).To(MatchError(ContainSubstring("no signature found")))
+	})
+
+	It("rejects a signature made with another key", func() {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		sign(other, digest.String())
+
+		_, err = newVerifier().Verify(context.Background(), imageRef)
+		Expect(err).To(MatchError(ContainSubstring("verified with the configured public key")))
+	})
+
+	It("rejects a signature for a different digest", func() {
+		sign(key, "sha256:"+strings.Repeat("0", 64))
+
+		_, err := newVerifier().Verify(context.Background(), imageRef)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("CompareService.pullContainerImage", func() {
+		It("verifies with the injected verifier and pulls by the verified digest", func() {
+			sign(key, digest.String())
+
+			_, pulled, _, err := (&CompareService{Verifier: newVerifier()}).pullContainerImage(context.Background(), imageRef, "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pulled).To(Equal(digest.String()))
+		})
+
+		It("rejects an unsigned image with the injected verifier", func() {
+			_, _, _, err := (&CompareService{Verifier: newVerifier()}).pullContainerImage(context.Background(), imageRef, "", "")
+			var secErr *SecurityError
+			Expect(errors.As(err, &secErr)).To(BeTrue())
+		})
+
+		It("does not verify again an image already verified", func() {
+			// The image is unsigned, so verifying it again would fail
+			_, pulled, _, err := (&CompareService{Verifier: newVerifier()}).pullContainerImage(context.Background(), imageRef, "", digest.String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pulled).To(Equal(digest.String()))
+		})
+	})
+
+	Describe("LoadCosignPublicKeyFromEnv", func() {
+		writeKey := func(keyPEM []byte) string {
+			keyPath := filepath.Join(GinkgoT().TempDir(), "cosign.pub")
+			Expect(os.WriteFile(keyPath, keyPEM, 0o600)).To(Succeed())
+			return keyPath
+		}
+
+		It("returns no key when none is configured", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY", "")
+			publicKey, err := LoadCosignPublicKeyFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publicKey).To(BeNil())
+		})
+
+		It("loads the configured public key", func() {
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY", writeKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+			publicKey, err := LoadCosignPublicKeyFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publicKey).To(Equal(&key.PublicKey))
+		})
+
+		It("fails when the public key cannot be read", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY", filepath.Join(GinkgoT().TempDir(), "missing.pub"))
+			_, err := LoadCosignPublicKeyFromEnv()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails when the public key is not PEM encoded", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_COSIGN_PUBLIC_KEY", writeKey([]byte("not a key")))
+			_, err := LoadCosignPublicKeyFromEnv()
+			Expect(err).To(MatchError(ContainSubstring("not PEM encoded")))
+		})
+	})
+
+	Describe("newConfiguredImageVerifier", func() {
+		It("is disabled when no public key is set", func() {
+			Expect(newConfiguredImageVerifier(nil)).To(BeNil())
+		})
+
+		It("verifies with the public key set at startup", func() {
+			SetCosignPublicKey(&key.PublicKey)
+			DeferCleanup(func() { SetCosignPublicKey(nil) })
+
+			verifier := newConfiguredImageVerifier(nil)
+			Expect(verifier).To(BeAssignableToTypeOf(&CosignVerifier{}))
+			Expect(verifier.(*CosignVerifier).PublicKey).To(Equal(&key.PublicKey))
+		})
+	})
+})
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+const (
+	// DefaultServiceAccountDir is where Kubernetes mounts the pod's service account credentials.
+	DefaultServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// inClusterConfigAttempts is the number of times a missing token or CA is retried.
+	inClusterConfigAttempts = 5
+)
+
+// inClusterRetryInterval is the delay between in-cluster config attempts.
+// It is a variable so tests can shorten it.
+var inClusterRetryInterval = 500 * time.Millisecond
+
+// localInClusterDisabled is set when the server must not fall back to its own
+// cluster for target operations. See SetDisableLocalInCluster.
+var localInClusterDisabled atomic.Bool
+
+// SetDisableLocalInCluster controls whether tools may implicitly target the cluster
+// the server runs in when no kubeconfig is provided. When disabled, every target
+// operation requires an explicit kubeconfig. The reference ConfigMap lookup for the
+// BIOS tools still uses the in-cluster config, since it is scoped to the server's
+// own cluster by design.
+func SetDisableLocalInCluster(disable bool) {
+	localInClusterDisabled.Store(disable)
+}
+
+// requireExplicitKubeconfig returns an error wrapping ErrExplicitKubeconfigRequired
+// when implicit in-cluster access to the target cluster is disabled, and nil otherwise.
+func requireExplicitKubeconfig(op string) error {
+	if !localInClusterDisabled.Load() {
+		return nil
+	}
+	return NewCompareError(op, ErrExplicitKubeconfigRequired,
+		"The server was started with --disable-local-in-cluster and will not act on the cluster it runs in. "+
+			"Provide a kubeconfig for the target cluster.")
+}
+
+// resolveTargetInClusterConfig is resolveInClusterConfig for operations on a target
+// cluster. It fails when implicit in-cluster access is disabled.
+func resolveTargetInClusterConfig(ctx context.Context, op, purpose string) (*rest.Config, error) {
+	if err := requireExplicitKubeconfig(op); err != nil {
+		return nil, err
+	}
+	return resolveInClusterConfig(ctx, op, purpose)
+}
+
+// getServiceAccountDir returns the directory containing the service account token and CA.
+// Can be configured via KUBE_COMPARE_MCP_SERVICE_ACCOUNT_DIR environment variable.
+func getServiceAccountDir() string {
+	if val := os.Getenv("KUBE_COMPARE_MCP_SERVICE_ACCOUNT_DIR"); val != "" {
+		return val
+	}
+	return DefaultServiceAccountDir
+}
+
+// resolveInClusterConfig builds a REST config from the pod's service account.
+// A missing token or CA file is retried briefly since the service account volume
+// may not be populated yet when the server starts. The returned error wraps
+// ErrNotInCluster, ErrServiceAccountTokenMissing, or ErrServiceAccountCAMissing
+// so callers can report the specific cause. op and purpose describe the caller
+// for the error message.
+func resolveInClusterConfig(ctx context.Context, op, purpose string) (*rest.Config, error) {
+	logger := slog.Default()
+
+	var lastErr error
+	for attempt := 1; attempt <= inClusterConfigAttempts; attempt++ {
+		config, err := loadInClusterConfig(getServiceAccountDir())
+		if err == nil {
+			return config, nil
+		}
+		lastErr = err
+
+		// Not running in a pod will not resolve itself
+		if errors.Is(err, ErrNotInCluster) || attempt == inClusterConfigAttempts {
+			break
+		}
+
+		logger.Debug("In-cluster config not ready, retrying",
+			"attempt", attempt,
+			"retryInterval", inClusterRetryInterval,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, NewCompareError(op, ErrContextCanceled, "The request was canceled while waiting for in-cluster config")
+		case <-time.After(inClusterRetryInterval):
+		}
+	}
+
+	return nil, NewCompareError(op, lastErr, inClusterRemediation(lastErr, purpose))
+}
+
+// loadInClusterConfig mirrors rest.InClusterConfig but reads credentials from
+// saDir and reports which prerequisite is missing. Like a kubeconfig's REST config,
+// it retries requests the API server rejects with HTTP 429.
+func loadInClusterConfig(saDir string) (*rest.Config, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("%w: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set", ErrNotInCluster)
+	}
+
+	tokenFile := filepath.Join(saDir, "token")
+	if _, err := os.Stat(tokenFile); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrServiceAccountTokenMissing, tokenFile, err)
+	}
+
+	caFile := filepath.Join(saDir, "ca.crt")
+	if _, err := certutil.NewPool(caFile); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrServiceAccountCAMissing, caFile, err)
+	}
+
+	config := &rest.Config{
+		Host:            "https://" + net.JoinHostPort(host, port),
+		TLSClientConfig: rest.TLSClientConfig{CAFile: caFile},
+		BearerTokenFile: tokenFile,
+	}
+	withRateLimitRetry(config)
+	return config, nil
+}
+
+// inClusterRemediation returns a remediation hint for an in-cluster config failure.
+func inClusterRemediation(err error, purpose string) string {
+	switch {
+	case errors.Is(err, ErrNotInCluster):
+		return "The server is not running inside a Kubernetes pod, so in-cluster config is not available. " + purpose
+	case errors.Is(err, ErrServiceAccountTokenMissing):
+		return "The service account token could not be read. Ensure automountServiceAccountToken is not disabled for the pod, " +
+			"or set KUBE_COMPARE_MCP_SERVICE_ACCOUNT_DIR if the token is mounted at a non-standard path. " + purpose
+	case errors.Is(err, ErrServiceAccountCAMissing):
+		return "The service account CA certificate could not be read. Ensure the pod's service account volume includes ca.crt, " +
+			"or set KUBE_COMPARE_MCP_SERVICE_ACCOUNT_DIR if it is mounted at a non-standard path. " + purpose
+	default:
+		return purpose
+	}
+}
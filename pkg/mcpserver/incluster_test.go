@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+var _ = Describe("resolveInClusterConfig", func() {
+	var saDir string
+
+	writeToken := func() {
+		Expect(os.WriteFile(filepath.Join(saDir, "token"), []byte("sa-token"), 0o600)).To(Succeed())
+	}
+
+	writeCA := func() {
+		cert, _, err := certutil.GenerateSelfSignedCertKey("kubernetes.default.svc", nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(saDir, "ca.crt"), cert, 0o600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		saDir = GinkgoT().TempDir()
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_SERVICE_ACCOUNT_DIR", saDir)
+		GinkgoT().Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		GinkgoT().Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+		original := inClusterRetryInterval
+		inClusterRetryInterval = time.Millisecond
+		DeferCleanup(func() { inClusterRetryInterval = original })
+	})
+
+	It("builds a config from the service account directory", func() {
+		writeToken()
+		writeCA()
+
+		config, err := resolveInClusterConfig(context.Background(), "cluster-config", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Host).To(Equal("https://10.0.0.1:443"))
+		Expect(config.BearerTokenFile).To(Equal(filepath.Join(saDir, "token")))
+		Expect(config.TLSClientConfig.CAFile).To(Equal(filepath.Join(saDir, "ca.crt")))
+	})
+
+	It("reports a missing token file", func() {
+		writeCA()
+
+		_, err := resolveInClusterConfig(context.Background(), "cluster-config", "Provide a kubeconfig.")
+		Expect(err).To(MatchError(ErrServiceAccountTokenMissing))
+		Expect(err).NotTo(MatchError(ErrServiceAccountCAMissing))
+		Expect(err.Error()).To(ContainSubstring("automountServiceAccountToken"))
+		Expect(err.Error()).To(ContainSubstring("Provide a kubeconfig."))
+	})
+
+	It("reports a missing CA file", func() {
+		writeToken()
+
+		_, err := resolveInClusterConfig(context.Background(), "cluster-config", "")
+		Expect(err).To(MatchError(ErrServiceAccountCAMissing))
+		Expect(err).NotTo(MatchError(ErrServiceAccountTokenMissing))
+		Expect(err.Error()).To(ContainSubstring("ca.crt"))
+	})
+
+	It("reports when not running in a pod", func() {
+		GinkgoT().Setenv("KUBERNETES_SERVICE_HOST", "")
+
+		_, err := resolveInClusterConfig(context.Background(), "cluster-config", "")
+		Expect(err).To(MatchError(ErrNotInCluster))
+		Expect(err.Error()).To(ContainSubstring("not running inside a Kubernetes pod"))
+	})
+
+	It("retries until the token becomes available", func() {
+		writeCA()
+		inClusterRetryInterval = 20 * time.Millisecond
+		tokenFile := filepath.Join(saDir, "token")
+		time.AfterFunc(30*time.Millisecond, func() {
+			_ = os.WriteFile(tokenFile, []byte("sa-token"), 0o600)
+		})
+
+		config, err := resolveInClusterConfig(context.Background(), "cluster-config", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.BearerTokenFile).To(Equal(filepath.Join(saDir, "token")))
+	})
+
+	It("stops retrying when the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := resolveInClusterConfig(ctx, "cluster-config", "")
+		Expect(err).To(MatchError(ErrContextCanceled))
+	})
+})
+
+var _ = Describe("SetDisableLocalInCluster", func() {
+	resultText := func(result *mcp.CallToolResult) string {
+		Expect(result.IsError).To(BeTrue())
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		Expect(ok).To(BeTrue())
+		return textContent.Text
+	}
+
+	BeforeEach(func() {
+		SetDisableLocalInCluster(true)
+		DeferCleanup(func() { SetDisableLocalInCluster(false) })
+
+		original := defaultReferenceService
+		defaultReferenceService = &ReferenceService{Registry: &staticRegistry{tags: []string{"v4.18"}}}
+		DeferCleanup(func() { defaultReferenceService = original })
+	})
+
+	It("requires an explicit kubeconfig for kube_compare_cluster_diff", func() {
+		result, _, err := HandleClusterDiff(context.Background(), nil, ClusterDiffInput{
+			Reference: "https://example.com/metadata.yaml",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resultText(result)).To(ContainSubstring("explicit kubeconfig required"))
+	})
+
+	It("requires an explicit kubeconfig for kube_compare_validate_rds, even with ocp_version", func() {
+		result, _, err := HandleValidateRDS(context.Background(), nil, ValidateRDSInput{
+			RDSType:    RDSTypeCore,
+			OCPVersion: "4.18",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resultText(result)).To(ContainSubstring("explicit kubeconfig required"))
+	})
+
+	It("requires an explicit kubeconfig to detect the cluster version", func() {
+		result, _, err := HandleResolveRDS(context.Background(), nil, ResolveRDSInput{RDSType: RDSTypeCore})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resultText(result)).To(ContainSubstring("explicit kubeconfig required"))
+	})
+
+	It("still resolves an RDS for an explicit ocp_version", func() {
+		result, resolved, err := HandleResolveRDS(context.Background(), nil, ResolveRDSInput{
+			RDSType:    RDSTypeCore,
+			OCPVersion: "4.18",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+		Expect(resolved.ClusterVersion).To(Equal("4.18"))
+	})
+
+	It("requires an explicit kubeconfig for the BIOS target cluster", func() {
+		result, _, err := HandleHostFirmwareSettings(context.Background(), nil, HostFirmwareSettingsInput{
+			Namespace: "spoke",
+			HostName:  "node-0",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resultText(result)).To(ContainSubstring("explicit kubeconfig required"))
+		Expect(resultText(result)).To(ContainSubstring("--disable-local-in-cluster"))
+	})
+
+	It("leaves the in-cluster config available for the server's own cluster", func() {
+		saDir := GinkgoT().TempDir()
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_SERVICE_ACCOUNT_DIR", saDir)
+		GinkgoT().Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		GinkgoT().Setenv("KUBERNETES_SERVICE_PORT", "443")
+		Expect(os.WriteFile(filepath.Join(saDir, "token"), []byte("sa-token"), 0o600)).To(Succeed())
+		cert, _, err := certutil.GenerateSelfSignedCertKey("kubernetes.default.svc", nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(saDir, "ca.crt"), cert, 0o600)).To(Succeed())
+
+		_, err = resolveInClusterConfig(context.Background(), "reference-config", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = resolveTargetInClusterConfig(context.Background(), "cluster-config", "")
+		Expect(err).To(MatchError(ErrExplicitKubeconfigRequired))
+	})
+})
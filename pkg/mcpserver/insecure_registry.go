@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// getInsecureRegistries returns the registry hosts, such as localhost:5000, that are
+// reached over plain HTTP or without verifying their TLS certificate. Meant for
+// development registries only; every other registry is reached securely.
+// Can be configured via KUBE_COMPARE_MCP_INSECURE_REGISTRIES environment variable
+// (comma-separated host[:port] list).
+func getInsecureRegistries() []string {
+	var hosts []string
+	for _, host := range strings.Split(os.Getenv("KUBE_COMPARE_MCP_INSECURE_REGISTRIES"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// isInsecureRegistry reports whether the registry at host is listed in
+// KUBE_COMPARE_MCP_INSECURE_REGISTRIES. Hosts must match exactly, port included.
+func isInsecureRegistry(host string) bool {
+	return slices.Contains(getInsecureRegistries(), strings.ToLower(host))
+}
+
+// parseImageReference parses imageRef, allowing plain HTTP for a registry listed in
+// KUBE_COMPARE_MCP_INSECURE_REGISTRIES.
+func parseImageReference(imageRef string) (name.Reference, error) {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil || !isInsecureRegistry(ref.Context().RegistryStr()) {
+		return ref, err
+	}
+	slog.Default().Warn("Accessing registry listed in KUBE_COMPARE_MCP_INSECURE_REGISTRIES without TLS verification",
+		"registry", ref.Context().RegistryStr())
+	return name.ParseReference(imageRef, name.Insecure)
+}
+
+// parseRepository parses repoRef, allowing plain HTTP for a registry listed in
+// KUBE_COMPARE_MCP_INSECURE_REGISTRIES.
+func parseRepository(repoRef string) (name.Repository, error) {
+	repo, err := name.NewRepository(repoRef)
+	if err != nil || !isInsecureRegistry(repo.RegistryStr()) {
+		return repo, err
+	}
+	slog.Default().Warn("Accessing registry listed in KUBE_COMPARE_MCP_INSECURE_REGISTRIES without TLS verification",
+		"registry", repo.RegistryStr())
+	return name.NewRepository(repoRef, name.Insecure)
+}
+
+// insecureRegistryTransport sends requests to the registries listed in
+// KUBE_COMPARE_MCP_INSECURE_REGISTRIES through a transport that skips TLS
+// verification, and every other request through the secure transport.
+type insecureRegistryTransport struct {
+	secure   http.RoundTripper
+	insecure http.RoundTripper
+}
+
+// newInsecureRegistryTransport returns secure, sending requests to the listed
+// insecure registries through a copy of it that skips TLS verification.
+func newInsecureRegistryTransport(secure http.RoundTripper) http.RoundTripper {
+	base, ok := secure.(*http.Transport)
+	if !ok {
+		return secure
+	}
+	insecure := base.Clone()
+	if insecure.TLSClientConfig == nil {
+		insecure.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	insecure.TLSClientConfig.InsecureSkipVerify = true
+	return &insecureRegistryTransport{secure: secure, insecure: insecure}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *insecureRegistryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isInsecureRegistry(req.URL.Host) {
+		return t.insecure.RoundTrip(req)
+	}
+	return t.secure.RoundTrip(req)
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newSelfSignedTestRegistry starts a registry serving TLS with a self-signed
+// certificate and holding the image org/refs:v1, and returns its host.
+func newSelfSignedTestRegistry() string {
+	server := httptest.NewTLSServer(registry.New())
+	DeferCleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	Expect(err).NotTo(HaveOccurred())
+
+	ref, err := name.ParseReference(u.Host + "/org/refs:v1")
+	Expect(err).NotTo(HaveOccurred())
+	img := newTestReferenceImage(map[string]string{"reference/metadata.yaml": "apiVersion: v2\n"})
+	Expect(remote.Write(ref, img, remote.WithTransport(server.Client().Transport))).To(Succeed())
+	return u.Host
+}
+
+var _ = Describe("Insecure registries", func() {
+	Describe("getInsecureRegistries", func() {
+		It("is empty by default", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_INSECURE_REGISTRIES", "")
+			Expect(getInsecureRegistries()).To(BeEmpty())
+		})
+
+		It("trims and lowercases the listed hosts", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_INSECURE_REGISTRIES", " Dev-Registry.example.com:5000, ,localhost:5000 ")
+			Expect(getInsecureRegistries()).To(Equal([]string{"dev-registry.example.com:5000", "localhost:5000"}))
+		})
+	})
+
+	Describe("parseImageReference", func() {
+		BeforeEach(func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_INSECURE_REGISTRIES", "dev-registry.example.com:5000")
+		})
+
+		It("allows plain HTTP for a listed registry", func() {
+			ref, err := parseImageReference("dev-registry.example.com:5000/org/refs:v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref.Context().Scheme()).To(Equal("http"))
+		})
+
+		It("keeps HTTPS for a registry that is not listed", func() {
+			ref, err := parseImageReference("quay.io/org/refs:v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref.Context().Scheme()).To(Equal("https"))
+
+			ref, err = parseImageReference("dev-registry.example.com:5001/org/refs:v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref.Context().Scheme()).To(Equal("https"))
+		})
+	})
+
+	Describe("parseRepository", func() {
+		It("allows plain HTTP only for a listed registry", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_INSECURE_REGISTRIES", "dev-registry.example.com:5000")
+
+			repo, err := parseRepository("dev-registry.example.com:5000/org/refs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.Scheme()).To(Equal("http"))
+
+			repo, err = parseRepository("quay.io/org/refs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.Scheme()).To(Equal("https"))
+		})
+	})
+
+	Describe("registry access", func() {
+		var (
+			client       *DefaultRegistryClient
+			registryHost string
+		)
+
+		BeforeEach(func() {
+			client = &DefaultRegistryClient{}
+			registryHost = newSelfSignedTestRegistry()
+		})
+
+		It("skips TLS verification for a listed registry", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_INSECURE_REGISTRIES", registryHost)
+
+			tags, err := client.ListTags(context.Background(), registryHost+"/org/refs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tags).To(ConsistOf("v1"))
+
+			Expect(client.HeadImage(context.Background(), registryHost+"/org/refs:v1")).To(Succeed())
+		})
+
+		It("verifies TLS for a registry that is not listed", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_INSECURE_REGISTRIES", "dev-registry.example.com:5000")
+
+			_, err := client.ListTags(context.Background(), registryHost+"/org/refs")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("certificate"))
+		})
+	})
+})
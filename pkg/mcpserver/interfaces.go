@@ -1,6 +1,6 @@
 // SPDX-License-Identifier: Apache-2.0
 
-//go:generate mockgen -destination=mock_interfaces_test.go -package=mcpserver_test github.com/sakhoury/kube-compare-mcp/pkg/mcpserver RegistryClient,ClusterClient,ClusterClientFactory,HTTPDoer
+//go:generate mockgen -destination=mock_interfaces_test.go -package=mcpserver_test github.com/sakhoury/kube-compare-mcp/pkg/mcpserver RegistryClient,ClusterClient,ClusterClientFactory,HTTPDoer,ImageVerifier
 
 package mcpserver
 
@@ -11,7 +11,7 @@ import (
 	"net/http"
 
 	"github.com/google/go-containerregistry/pkg/authn"
-	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -26,12 +26,46 @@ type RegistryClient interface {
 	ListTags(ctx context.Context, repo string) ([]string, error)
 	// HeadImage performs a HEAD request on an image to validate it exists.
 	HeadImage(ctx context.Context, imageRef string) error
+	// Digest returns the manifest digest an image reference resolves to.
+	Digest(ctx context.Context, imageRef string) (string, error)
+	// GetImageConfig returns the digest and config file of an image.
+	GetImageConfig(ctx context.Context, imageRef string) (*ImageConfig, error)
+}
+
+// ImageConfig is the digest and config file of an image in a registry.
+type ImageConfig struct {
+	// Digest is the digest of the image manifest.
+	Digest string
+	// Config is the image's config file, carrying its labels and creation time.
+	Config *v1.ConfigFile
 }
 
 // ClusterClient abstracts Kubernetes cluster operations for testing.
 type ClusterClient interface {
 	// GetClusterVersion returns the OpenShift cluster version from the ClusterVersion resource.
-	GetClusterVersion(ctx context.Context) (string, error)
+	GetClusterVersion(ctx context.Context) (*ClusterVersionInfo, error)
+}
+
+// ClusterVersionInfo describes the OpenShift version a cluster runs and, during an
+// upgrade, the version it is moving to.
+type ClusterVersionInfo struct {
+	// Desired is status.desired.version, the target of any in-progress update.
+	Desired string
+	// Completed is the newest status.history version whose update completed.
+	// Empty when the cluster has no completed update, e.g. during installation.
+	Completed string
+	// Upgrading is true when the newest status.history entry has not completed
+	// and an earlier version had.
+	Upgrading bool
+}
+
+// Current returns the version the cluster is actually running: the last completed
+// version during an upgrade, and the desired version otherwise.
+func (v *ClusterVersionInfo) Current() string {
+	if v.Upgrading {
+		return v.Completed
+	}
+	return v.Desired
 }
 
 // ClusterClientFactory creates ClusterClient instances from rest.Config.
@@ -46,20 +80,27 @@ type HTTPDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// ImageVerifier abstracts container image signature verification for testing.
+type ImageVerifier interface {
+	// Verify checks the signature of imageRef and returns the verified image digest.
+	Verify(ctx context.Context, imageRef string) (string, error)
+}
+
 // DefaultRegistryClient is the production implementation of RegistryClient.
-type DefaultRegistryClient struct{}
+type DefaultRegistryClient struct {
+	// Keychain supplies registry credentials. When nil, the keychain set by
+	// SetRegistryKeychain is used.
+	Keychain authn.Keychain
+}
 
 // ListTags lists all available tags from a container image repository.
 func (c *DefaultRegistryClient) ListTags(ctx context.Context, repoRef string) ([]string, error) {
-	repo, err := name.NewRepository(repoRef)
+	repo, err := parseRepository(repoRef)
 	if err != nil {
 		return nil, fmt.Errorf("invalid repository reference %q: %w", repoRef, err)
 	}
 
-	tags, err := remote.List(repo,
-		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
-	)
+	tags, err := remote.List(repo, append(registryOptions(c.Keychain), remote.WithContext(ctx))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tags for %q: %w", repoRef, err)
 	}
@@ -68,28 +109,63 @@ func (c *DefaultRegistryClient) ListTags(ctx context.Context, repoRef string) ([
 
 // HeadImage performs a HEAD request on an image to validate it exists and is accessible.
 func (c *DefaultRegistryClient) HeadImage(ctx context.Context, imageRef string) error {
-	ref, err := name.ParseReference(imageRef)
+	ref, err := parseImageReference(imageRef)
 	if err != nil {
 		return fmt.Errorf("invalid image reference %q: %w", imageRef, err)
 	}
 
-	_, err = remote.Head(ref,
-		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
-	)
+	_, err = remote.Head(ref, append(registryOptions(c.Keychain), remote.WithContext(ctx))...)
 	if err != nil {
 		return fmt.Errorf("failed to access image %q: %w", imageRef, err)
 	}
 	return nil
 }
 
+// Digest resolves an image reference to the digest of its manifest with a HEAD request.
+func (c *DefaultRegistryClient) Digest(ctx context.Context, imageRef string) (string, error) {
+	ref, err := parseImageReference(imageRef)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", imageRef, err)
+	}
+
+	desc, err := remote.Head(ref, append(registryOptions(c.Keychain), remote.WithContext(ctx))...)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of image %q: %w", imageRef, err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// GetImageConfig fetches the manifest and config file of an image. For a multi-platform
+// image, the linux/amd64 image is used.
+func (c *DefaultRegistryClient) GetImageConfig(ctx context.Context, imageRef string) (*ImageConfig, error) {
+	ref, err := parseImageReference(imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %q: %w", imageRef, err)
+	}
+
+	img, err := remote.Image(ref, append(registryOptions(c.Keychain), remote.WithContext(ctx))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access image %q: %w", imageRef, err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest of image %q: %w", imageRef, err)
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config of image %q: %w", imageRef, err)
+	}
+	return &ImageConfig{Digest: digest.String(), Config: config}, nil
+}
+
 // DefaultClusterClient is the production implementation of ClusterClient.
 type DefaultClusterClient struct {
 	client dynamic.Interface
 }
 
 // GetClusterVersion queries the cluster for its OpenShift version.
-func (c *DefaultClusterClient) GetClusterVersion(ctx context.Context) (string, error) {
+func (c *DefaultClusterClient) GetClusterVersion(ctx context.Context) (*ClusterVersionInfo, error) {
 	clusterVersionGVR := schema.GroupVersionResource{
 		Group:    "config.openshift.io",
 		Version:  "v1",
@@ -98,18 +174,71 @@ func (c *DefaultClusterClient) GetClusterVersion(ctx context.Context) (string, e
 
 	result, err := c.client.Resource(clusterVersionGVR).Get(ctx, "version", metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to get ClusterVersion: %w", err)
+		return nil, fmt.Errorf("failed to get ClusterVersion: %w", err)
 	}
 
-	version, found, err := unstructured.NestedString(result.Object, "status", "desired", "version")
+	return parseClusterVersion(result)
+}
+
+// parseClusterVersion reads the desired version and update history from a ClusterVersion.
+// status.history is ordered newest first.
+func parseClusterVersion(obj *unstructured.Unstructured) (*ClusterVersionInfo, error) {
+	info := &ClusterVersionInfo{}
+
+	history, _, err := unstructured.NestedSlice(obj.Object, "status", "history")
 	if err != nil {
-		return "", fmt.Errorf("failed to extract version from ClusterVersion: %w", err)
+		return nil, fmt.Errorf("failed to extract history from ClusterVersion: %w", err)
 	}
-	if !found {
-		return "", errors.New("version not found in ClusterVersion status")
+	for i, item := range history {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		state, _, _ := unstructured.NestedString(entry, "state")
+		if state != "Completed" {
+			continue
+		}
+		info.Completed, _, _ = unstructured.NestedString(entry, "version")
+		info.Upgrading = i > 0 && info.Completed != ""
+		break
+	}
+
+	info.Desired = desiredClusterVersion(obj)
+	if info.Desired == "" {
+		// Without a desired version there is no upgrade to detect
+		info.Desired = info.Completed
+		info.Upgrading = false
+	}
+	if info.Desired == "" {
+		if version, _, _ := unstructured.NestedString(obj.Object, "status", "version"); version != "" {
+			info.Desired = version
+		}
+	}
+	if info.Desired == "" {
+		return nil, errors.New("version not found in ClusterVersion status: " +
+			"none of status.desired.version, a completed status.history entry, or status.version is set")
 	}
 
-	return version, nil
+	return info, nil
+}
+
+// desiredClusterVersion returns the version the cluster is updating to, read from
+// status.desired.version or, on clusters that report it as a string, status.desired.
+// It returns "" when neither is set.
+func desiredClusterVersion(obj *unstructured.Unstructured) string {
+	desired, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "status", "desired")
+	if !found {
+		return ""
+	}
+	switch desired := desired.(type) {
+	case map[string]any:
+		version, _ := desired["version"].(string)
+		return version
+	case string:
+		return desired
+	default:
+		return ""
+	}
 }
 
 // DefaultClusterClientFactory is the production implementation of ClusterClientFactory.
@@ -127,8 +256,10 @@ func (f *DefaultClusterClientFactory) NewClient(config *rest.Config) (ClusterCli
 // Package-level default implementations for production use.
 // These can be overridden in tests.
 var (
-	// DefaultRegistry is the default RegistryClient implementation.
-	DefaultRegistry RegistryClient = &DefaultRegistryClient{}
+	// DefaultRegistry is the default RegistryClient implementation. It authenticates
+	// with the keychain set by SetRegistryKeychain.
+	// Concurrent calls for the same repository or image are coalesced.
+	DefaultRegistry RegistryClient = NewSingleflightRegistryClient(&DefaultRegistryClient{})
 
 	// DefaultClusterFactory is the default ClusterClientFactory implementation.
 	DefaultClusterFactory ClusterClientFactory = &DefaultClusterClientFactory{}
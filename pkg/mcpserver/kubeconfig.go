@@ -6,6 +6,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
 	"strings"
 
 	"k8s.io/client-go/rest"
@@ -221,6 +223,127 @@ func BlockAuthProviderPlugins(config *clientcmdapi.Config) error {
 	return nil
 }
 
+const (
+	// PlainHTTPServerWarn logs a kubeconfig cluster whose server is http:// and builds
+	// the config anyway.
+	PlainHTTPServerWarn = "warn"
+	// PlainHTTPServerError rejects a kubeconfig cluster whose server is http://.
+	PlainHTTPServerError = "error"
+)
+
+// getPlainHTTPServerPolicy returns how a kubeconfig cluster whose server URL is
+// http:// is handled. Can be configured via KUBE_COMPARE_MCP_PLAIN_HTTP_SERVER environment
+// variable ("warn" or "error"). Defaults to "warn".
+func getPlainHTTPServerPolicy() string {
+	if val := os.Getenv("KUBE_COMPARE_MCP_PLAIN_HTTP_SERVER"); val == PlainHTTPServerError {
+		return PlainHTTPServerError
+	}
+	return PlainHTTPServerWarn
+}
+
+// checkAPIServerTransport flags a cluster whose server URL is http://, which real
+// API servers almost never serve and which otherwise surfaces later as a confusing
+// TLS or connection error, and logs a security note when TLS verification is skipped.
+func checkAPIServerTransport(clusterName string, cluster *clientcmdapi.Cluster) error {
+	logger := slog.Default()
+
+	if cluster.InsecureSkipTLSVerify {
+		logger.Warn("Kubeconfig cluster skips TLS verification",
+			"event", "security_note",
+			"cluster", clusterName,
+			"server", cluster.Server,
+		)
+	}
+
+	serverURL, err := url.Parse(cluster.Server)
+	if err != nil || !strings.EqualFold(serverURL.Scheme, "http") {
+		// Malformed URLs are reported when the client config is built
+		return nil
+	}
+	if getPlainHTTPServerPolicy() == PlainHTTPServerError {
+		logger.Error("Security violation: plain HTTP API server blocked",
+			"event", "security_violation",
+			"violation_type", "plain_http_server_blocked",
+			"cluster", clusterName,
+			"server", cluster.Server,
+		)
+		return NewSecurityError("plain-http-server-blocked",
+			fmt.Sprintf("server '%s' of cluster '%s' does not use https", cluster.Server, clusterName),
+			"Use the https:// URL of the API server, usually https://api.<cluster-domain>:6443")
+	}
+	logger.Warn("Kubeconfig cluster server does not use https",
+		"cluster", clusterName,
+		"server", cluster.Server,
+		"hint", "API servers are almost always served over https; check the server URL",
+	)
+	return nil
+}
+
+// AuthMethod is how a kubeconfig user authenticates, recorded for auditing without
+// the credential itself.
+type AuthMethod string
+
+const (
+	// AuthMethodToken is a bearer token, inline or from a token file.
+	AuthMethodToken AuthMethod = "token"
+	// AuthMethodClientCert is a client certificate.
+	AuthMethodClientCert AuthMethod = "client-cert"
+	// AuthMethodBasic is a username and password.
+	AuthMethodBasic AuthMethod = "basic"
+	// AuthMethodExec is an exec credential plugin, which ValidateKubeconfigSecurity rejects.
+	AuthMethodExec AuthMethod = "exec"
+	// AuthMethodAuthProvider is an auth provider plugin, which ValidateKubeconfigSecurity rejects.
+	AuthMethodAuthProvider AuthMethod = "auth-provider"
+	// AuthMethodNone is a user without credentials.
+	AuthMethodNone AuthMethod = "none"
+)
+
+// KubeconfigContextNamespace returns the namespace set on the context contextName of
+// kubeconfig (raw YAML or base64-encoded), or on its current context when contextName
+// is empty. It returns "" when kubeconfig is empty, sets no namespace, or cannot be
+// parsed; a kubeconfig that cannot be parsed is reported when the tool connects.
+func KubeconfigContextNamespace(kubeconfig, contextName string) string {
+	if kubeconfig == "" {
+		return ""
+	}
+	data, err := DecodeOrParseKubeconfig(kubeconfig)
+	if err != nil {
+		return ""
+	}
+	config, err := ParseKubeconfig(data)
+	if err != nil {
+		return ""
+	}
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	if ctx, ok := config.Contexts[contextName]; ok {
+		return ctx.Namespace
+	}
+	return ""
+}
+
+// DetectAuthMethod returns the auth method of authInfo. When a user sets several, the
+// first of token, client certificate, basic auth, exec, and auth provider is reported.
+func DetectAuthMethod(authInfo *clientcmdapi.AuthInfo) AuthMethod {
+	switch {
+	case authInfo == nil:
+		return AuthMethodNone
+	case authInfo.Token != "" || authInfo.TokenFile != "":
+		return AuthMethodToken
+	case len(authInfo.ClientCertificateData) > 0 || authInfo.ClientCertificate != "":
+		return AuthMethodClientCert
+	case authInfo.Username != "" || authInfo.Password != "":
+		return AuthMethodBasic
+	case authInfo.Exec != nil:
+		return AuthMethodExec
+	case authInfo.AuthProvider != nil:
+		return AuthMethodAuthProvider
+	default:
+		return AuthMethodNone
+	}
+}
+
 // BuildRestConfig creates a rest.Config from the validated kubeconfig.
 func BuildRestConfig(config *clientcmdapi.Config, contextName string) (*rest.Config, error) {
 	logger := slog.Default()
@@ -247,7 +370,8 @@ func BuildRestConfig(config *clientcmdapi.Config, contextName string) (*rest.Con
 			fmt.Sprintf("Available contexts: %s", strings.Join(availableContexts, ", ")))
 	}
 
-	if _, exists := config.Clusters[ctx.Cluster]; !exists {
+	cluster, exists := config.Clusters[ctx.Cluster]
+	if !exists {
 		availableClusters := make([]string, 0, len(config.Clusters))
 		for name := range config.Clusters {
 			availableClusters = append(availableClusters, name)
@@ -267,6 +391,10 @@ func BuildRestConfig(config *clientcmdapi.Config, contextName string) (*rest.Con
 			fmt.Sprintf("Available users: %s", strings.Join(availableUsers, ", ")))
 	}
 
+	if err := checkAPIServerTransport(ctx.Cluster, cluster); err != nil {
+		return nil, err
+	}
+
 	logger.Debug("Building REST config",
 		"context", targetContext,
 		"cluster", ctx.Cluster,
@@ -287,9 +415,18 @@ func BuildRestConfig(config *clientcmdapi.Config, contextName string) (*rest.Con
 			"Verify the kubeconfig authentication and cluster settings are correct")
 	}
 
+	// Honor HTTPS_PROXY/NO_PROXY unless the kubeconfig pins an explicit proxy-url
+	if restConfig.Proxy == nil {
+		restConfig.Proxy = newProxyFunc()
+	}
+
+	// Retry requests the API server rejects with HTTP 429 before failing the tool
+	withRateLimitRetry(restConfig)
+
 	logger.Info("Kubeconfig configured for remote cluster",
 		"context", targetContext,
 		"host", restConfig.Host,
+		"authMethod", DetectAuthMethod(config.AuthInfos[ctx.AuthInfo]),
 	)
 
 	return restConfig, nil
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultKubeconfigSecretKey is the Secret data key read when kubeconfig_secret names no key.
+const DefaultKubeconfigSecretKey = "kubeconfig"
+
+var (
+	kubeconfigSecretNamespacesMu sync.RWMutex
+	kubeconfigSecretNamespaces   []string
+)
+
+// SetKubeconfigSecretNamespaces sets the namespaces, or glob patterns, from which
+// kubeconfig_secret may read Secrets. kubeconfig_secret is disabled when none are set.
+func SetKubeconfigSecretNamespaces(patterns []string) {
+	kubeconfigSecretNamespacesMu.Lock()
+	defer kubeconfigSecretNamespacesMu.Unlock()
+	kubeconfigSecretNamespaces = patterns
+}
+
+// getKubeconfigSecretNamespaces returns the patterns set by SetKubeconfigSecretNamespaces.
+func getKubeconfigSecretNamespaces() []string {
+	kubeconfigSecretNamespacesMu.RLock()
+	defer kubeconfigSecretNamespacesMu.RUnlock()
+	return kubeconfigSecretNamespaces
+}
+
+// kubeconfigSecretRef identifies the Secret data key holding a kubeconfig.
+type kubeconfigSecretRef struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+func (r kubeconfigSecretRef) String() string {
+	return r.Namespace + "/" + r.Name + "/" + r.Key
+}
+
+// parseKubeconfigSecretRef parses a kubeconfig_secret of the form namespace/name[/key].
+func parseKubeconfigSecretRef(ref string) (kubeconfigSecretRef, error) {
+	const formatHint = "Use the form namespace/name or namespace/name/key"
+
+	parts := strings.Split(strings.TrimSpace(ref), "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return kubeconfigSecretRef{}, NewValidationError("kubeconfig_secret",
+			fmt.Sprintf("invalid Secret reference %q", ref), formatHint)
+	}
+
+	secretRef := kubeconfigSecretRef{Namespace: parts[0], Name: parts[1], Key: DefaultKubeconfigSecretKey}
+	if len(parts) == 3 {
+		secretRef.Key = parts[2]
+	}
+
+	if errs := validation.IsDNS1123Label(secretRef.Namespace); len(errs) > 0 {
+		return kubeconfigSecretRef{}, NewValidationError("kubeconfig_secret",
+			fmt.Sprintf("invalid namespace %q: %s", secretRef.Namespace, strings.Join(errs, "; ")), formatHint)
+	}
+	if errs := validation.IsDNS1123Subdomain(secretRef.Name); len(errs) > 0 {
+		return kubeconfigSecretRef{}, NewValidationError("kubeconfig_secret",
+			fmt.Sprintf("invalid Secret name %q: %s", secretRef.Name, strings.Join(errs, "; ")), formatHint)
+	}
+	if errs := validation.IsConfigMapKey(secretRef.Key); len(errs) > 0 {
+		return kubeconfigSecretRef{}, NewValidationError("kubeconfig_secret",
+			fmt.Sprintf("invalid Secret key %q: %s", secretRef.Key, strings.Join(errs, "; ")), formatHint)
+	}
+	return secretRef, nil
+}
+
+// kubeconfigSecretNamespaceAllowed reports whether namespace matches one of patterns.
+func kubeconfigSecretNamespaceAllowed(namespace string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveKubeconfigInput returns the kubeconfig a tool call should use: kubeconfig
+// itself, or the kubeconfig read from the Secret named by secretRef using the MCP
+// server's own in-cluster identity.
+func resolveKubeconfigInput(ctx context.Context, kubeconfig, secretRef string) (string, error) {
+	if secretRef == "" {
+		return kubeconfig, nil
+	}
+	if kubeconfig != "" {
+		return "", NewValidationError("kubeconfig_secret",
+			"'kubeconfig' and 'kubeconfig_secret' cannot both be provided",
+			"Provide the kubeconfig content or a Secret reference, not both")
+	}
+
+	ref, err := checkKubeconfigSecretRef(secretRef)
+	if err != nil {
+		return "", err
+	}
+
+	config, err := resolveInClusterConfig(ctx, "kubeconfig-secret",
+		"The MCP server reads kubeconfig_secret from the cluster it runs in.")
+	if err != nil {
+		return "", err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", NewCompareError("kubeconfig-secret",
+			fmt.Errorf("failed to create client: %w", err),
+			"Unable to connect to the MCP server cluster to read kubeconfig_secret")
+	}
+	return readKubeconfigSecret(ctx, client, ref)
+}
+
+// checkKubeconfigSecretRef parses secretRef and checks that kubeconfig_secret is
+// enabled for its namespace.
+func checkKubeconfigSecretRef(secretRef string) (kubeconfigSecretRef, error) {
+	allowed := getKubeconfigSecretNamespaces()
+	if len(allowed) == 0 {
+		return kubeconfigSecretRef{}, NewValidationError("kubeconfig_secret",
+			"kubeconfig_secret is not enabled on this server",
+			"Start the server with --kubeconfig-secret-namespaces, or pass the kubeconfig content")
+	}
+
+	ref, err := parseKubeconfigSecretRef(secretRef)
+	if err != nil {
+		return kubeconfigSecretRef{}, err
+	}
+	if !kubeconfigSecretNamespaceAllowed(ref.Namespace, allowed) {
+		return kubeconfigSecretRef{}, NewSecurityError("kubeconfig-secret-namespace",
+			fmt.Sprintf("reading kubeconfig Secrets from namespace %q is not allowed", ref.Namespace),
+			"Allowed namespaces: "+strings.Join(allowed, ", "))
+	}
+	return ref, nil
+}
+
+// readKubeconfigSecret reads the kubeconfig stored under ref and applies the same
+// size and security checks as a kubeconfig passed inline. It returns the kubeconfig
+// as raw YAML.
+func readKubeconfigSecret(ctx context.Context, client kubernetes.Interface, ref kubeconfigSecretRef) (string, error) {
+	secret, err := client.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		switch {
+		case apierrors.IsNotFound(err):
+			return "", NewCompareError("kubeconfig-secret",
+				fmt.Errorf("secret %s/%s not found", ref.Namespace, ref.Name),
+				"Verify the Secret name and namespace")
+		case apierrors.IsForbidden(err):
+			return "", NewCompareError("kubeconfig-secret",
+				fmt.Errorf("not allowed to read secret %s/%s", ref.Namespace, ref.Name),
+				"Grant the MCP server's service account 'get' on secrets in namespace "+ref.Namespace)
+		default:
+			return "", NewCompareError("kubeconfig-secret",
+				fmt.Errorf("failed to read secret %s/%s: %w", ref.Namespace, ref.Name, err), "")
+		}
+	}
+
+	data, ok := secret.Data[ref.Key]
+	if !ok || len(data) == 0 {
+		return "", NewValidationError("kubeconfig_secret",
+			fmt.Sprintf("secret %s/%s has no data under key %q", ref.Namespace, ref.Name, ref.Key),
+			"Keys in the Secret: "+strings.Join(slices.Sorted(maps.Keys(secret.Data)), ", "))
+	}
+
+	kubeconfigData, err := DecodeOrParseKubeconfig(string(data))
+	if err != nil {
+		return "", err
+	}
+	config, err := ParseKubeconfig(kubeconfigData)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateKubeconfigSecurity(config); err != nil {
+		return "", err
+	}
+	return string(kubeconfigData), nil
+}
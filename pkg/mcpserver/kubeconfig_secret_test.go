@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const secretTestKubeconfig = `apiVersion: v1
+kind: Config
+current-context: spoke
+clusters:
+- name: spoke
+  cluster:
+    server: https://api.spoke.example.com:6443
+contexts:
+- name: spoke
+  context:
+    cluster: spoke
+    user: spoke
+users:
+- name: spoke
+  user:
+    token: spoke-token
+`
+
+const secretTestExecKubeconfig = `apiVersion: v1
+kind: Config
+current-context: spoke
+clusters:
+- name: spoke
+  cluster:
+    server: https://api.spoke.example.com:6443
+contexts:
+- name: spoke
+  context:
+    cluster: spoke
+    user: spoke
+users:
+- name: spoke
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: /bin/sh
+`
+
+var _ = Describe("Kubeconfig Secrets", func() {
+	newSecret := func(namespace, name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       data,
+		}
+	}
+
+	BeforeEach(func() {
+		SetKubeconfigSecretNamespaces([]string{"spoke-*"})
+		DeferCleanup(SetKubeconfigSecretNamespaces, []string(nil))
+	})
+
+	DescribeTable("parseKubeconfigSecretRef",
+		func(ref string, expected kubeconfigSecretRef, valid bool) {
+			parsed, err := parseKubeconfigSecretRef(ref)
+			if !valid {
+				Expect(err).To(MatchError(ContainSubstring("kubeconfig_secret")))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(expected))
+		},
+		Entry("namespace and name", "spoke-1/admin-kubeconfig",
+			kubeconfigSecretRef{Namespace: "spoke-1", Name: "admin-kubeconfig", Key: DefaultKubeconfigSecretKey}, true),
+		Entry("with a key", "spoke-1/admin-kubeconfig/value",
+			kubeconfigSecretRef{Namespace: "spoke-1", Name: "admin-kubeconfig", Key: "value"}, true),
+		Entry("name only", "admin-kubeconfig", kubeconfigSecretRef{}, false),
+		Entry("too many parts", "spoke-1/admin/kubeconfig/extra", kubeconfigSecretRef{}, false),
+		Entry("invalid namespace", "Spoke_1/admin-kubeconfig", kubeconfigSecretRef{}, false),
+		Entry("empty name", "spoke-1/", kubeconfigSecretRef{}, false),
+	)
+
+	Describe("checkKubeconfigSecretRef", func() {
+		It("is disabled when no namespaces are allowed", func() {
+			SetKubeconfigSecretNamespaces(nil)
+			_, err := checkKubeconfigSecretRef("spoke-1/admin-kubeconfig")
+			Expect(err).To(MatchError(ContainSubstring("not enabled")))
+		})
+
+		It("rejects namespaces outside the allowed list", func() {
+			_, err := checkKubeconfigSecretRef("kube-system/admin-kubeconfig")
+			var secErr *SecurityError
+			Expect(errors.As(err, &secErr)).To(BeTrue())
+			Expect(secErr.Code).To(Equal("kubeconfig-secret-namespace"))
+		})
+
+		It("accepts namespaces matching an allowed pattern", func() {
+			ref, err := checkKubeconfigSecretRef("spoke-1/admin-kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref.Namespace).To(Equal("spoke-1"))
+		})
+	})
+
+	Describe("readKubeconfigSecret", func() {
+		ref := kubeconfigSecretRef{Namespace: "spoke-1", Name: "admin-kubeconfig", Key: DefaultKubeconfigSecretKey}
+
+		It("returns the kubeconfig stored in the Secret", func() {
+			client := fake.NewClientset(newSecret("spoke-1", "admin-kubeconfig",
+				map[string][]byte{"kubeconfig": []byte(secretTestKubeconfig)}))
+
+			kubeconfig, err := readKubeconfigSecret(context.Background(), client, ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kubeconfig).To(Equal(strings.TrimSpace(secretTestKubeconfig)))
+		})
+
+		It("accepts a base64-encoded kubeconfig", func() {
+			encoded := base64.StdEncoding.EncodeToString([]byte(secretTestKubeconfig))
+			client := fake.NewClientset(newSecret("spoke-1", "admin-kubeconfig",
+				map[string][]byte{"kubeconfig": []byte(encoded)}))
+
+			kubeconfig, err := readKubeconfigSecret(context.Background(), client, ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kubeconfig).To(Equal(secretTestKubeconfig))
+		})
+
+		It("applies the kubeconfig security checks", func() {
+			client := fake.NewClientset(newSecret("spoke-1", "admin-kubeconfig",
+				map[string][]byte{"kubeconfig": []byte(secretTestExecKubeconfig)}))
+
+			_, err := readKubeconfigSecret(context.Background(), client, ref)
+			var secErr *SecurityError
+			Expect(errors.As(err, &secErr)).To(BeTrue())
+			Expect(secErr.Code).To(Equal("exec-auth-blocked"))
+		})
+
+		It("reports a missing key with the keys present", func() {
+			client := fake.NewClientset(newSecret("spoke-1", "admin-kubeconfig",
+				map[string][]byte{"value": []byte(secretTestKubeconfig)}))
+
+			_, err := readKubeconfigSecret(context.Background(), client, ref)
+			Expect(err).To(MatchError(ContainSubstring(`no data under key "kubeconfig"`)))
+			Expect(err).To(MatchError(ContainSubstring("Keys in the Secret: value")))
+		})
+
+		It("reports a missing Secret", func() {
+			_, err := readKubeconfigSecret(context.Background(), fake.NewClientset(), ref)
+			Expect(err).To(MatchError(ContainSubstring("secret spoke-1/admin-kubeconfig not found")))
+		})
+	})
+
+	Describe("resolveKubeconfigInput", func() {
+		It("returns an inline kubeconfig unchanged", func() {
+			kubeconfig, err := resolveKubeconfigInput(context.Background(), secretTestKubeconfig, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kubeconfig).To(Equal(secretTestKubeconfig))
+		})
+
+		It("rejects both a kubeconfig and a Secret", func() {
+			_, err := resolveKubeconfigInput(context.Background(), secretTestKubeconfig, "spoke-1/admin-kubeconfig")
+			Expect(err).To(MatchError(ContainSubstring("cannot both be provided")))
+		})
+	})
+
+	Describe("kubeconfig_secret input", func() {
+		It("is checked before the tool runs", func() {
+			SetKubeconfigSecretNamespaces(nil)
+			result, _, err := HandleClusterAccess(context.Background(), &mcp.CallToolRequest{},
+				ClusterAccessInput{KubeconfigSecret: "spoke-1/admin-kubeconfig"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("kubeconfig_secret is not enabled"))
+		})
+
+		It("is accepted by the tools that target spoke clusters", func() {
+			Expect(ClusterDiffInputSchema().Properties).To(HaveKey("kubeconfig_secret"))
+			Expect(ResolveRDSInputSchema().Properties).To(HaveKey("kubeconfig_secret"))
+			Expect(ValidateRDSInputSchema().Properties).To(HaveKey("kubeconfig_secret"))
+			Expect(ClusterAccessInputSchema().Properties).To(HaveKey("kubeconfig_secret"))
+		})
+	})
+})
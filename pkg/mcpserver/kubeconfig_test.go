@@ -3,7 +3,10 @@
 package mcpserver_test
 
 import (
+	"bytes"
 	"encoding/base64"
+	"errors"
+	"log/slog"
 	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -194,6 +197,93 @@ users:
 		)
 	})
 
+	DescribeTable("KubeconfigContextNamespace",
+		func(kubeconfig, contextName, expected string) {
+			Expect(mcpserver.KubeconfigContextNamespace(kubeconfig, contextName)).To(Equal(expected))
+		},
+		Entry("reads the current context", NamespacedKubeconfig, "", "hub-hosts"),
+		Entry("reads the named context", NamespacedKubeconfig, "other", "other-hosts"),
+		Entry("reads base64-encoded kubeconfigs", EncodeKubeconfig(NamespacedKubeconfig), "", "hub-hosts"),
+		Entry("is empty for a context without a namespace", NamespacedKubeconfig, "bare", ""),
+		Entry("is empty for an unknown context", NamespacedKubeconfig, "missing", ""),
+		Entry("is empty without a kubeconfig", "", "", ""),
+		Entry("is empty for an invalid kubeconfig", "not a kubeconfig", "", ""),
+	)
+
+	Describe("DetectAuthMethod", func() {
+		DescribeTable("detecting the auth method of the current context's user",
+			func(kubeconfig string, want mcpserver.AuthMethod) {
+				config, err := mcpserver.ParseKubeconfig([]byte(kubeconfig))
+				Expect(err).NotTo(HaveOccurred())
+				user := config.Contexts[config.CurrentContext].AuthInfo
+				Expect(mcpserver.DetectAuthMethod(config.AuthInfos[user])).To(Equal(want))
+			},
+			Entry("token", ValidKubeconfig, mcpserver.AuthMethodToken),
+			Entry("client certificate", CertAuthKubeconfig, mcpserver.AuthMethodClientCert),
+			Entry("exec", ExecAuthKubeconfig, mcpserver.AuthMethodExec),
+			Entry("auth provider", AuthProviderKubeconfig, mcpserver.AuthMethodAuthProvider),
+		)
+
+		It("reports a missing user as none", func() {
+			Expect(mcpserver.DetectAuthMethod(nil)).To(Equal(mcpserver.AuthMethodNone))
+		})
+
+		It("logs the auth method without the credential when building the REST config", func() {
+			var logs bytes.Buffer
+			original := slog.Default()
+			slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+			DeferCleanup(func() { slog.SetDefault(original) })
+
+			_, err := mcpserver.BuildSecureRestConfigFromBytes([]byte(CertAuthKubeconfig), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs.String()).To(ContainSubstring(`"authMethod":"client-cert"`))
+			Expect(logs.String()).NotTo(ContainSubstring("dGVzdC1jbGllbnQtY2VydA=="))
+		})
+	})
+
+	Describe("API server transport", func() {
+		var logs *bytes.Buffer
+
+		BeforeEach(func() {
+			logs = &bytes.Buffer{}
+			original := slog.Default()
+			slog.SetDefault(slog.New(slog.NewJSONHandler(logs, nil)))
+			DeferCleanup(func() { slog.SetDefault(original) })
+		})
+
+		It("warns about an http server by default", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_PLAIN_HTTP_SERVER", "")
+			restConfig, err := mcpserver.BuildSecureRestConfigFromBytes([]byte(PlainHTTPKubeconfig), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restConfig.Host).To(Equal("http://192.168.1.100:6443"))
+			Expect(logs.String()).To(ContainSubstring("Kubeconfig cluster server does not use https"))
+		})
+
+		It("rejects an http server when configured to", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_PLAIN_HTTP_SERVER", mcpserver.PlainHTTPServerError)
+			_, err := mcpserver.BuildSecureRestConfigFromBytes([]byte(PlainHTTPKubeconfig), "")
+			var secErr *mcpserver.SecurityError
+			Expect(errors.As(err, &secErr)).To(BeTrue())
+			Expect(secErr.Error()).To(ContainSubstring("does not use https"))
+		})
+
+		It("logs a security note for an https server that skips TLS verification", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_PLAIN_HTTP_SERVER", mcpserver.PlainHTTPServerError)
+			restConfig, err := mcpserver.BuildSecureRestConfigFromBytes([]byte(InsecureSkipVerifyKubeconfig), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restConfig.Insecure).To(BeTrue())
+			Expect(logs.String()).To(ContainSubstring(`"event":"security_note"`))
+			Expect(logs.String()).NotTo(ContainSubstring("does not use https"))
+		})
+
+		It("accepts an https server silently", func() {
+			_, err := mcpserver.BuildSecureRestConfigFromBytes([]byte(ValidKubeconfig), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs.String()).NotTo(ContainSubstring("security_note"))
+			Expect(logs.String()).NotTo(ContainSubstring("does not use https"))
+		})
+	})
+
 	Describe("BuildSecureRestConfig", func() {
 		DescribeTable("end-to-end secure config building",
 			func(kubeconfig string, contextName string, wantErr bool, errContains string) {
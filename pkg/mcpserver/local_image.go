@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+const (
+	// ociLayoutPrefix identifies a reference stored as an OCI image layout directory on disk.
+	ociLayoutPrefix = "oci-layout://"
+	// ociArchivePrefix identifies a reference stored as an image tarball (docker save format) on disk.
+	ociArchivePrefix = "oci-archive://"
+)
+
+// getAllowLocalImages reports whether oci-layout:// and oci-archive:// references are allowed.
+// Can be enabled via KUBE_COMPARE_MCP_ALLOW_LOCAL_IMAGES environment variable (boolean).
+// Disabled by default since these references read from the server's filesystem.
+func getAllowLocalImages() bool {
+	if val := os.Getenv("KUBE_COMPARE_MCP_ALLOW_LOCAL_IMAGES"); val != "" {
+		if allow, err := strconv.ParseBool(val); err == nil {
+			return allow
+		}
+	}
+	return false
+}
+
+// getLocalImageRoot returns the directory oci-layout:// and oci-archive:// references
+// must lie within. Configured via KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT environment variable;
+// local image references are rejected while it is unset.
+func getLocalImageRoot() string {
+	return os.Getenv("KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT")
+}
+
+// ParseLocalImageReference parses a local image reference.
+// Format: oci-layout:///path/to/layout:/path/to/metadata.yaml
+// or oci-archive:///path/to/image.tar:/path/to/metadata.yaml
+func ParseLocalImageReference(ref string) (prefix, imagePath, filePath string, err error) {
+	const formatHint = "Use format: oci-layout:///path/to/layout:/path/to/metadata.yaml " +
+		"or oci-archive:///path/to/image.tar:/path/to/metadata.yaml"
+
+	switch {
+	case strings.HasPrefix(ref, ociLayoutPrefix):
+		prefix = ociLayoutPrefix
+	case strings.HasPrefix(ref, ociArchivePrefix):
+		prefix = ociArchivePrefix
+	default:
+		return "", "", "", NewValidationError("reference",
+			"invalid local image reference format", formatHint)
+	}
+
+	remainder := strings.TrimPrefix(ref, prefix)
+	pathSepIdx := strings.Index(remainder, ":/")
+	if pathSepIdx == -1 {
+		return "", "", "", NewValidationError("reference",
+			"local image reference must include a file path", formatHint)
+	}
+
+	imagePath = remainder[:pathSepIdx]
+	filePath = remainder[pathSepIdx+1:]
+
+	if imagePath == "" || !filepath.IsAbs(imagePath) {
+		return "", "", "", NewValidationError("reference",
+			fmt.Sprintf("local image path '%s' must be an absolute path", imagePath), formatHint)
+	}
+	if filePath == "/" {
+		return "", "", "", NewValidationError("reference",
+			"file path within the image cannot be empty", formatHint)
+	}
+	if err := validateReferenceFilePath(filePath); err != nil {
+		return "", "", "", err
+	}
+
+	return prefix, filepath.Clean(imagePath), filePath, nil
+}
+
+// validateLocalImageReference validates that local image references are enabled
+// and that the referenced layout directory or archive exists within the local image root.
+func validateLocalImageReference(ref string) error {
+	_, _, _, err := resolveLocalImageReference(ref)
+	return err
+}
+
+// resolveLocalImageReference parses a local image reference and checks that local image
+// references are enabled and that the layout directory or archive exists within the
+// local image root. The returned image path has its symbolic links resolved.
+func resolveLocalImageReference(ref string) (prefix, imagePath, filePath string, err error) {
+	if !getAllowLocalImages() {
+		return "", "", "", NewCompareError("validate",
+			ErrLocalPathNotSupported,
+			fmt.Sprintf("Local image references are disabled. The reference '%s' reads from the server's filesystem.\n\n"+
+				"Set KUBE_COMPARE_MCP_ALLOW_LOCAL_IMAGES=true and KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT on the server to enable oci-layout:// and oci-archive:// references.",
+				ref))
+	}
+	root := getLocalImageRoot()
+	if root == "" {
+		return "", "", "", NewCompareError("validate",
+			ErrLocalPathNotSupported,
+			"Local image references need a root directory. "+
+				"Set KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT on the server to the directory holding the image layouts and archives.")
+	}
+
+	prefix, imagePath, filePath, err = ParseLocalImageReference(ref)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	imagePath, err = resolveLocalImagePath(imagePath, root)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return "", "", "", NewCompareError("validate",
+			fmt.Errorf("%w: %s", ErrReferenceNotFound, imagePath),
+			fmt.Sprintf("The local image path '%s' could not be read: %v", imagePath, err))
+	}
+
+	if prefix == ociLayoutPrefix && !info.IsDir() {
+		return "", "", "", NewValidationError("reference",
+			fmt.Sprintf("'%s' is not an OCI layout directory", imagePath),
+			"Use oci-archive:// for image tarballs")
+	}
+	if prefix == ociArchivePrefix && info.IsDir() {
+		return "", "", "", NewValidationError("reference",
+			fmt.Sprintf("'%s' is a directory, not an image archive", imagePath),
+			"Use oci-layout:// for OCI layout directories")
+	}
+
+	return prefix, imagePath, filePath, nil
+}
+
+// resolveLocalImagePath resolves the symbolic links of imagePath and checks that the
+// result lies within root, itself resolved the same way.
+func resolveLocalImagePath(imagePath, root string) (string, error) {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", NewCompareError("validate",
+			fmt.Errorf("failed to resolve local image root '%s': %w", root, err),
+			"Set KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT on the server to an existing directory")
+	}
+	resolved, err := filepath.EvalSymlinks(imagePath)
+	if err != nil {
+		return "", NewCompareError("validate",
+			fmt.Errorf("%w: %s", ErrReferenceNotFound, imagePath),
+			fmt.Sprintf("The local image path '%s' could not be read: %v", imagePath, err))
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", NewValidationError("reference",
+			fmt.Sprintf("local image path '%s' is outside the local image root", imagePath),
+			fmt.Sprintf("Use a layout directory or archive under %s", root))
+	}
+	return resolved, nil
+}
+
+// loadLocalImage loads an image from an OCI layout directory or an image tarball. When
+// the layout holds a multi-platform image index, the image for platform is selected
+// from it, or for DefaultPlatform when platform is empty.
+func loadLocalImage(prefix, imagePath, platform string) (v1.Image, error) {
+	if prefix == ociArchivePrefix {
+		img, err := tarball.ImageFromPath(imagePath, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load image archive '%s': %w", imagePath, err)
+		}
+		return img, nil
+	}
+
+	index, err := layout.ImageIndexFromPath(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OCI layout '%s': %w", imagePath, err)
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCI layout index '%s': %w", imagePath, err)
+	}
+
+	for _, desc := range manifest.Manifests {
+		switch {
+		case desc.MediaType.IsImage():
+			img, err := index.Image(desc.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load image %s from OCI layout '%s': %w", desc.Digest, imagePath, err)
+			}
+			return img, nil
+		case desc.MediaType.IsIndex():
+			wantPlatform, err := parsePlatform(platform)
+			if err != nil {
+				return nil, err
+			}
+			child, err := index.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load image index %s from OCI layout '%s': %w", desc.Digest, imagePath, err)
+			}
+			return selectPlatformImage(child, imagePath, wantPlatform)
+		}
+	}
+
+	return nil, fmt.Errorf("no image manifest found in OCI layout '%s'", imagePath)
+}
+
+// extractLocalImageReference extracts files from a local OCI layout or image tarball
+// to a local directory and returns the path of the extracted metadata file. platform
+// selects the image of a multi-platform layout, DefaultPlatform when empty.
+func extractLocalImageReference(ctx context.Context, ref, platform, destDir string) (string, error) {
+	logger := slog.Default()
+
+	prefix, imagePath, targetPath, err := resolveLocalImageReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Debug("Loading local image", "path", imagePath, "targetPath", targetPath, "platform", platform)
+
+	img, err := loadLocalImage(prefix, imagePath, platform)
+	if err != nil {
+		return "", err
+	}
+
+	return extractImageFiles(ctx, img, imagePath, targetPath, destDir)
+}
@@ -0,0 +1,372 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Local image references", func() {
+	const metadataContent = "apiVersion: v2\nparts: []\n"
+
+	var (
+		tmpDir     string
+		layoutPath string
+		archPath   string
+	)
+
+	BeforeEach(func() {
+		tmpDir = GinkgoT().TempDir()
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT", tmpDir)
+		img := newTestReferenceImage(map[string]string{
+			"reference/metadata.yaml":       metadataContent,
+			"reference/templates/node.yaml": "kind: Node\n",
+		})
+
+		layoutPath = filepath.Join(tmpDir, "layout")
+		p, err := layout.Write(layoutPath, empty.Index)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.AppendImage(img)).To(Succeed())
+
+		archPath = filepath.Join(tmpDir, "image.tar")
+		tag, err := name.NewTag("example.com/reference:v1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tarball.WriteToFile(archPath, tag, img)).To(Succeed())
+	})
+
+	Describe("ClassifyReference", func() {
+		It("classifies oci-layout and oci-archive references", func() {
+			Expect(ClassifyReference("oci-layout:///data/layout:/metadata.yaml")).To(Equal(ReferenceTypeLocalImage))
+			Expect(ClassifyReference("oci-archive:///data/image.tar:/metadata.yaml")).To(Equal(ReferenceTypeLocalImage))
+		})
+	})
+
+	Describe("ParseLocalImageReference", func() {
+		DescribeTable("valid references",
+			func(ref, expectedPrefix, expectedImage, expectedFile string) {
+				prefix, imagePath, filePath, err := ParseLocalImageReference(ref)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(prefix).To(Equal(expectedPrefix))
+				Expect(imagePath).To(Equal(expectedImage))
+				Expect(filePath).To(Equal(expectedFile))
+			},
+			Entry("layout", "oci-layout:///data/layout:/reference/metadata.yaml",
+				ociLayoutPrefix, "/data/layout", "/reference/metadata.yaml"),
+			Entry("archive", "oci-archive:///data/image.tar:/metadata.yaml",
+				ociArchivePrefix, "/data/image.tar", "/metadata.yaml"),
+		)
+
+		DescribeTable("invalid references",
+			func(ref string) {
+				_, _, _, err := ParseLocalImageReference(ref)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("missing file path", "oci-layout:///data/layout"),
+			Entry("relative image path", "oci-layout://data/layout:/metadata.yaml"),
+			Entry("empty file path", "oci-archive:///data/image.tar:/"),
+			Entry("wrong scheme", "container://quay.io/org/image:v1:/metadata.yaml"),
+			Entry("glob file path", "oci-layout:///data/layout:/refs/*.yaml"),
+		)
+	})
+
+	Describe("validateLocalImageReference", func() {
+		It("rejects local images unless explicitly allowed", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_ALLOW_LOCAL_IMAGES", "")
+			err := validateLocalImageReference("oci-layout://" + layoutPath + ":/reference/metadata.yaml")
+			Expect(err).To(MatchError(ErrLocalPathNotSupported))
+		})
+
+		Context("when allowed", func() {
+			BeforeEach(func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_ALLOW_LOCAL_IMAGES", "true")
+			})
+
+			It("accepts an existing layout and archive", func() {
+				Expect(validateLocalImageReference("oci-layout://" + layoutPath + ":/reference/metadata.yaml")).To(Succeed())
+				Expect(validateLocalImageReference("oci-archive://" + archPath + ":/reference/metadata.yaml")).To(Succeed())
+			})
+
+			It("rejects a missing path", func() {
+				err := validateLocalImageReference("oci-layout://" + filepath.Join(tmpDir, "missing") + ":/metadata.yaml")
+				Expect(err).To(MatchError(ErrReferenceNotFound))
+			})
+
+			It("rejects a mismatched scheme", func() {
+				Expect(validateLocalImageReference("oci-layout://" + archPath + ":/metadata.yaml")).NotTo(Succeed())
+				Expect(validateLocalImageReference("oci-archive://" + layoutPath + ":/metadata.yaml")).NotTo(Succeed())
+			})
+
+			It("rejects local images without a local image root", func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT", "")
+				err := validateLocalImageReference("oci-layout://" + layoutPath + ":/reference/metadata.yaml")
+				Expect(err).To(MatchError(ErrLocalPathNotSupported))
+			})
+
+			It("rejects a path outside the local image root", func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT", filepath.Join(tmpDir, "root"))
+				Expect(os.Mkdir(filepath.Join(tmpDir, "root"), DirectoryPermissions)).To(Succeed())
+
+				err := validateLocalImageReference("oci-layout://" + layoutPath + ":/reference/metadata.yaml")
+				Expect(err).To(MatchError(ContainSubstring("outside the local image root")))
+			})
+
+			It("rejects a symbolic link in the root that points outside it", func() {
+				root := filepath.Join(tmpDir, "root")
+				Expect(os.Mkdir(root, DirectoryPermissions)).To(Succeed())
+				Expect(os.Symlink(layoutPath, filepath.Join(root, "layout"))).To(Succeed())
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT", root)
+
+				err := validateLocalImageReference("oci-layout://" + filepath.Join(root, "layout") + ":/reference/metadata.yaml")
+				Expect(err).To(MatchError(ContainSubstring("outside the local image root")))
+			})
+		})
+	})
+
+	Describe("extractLocalImageReference", func() {
+		BeforeEach(func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_ALLOW_LOCAL_IMAGES", "true")
+		})
+
+		DescribeTable("extracts the metadata directory",
+			func(scheme string, imagePath func() string) {
+				destDir := filepath.Join(tmpDir, "extracted")
+				Expect(os.MkdirAll(destDir, DirectoryPermissions)).To(Succeed())
+
+				extracted, err := extractLocalImageReference(context.Background(),
+					scheme+imagePath()+":/reference/metadata.yaml", "", destDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(extracted)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal(metadataContent))
+				Expect(filepath.Join(filepath.Dir(extracted), "templates", "node.yaml")).To(BeAnExistingFile())
+			},
+			Entry("from an OCI layout", ociLayoutPrefix, func() string { return layoutPath }),
+			Entry("from an image tarball", ociArchivePrefix, func() string { return archPath }),
+		)
+
+		It("fails when the metadata path is not in the image", func() {
+			_, err := extractLocalImageReference(context.Background(),
+				"oci-layout://"+layoutPath+":/missing/metadata.yaml", "", tmpDir)
+			Expect(err).To(HaveOccurred())
+		})
+
+		Context("with a multi-platform OCI layout", func() {
+			var multiPath string
+
+			BeforeEach(func() {
+				index := mutate.AppendManifests(empty.Index,
+					mutate.IndexAddendum{
+						Add:        newTestReferenceImage(map[string]string{"reference/metadata.yaml": "platform: amd64\n"}),
+						Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+					},
+					mutate.IndexAddendum{
+						Add:        newTestReferenceImage(map[string]string{"reference/metadata.yaml": "platform: arm64\n"}),
+						Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}},
+					},
+				)
+				multiPath = filepath.Join(tmpDir, "multi")
+				p, err := layout.Write(multiPath, empty.Index)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p.AppendIndex(index)).To(Succeed())
+			})
+
+			DescribeTable("extracts the image for the requested platform",
+				func(platform, expected string) {
+					extracted, err := extractLocalImageReference(context.Background(),
+						"oci-layout://"+multiPath+":/reference/metadata.yaml", platform, filepath.Join(tmpDir, "extracted"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(os.ReadFile(extracted)).To(Equal([]byte(expected)))
+				},
+				Entry("default platform", "", "platform: amd64\n"),
+				Entry("explicit platform", "linux/arm64", "platform: arm64\n"),
+			)
+
+			It("reports the platforms the layout offers", func() {
+				_, err := extractLocalImageReference(context.Background(),
+					"oci-layout://"+multiPath+":/reference/metadata.yaml", "linux/s390x", filepath.Join(tmpDir, "extracted"))
+				Expect(err).To(MatchError(ErrPlatformNotFound))
+				Expect(err).To(MatchError(ContainSubstring("linux/amd64, linux/arm64")))
+			})
+		})
+	})
+
+	Describe("extractImageFiles", func() {
+		var destDir string
+
+		BeforeEach(func() {
+			destDir = filepath.Join(tmpDir, "extracted")
+			Expect(os.MkdirAll(destDir, DirectoryPermissions)).To(Succeed())
+		})
+
+		It("rejects a target path that is a directory", func() {
+			img := newTestReferenceImage(map[string]string{
+				"reference/metadata.yaml":       metadataContent,
+				"reference/templates/node.yaml": "kind: Node\n",
+			})
+
+			_, err := extractImageFiles(context.Background(), img, "test", "/reference/templates", destDir)
+			Expect(err).To(MatchError(ContainSubstring("expected a file at /reference/templates but found a directory")))
+		})
+
+		It("rejects an empty target file", func() {
+			img := newTestReferenceImage(map[string]string{
+				"reference/metadata.yaml": "",
+			})
+
+			_, err := extractImageFiles(context.Background(), img, "test", "/reference/metadata.yaml", destDir)
+			Expect(err).To(MatchError(ContainSubstring("/reference/metadata.yaml in container image is empty")))
+		})
+
+		DescribeTable("rejects a target file with binary content",
+			func(content string) {
+				img := newTestReferenceImage(map[string]string{
+					"reference/metadata.yaml": content,
+				})
+
+				_, err := extractImageFiles(context.Background(), img, "test", "/reference/metadata.yaml", destDir)
+				Expect(err).To(MatchError(ContainSubstring("expected YAML text at /reference/metadata.yaml but found binary content")))
+			},
+			Entry("gzip data", "\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\xff\xfe"),
+			Entry("null bytes", "apiVersion: v2\x00\x00parts: []\n"),
+			Entry("invalid UTF-8", "apiVersion: v2\nparts: \xc3\x28\n"),
+		)
+
+		It("accepts multi-byte UTF-8 text", func() {
+			img := newTestReferenceImage(map[string]string{
+				"reference/metadata.yaml": "# Référence — 参照\n" + metadataContent,
+			})
+
+			_, err := extractImageFiles(context.Background(), img, "test", "/reference/metadata.yaml", destDir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects a missing target file", func() {
+			img := newTestReferenceImage(map[string]string{
+				"reference/metadata.yaml": metadataContent,
+			})
+
+			_, err := extractImageFiles(context.Background(), img, "test", "/reference/missing.yaml", destDir)
+			Expect(err).To(MatchError(ContainSubstring("target file not found")))
+		})
+
+		It("extracts a gzip-compressed layer declared as uncompressed tar", func() {
+			var compressed bytes.Buffer
+			gz := gzip.NewWriter(&compressed)
+			_, err := gz.Write(newTestReferenceTar(map[string]string{
+				"reference/metadata.yaml": metadataContent,
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gz.Close()).To(Succeed())
+
+			layer := static.NewLayer(compressed.Bytes(), types.OCIUncompressedLayer)
+			img, err := mutate.AppendLayers(empty.Image, layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			extracted, err := extractImageFiles(context.Background(), img, "test", "/reference/metadata.yaml", destDir)
+			Expect(err).NotTo(HaveOccurred())
+			content, err := os.ReadFile(extracted)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(metadataContent))
+		})
+
+		Context("when an RDS metadata path is missing from the image", func() {
+			const expectedPath = "/usr/share/telco-core-rds/configuration/reference-crs-kube-compare/metadata.yaml"
+			const siblingPath = "/usr/share/telco-core-rds/configuration/kube-compare-reference/metadata.yaml"
+
+			var err error
+
+			BeforeEach(func() {
+				img := newTestReferenceImage(map[string]string{
+					strings.TrimPrefix(siblingPath, "/"): metadataContent,
+					"usr/share/other/metadata.yaml":      metadataContent,
+				})
+				_, err = extractImageFiles(context.Background(), img, "test", expectedPath, destDir)
+			})
+
+			It("reports metadata.yaml files found near the expected directory", func() {
+				var notFound *TargetNotFoundError
+				Expect(errors.As(err, &notFound)).To(BeTrue())
+				Expect(notFound.TargetPath).To(Equal(expectedPath))
+				Expect(notFound.Candidates).To(ConsistOf(siblingPath))
+			})
+
+			It("suggests the discovered path for the RDS type", func() {
+				var notFound *TargetNotFoundError
+				Expect(errors.As(err, &notFound)).To(BeTrue())
+
+				rdsErr := newRDSPathNotFoundError(&ResolveRDSResult{
+					RDSType:  RDSTypeCore,
+					ImageRef: "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9:v4.18",
+				}, notFound)
+
+				message := FormatErrorForUser(rdsErr)
+				Expect(message).To(ContainSubstring("core RDS metadata not found at " + expectedPath))
+				Expect(message).To(ContainSubstring("  - " + siblingPath))
+				Expect(message).To(ContainSubstring("container://registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9:v4.18:" + siblingPath))
+			})
+		})
+	})
+})
+
+var _ = DescribeTable("isBinaryContent",
+	func(data string, truncated, expected bool) {
+		Expect(isBinaryContent([]byte(data), truncated)).To(Equal(expected))
+	},
+	Entry("YAML text", "apiVersion: v2\n", false, false),
+	Entry("a null byte", "a\x00b", false, true),
+	Entry("invalid UTF-8", "a\xffb", false, true),
+	Entry("a complete multi-byte character", "caf\xc3\xa9", false, false),
+	Entry("a multi-byte character cut off by the sample", "caf\xc3", true, false),
+	Entry("a multi-byte character cut off at the end of the content", "caf\xc3", false, true),
+)
+
+// newTestReferenceImage builds a single-layer image containing the given files.
+func newTestReferenceImage(files map[string]string) v1.Image {
+	layerBytes := newTestReferenceTar(files)
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(layerBytes)), nil
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	Expect(err).NotTo(HaveOccurred())
+	return img
+}
+
+// newTestReferenceTar builds an uncompressed tar archive containing the given files.
+func newTestReferenceTar(files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for path, content := range files {
+		Expect(tw.WriteHeader(&tar.Header{
+			Name:     path,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return buf.Bytes()
+}
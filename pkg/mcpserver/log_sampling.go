@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// HighFrequencyLogMessages are debug messages logged once per tar entry or per
+// candidate ConfigMap. They are the ones sampled by NewSamplingHandler.
+var HighFrequencyLogMessages = []string{
+	"Extracted file",
+	"Failed to create symlink",
+	"Scoring ConfigMap",
+}
+
+// samplingState is shared between a SamplingHandler and the handlers derived
+// from it through WithAttrs and WithGroup, so counts are per message globally.
+type samplingState struct {
+	rate     uint64
+	messages map[string]bool
+	counters sync.Map // message -> *atomic.Uint64
+}
+
+// SamplingHandler is a slog.Handler that passes only 1 in N debug records for
+// high-frequency messages. Other debug records and all info, warn, and error
+// records are always passed through.
+type SamplingHandler struct {
+	next  slog.Handler
+	state *samplingState
+}
+
+// NewSamplingHandler wraps next so that debug records whose message is one of
+// messages are passed through once every rate occurrences. The first occurrence
+// of each message is always logged. A rate of 1 or less disables sampling.
+func NewSamplingHandler(next slog.Handler, rate int, messages []string) *SamplingHandler {
+	state := &samplingState{
+		rate:     1,
+		messages: make(map[string]bool, len(messages)),
+	}
+	if rate > 1 {
+		state.rate = uint64(rate)
+	}
+	for _, m := range messages {
+		state.messages[m] = true
+	}
+	return &SamplingHandler{next: next, state: state}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle drops sampled-out debug records and passes everything else to the wrapped handler.
+func (h *SamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.sampled(record) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a SamplingHandler sharing this handler's counters.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a SamplingHandler sharing this handler's counters.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+// sampled reports whether record should be logged.
+func (h *SamplingHandler) sampled(record slog.Record) bool {
+	if h.state.rate <= 1 || record.Level > slog.LevelDebug || !h.state.messages[record.Message] {
+		return true
+	}
+
+	counter, _ := h.state.counters.LoadOrStore(record.Message, new(atomic.Uint64))
+	n := counter.(*atomic.Uint64).Add(1)
+	return (n-1)%h.state.rate == 0
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
+)
+
+// countingHandler records the messages of the records it handles.
+type countingHandler struct {
+	mu       *sync.Mutex
+	messages *[]string
+}
+
+func newCountingHandler() *countingHandler {
+	return &countingHandler{mu: &sync.Mutex{}, messages: &[]string{}}
+}
+
+func (h *countingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *countingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.messages = append(*h.messages, r.Message)
+	return nil
+}
+
+func (h *countingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *countingHandler) WithGroup(string) slog.Handler      { return h }
+
+func (h *countingHandler) count(message string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, m := range *h.messages {
+		if m == message {
+			n++
+		}
+	}
+	return n
+}
+
+var _ = Describe("SamplingHandler", func() {
+	var (
+		recorder *countingHandler
+		logger   *slog.Logger
+	)
+
+	BeforeEach(func() {
+		recorder = newCountingHandler()
+		logger = slog.New(mcpserver.NewSamplingHandler(recorder, 10, mcpserver.HighFrequencyLogMessages))
+	})
+
+	It("passes roughly a tenth of high-frequency debug records at 1:10", func() {
+		for range 1000 {
+			logger.Debug("Extracted file", "path", "metadata.yaml")
+		}
+		Expect(recorder.count("Extracted file")).To(BeNumerically("~", 100, 5))
+	})
+
+	It("samples each message independently and logs the first occurrence", func() {
+		logger.Debug("Scoring ConfigMap")
+		logger.Debug("Extracted file")
+		Expect(recorder.count("Scoring ConfigMap")).To(Equal(1))
+		Expect(recorder.count("Extracted file")).To(Equal(1))
+	})
+
+	It("never samples info, warn, or error records", func() {
+		for range 100 {
+			logger.Info("Extracted file")
+			logger.Warn("Extracted file")
+			logger.Error("Extracted file")
+		}
+		Expect(recorder.count("Extracted file")).To(Equal(300))
+	})
+
+	It("does not sample other debug messages", func() {
+		for range 100 {
+			logger.Debug("Received tool request")
+		}
+		Expect(recorder.count("Received tool request")).To(Equal(100))
+	})
+
+	It("shares counters with loggers derived through With", func() {
+		for i := range 100 {
+			logger.With("requestID", i).Debug("Scoring ConfigMap")
+		}
+		Expect(recorder.count("Scoring ConfigMap")).To(Equal(10))
+	})
+
+	It("passes everything when the rate is 1", func() {
+		logger = slog.New(mcpserver.NewSamplingHandler(recorder, 1, mcpserver.HighFrequencyLogMessages))
+		for range 50 {
+			logger.Debug("Extracted file")
+		}
+		Expect(recorder.count("Extracted file")).To(Equal(50))
+	})
+})
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// metal3DefaultGVRs are the metal3 resources read by the BIOS tools, at the versions
+// used unless configured or discovered otherwise.
+var metal3DefaultGVRs = []schema.GroupVersionResource{
+	bareMetalHostGVR,
+	hardwareDataGVR,
+	hostFirmwareComponentsGVR,
+	hostFirmwareSettingsGVR,
+}
+
+// metal3GVRs maps the default group and resource of each metal3 resource to the
+// GroupVersionResource it is read from.
+type metal3GVRs map[schema.GroupResource]schema.GroupVersionResource
+
+// getMetal3GVRs returns the GroupVersionResources the BIOS tools read metal3 resources
+// from. Each can be overridden via the KUBE_COMPARE_MCP_METAL3_GVRS environment
+// variable as comma-separated resource.version.group entries, such as
+// hostfirmwaresettings.v1beta1.metal3.io, so a newer metal3 API can be used before it
+// is the default. An entry for a resource the tools do not read is an error.
+func getMetal3GVRs() (metal3GVRs, error) {
+	gvrs := make(metal3GVRs, len(metal3DefaultGVRs))
+	for _, gvr := range metal3DefaultGVRs {
+		gvrs[gvr.GroupResource()] = gvr
+	}
+
+	val := strings.TrimSpace(os.Getenv("KUBE_COMPARE_MCP_METAL3_GVRS"))
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		gvr, _ := schema.ParseResourceArg(entry)
+		if gvr == nil || gvr.Version == "" {
+			return nil, fmt.Errorf("KUBE_COMPARE_MCP_METAL3_GVRS entry %q must be resource.version.group", entry)
+		}
+		idx := slices.IndexFunc(metal3DefaultGVRs, func(d schema.GroupVersionResource) bool {
+			return d.Resource == gvr.Resource
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("KUBE_COMPARE_MCP_METAL3_GVRS entry %q names an unknown resource; expected one of %s",
+				entry, strings.Join(metal3ResourceNames(), ", "))
+		}
+		gvrs[metal3DefaultGVRs[idx].GroupResource()] = *gvr
+	}
+	return gvrs, nil
+}
+
+// metal3ResourceNames returns the names of the metal3 resources read by the BIOS tools.
+func metal3ResourceNames() []string {
+	names := make([]string, len(metal3DefaultGVRs))
+	for i, gvr := range metal3DefaultGVRs {
+		names[i] = gvr.Resource
+	}
+	return names
+}
+
+// resolveMetal3GVRs returns gvrs with each resource whose configured version the
+// cluster does not serve moved to a version it does, preferring the group's preferred
+// version. Resources the cluster serves at no version keep their configured version
+// and are named in the returned error, which wraps ErrMetal3ResourceNotServed; the
+// returned map is usable either way.
+func resolveMetal3GVRs(client discovery.DiscoveryInterface, gvrs metal3GVRs, logger *slog.Logger) (metal3GVRs, error) {
+	served := make(map[schema.GroupVersion][]metav1.APIResource)
+	serves := func(gvr schema.GroupVersionResource) (bool, error) {
+		gv := gvr.GroupVersion()
+		resources, ok := served[gv]
+		if !ok {
+			list, err := client.ServerResourcesForGroupVersion(gv.String())
+			if err != nil && !apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("failed to discover %s resources: %w", gv, err)
+			}
+			if list != nil {
+				resources = list.APIResources
+			}
+			served[gv] = resources
+		}
+		return slices.ContainsFunc(resources, func(r metav1.APIResource) bool { return r.Name == gvr.Resource }), nil
+	}
+
+	resolved := make(metal3GVRs, len(gvrs))
+	var groups *metav1.APIGroupList
+	var unserved []string
+	for _, def := range metal3DefaultGVRs {
+		key := def.GroupResource()
+		gvr := gvrs[key]
+		resolved[key] = gvr
+		ok, err := serves(gvr)
+		if err != nil {
+			return gvrs, err
+		}
+		if ok {
+			continue
+		}
+
+		if groups == nil {
+			if groups, err = client.ServerGroups(); err != nil {
+				return gvrs, fmt.Errorf("failed to discover API groups: %w", err)
+			}
+		}
+		var versions []string
+		for _, group := range groups.Groups {
+			if group.Name != gvr.Group {
+				continue
+			}
+			versions = append(versions, group.PreferredVersion.Version)
+			for _, version := range group.Versions {
+				versions = append(versions, version.Version)
+			}
+		}
+
+		found := false
+		for _, version := range versions {
+			if version == gvr.Version {
+				continue
+			}
+			candidate := gvr.GroupResource().WithVersion(version)
+			if ok, err := serves(candidate); err != nil {
+				return gvrs, err
+			} else if ok {
+				logger.Info("Using the served version of a metal3 resource",
+					"resource", gvr.GroupResource().String(),
+					"configuredVersion", gvr.Version,
+					"servedVersion", version,
+				)
+				resolved[key] = candidate
+				found = true
+				break
+			}
+		}
+		if !found {
+			unserved = append(unserved, fmt.Sprintf("%s.%s.%s", gvr.Resource, gvr.Version, gvr.Group))
+		}
+	}
+	if len(unserved) > 0 {
+		return resolved, fmt.Errorf("%w: %s", ErrMetal3ResourceNotServed, strings.Join(unserved, ", "))
+	}
+	return resolved, nil
+}
+
+// metal3Client is a dynamic client that reads each metal3 resource from the
+// GroupVersionResource resolved for it, whatever version the caller asks for.
+type metal3Client struct {
+	dynamic.Interface
+	gvrs metal3GVRs
+}
+
+// Resource returns the client for gvr, at its resolved version for a metal3 resource.
+func (c metal3Client) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	if resolved, ok := c.gvrs[gvr.GroupResource()]; ok {
+		gvr = resolved
+	}
+	return c.Interface.Resource(gvr)
+}
+
+// newMetal3Client wraps client so metal3 resources are read at the versions configured
+// via KUBE_COMPARE_MCP_METAL3_GVRS, or at the version the cluster serves when it does
+// not serve the configured one. Discovery failures are logged and leave the configured
+// versions in place, so reads fail with the API server's error rather than here.
+func newMetal3Client(client dynamic.Interface, discoveryClient discovery.DiscoveryInterface, logger *slog.Logger) (dynamic.Interface, error) {
+	gvrs, err := getMetal3GVRs()
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := resolveMetal3GVRs(discoveryClient, gvrs, logger)
+	if err != nil {
+		if errors.Is(err, ErrMetal3ResourceNotServed) {
+			logger.Warn("Cluster does not serve metal3 resources read by the BIOS tools", "error", err)
+		} else {
+			logger.Debug("Could not discover metal3 API versions; using configured versions", "error", err)
+		}
+	}
+	return metal3Client{Interface: client, gvrs: resolved}, nil
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newMetal3TestDiscovery returns a discovery client serving the metal3 resources read
+// by the BIOS tools at each of versions.
+func newMetal3TestDiscovery(versions ...string) *discoveryfake.FakeDiscovery {
+	fake := &clienttesting.Fake{}
+	for _, version := range versions {
+		list := &metav1.APIResourceList{GroupVersion: "metal3.io/" + version}
+		for _, name := range metal3ResourceNames() {
+			list.APIResources = append(list.APIResources, metav1.APIResource{Name: name, Namespaced: true})
+		}
+		fake.Resources = append(fake.Resources, list)
+	}
+	return &discoveryfake.FakeDiscovery{Fake: fake}
+}
+
+var _ = Describe("metal3 GVRs", func() {
+	Describe("getMetal3GVRs", func() {
+		It("defaults to metal3.io/v1alpha1", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_METAL3_GVRS", "")
+			gvrs, err := getMetal3GVRs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gvrs).To(HaveLen(4))
+			Expect(gvrs[hostFirmwareSettingsGVR.GroupResource()]).To(Equal(hostFirmwareSettingsGVR))
+		})
+
+		It("overrides the configured resources", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_METAL3_GVRS", " hostfirmwaresettings.v1beta1.metal3.io ,")
+			gvrs, err := getMetal3GVRs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gvrs[hostFirmwareSettingsGVR.GroupResource()]).To(Equal(
+				schema.GroupVersionResource{Group: "metal3.io", Version: "v1beta1", Resource: "hostfirmwaresettings"}))
+			Expect(gvrs[bareMetalHostGVR.GroupResource()]).To(Equal(bareMetalHostGVR))
+		})
+
+		DescribeTable("rejects invalid entries",
+			func(val, message string) {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_METAL3_GVRS", val)
+				_, err := getMetal3GVRs()
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("without a version", "hostfirmwaresettings", "must be resource.version.group"),
+			Entry("an unknown resource", "provisionings.v1alpha1.metal3.io", "names an unknown resource"),
+		)
+	})
+
+	Describe("resolveMetal3GVRs", func() {
+		var defaults metal3GVRs
+
+		BeforeEach(func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_METAL3_GVRS", "")
+			var err error
+			defaults, err = getMetal3GVRs()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("keeps the configured versions the cluster serves", func() {
+			resolved, err := resolveMetal3GVRs(newMetal3TestDiscovery("v1alpha1", "v1beta1"), defaults, discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved).To(Equal(defaults))
+		})
+
+		It("moves to the served version when the configured one is not served", func() {
+			resolved, err := resolveMetal3GVRs(newMetal3TestDiscovery("v1beta1"), defaults, discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+			for _, gvr := range metal3DefaultGVRs {
+				Expect(resolved[gvr.GroupResource()].Version).To(Equal("v1beta1"))
+			}
+		})
+
+		It("names the resources the cluster serves at no version", func() {
+			resolved, err := resolveMetal3GVRs(newMetal3TestDiscovery(), defaults, discardLogger)
+			Expect(errors.Is(err, ErrMetal3ResourceNotServed)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("hostfirmwaresettings.v1alpha1.metal3.io"))
+			Expect(resolved).To(Equal(defaults))
+		})
+
+		It("returns the configured versions when discovery fails", func() {
+			discovery := newMetal3TestDiscovery("v1beta1")
+			discovery.AddReactor("get", "resource", func(clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("connection refused")
+			})
+			resolved, err := resolveMetal3GVRs(discovery, defaults, discardLogger)
+			Expect(err).To(MatchError(ContainSubstring("connection refused")))
+			Expect(resolved).To(Equal(defaults))
+		})
+	})
+
+	Describe("newMetal3Client", func() {
+		It("reads a v1beta1 HostFirmwareSettings when only v1beta1 is served", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_METAL3_GVRS", "")
+			ctx := context.Background()
+			v1beta1 := hostFirmwareSettingsGVR.GroupResource().WithVersion("v1beta1")
+			hfs := newTestHostFirmwareSettings("node-0", "spoke", map[string]string{"ProcTurboMode": "Enabled"})
+			hfs.SetAPIVersion("metal3.io/v1beta1")
+
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{v1beta1: "HostFirmwareSettingsList"})
+			_, err := dynamicClient.Resource(v1beta1).Namespace("spoke").Create(ctx, hfs, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			client, err := newMetal3Client(dynamicClient, newMetal3TestDiscovery("v1beta1"), discardLogger)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := getHostFirmwareSettings(ctx, client, "spoke", "node-0", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Settings).To(HaveKeyWithValue("ProcTurboMode", "Enabled"))
+		})
+
+		It("rejects an invalid KUBE_COMPARE_MCP_METAL3_GVRS", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_METAL3_GVRS", "hostfirmwaresettings")
+			_, err := newMetal3Client(newBIOSTestFakeDynamicClient(), newMetal3TestDiscovery("v1alpha1"), discardLogger)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/sakhoury/kube-compare-mcp/pkg/mcpserver (interfaces: RegistryClient,ClusterClient,ClusterClientFactory,HTTPDoer)
+// Source: github.com/sakhoury/kube-compare-mcp/pkg/mcpserver (interfaces: RegistryClient,ClusterClient,ClusterClientFactory,HTTPDoer,ImageVerifier)
 //
 // Generated by this command:
 //
-//	mockgen -destination=mock_interfaces_test.go -package=mcpserver_test github.com/sakhoury/kube-compare-mcp/pkg/mcpserver RegistryClient,ClusterClient,ClusterClientFactory,HTTPDoer
+//	mockgen -destination=mock_interfaces_test.go -package=mcpserver_test github.com/sakhoury/kube-compare-mcp/pkg/mcpserver RegistryClient,ClusterClient,ClusterClientFactory,HTTPDoer,ImageVerifier
 //
 
 // Package mcpserver_test is a generated GoMock package.
@@ -43,6 +43,36 @@ func (m *MockRegistryClient) EXPECT() *MockRegistryClientMockRecorder {
 	return m.recorder
 }
 
+// Digest mocks base method.
+func (m *MockRegistryClient) Digest(ctx context.Context, imageRef string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Digest", ctx, imageRef)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Digest indicates an expected call of Digest.
+func (mr *MockRegistryClientMockRecorder) Digest(ctx, imageRef any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Digest", reflect.TypeOf((*MockRegistryClient)(nil).Digest), ctx, imageRef)
+}
+
+// GetImageConfig mocks base method.
+func (m *MockRegistryClient) GetImageConfig(ctx context.Context, imageRef string) (*mcpserver.ImageConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageConfig", ctx, imageRef)
+	ret0, _ := ret[0].(*mcpserver.ImageConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageConfig indicates an expected call of GetImageConfig.
+func (mr *MockRegistryClientMockRecorder) GetImageConfig(ctx, imageRef any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageConfig", reflect.TypeOf((*MockRegistryClient)(nil).GetImageConfig), ctx, imageRef)
+}
+
 // HeadImage mocks base method.
 func (m *MockRegistryClient) HeadImage(ctx context.Context, imageRef string) error {
 	m.ctrl.T.Helper()
@@ -97,10 +127,10 @@ func (m *MockClusterClient) EXPECT() *MockClusterClientMockRecorder {
 }
 
 // GetClusterVersion mocks base method.
-func (m *MockClusterClient) GetClusterVersion(ctx context.Context) (string, error) {
+func (m *MockClusterClient) GetClusterVersion(ctx context.Context) (*mcpserver.ClusterVersionInfo, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetClusterVersion", ctx)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(*mcpserver.ClusterVersionInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -188,3 +218,42 @@ func (mr *MockHTTPDoerMockRecorder) Do(req any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockHTTPDoer)(nil).Do), req)
 }
+
+// MockImageVerifier is a mock of ImageVerifier interface.
+type MockImageVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageVerifierMockRecorder
+	isgomock struct{}
+}
+
+// MockImageVerifierMockRecorder is the mock recorder for MockImageVerifier.
+type MockImageVerifierMockRecorder struct {
+	mock *MockImageVerifier
+}
+
+// NewMockImageVerifier creates a new mock instance.
+func NewMockImageVerifier(ctrl *gomock.Controller) *MockImageVerifier {
+	mock := &MockImageVerifier{ctrl: ctrl}
+	mock.recorder = &MockImageVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageVerifier) EXPECT() *MockImageVerifierMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockImageVerifier) Verify(ctx context.Context, imageRef string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, imageRef)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockImageVerifierMockRecorder) Verify(ctx, imageRef any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockImageVerifier)(nil).Verify), ctx, imageRef)
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openshift/kube-compare/pkg/compare"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxOwnedResourceDepth bounds how many levels of ownerReferences include_owned
+// follows below a compared CR, such as Deployment to ReplicaSet to Pod.
+const maxOwnedResourceDepth = 3
+
+// ownedResourceKind is a kind of resource include_owned looks for among the children
+// of a compared CR.
+type ownedResourceKind struct {
+	kind string
+	gvr  schema.GroupVersionResource
+}
+
+// ownedResourceKinds are the kinds of children include_owned reports: the resources
+// workload controllers generate. Children of other kinds are not looked for.
+var ownedResourceKinds = []ownedResourceKind{
+	{kind: "ReplicaSet", gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}},
+	{kind: "Job", gvr: schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}},
+	{kind: "Pod", gvr: schema.GroupVersionResource{Version: "v1", Resource: "pods"}},
+}
+
+// OwnedResourcesReport lists the resources the compared CRs own on the cluster. It
+// is returned when include_owned is set.
+type OwnedResourcesReport struct {
+	Resources []OwnedResources `json:"owned_resources"`
+}
+
+// OwnedResources is a compared CR and the resources it owns. Error is set when its
+// children could not be listed.
+type OwnedResources struct {
+	CRName string          `json:"cr_name"`
+	Owned  []OwnedResource `json:"owned,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// OwnedResource is a resource that lists a compared CR, or another owned resource,
+// in its ownerReferences. It reports presence and readiness, not a diff.
+type OwnedResource struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	// Status explains Ready, such as the Pod phase or the ready ReplicaSet replicas
+	Status string          `json:"status,omitempty"`
+	Owned  []OwnedResource `json:"owned,omitempty"`
+}
+
+// content returns the MCP content block carrying the report as JSON text.
+func (r *OwnedResourcesReport) content() (mcp.Content, error) {
+	reportJSON, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format owned resources: %w", err)
+	}
+	return &mcp.TextContent{Text: string(reportJSON)}, nil
+}
+
+// ownedResourceLister finds the children of a resource by listing the
+// ownedResourceKinds in its namespace. Each namespace is listed once.
+type ownedResourceLister struct {
+	client dynamic.Interface
+	lists  map[string][]unstructured.Unstructured
+}
+
+// newOwnedResourceLister returns a lister that reads from the cluster client is
+// configured for.
+func newOwnedResourceLister(client dynamic.Interface) *ownedResourceLister {
+	return &ownedResourceLister{client: client, lists: make(map[string][]unstructured.Unstructured)}
+}
+
+// list returns the resources of kind in namespace.
+func (l *ownedResourceLister) list(ctx context.Context, namespace string, kind ownedResourceKind) ([]unstructured.Unstructured, error) {
+	key := namespace + "/" + kind.gvr.Resource
+	if items, ok := l.lists[key]; ok {
+		return items, nil
+	}
+	list, err := l.client.Resource(kind.gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s in namespace %s: %w", kind.gvr.Resource, namespace, err)
+	}
+	l.lists[key] = list.Items
+	return list.Items, nil
+}
+
+// children returns the resources in namespace whose ownerReferences name the owner of
+// the given group, kind, and name, with their own children down to depth levels.
+func (l *ownedResourceLister) children(ctx context.Context, namespace, group, kind, name string, depth int) ([]OwnedResource, error) {
+	if depth <= 0 {
+		return nil, nil
+	}
+	var owned []OwnedResource
+	for _, childKind := range ownedResourceKinds {
+		items, err := l.list(ctx, namespace, childKind)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			if !ownedBy(&items[i], group, kind, name) {
+				continue
+			}
+			child := OwnedResource{Kind: childKind.kind, Name: items[i].GetName()}
+			child.Ready, child.Status = ownedResourceReadiness(&items[i], childKind.kind)
+			child.Owned, err = l.children(ctx, namespace, childKind.gvr.Group, childKind.kind, child.Name, depth-1)
+			if err != nil {
+				return nil, err
+			}
+			owned = append(owned, child)
+		}
+	}
+	return owned, nil
+}
+
+// ownedBy reports whether obj lists the owner of the given group, kind, and name in
+// its ownerReferences. Owner and child are in the same namespace, where the name
+// identifies the owner.
+func ownedBy(obj *unstructured.Unstructured, group, kind, name string) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == group && ref.Kind == kind && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ownedResourceReadiness reports whether an owned resource is ready and why: a Pod
+// when its Ready condition is true, a ReplicaSet when all its replicas are ready, and
+// a Job when it has completed.
+func ownedResourceReadiness(obj *unstructured.Unstructured, kind string) (bool, string) {
+	switch kind {
+	case "Pod":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return conditionTrue(obj, "Ready"), phase
+	case "ReplicaSet":
+		desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			desired = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		return ready >= desired, fmt.Sprintf("%d/%d replicas ready", ready, desired)
+	case "Job":
+		switch {
+		case conditionTrue(obj, "Complete"):
+			return true, "Complete"
+		case conditionTrue(obj, "Failed"):
+			return false, "Failed"
+		default:
+			return false, "Active"
+		}
+	default:
+		return false, ""
+	}
+}
+
+// conditionTrue reports whether obj has a status condition of conditionType with
+// status True.
+func conditionTrue(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]any)
+		if ok && condition["type"] == conditionType && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportOwnedResources returns the resources each compared CR of output owns on the
+// cluster. Only CRs that own a resource, or whose children could not be listed, are
+// listed. Cluster-scoped CRs own no namespaced children and are skipped, as are
+// unmatched CRs, which were not compared.
+func ReportOwnedResources(ctx context.Context, output *compare.Output, lister *ownedResourceLister) *OwnedResourcesReport {
+	logger := slog.Default()
+
+	report := &OwnedResourcesReport{Resources: []OwnedResources{}}
+	if output.Diffs == nil {
+		return report
+	}
+	for _, diff := range *output.Diffs {
+		gvk, namespace, name, err := parseCRName(diff.CRName)
+		if err != nil || namespace == "" {
+			continue
+		}
+		owned, err := lister.children(ctx, namespace, gvk.Group, gvk.Kind, name, maxOwnedResourceDepth)
+		if err != nil {
+			logger.Debug("Could not list resources owned by CR", "cr", diff.CRName, "error", err)
+			report.Resources = append(report.Resources, OwnedResources{CRName: diff.CRName, Error: err.Error()})
+			continue
+		}
+		if len(owned) > 0 {
+			report.Resources = append(report.Resources, OwnedResources{CRName: diff.CRName, Owned: owned})
+		}
+	}
+	return report
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// ownedTestObject returns an object of apiVersion and kind in namespace "apps" owned
+// by the owner of ownerAPIVersion, ownerKind, and ownerName, with the given status.
+func ownedTestObject(apiVersion, kind, name, ownerAPIVersion, ownerKind, ownerName string, status map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": "apps",
+			"ownerReferences": []any{map[string]any{
+				"apiVersion": ownerAPIVersion,
+				"kind":       ownerKind,
+				"name":       ownerName,
+				"uid":        ownerName + "-uid",
+			}},
+		},
+		"status": status,
+	}}
+}
+
+// readyPodStatus returns the status of a running Pod whose Ready condition is ready.
+func readyPodStatus(ready string) map[string]any {
+	return map[string]any{
+		"phase":      "Running",
+		"conditions": []any{map[string]any{"type": "Ready", "status": ready}},
+	}
+}
+
+// newOwnedTestClient returns a fake cluster where Deployment apps/web owns a
+// ReplicaSet, which owns one ready and one unready Pod.
+func newOwnedTestClient() *dynamicfake.FakeDynamicClient {
+	replicaSet := ownedTestObject("apps/v1", "ReplicaSet", "web-5d8f", "apps/v1", "Deployment", "web",
+		map[string]any{"readyReplicas": int64(1)})
+	Expect(unstructured.SetNestedField(replicaSet.Object, int64(2), "spec", "replicas")).To(Succeed())
+
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "apps", Version: "v1", Resource: "replicasets"}: "ReplicaSetList",
+			{Group: "batch", Version: "v1", Resource: "jobs"}:       "JobList",
+			{Version: "v1", Resource: "pods"}:                       "PodList",
+		},
+		replicaSet,
+		ownedTestObject("v1", "Pod", "web-5d8f-a", "apps/v1", "ReplicaSet", "web-5d8f", readyPodStatus("True")),
+		ownedTestObject("v1", "Pod", "web-5d8f-b", "apps/v1", "ReplicaSet", "web-5d8f", readyPodStatus("False")),
+		// Owned by another ReplicaSet, so not a child of web
+		ownedTestObject("v1", "Pod", "api-7c9d-a", "apps/v1", "ReplicaSet", "api-7c9d", readyPodStatus("True")),
+		ownedTestObject("batch/v1", "Job", "backup-123", "batch/v1", "CronJob", "backup",
+			map[string]any{"conditions": []any{map[string]any{"type": "Complete", "status": "True"}}}),
+	)
+}
+
+// newOwnedTestOutput returns kube-compare output comparing the given CRs.
+func newOwnedTestOutput(crNames ...string) *compare.Output {
+	diffs := make([]compare.DiffSum, 0, len(crNames))
+	for _, crName := range crNames {
+		diffs = append(diffs, compare.DiffSum{CRName: crName, CorrelatedTemplate: "template.yaml"})
+	}
+	return &compare.Output{Summary: &compare.Summary{TotalCRs: len(diffs)}, Diffs: &diffs}
+}
+
+var _ = Describe("include_owned", func() {
+	ctx := context.Background()
+
+	It("reports the ReplicaSet and Pods a Deployment owns with their readiness", func() {
+		output := newOwnedTestOutput("apps/v1_Deployment_apps_web", "v1_ConfigMap_apps_settings", "v1_Namespace_apps")
+
+		report := ReportOwnedResources(ctx, output, newOwnedResourceLister(newOwnedTestClient()))
+		Expect(report.Resources).To(Equal([]OwnedResources{{
+			CRName: "apps/v1_Deployment_apps_web",
+			Owned: []OwnedResource{{
+				Kind:   "ReplicaSet",
+				Name:   "web-5d8f",
+				Ready:  false,
+				Status: "1/2 replicas ready",
+				Owned: []OwnedResource{
+					{Kind: "Pod", Name: "web-5d8f-a", Ready: true, Status: "Running"},
+					{Kind: "Pod", Name: "web-5d8f-b", Ready: false, Status: "Running"},
+				},
+			}},
+		}}))
+	})
+
+	It("reports a completed Job as ready", func() {
+		report := ReportOwnedResources(ctx, newOwnedTestOutput("batch/v1_CronJob_apps_backup"), newOwnedResourceLister(newOwnedTestClient()))
+		Expect(report.Resources).To(HaveLen(1))
+		Expect(report.Resources[0].Owned).To(Equal([]OwnedResource{{Kind: "Job", Name: "backup-123", Ready: true, Status: "Complete"}}))
+	})
+
+	It("lists each kind once per namespace", func() {
+		client := newOwnedTestClient()
+		output := newOwnedTestOutput("apps/v1_Deployment_apps_web", "apps/v1_Deployment_apps_api", "batch/v1_CronJob_apps_backup")
+
+		ReportOwnedResources(ctx, output, newOwnedResourceLister(client))
+		lists := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "list" {
+				lists++
+			}
+		}
+		Expect(lists).To(Equal(len(ownedResourceKinds)))
+	})
+
+	It("reports a CR whose children cannot be listed with the error", func() {
+		client := newOwnedTestClient()
+		client.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("denied"))
+		})
+
+		report := ReportOwnedResources(ctx, newOwnedTestOutput("apps/v1_Deployment_apps_web"), newOwnedResourceLister(client))
+		Expect(report.Resources).To(HaveLen(1))
+		Expect(report.Resources[0].Owned).To(BeEmpty())
+		Expect(report.Resources[0].Error).To(ContainSubstring("failed to list pods in namespace apps"))
+	})
+
+	It("makes kube-compare output JSON", func() {
+		Expect(compareOutputFormat(&CompareArgs{OutputFormat: compare.Yaml, IncludeOwned: true})).To(Equal(compare.Json))
+	})
+})
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newProxyFunc returns a proxy selector built from the HTTPS_PROXY, HTTP_PROXY
+// and NO_PROXY environment variables (upper or lower case) as they are now.
+// NO_PROXY entries may be hostnames, domain suffixes (".corp.example"),
+// IP addresses, or CIDR ranges (e.g., "10.0.0.0/8"). Matching hosts, as well
+// as localhost and loopback addresses, are always contacted directly.
+func newProxyFunc() func(*http.Request) (*url.URL, error) {
+	proxyFunc := httpproxy.FromEnvironment().ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// newRegistryTransport returns the transport used for OCI registry operations.
+// It keeps go-containerregistry's transport defaults and routes requests through
+// the configured proxy unless the registry host is listed in NO_PROXY.
+func newRegistryTransport() http.RoundTripper {
+	base, ok := remote.DefaultTransport.(*http.Transport)
+	if !ok {
+		base = &http.Transport{}
+	}
+	transport := base.Clone()
+	transport.Proxy = newProxyFunc()
+	return transport
+}
+
+// registryTransport is the shared proxy-aware transport for registry access. It
+// retries transient failures within registryRetryBackoff, and skips TLS verification
+// for the registries listed in KUBE_COMPARE_MCP_INSECURE_REGISTRIES.
+var registryTransport http.RoundTripper = newRegistryRetryTransport(newInsecureRegistryTransport(newRegistryTransport()), registryRetryBackoff)
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/doyensec/safeurl"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Proxy support", func() {
+	Describe("proxy selection", func() {
+		const proxyURL = "http://proxy.corp.example:3128"
+
+		BeforeEach(func() {
+			GinkgoT().Setenv("HTTPS_PROXY", proxyURL)
+			GinkgoT().Setenv("HTTP_PROXY", proxyURL)
+			GinkgoT().Setenv("NO_PROXY", "registry.internal,.svc.cluster.local,10.0.0.0/8")
+		})
+
+		proxyFor := func(proxy func(*http.Request) (*url.URL, error), target string) string {
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			Expect(err).NotTo(HaveOccurred())
+			u, err := proxy(req)
+			Expect(err).NotTo(HaveOccurred())
+			if u == nil {
+				return ""
+			}
+			return u.String()
+		}
+
+		registryProxy := func() func(*http.Request) (*url.URL, error) {
+			transport, ok := newRegistryTransport().(*http.Transport)
+			Expect(ok).To(BeTrue())
+			return transport.Proxy
+		}
+
+		clusterProxy := func() func(*http.Request) (*url.URL, error) {
+			restConfig, err := BuildSecureRestConfigFromBytes([]byte(`
+apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: https://api.cluster.example:6443
+users:
+- name: test
+  user:
+    token: abc
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+`), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restConfig.Proxy).NotTo(BeNil())
+			return restConfig.Proxy
+		}
+
+		DescribeTable("honors HTTPS_PROXY and NO_PROXY",
+			func(target, expected string) {
+				Expect(proxyFor(registryProxy(), target)).To(Equal(expected), "registry transport")
+				Expect(proxyFor(clusterProxy(), target)).To(Equal(expected), "cluster REST config")
+			},
+			Entry("unlisted host uses the proxy", "https://registry.redhat.io/v2/", proxyURL),
+			Entry("exact NO_PROXY host bypasses the proxy", "https://registry.internal/v2/", ""),
+			Entry("NO_PROXY suffix bypasses the proxy", "https://kubernetes.default.svc.cluster.local:443/api", ""),
+			Entry("NO_PROXY CIDR bypasses the proxy", "https://10.1.2.3:6443/version", ""),
+			Entry("address outside the NO_PROXY CIDR uses the proxy", "https://172.30.0.1:443/version", proxyURL),
+		)
+
+		It("keeps the proxy-url pinned by the kubeconfig", func() {
+			restConfig, err := BuildSecureRestConfigFromBytes([]byte(`
+apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: https://10.1.2.3:6443
+    proxy-url: http://cluster-proxy.corp.example:3128
+users:
+- name: test
+  user:
+    token: abc
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+`), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proxyFor(restConfig.Proxy, "https://10.1.2.3:6443/version")).To(Equal("http://cluster-proxy.corp.example:3128"))
+		})
+	})
+
+	Describe("NewCompareService", func() {
+		var proxied atomic.Int32
+
+		BeforeEach(func() {
+			proxied.Store(0)
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				proxied.Add(1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			DeferCleanup(proxy.Close)
+			GinkgoT().Setenv("HTTPS_PROXY", proxy.URL)
+			GinkgoT().Setenv("HTTP_PROXY", proxy.URL)
+		})
+
+		It("does not send reference checks through the proxy", func() {
+			wrapped, ok := NewCompareService().HTTPClient.(*safeurl.WrappedClient)
+			Expect(ok).To(BeTrue())
+			transport, ok := wrapped.Client.Transport.(*http.Transport)
+			Expect(ok).To(BeTrue())
+			Expect(transport.Proxy).To(BeNil())
+		})
+
+		DescribeTable("still blocks internal targets with a proxy configured",
+			func(target string) {
+				req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, target, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				resp, err := NewCompareService().HTTPClient.Do(req)
+				if resp != nil {
+					_ = resp.Body.Close()
+				}
+				Expect(err).To(HaveOccurred())
+				Expect(proxied.Load()).To(BeZero())
+			},
+			Entry("link-local metadata address", "http://169.254.169.254/latest/meta-data/"),
+			Entry("private address", "http://10.0.0.1/metadata.yaml"),
+			Entry("loopback address", "http://127.0.0.1/metadata.yaml"),
+		)
+	})
+})
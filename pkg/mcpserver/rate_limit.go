@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// maxRateLimitRetries is how many times a request the API server rejected with
+	// HTTP 429 is retried before the rejection is returned.
+	maxRateLimitRetries = 3
+	// defaultRateLimitBackoff is the first wait before a retry when the response has
+	// no Retry-After header. It doubles with each retry.
+	defaultRateLimitBackoff = 500 * time.Millisecond
+	// maxRateLimitBackoff caps a single wait, including one asked for by Retry-After,
+	// so that a throttled tool call fails in seconds rather than hanging.
+	maxRateLimitBackoff = 5 * time.Second
+
+	// clusterRateLimitHint is the remediation reported for a request that stayed
+	// rate-limited after retrying.
+	clusterRateLimitHint = "The cluster's API priority and fairness limits rejected the requests (HTTP 429) even after retrying. " +
+		"Narrow the request so it makes fewer API calls, for example by setting host_name or comparing a single namespace, " +
+		"or retry when the cluster is less busy."
+)
+
+// withRateLimitRetry makes the clients built from config retry requests the API
+// server rejects with HTTP 429.
+func withRateLimitRetry(config *rest.Config) {
+	config.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return &rateLimitRoundTripper{next: next, wait: waitForRetry}
+	})
+}
+
+// rateLimitRoundTripper retries requests rejected with HTTP 429 after a short
+// backoff that honors the Retry-After header. When the retries are spent the last
+// rejection is returned, which client-go reports as a TooManyRequests error.
+type rateLimitRoundTripper struct {
+	next http.RoundTripper
+	wait func(ctx context.Context, d time.Duration) error
+}
+
+func (rt *rateLimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := defaultRateLimitBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := rt.next.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+		// A request whose body cannot be replayed is not retried
+		if attempt > maxRateLimitRetries || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			// client-go retries a 429 with Retry-After on its own. The retries were
+			// spent here, so drop the header to return the rejection now.
+			resp.Header.Del("Retry-After")
+			return resp, nil
+		}
+
+		delay := retryAfter(resp, backoff)
+		backoff *= 2
+		slog.Default().Debug("Cluster API rate-limited the request, retrying",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"attempt", attempt,
+			"delay", delay,
+		)
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		_ = resp.Body.Close()
+
+		if err := rt.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter returns the wait asked for by the response's Retry-After header, in
+// seconds or as an HTTP date, or backoff when there is none. The wait is capped at
+// maxRateLimitBackoff.
+func retryAfter(resp *http.Response, backoff time.Duration) time.Duration {
+	delay := backoff
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(value); err == nil {
+			delay = max(time.Until(date), 0)
+		}
+	}
+	return min(delay, maxRateLimitBackoff)
+}
+
+// waitForRetry waits for d, or returns the context's error if it is canceled first.
+func waitForRetry(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimitError reports err, when it is an API server HTTP 429 rejection, as the
+// cluster rate-limiting requests rather than as the operation it failed in, whose
+// remediation would not apply. It returns nil for other errors.
+func rateLimitError(err error) *CompareError {
+	if !apierrors.IsTooManyRequests(err) {
+		return nil
+	}
+	// The status error names the request that was rejected
+	var statusErr *apierrors.StatusError
+	if errors.As(err, &statusErr) {
+		err = statusErr
+	}
+	return NewCompareError("cluster-rate-limit", fmt.Errorf("%w: %w", ErrClusterRateLimited, err), clusterRateLimitHint)
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// tooManyRequestsStatus is the Status body the API server returns with HTTP 429.
+const tooManyRequestsStatus = `{"kind":"Status","apiVersion":"v1","status":"Failure",` +
+	`"message":"Too many requests, please try again later.","reason":"TooManyRequests","code":429}`
+
+// scriptedRoundTripper answers each request with the next status in statuses,
+// repeating the last one.
+type scriptedRoundTripper struct {
+	statuses   []int
+	retryAfter string
+	calls      int
+}
+
+func (rt *scriptedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := rt.statuses[min(rt.calls, len(rt.statuses)-1)]
+	rt.calls++
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}
+	if status == http.StatusTooManyRequests && rt.retryAfter != "" {
+		resp.Header.Set("Retry-After", rt.retryAfter)
+	}
+	return resp, nil
+}
+
+var _ = Describe("Cluster API rate limiting", func() {
+	Describe("rateLimitRoundTripper", func() {
+		var waits []time.Duration
+
+		newRoundTripper := func(next http.RoundTripper) *rateLimitRoundTripper {
+			waits = nil
+			return &rateLimitRoundTripper{next: next, wait: func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}}
+		}
+
+		roundTrip := func(rt http.RoundTripper) *http.Response {
+			req, err := http.NewRequest(http.MethodGet, "https://api.example.com/apis/metal3.io/v1alpha1/baremetalhosts", nil)
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := rt.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			return resp
+		}
+
+		It("retries a 429 after the wait Retry-After asks for", func() {
+			next := &scriptedRoundTripper{statuses: []int{http.StatusTooManyRequests, http.StatusOK}, retryAfter: "2"}
+
+			resp := roundTrip(newRoundTripper(next))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(next.calls).To(Equal(2))
+			Expect(waits).To(Equal([]time.Duration{2 * time.Second}))
+		})
+
+		It("backs off exponentially without Retry-After and returns the last 429", func() {
+			next := &scriptedRoundTripper{statuses: []int{http.StatusTooManyRequests}}
+
+			resp := roundTrip(newRoundTripper(next))
+			Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+			Expect(next.calls).To(Equal(maxRateLimitRetries + 1))
+			Expect(waits).To(Equal([]time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second}))
+		})
+
+		It("caps a long Retry-After and drops it from the returned 429", func() {
+			next := &scriptedRoundTripper{statuses: []int{http.StatusTooManyRequests}, retryAfter: "120"}
+
+			resp := roundTrip(newRoundTripper(next))
+			Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+			Expect(resp.Header.Get("Retry-After")).To(BeEmpty())
+			Expect(waits).To(HaveEach(maxRateLimitBackoff))
+		})
+
+		It("does not retry other responses", func() {
+			next := &scriptedRoundTripper{statuses: []int{http.StatusForbidden}}
+
+			Expect(roundTrip(newRoundTripper(next)).StatusCode).To(Equal(http.StatusForbidden))
+			Expect(next.calls).To(Equal(1))
+			Expect(waits).To(BeEmpty())
+		})
+
+		It("stops waiting when the request is canceled", func() {
+			next := &scriptedRoundTripper{statuses: []int{http.StatusTooManyRequests}, retryAfter: "1"}
+			rt := &rateLimitRoundTripper{next: next, wait: waitForRetry}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/version", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rt.RoundTrip(req)
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(next.calls).To(Equal(1))
+		})
+	})
+
+	Describe("with a dynamic client", func() {
+		// newThrottledClient returns a dynamic client for a server that rejects the
+		// first rejections requests with HTTP 429, and the number of requests it served.
+		newThrottledClient := func(rejections int32) (dynamic.Interface, *atomic.Int32) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if requests.Add(1) <= rejections {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					_, _ = w.Write([]byte(tooManyRequestsStatus))
+					return
+				}
+				_, _ = w.Write([]byte(`{"apiVersion":"metal3.io/v1alpha1","kind":"BareMetalHost","metadata":{"name":"host-0","namespace":"hosts"}}`))
+			}))
+			DeferCleanup(server.Close)
+
+			config := &rest.Config{Host: server.URL}
+			withRateLimitRetry(config)
+			client, err := dynamic.NewForConfig(config)
+			Expect(err).NotTo(HaveOccurred())
+			return client, &requests
+		}
+
+		It("succeeds once the API server stops rate-limiting", func() {
+			client, requests := newThrottledClient(1)
+
+			bmh, err := client.Resource(bareMetalHostGVR).Namespace("hosts").Get(context.Background(), "host-0", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bmh.GetName()).To(Equal("host-0"))
+			Expect(requests.Load()).To(BeEquivalentTo(2))
+		})
+
+		It("reports persistent rate limiting with guidance to narrow the request", func() {
+			client, requests := newThrottledClient(100)
+
+			_, err := client.Resource(bareMetalHostGVR).Namespace("hosts").Get(context.Background(), "host-0", metav1.GetOptions{})
+			Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+			// client-go does not add its own retries to the ones already made
+			Expect(requests.Load()).To(BeEquivalentTo(maxRateLimitRetries + 1))
+
+			err = NewCompareError("get-bmh",
+				fmt.Errorf("failed to get BareMetalHost hosts/host-0: %w", err),
+				"Verify the host name and namespace are correct")
+			Expect(errors.Is(rateLimitError(err), ErrClusterRateLimited)).To(BeTrue())
+
+			message := FormatErrorForUser(err)
+			Expect(message).To(HavePrefix("cluster-rate-limit: cluster API is rate-limiting requests"))
+			Expect(message).To(ContainSubstring("Narrow the request"))
+			Expect(message).NotTo(ContainSubstring("Verify the host name"))
+
+			data := StructuredError(err)
+			Expect(data.Op).To(Equal("cluster-rate-limit"))
+			Expect(data.Hint).To(Equal(clusterRateLimitHint))
+		})
+	})
+
+	It("leaves other errors to their operation", func() {
+		Expect(rateLimitError(apierrors.NewForbidden(bareMetalHostGVR.GroupResource(), "host-0", errors.New("denied")))).To(BeNil())
+	})
+})
@@ -21,6 +21,9 @@ import (
 var (
 	majorMinorVersionRegex = regexp.MustCompile(`^(\d+)\.(\d+)`)
 	versionTagRegex        = regexp.MustCompile(`^v\d+\.\d+$`)
+	ocpVersionRegex        = regexp.MustCompile(`^v?\d+\.\d+(\.\d+)?(-[0-9A-Za-z.-]+)?$`)
+	// imageTagRegex is the OCI distribution grammar for a tag
+	imageTagRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
 )
 
 const (
@@ -38,7 +41,8 @@ type RDSConfig struct {
 	MinOCPVersion string   // Minimum OpenShift version required (e.g., "v4.19"); empty means no minimum
 }
 
-var rdsConfigs = map[string]RDSConfig{
+// builtinRDSConfigs are the RDS types the server knows without an RDS config file.
+var builtinRDSConfigs = map[string]RDSConfig{
 	RDSTypeCore: {
 		ImageBase:    "registry.redhat.io/openshift4/openshift-telco-core-rds",
 		Path:         "/usr/share/telco-core-rds/configuration/reference-crs-kube-compare/metadata.yaml",
@@ -58,9 +62,9 @@ var rdsConfigs = map[string]RDSConfig{
 }
 
 func init() {
-	for name, cfg := range rdsConfigs {
-		if cfg.MinOCPVersion != "" && !versionTagRegex.MatchString(cfg.MinOCPVersion) {
-			panic(fmt.Sprintf("rdsConfigs[%q].MinOCPVersion %q does not match vMAJOR.MINOR format", name, cfg.MinOCPVersion))
+	for name, cfg := range builtinRDSConfigs {
+		if err := validateRDSConfig(name, cfg); err != nil {
+			panic(fmt.Sprintf("builtinRDSConfigs[%q]: %v", name, err))
 		}
 	}
 }
@@ -68,11 +72,42 @@ func init() {
 // ResolveRDSResult is the structured response for the kube_compare_resolve_rds tool.
 type ResolveRDSResult struct {
 	ClusterVersion    string   `json:"cluster_version"`
+	DesiredVersion    string   `json:"desired_version,omitempty"`
+	UpgradeInProgress bool     `json:"upgrade_in_progress,omitempty"`
 	RHELVersion       string   `json:"rhel_version"`
 	RDSType           string   `json:"rds_type"`
 	Reference         string   `json:"reference"`
+	ImageRef          string   `json:"image_ref"`
+	MetadataPath      string   `json:"metadata_path"`
 	AvailableVersions []string `json:"available_versions"`
 	Validated         bool     `json:"validated"`
+	// Channel is the channel alias the reference was resolved from, when requested
+	Channel string `json:"channel,omitempty"`
+	// Floating is set for a reference resolved from a channel alias, whose image can
+	// change between calls, so a comparison against it is not reproducible
+	Floating bool `json:"floating,omitempty"`
+	// ReferenceVersion is the OpenShift version the reference was selected for when it
+	// was set apart from the cluster's version, such as an upgrade target
+	ReferenceVersion string `json:"reference_version,omitempty"`
+	// Digest is the manifest digest the reference is pinned to, when pin_digest is set
+	Digest string `json:"digest,omitempty"`
+	// VariantSelection lists the RHEL variants tried, in order, when resolving for a
+	// version, to show why the chosen variant was picked. It is unset when the
+	// reference came from the RDS cache, since no variant was tried
+	VariantSelection []RHELVariantAttempt `json:"variant_selection,omitempty"`
+}
+
+// RHELVariantAttempt is one RHEL variant tried when resolving an RDS for a version.
+type RHELVariantAttempt struct {
+	RHELVariant string `json:"rhel_variant"`
+	Repository  string `json:"repository"`
+	// Reachable is whether the repository's tags could be listed
+	Reachable bool `json:"reachable"`
+	// VersionPresent is whether the repository has a tag for the version
+	VersionPresent bool `json:"version_present"`
+	Chosen         bool `json:"chosen"`
+	// Error is why the repository's tags could not be listed
+	Error string `json:"error,omitempty"`
 }
 
 // ReferenceService encapsulates dependencies for RDS reference operations.
@@ -80,6 +115,9 @@ type ResolveRDSResult struct {
 type ReferenceService struct {
 	Registry       RegistryClient
 	ClusterFactory ClusterClientFactory
+	// Cache reuses references resolved for an RDS type and OpenShift version
+	// (optional, nothing is cached when nil). Channel aliases are never cached.
+	Cache *RDSCache
 }
 
 // NewReferenceService creates a new ReferenceService with default implementations.
@@ -87,6 +125,7 @@ func NewReferenceService() *ReferenceService {
 	return &ReferenceService{
 		Registry:       DefaultRegistry,
 		ClusterFactory: DefaultClusterFactory,
+		Cache:          NewRDSCache(getRDSCacheTTL()),
 	}
 }
 
@@ -98,17 +137,19 @@ type ResolveRDSInput struct {
 	Context    string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig"`
 	RDSType    string `json:"rds_type" jsonschema:"RDS type to find: core for Telco Core RDS, ran for Telco RAN DU RDS, or hub for Telco Hub RDS"`
 	OCPVersion string `json:"ocp_version,omitempty" jsonschema:"OpenShift version (e.g. 4.18 or 4.20.0)"`
-}
+	Channel    string `json:"channel,omitempty" jsonschema:"Floating channel alias tag of the RDS image, such as stable, fast, or eus-4.18, to resolve instead of a version. The image an alias names can change, so the result is marked floating. Use instead of ocp_version."`
+	PinDigest  bool   `json:"pin_digest,omitempty" jsonschema:"Pin the reference to the manifest digest the RDS image tag currently resolves to, as image@sha256:..., so later comparisons use the same image even if the tag moves"`
 
-// ResolveRDSOutput is an empty output struct (tool returns text content).
-type ResolveRDSOutput struct{}
+	KubeconfigSecret string `json:"kubeconfig_secret,omitempty" jsonschema:"Secret holding the kubeconfig, as namespace/name or namespace/name/key (key defaults to kubeconfig). Read by the MCP server from its own cluster; must be enabled by the server. Use instead of kubeconfig."`
+}
 
 // ResolveRDSTool returns the MCP tool definition for finding RDS references.
 func ResolveRDSTool() *mcp.Tool {
 	return &mcp.Tool{
-		Name:        "kube_compare_resolve_rds",
-		Description: "Get the correct Red Hat Telco RDS container reference for a cluster's OpenShift version.",
-		InputSchema: ResolveRDSInputSchema(),
+		Name:         "kube_compare_resolve_rds",
+		Description:  "Get the correct Red Hat Telco RDS container reference for a cluster's OpenShift version.",
+		InputSchema:  ResolveRDSInputSchema(),
+		OutputSchema: ResolveRDSOutputSchema(),
 		Annotations: &mcp.ToolAnnotations{
 			ReadOnlyHint:    true,
 			DestructiveHint: ptrBool(false),
@@ -120,7 +161,7 @@ func ResolveRDSTool() *mcp.Tool {
 
 // HandleResolveRDS is the MCP tool handler for the kube_compare_resolve_rds tool.
 // It uses typed input via the ResolveRDSInput struct.
-func HandleResolveRDS(ctx context.Context, req *mcp.CallToolRequest, input ResolveRDSInput) (toolResult *mcp.CallToolResult, resolveOutput ResolveRDSOutput, toolErr error) {
+func HandleResolveRDS(ctx context.Context, req *mcp.CallToolRequest, input ResolveRDSInput) (toolResult *mcp.CallToolResult, resolveOutput *ResolveRDSResult, toolErr error) {
 	requestID := generateRequestID()
 	logger := slog.Default().With("requestID", requestID)
 	start := time.Now()
@@ -141,25 +182,50 @@ func HandleResolveRDS(ctx context.Context, req *mcp.CallToolRequest, input Resol
 
 	if err := ctx.Err(); err != nil {
 		logger.Warn("Request canceled", "error", err)
-		return newToolResultError(formatErrorForUser(ErrContextCanceled)), ResolveRDSOutput{}, nil
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
 	}
 
+	resolvedKubeconfig, err := resolveKubeconfigInput(ctx, input.Kubeconfig, input.KubeconfigSecret)
+	if err != nil {
+		logger.Debug("Kubeconfig Secret lookup failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+	input.Kubeconfig = resolvedKubeconfig
+
 	// Validate context requires kubeconfig
 	if input.Context != "" && input.Kubeconfig == "" {
 		err := NewValidationError("context",
 			"'context' parameter requires 'kubeconfig' to also be provided",
 			"Provide a kubeconfig along with the context name")
 		logger.Debug("Validation failed", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ResolveRDSOutput{}, nil
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	if err := validateOCPVersion(input.OCPVersion); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	if err := validateRDSChannel(input.Channel, input.OCPVersion); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	// The SDK validates the enum, but direct callers bypass it
+	rdsType, err := normalizeRDSType(input.RDSType)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
 	}
 
 	// Convert typed input to ResolveRDSArgs
-	// Note: SDK validates enum constraint, so RDSType is already lowercase ("core" or "ran")
 	args := &ResolveRDSArgs{
 		Kubeconfig: input.Kubeconfig,
 		Context:    input.Context,
-		RDSType:    input.RDSType,
+		RDSType:    rdsType,
 		OCPVersion: input.OCPVersion,
+		Channel:    input.Channel,
+		PinDigest:  input.PinDigest,
 	}
 
 	logger.Debug("Parsed kube_compare_resolve_rds arguments",
@@ -167,18 +233,20 @@ func HandleResolveRDS(ctx context.Context, req *mcp.CallToolRequest, input Resol
 		"hasKubeconfig", args.Kubeconfig != "",
 		"context", args.Context,
 		"explicitOCPVersion", args.OCPVersion,
+		"channel", args.Channel,
+		"pinDigest", args.PinDigest,
 	)
 
 	resultData, err := ResolveRDSInternal(ctx, args)
 	if err != nil {
 		logger.Debug("Failed to find RDS reference", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ResolveRDSOutput{}, nil
+		return newToolResultErrorFor(err), nil, nil
 	}
 
 	jsonOutput, err := json.MarshalIndent(resultData, "", "  ")
 	if err != nil {
 		logger.Error("Failed to marshal result", "error", err)
-		return newToolResultError(fmt.Sprintf("Failed to format result: %v", err)), ResolveRDSOutput{}, nil
+		return newToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil, nil
 	}
 
 	duration := time.Since(start)
@@ -189,9 +257,21 @@ func HandleResolveRDS(ctx context.Context, req *mcp.CallToolRequest, input Resol
 		"rhelVersion", resultData.RHELVersion,
 		"reference", resultData.Reference,
 		"validated", resultData.Validated,
+		"floating", resultData.Floating,
 	)
 
-	return newToolResultText(string(jsonOutput)), ResolveRDSOutput{}, nil
+	return newToolResultText(string(jsonOutput)), resultData, nil
+}
+
+// normalizeRDSType trims and lowercases rdsType and checks that it is a known RDS type.
+func normalizeRDSType(rdsType string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(rdsType))
+	if _, ok := getRDSConfig(normalized); !ok {
+		return "", NewValidationError("rds_type",
+			fmt.Sprintf("unknown RDS type '%s'", rdsType),
+			"Supported RDS types are "+strings.Join(getRDSTypes(), ", "))
+	}
+	return normalized, nil
 }
 
 // ResolveRDSInternal is the core logic for finding RDS references.
@@ -199,11 +279,64 @@ func ResolveRDSInternal(ctx context.Context, args *ResolveRDSArgs) (*ResolveRDSR
 	return defaultReferenceService.ResolveRDS(ctx, args)
 }
 
-// ResolveRDS finds the RDS reference for the given arguments.
+// ResolveRDSTypesInternal resolves references for several RDS types using the default service.
+func ResolveRDSTypesInternal(ctx context.Context, args *ResolveRDSArgs, rdsTypes []string) ([]*ResolveRDSResult, error) {
+	return defaultReferenceService.ResolveRDSTypes(ctx, args, rdsTypes)
+}
+
+// ResolveRDSTypes finds the RDS reference for each of rdsTypes, in order.
+// The cluster version is detected once (unless args.OCPVersion is set) and
+// reused for every type, along with any in-progress upgrade. args.RDSType is ignored.
+func (s *ReferenceService) ResolveRDSTypes(ctx context.Context, args *ResolveRDSArgs, rdsTypes []string) ([]*ResolveRDSResult, error) {
+	results := make([]*ResolveRDSResult, 0, len(rdsTypes))
+	typeArgs := *args
+
+	for i, rdsType := range rdsTypes {
+		typeArgs.RDSType = rdsType
+		result, err := s.ResolveRDS(ctx, &typeArgs)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			// Carry over the upgrade state detected for the first type
+			result.DesiredVersion = results[0].DesiredVersion
+			result.UpgradeInProgress = results[0].UpgradeInProgress
+		}
+		results = append(results, result)
+
+		// Reuse the detected version for the remaining types
+		typeArgs.OCPVersion = result.ClusterVersion
+	}
+
+	return results, nil
+}
+
+// ResolveRDS finds the RDS reference for the given arguments. With args.PinDigest, the
+// reference names the image by the digest its tag resolves to.
 func (s *ReferenceService) ResolveRDS(ctx context.Context, args *ResolveRDSArgs) (*ResolveRDSResult, error) {
+	result, err := s.resolveRDSTag(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if args.ReferenceOCPVersion != "" {
+		result.ReferenceVersion = ExtractMajorMinorVersion(args.ReferenceOCPVersion)
+	}
+	if !args.PinDigest {
+		return result, nil
+	}
+	return s.pinRDSDigest(ctx, result)
+}
+
+// resolveRDSTag finds the RDS reference for the given arguments, naming the image by
+// its version or channel alias tag.
+func (s *ReferenceService) resolveRDSTag(ctx context.Context, args *ResolveRDSArgs) (*ResolveRDSResult, error) {
 	logger := slog.Default()
 
-	var clusterVersion string
+	if args.Channel != "" {
+		return s.resolveRDSChannel(ctx, args)
+	}
+
+	var clusterVersion, desiredVersion string
 
 	// Use explicit version if provided, otherwise auto-detect from cluster
 	if args.OCPVersion != "" {
@@ -228,14 +361,13 @@ func (s *ReferenceService) ResolveRDS(ctx context.Context, args *ResolveRDSArgs)
 			}
 		} else {
 			logger.Debug("Using in-cluster config for version detection")
-			restConfig, err = rest.InClusterConfig()
+			restConfig, err = resolveTargetInClusterConfig(ctx, "cluster-config",
+				"No kubeconfig provided: either provide a kubeconfig, specify ocp_version explicitly, or run the server inside a Kubernetes cluster.")
 			if err != nil {
-				return nil, NewCompareError("cluster-config",
-					fmt.Errorf("failed to get in-cluster config: %w", err),
-					"No kubeconfig provided and in-cluster config not available. "+
-						"Either provide a kubeconfig, specify ocp_version explicitly, or ensure the server is running inside a Kubernetes cluster.")
+				return nil, err
 			}
 		}
+		logger = withClusterIdentity(logger, restConfig)
 
 		// Get cluster version using the injected factory
 		clusterClient, err := s.ClusterFactory.NewClient(restConfig)
@@ -245,18 +377,39 @@ func (s *ReferenceService) ResolveRDS(ctx context.Context, args *ResolveRDSArgs)
 				"Verify the kubeconfig is valid and has cluster access")
 		}
 
-		clusterVersion, err = clusterClient.GetClusterVersion(ctx)
+		versionInfo, err := clusterClient.GetClusterVersion(ctx)
 		if err != nil {
 			return nil, NewCompareError("cluster-version",
 				fmt.Errorf("failed to get ClusterVersion: %w", err),
 				"Verify the cluster is an OpenShift cluster and you have permission to read ClusterVersion")
 		}
 
+		// During an upgrade the desired version is the target, not what is running
+		clusterVersion = versionInfo.Current()
+		if versionInfo.Upgrading {
+			desiredVersion = versionInfo.Desired
+			logger.Warn("Cluster upgrade in progress, using the last completed version",
+				"completedVersion", versionInfo.Completed,
+				"desiredVersion", versionInfo.Desired,
+			)
+		}
+
 		logger.Debug("Got cluster version", "version", clusterVersion)
 	}
 
 	ocpVersion := ExtractMajorMinorVersion(clusterVersion)
-	cfg := rdsConfigs[args.RDSType]
+	if args.ReferenceOCPVersion != "" {
+		// Select the reference for another version, such as an upgrade target, while
+		// still reporting the version the cluster runs
+		ocpVersion = ExtractMajorMinorVersion(args.ReferenceOCPVersion)
+		logger.Debug("Using reference OCP version", "referenceVersion", ocpVersion, "clusterVersion", clusterVersion)
+	}
+	cfg, ok := getRDSConfig(args.RDSType)
+	if !ok {
+		return nil, NewValidationError("rds_type",
+			fmt.Sprintf("unknown RDS type '%s'", args.RDSType),
+			"Supported RDS types are "+strings.Join(getRDSTypes(), ", "))
+	}
 
 	if cfg.MinOCPVersion != "" && CompareVersionTags(ocpVersion, cfg.MinOCPVersion) < 0 {
 		return nil, NewValidationError(
@@ -267,7 +420,14 @@ func (s *ReferenceService) ResolveRDS(ctx context.Context, args *ResolveRDSArgs)
 		)
 	}
 
-	rhelVariant, repoRef, versionTags, err := s.findBestRHELVariant(ctx, cfg, ocpVersion)
+	if s.Cache != nil {
+		if cached, ok := s.Cache.get(args.RDSType, cfg, ocpVersion); ok {
+			logger.Debug("Using cached RDS reference", "rdsType", args.RDSType, "ocpVersion", ocpVersion)
+			return withClusterVersion(cached, clusterVersion, desiredVersion), nil
+		}
+	}
+
+	rhelVariant, versionTags, selection, err := s.findBestRHELVariant(ctx, cfg, ocpVersion)
 	if err != nil {
 		logger.Debug("Failed to find RHEL variant", "error", err)
 		return nil, err
@@ -275,33 +435,182 @@ func (s *ReferenceService) ResolveRDS(ctx context.Context, args *ResolveRDSArgs)
 
 	logger.Debug("Found best RHEL variant",
 		"rhelVariant", rhelVariant,
-		"repoRef", repoRef,
 		"ocpVersion", ocpVersion,
 	)
 
 	reference := BuildRDSReference(args.RDSType, rhelVariant, ocpVersion)
 
-	// Validate image accessibility before returning
-	imageRef := fmt.Sprintf("%s:%s", repoRef, ocpVersion)
-	if err := s.Registry.HeadImage(ctx, imageRef); err != nil {
+	// Decompose the reference the same way RunCompare will, so clients can use
+	// the image and metadata path directly with other tooling.
+	refImage, metadataPath, err := ParseContainerReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ResolveRDSResult{
+		RHELVersion:       rhelVariant,
+		RDSType:           args.RDSType,
+		Reference:         reference,
+		ImageRef:          refImage,
+		MetadataPath:      metadataPath,
+		AvailableVersions: versionTags,
+		Validated:         true,
+		VariantSelection:  selection,
+	}
+	if s.Cache != nil {
+		s.Cache.put(args.RDSType, cfg, ocpVersion, result)
+	}
+	return withClusterVersion(result, clusterVersion, desiredVersion), nil
+}
+
+// withClusterVersion sets the cluster version fields of result, which are not cached
+// with the reference since they belong to the cluster of one call.
+func withClusterVersion(result *ResolveRDSResult, clusterVersion, desiredVersion string) *ResolveRDSResult {
+	result.ClusterVersion = clusterVersion
+	result.DesiredVersion = desiredVersion
+	result.UpgradeInProgress = desiredVersion != ""
+	return result
+}
+
+// pinRDSDigest replaces the tag of result's reference with the digest the tag resolves
+// to now, so that comparisons against the reference keep using the same image. A
+// pinned channel alias is no longer floating.
+func (s *ReferenceService) pinRDSDigest(ctx context.Context, result *ResolveRDSResult) (*ResolveRDSResult, error) {
+	digest, err := s.digest(ctx, result.ImageRef)
+	if err != nil {
 		return nil, NewCompareError("registry",
-			fmt.Errorf("rds image found but not accessible: %s", ocpVersion),
-			fmt.Sprintf("Image: %s\nError: %v\n\nThis may be an authentication issue. Ensure the server has credentials for registry.redhat.io.",
-				imageRef, err))
+			fmt.Errorf("failed to resolve digest of rds image %s", result.ImageRef),
+			fmt.Sprintf("Image: %s\nError: %v\n\nRetry without pin_digest to use the tag reference.", result.ImageRef, err))
+	}
+
+	reference := BuildRDSDigestReference(result.RDSType, result.RHELVersion, digest)
+	refImage, metadataPath, err := ParseContainerReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Default().Debug("Pinned RDS reference to digest", "imageRef", result.ImageRef, "digest", digest)
+	result.Reference = reference
+	result.ImageRef = refImage
+	result.MetadataPath = metadataPath
+	result.Digest = digest
+	result.Floating = false
+	return result, nil
+}
+
+// resolveRDSChannel finds the RDS reference whose image tag is the channel alias
+// args.Channel. The cluster version is not detected, since the alias, not the
+// version, selects the image.
+func (s *ReferenceService) resolveRDSChannel(ctx context.Context, args *ResolveRDSArgs) (*ResolveRDSResult, error) {
+	cfg, ok := getRDSConfig(args.RDSType)
+	if !ok {
+		return nil, NewValidationError("rds_type",
+			fmt.Sprintf("unknown RDS type '%s'", args.RDSType),
+			"Supported RDS types are "+strings.Join(getRDSTypes(), ", "))
+	}
+
+	rhelVariant, versionTags, err := s.findChannelRHELVariant(ctx, cfg, args.Channel)
+	if err != nil {
+		return nil, err
+	}
+	slog.Default().Warn("Resolved RDS reference from a floating channel alias; comparisons against it are not reproducible",
+		"rdsType", args.RDSType,
+		"channel", args.Channel,
+		"rhelVariant", rhelVariant,
+	)
+
+	reference := BuildRDSReference(args.RDSType, rhelVariant, args.Channel)
+	refImage, metadataPath, err := ParseContainerReference(reference)
+	if err != nil {
+		return nil, err
 	}
 
 	return &ResolveRDSResult{
-		ClusterVersion:    clusterVersion,
 		RHELVersion:       rhelVariant,
 		RDSType:           args.RDSType,
 		Reference:         reference,
+		ImageRef:          refImage,
+		MetadataPath:      metadataPath,
 		AvailableVersions: versionTags,
 		Validated:         true,
+		Channel:           args.Channel,
+		Floating:          true,
 	}, nil
 }
 
+// findChannelRHELVariant finds the first RHEL variant of cfg whose tags include the
+// channel alias and confirms its image is accessible. It returns the variant and the
+// version tags of its repository.
+func (s *ReferenceService) findChannelRHELVariant(ctx context.Context, cfg RDSConfig, channel string) (rhelVariant string, versionTags []string, err error) {
+	logger := slog.Default()
+
+	var lastErr error
+	var aliasesFound []string
+
+	listCtx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+
+	for _, rhel := range cfg.RHELVariants {
+		repoRef := fmt.Sprintf("%s-%s", cfg.ImageBase, rhel)
+		logger.Debug("Trying RHEL variant for channel", "variant", rhel, "repo", repoRef, "channel", channel)
+
+		tags, err := s.listTags(listCtx, repoRef)
+		if err != nil {
+			logger.Debug("Failed to list tags for variant", "variant", rhel, "error", err)
+			lastErr = wrapRegistryError(err, repoRef)
+			continue
+		}
+
+		if len(aliasesFound) == 0 {
+			aliasesFound = channelTags(tags)
+		}
+
+		if ContainsTag(tags, channel) {
+			imageRef := fmt.Sprintf("%s:%s", repoRef, channel)
+			if err := s.headImage(ctx, imageRef); err != nil {
+				return "", nil, NewCompareError("registry",
+					fmt.Errorf("rds image found but not accessible: %s", channel),
+					fmt.Sprintf("Image: %s\nError: %v\n\nThis may be an authentication issue. Ensure the server has credentials for registry.redhat.io.",
+						imageRef, err))
+			}
+			return rhel, FilterVersionTags(tags), nil
+		}
+	}
+
+	if lastErr != nil {
+		return "", nil, NewCompareError("registry",
+			fmt.Errorf("could not find RDS image for channel %s", channel),
+			fmt.Sprintf("Failed to access container registry: %v\n\nThis may be an authentication issue.", lastErr))
+	}
+
+	available := "none"
+	if len(aliasesFound) > 0 {
+		available = strings.Join(aliasesFound, ", ")
+	}
+	return "", nil, NewCompareError("registry",
+		fmt.Errorf("rds image not found for channel %s", channel),
+		fmt.Sprintf("Expected image tag: %s\nRDS type image base: %s\nTried RHEL variants: %v\n\nAvailable channel aliases: %s\n\nUse ocp_version to resolve a specific version instead.",
+			channel, cfg.ImageBase, cfg.RHELVariants, available))
+}
+
+// channelTags returns the tags that may be channel aliases: those that are neither
+// version tags nor the sha256-<digest> tags of signatures and attestations, sorted.
+func channelTags(tags []string) []string {
+	aliases := []string{}
+	for _, tag := range tags {
+		if !versionTagRegex.MatchString(tag) && !strings.HasPrefix(tag, "sha256-") {
+			aliases = append(aliases, tag)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
 // findBestRHELVariant finds the best RHEL variant for a given RDS config and OCP version.
-func (s *ReferenceService) findBestRHELVariant(ctx context.Context, cfg RDSConfig, ocpVersion string) (rhelVariant, repoRef string, versionTags []string, err error) {
+// The image of the first variant whose tags include the version is confirmed to be
+// accessible before returning, so later variants are not listed. It also returns each
+// variant tried, in order.
+func (s *ReferenceService) findBestRHELVariant(ctx context.Context, cfg RDSConfig, ocpVersion string) (rhelVariant string, versionTags []string, selection []RHELVariantAttempt, err error) {
 	logger := slog.Default()
 
 	var lastErr error
@@ -313,13 +622,17 @@ func (s *ReferenceService) findBestRHELVariant(ctx context.Context, cfg RDSConfi
 	for _, rhel := range cfg.RHELVariants {
 		repoRef := fmt.Sprintf("%s-%s", cfg.ImageBase, rhel)
 		logger.Debug("Trying RHEL variant", "variant", rhel, "repo", repoRef)
+		attempt := RHELVariantAttempt{RHELVariant: rhel, Repository: repoRef}
 
-		tags, err := s.Registry.ListTags(listCtx, repoRef)
+		tags, err := s.listTags(listCtx, repoRef)
 		if err != nil {
 			logger.Debug("Failed to list tags for variant", "variant", rhel, "error", err)
 			lastErr = wrapRegistryError(err, repoRef)
+			attempt.Error = lastErr.Error()
+			selection = append(selection, attempt)
 			continue
 		}
+		attempt.Reachable = true
 
 		versions := FilterVersionTags(tags)
 		logger.Debug("Found version tags", "variant", rhel, "count", len(versions), "versions", versions)
@@ -331,22 +644,62 @@ func (s *ReferenceService) findBestRHELVariant(ctx context.Context, cfg RDSConfi
 
 		if ContainsTag(versions, ocpVersion) {
 			logger.Debug("Found matching RHEL variant", "variant", rhel, "version", ocpVersion)
-			return rhel, repoRef, versions, nil
+
+			imageRef := fmt.Sprintf("%s:%s", repoRef, ocpVersion)
+			if err := s.headImage(ctx, imageRef); err != nil {
+				return "", nil, nil, NewCompareError("registry",
+					fmt.Errorf("rds image found but not accessible: %s", ocpVersion),
+					fmt.Sprintf("Image: %s\nError: %v\n\nThis may be an authentication issue. Ensure the server has credentials for registry.redhat.io.",
+						imageRef, err))
+			}
+			attempt.VersionPresent = true
+			attempt.Chosen = true
+			return rhel, versions, append(selection, attempt), nil
 		}
+		selection = append(selection, attempt)
 	}
 
 	if lastErr != nil {
-		return "", "", nil, NewCompareError("registry",
+		return "", nil, nil, NewCompareError("registry",
 			fmt.Errorf("could not find RDS image for OpenShift %s", ocpVersion),
 			fmt.Sprintf("Failed to access container registry: %v\n\nThis may be an authentication issue.", lastErr))
 	}
 
-	return "", "", nil, NewCompareError("registry",
+	return "", nil, nil, NewCompareError("registry",
 		fmt.Errorf("rds image not found for OpenShift %s", ocpVersion),
 		fmt.Sprintf("Expected image tag: %s\nRDS type image base: %s\nTried RHEL variants: %v\n\nAvailable versions:\n  %s\n\nThe requested version may not be released yet.",
 			ocpVersion, cfg.ImageBase, cfg.RHELVariants, strings.Join(allVersionsFound, "\n  ")))
 }
 
+// digest resolves imageRef to its manifest digest, retrying transient registry failures.
+func (s *ReferenceService) digest(ctx context.Context, imageRef string) (string, error) {
+	var digest string
+	err := retryRegistryCall(ctx, "digest", func(ctx context.Context) error {
+		var err error
+		digest, err = s.Registry.Digest(ctx, imageRef)
+		return err
+	})
+	return digest, err
+}
+
+// listTags lists the tags of repoRef, retrying transient registry failures.
+func (s *ReferenceService) listTags(ctx context.Context, repoRef string) ([]string, error) {
+	var tags []string
+	err := retryRegistryCall(ctx, "list-tags", func(ctx context.Context) error {
+		var err error
+		tags, err = s.Registry.ListTags(ctx, repoRef)
+		return err
+	})
+	return tags, err
+}
+
+// headImage checks that imageRef exists, retrying transient registry failures.
+func (s *ReferenceService) headImage(ctx context.Context, imageRef string) error {
+	return retryRegistryCall(ctx, "head-image", func(ctx context.Context) error {
+		return s.Registry.HeadImage(ctx, imageRef)
+	})
+}
+
 // wrapRegistryError wraps registry errors with user-friendly messages.
 func wrapRegistryError(err error, repoRef string) error {
 	errStr := err.Error()
@@ -371,10 +724,69 @@ type ResolveRDSArgs struct {
 	Context    string
 	RDSType    string
 	OCPVersion string // Optional: explicit OpenShift version
+	Channel    string // Optional: channel alias tag to resolve instead of a version
+	PinDigest  bool   // Optional: pin the reference to the digest of the resolved tag
+	// Optional: OpenShift version to select the reference for instead of the cluster's
+	ReferenceOCPVersion string
 }
 
-// ExtractMajorMinorVersion extracts the major.minor version from a full version string.
+// validateOCPVersion checks that an explicit OpenShift version looks like 4.18, 4.18.3, or 4.20.0-rc.1,
+// optionally prefixed with "v".
+// An empty version is valid and means the version is detected from the cluster.
+func validateOCPVersion(version string) error {
+	if version == "" || ocpVersionRegex.MatchString(version) {
+		return nil
+	}
+	return NewValidationError("ocp_version",
+		fmt.Sprintf("invalid OpenShift version '%s'", version),
+		"Use MAJOR.MINOR or MAJOR.MINOR.PATCH, e.g. 4.18 or 4.18.3")
+}
+
+// validateReferenceOCPVersion checks that a reference OpenShift version looks like an
+// explicit version and is not combined with a channel alias, which selects the
+// reference itself. An empty version is valid.
+func validateReferenceOCPVersion(version, channel string) error {
+	switch {
+	case version == "":
+		return nil
+	case channel != "":
+		return NewValidationError("reference_ocp_version",
+			"'reference_ocp_version' and 'channel' cannot both be set",
+			"Set reference_ocp_version to compare against the RDS of a specific version, or channel to follow an alias")
+	case !ocpVersionRegex.MatchString(version):
+		return NewValidationError("reference_ocp_version",
+			fmt.Sprintf("invalid OpenShift version '%s'", version),
+			"Use MAJOR.MINOR or MAJOR.MINOR.PATCH, e.g. 4.19 or 4.19.2")
+	}
+	return nil
+}
+
+// validateRDSChannel checks that a channel alias is an image tag that is not a version
+// tag, and that it is not combined with an explicit version. An empty channel is valid.
+func validateRDSChannel(channel, ocpVersion string) error {
+	switch {
+	case channel == "":
+		return nil
+	case ocpVersion != "":
+		return NewValidationError("channel",
+			"'channel' and 'ocp_version' cannot both be set",
+			"Set ocp_version to pin a version, or channel to follow an alias such as stable")
+	case !imageTagRegex.MatchString(channel):
+		return NewValidationError("channel",
+			fmt.Sprintf("invalid channel '%s'", channel),
+			"A channel is an image tag such as stable, fast, or eus-4.18")
+	case versionTagRegex.MatchString(channel) || ocpVersionRegex.MatchString(channel):
+		return NewValidationError("channel",
+			fmt.Sprintf("'%s' is a version, not a channel alias", channel),
+			"Use ocp_version to resolve a specific version")
+	}
+	return nil
+}
+
+// ExtractMajorMinorVersion extracts the major.minor version from a full version string,
+// which may carry a "v" prefix.
 func ExtractMajorMinorVersion(version string) string {
+	version = strings.TrimPrefix(version, "v")
 	matches := majorMinorVersionRegex.FindStringSubmatch(version)
 	if len(matches) >= 3 {
 		return fmt.Sprintf("v%s.%s", matches[1], matches[2])
@@ -383,14 +795,24 @@ func ExtractMajorMinorVersion(version string) string {
 	return "v" + version
 }
 
-// BuildRDSReference constructs the container reference string for an RDS type.
+// BuildRDSReference constructs the container reference string for an RDS type, which
+// may be a built-in type or one defined in the RDS config file.
 func BuildRDSReference(rdsType, rhelVariant, ocpVersion string) string {
-	cfg := rdsConfigs[rdsType]
+	cfg, _ := getRDSConfig(rdsType)
 	// Build image reference with RHEL variant: e.g., openshift-telco-core-rds-rhel9:v4.18
 	imageRef := fmt.Sprintf("%s-%s:%s", cfg.ImageBase, rhelVariant, ocpVersion)
 	return fmt.Sprintf("container://%s:%s", imageRef, cfg.Path)
 }
 
+// BuildRDSDigestReference constructs the container reference for an RDS image pinned
+// to a manifest digest.
+func BuildRDSDigestReference(rdsType, rhelVariant, digest string) string {
+	cfg, _ := getRDSConfig(rdsType)
+	// e.g. openshift-telco-core-rds-rhel9@sha256:...
+	imageRef := fmt.Sprintf("%s-%s@%s", cfg.ImageBase, rhelVariant, digest)
+	return fmt.Sprintf("container://%s:%s", imageRef, cfg.Path)
+}
+
 // FilterVersionTags filters a list of tags to only include version tags.
 func FilterVersionTags(tags []string) []string {
 	versionTags := []string{}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRDSCacheTTL = 10 * time.Minute
+
+// getRDSCacheTTL returns how long a resolved RDS reference is reused before the
+// registry is asked again. Zero disables the cache.
+// Can be configured via KUBE_COMPARE_MCP_RDS_CACHE_TTL environment variable.
+func getRDSCacheTTL() time.Duration {
+	if val := os.Getenv("KUBE_COMPARE_MCP_RDS_CACHE_TTL"); val != "" {
+		if duration, err := time.ParseDuration(val); err == nil && duration >= 0 {
+			return duration
+		}
+	}
+	return defaultRDSCacheTTL
+}
+
+// RDSCache holds the RDS references resolved from the registry for an RDS type, its
+// configuration, and an OpenShift version, so repeated resolutions skip listing tags
+// and checking the image. Only successful resolutions are cached. It is safe for
+// concurrent use.
+type RDSCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[rdsCacheKey]rdsCacheEntry
+}
+
+type rdsCacheKey struct {
+	rdsType string
+	// config identifies the RDSConfig the reference was resolved with, so a reloaded
+	// RDS config file does not serve references built from the previous one
+	config     string
+	ocpVersion string
+}
+
+// newRDSCacheKey returns the cache key of the reference resolved for rdsType with cfg
+// at ocpVersion.
+func newRDSCacheKey(rdsType string, cfg RDSConfig, ocpVersion string) rdsCacheKey {
+	return rdsCacheKey{
+		rdsType:    rdsType,
+		config:     strings.Join([]string{cfg.ImageBase, cfg.Path, strings.Join(cfg.RHELVariants, ","), cfg.MinOCPVersion}, "\x00"),
+		ocpVersion: ocpVersion,
+	}
+}
+
+type rdsCacheEntry struct {
+	result  ResolveRDSResult
+	expires time.Time
+}
+
+// NewRDSCache returns a cache whose entries expire after ttl. A cache with a ttl of
+// zero stores nothing.
+func NewRDSCache(ttl time.Duration) *RDSCache {
+	return &RDSCache{ttl: ttl, entries: make(map[rdsCacheKey]rdsCacheEntry)}
+}
+
+// get returns a copy of the reference resolved for rdsType with cfg at ocpVersion,
+// when one was stored less than the cache's ttl ago.
+func (c *RDSCache) get(rdsType string, cfg RDSConfig, ocpVersion string) (*ResolveRDSResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := newRDSCacheKey(rdsType, cfg, ocpVersion)
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	result := entry.result
+	result.AvailableVersions = slices.Clone(entry.result.AvailableVersions)
+	return &result, true
+}
+
+// put stores a copy of the reference resolved for rdsType with cfg at ocpVersion. The
+// variant selection is not stored: it describes the registry queries of one resolution,
+// which a cached reference skips.
+func (c *RDSCache) put(rdsType string, cfg RDSConfig, ocpVersion string, result *ResolveRDSResult) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *result
+	stored.AvailableVersions = slices.Clone(result.AvailableVersions)
+	stored.VariantSelection = nil
+	c.entries[newRDSCacheKey(rdsType, cfg, ocpVersion)] = rdsCacheEntry{
+		result:  stored,
+		expires: time.Now().Add(c.ttl),
+	}
+}
@@ -6,27 +6,87 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openshift/kube-compare/pkg/compare"
 )
 
+// DefaultRDSMismatchThreshold is the default share of missing reference templates
+// above which a comparison is flagged as a likely wrong rds_type.
+const DefaultRDSMismatchThreshold = 0.5
+
+// getRDSMismatchThreshold returns the share of missing reference templates, between 0
+// and 1, above which kube_compare_validate_rds warns that the rds_type may be wrong.
+// Can be configured via KUBE_COMPARE_MCP_RDS_MISMATCH_THRESHOLD environment variable.
+// A value of 1 disables the warning.
+func getRDSMismatchThreshold() float64 {
+	if envVal := os.Getenv("KUBE_COMPARE_MCP_RDS_MISMATCH_THRESHOLD"); envVal != "" {
+		if threshold, err := strconv.ParseFloat(envVal, 64); err == nil && threshold > 0 && threshold <= 1 {
+			return threshold
+		}
+	}
+	return DefaultRDSMismatchThreshold
+}
+
 // ValidateRDSResult is the structured response for the kube_compare_validate_rds tool.
 type ValidateRDSResult struct {
 	RDSReference *ResolveRDSResult `json:"rds_reference"`
 	Comparison   json.RawMessage   `json:"comparison"`
+	Warning      string            `json:"warning,omitempty"`
+
+	CommandEquivalent string             `json:"command_equivalent,omitempty"`
+	Coverage          *ReferenceCoverage `json:"coverage,omitempty"`
+
+	// referenceMetadata is returned as separate content blocks, not in the JSON result
+	referenceMetadata *ReferenceMetadata
+}
+
+// ValidateRDSMultiResult is the response for a kube_compare_validate_rds call with several RDS types.
+// Results are keyed by RDS type and share a single detected cluster version.
+type ValidateRDSMultiResult struct {
+	ClusterVersion string                        `json:"cluster_version"`
+	Results        map[string]*ValidateRDSResult `json:"results,omitempty"`
+	Summaries      map[string]json.RawMessage    `json:"summaries,omitempty"`
 }
 
 // ValidateRDSInput defines the typed input for the kube_compare_validate_rds tool.
 type ValidateRDSInput struct {
-	Kubeconfig   string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for connecting to the target cluster. If omitted, uses in-cluster config."`
-	Context      string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig"`
-	RDSType      string `json:"rds_type" jsonschema:"RDS type to compare against: core for Telco Core RDS, ran for Telco RAN DU RDS, or hub for Telco Hub RDS"`
-	OutputFormat string `json:"output_format,omitempty" jsonschema:"Output format for the comparison results"`
-	AllResources bool   `json:"all_resources,omitempty" jsonschema:"Compare all resources of types mentioned in the reference"`
+	Kubeconfig   string   `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for connecting to the target cluster. If omitted, uses in-cluster config."`
+	Context      string   `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig"`
+	RDSType      string   `json:"rds_type,omitempty" jsonschema:"RDS type to compare against: core for Telco Core RDS, ran for Telco RAN DU RDS, or hub for Telco Hub RDS"`
+	RDSTypes     []string `json:"rds_types,omitempty" jsonschema:"Several RDS types to compare against in one call, detecting the cluster version once. Use instead of rds_type."`
+	OCPVersion   string   `json:"ocp_version,omitempty" jsonschema:"OpenShift version (e.g. 4.18 or 4.20.0). Skips cluster version detection when set."`
+	Channel      string   `json:"channel,omitempty" jsonschema:"Floating channel alias tag of the RDS images, such as stable, fast, or eus-4.18, to compare against instead of the cluster's version. The image an alias names can change, so the result is marked floating. Use instead of ocp_version."`
+	OutputFormat string   `json:"output_format,omitempty" jsonschema:"Output format for the comparison results"`
+	AllResources bool     `json:"all_resources,omitempty" jsonschema:"Compare all resources of types mentioned in the reference"`
+
+	ReferenceOCPVersion string `json:"reference_ocp_version,omitempty" jsonschema:"OpenShift version to select the RDS reference for, such as an upgrade target (e.g. 4.20), while comparing the cluster as it runs now. The result notes the version mismatch. Cannot be combined with channel."`
+
+	IncludeReferenceMetadata bool   `json:"include_reference_metadata,omitempty" jsonschema:"Also return the RDS metadata.yaml each comparison ran against, with its SHA-256 and image digest. Large metadata is returned as an embedded resource."`
+	Profile                  string `json:"profile,omitempty" jsonschema:"Name of a server-side comparison profile whose options are used as defaults. Options set in this call take precedence."`
+
+	KubeconfigSecret string `json:"kubeconfig_secret,omitempty" jsonschema:"Secret holding the kubeconfig, as namespace/name or namespace/name/key (key defaults to kubeconfig). Read by the MCP server from its own cluster; must be enabled by the server. Use instead of kubeconfig."`
+
+	ReferenceTimeout string `json:"reference_timeout,omitempty" jsonschema:"Limit on the combined time to validate, pull, and extract the RDS references, as a duration such as '90s' or '5m' (at most 30m). Independent of the overall tool timeout."`
+
+	IncludeCommandEquivalent bool `json:"include_command_equivalent,omitempty" jsonschema:"Also return the kube-compare CLI command equivalent to each comparison, with the kubeconfig redacted."`
+
+	IgnoreVolatileFields bool `json:"ignore_volatile_fields,omitempty" jsonschema:"Drop diffs that only change volatile fields, such as metadata.resourceVersion, metadata.managedFields, and status. The fields are configured on the server."`
+
+	IncludeReferenceCoverage bool `json:"include_reference_coverage,omitempty" jsonschema:"Also return the templates each RDS reference declares and the distinct resource kinds they cover."`
+
+	Platform string `json:"platform,omitempty" jsonschema:"Platform to pull from the multi-platform RDS images, as os/arch or os/arch/variant (default linux/amd64)."`
+
+	Verbosity string `json:"verbosity,omitempty" jsonschema:"Detail of the comparison output: terse lists only the CRs that differ with the summary counts, normal (the default) adds their field diffs, and full also includes managedFields in the diffs. Ignored with output_format summary."`
 }
 
 // ValidateRDSOutput is an empty output struct (tool returns text content).
@@ -52,6 +112,7 @@ type ValidateRDSArgs struct {
 	Kubeconfig   string
 	Context      string
 	RDSType      string
+	OCPVersion   string
 	OutputFormat string
 	AllResources bool
 }
@@ -79,25 +140,71 @@ func HandleValidateRDS(ctx context.Context, req *mcp.CallToolRequest, input Vali
 
 	if err := ctx.Err(); err != nil {
 		logger.Warn("Request canceled", "error", err)
-		return newToolResultError(formatErrorForUser(ErrContextCanceled)), ValidateRDSOutput{}, nil
+		return newToolResultErrorFor(ErrContextCanceled), ValidateRDSOutput{}, nil
 	}
 
+	resolvedKubeconfig, err := resolveKubeconfigInput(ctx, input.Kubeconfig, input.KubeconfigSecret)
+	if err != nil {
+		logger.Debug("Kubeconfig Secret lookup failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
+	input.Kubeconfig = resolvedKubeconfig
+
 	// Validate context requires kubeconfig
 	if input.Context != "" && input.Kubeconfig == "" {
 		err := NewValidationError("context",
 			"'context' parameter requires 'kubeconfig' to also be provided",
 			"Provide a kubeconfig along with the context name")
 		logger.Debug("Validation failed", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ValidateRDSOutput{}, nil
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
+
+	// The comparison always runs against a cluster, even when ocp_version is set
+	if input.Kubeconfig == "" {
+		if err := requireExplicitKubeconfig("cluster-config"); err != nil {
+			logger.Debug("Validation failed", "error", err)
+			return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+		}
+	}
+
+	rdsTypes, err := selectRDSTypes(input.RDSType, input.RDSTypes)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
 	}
 
-	// Note: SDK validates enum constraint, so RDSType is already lowercase ("core" or "ran")
+	if err := validateOCPVersion(input.OCPVersion); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
+
+	if err := validateRDSChannel(input.Channel, input.OCPVersion); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
+
+	if err := validateReferenceOCPVersion(input.ReferenceOCPVersion, input.Channel); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
+
+	referenceTimeout, err := parseReferenceTimeout(input.ReferenceTimeout)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
+
+	platform, err := parsePlatform(input.Platform)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
 
 	// Auto-detect and process kubeconfig format
 	kubeconfigData, err := DecodeOrParseKubeconfig(input.Kubeconfig)
 	if err != nil {
 		logger.Debug("Failed to parse kubeconfig", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ValidateRDSOutput{}, nil
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
 	}
 
 	var kubeconfig string
@@ -107,52 +214,250 @@ func HandleValidateRDS(ctx context.Context, req *mcp.CallToolRequest, input Vali
 		logger.Debug("Kubeconfig auto-detected and processed", "size", len(kubeconfigData))
 	}
 
+	compareArgs := &CompareArgs{
+		OutputFormat: input.OutputFormat,
+		AllResources: input.AllResources,
+		Kubeconfig:   kubeconfig,
+		Context:      input.Context,
+
+		IncludeReferenceMetadata: input.IncludeReferenceMetadata,
+		ReferenceTimeout:         referenceTimeout,
+		IncludeCommandEquivalent: input.IncludeCommandEquivalent,
+		IncludeReferenceCoverage: input.IncludeReferenceCoverage,
+		Platform:                 platform.String(),
+		IgnoreVolatileFields:     input.IgnoreVolatileFields,
+	}
+	if input.Profile != "" {
+		profile, err := loadCompareProfile(input.Profile)
+		if err != nil {
+			logger.Debug("Profile lookup failed", "error", err)
+			return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+		}
+		profile.applyTo(compareArgs)
+	}
+
+	compareArgs.Verbosity, err = parseVerbosity(input.Verbosity, compareArgs.OutputFormat)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
+	}
+
 	logger.Debug("Parsed kube_compare_validate_rds arguments",
-		"rdsType", input.RDSType,
+		"rdsTypes", rdsTypes,
+		"explicitOCPVersion", input.OCPVersion,
+		"channel", input.Channel,
+		"referenceOCPVersion", input.ReferenceOCPVersion,
 		"hasKubeconfig", kubeconfig != "",
 		"context", input.Context,
-		"outputFormat", input.OutputFormat,
-		"allResources", input.AllResources,
+		"outputFormat", compareArgs.OutputFormat,
+		"allResources", compareArgs.AllResources,
+		"profile", input.Profile,
+		"referenceTimeout", compareArgs.ReferenceTimeout,
+		"verbosity", compareArgs.Verbosity,
 	)
 
-	logger.Info("Finding RDS reference for cluster")
 	rdsArgs := &ResolveRDSArgs{
 		Kubeconfig: kubeconfig,
 		Context:    input.Context,
-		RDSType:    input.RDSType,
+		OCPVersion: input.OCPVersion,
+		Channel:    input.Channel,
+
+		ReferenceOCPVersion: input.ReferenceOCPVersion,
+	}
+	results, rdsResults, err := validateRDSTypes(ctx, rdsArgs, rdsTypes, compareArgs, logger)
+	if err != nil {
+		return newToolResultErrorFor(err), ValidateRDSOutput{}, nil
 	}
 
-	rdsResult, err := ResolveRDSInternal(ctx, rdsArgs)
+	combinedResult := validateRDSResponse(results, rdsTypes, len(input.RDSTypes) > 0,
+		rdsResults[0].ClusterVersion, compareArgs.OutputFormat == OutputFormatSummary)
+
+	jsonOutput, err := json.MarshalIndent(combinedResult, "", "  ")
 	if err != nil {
-		logger.Debug("Failed to find RDS reference", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ValidateRDSOutput{}, nil
+		logger.Error("Failed to marshal result", "error", err)
+		return newToolResultError(fmt.Sprintf("Failed to format result: %v", err)), ValidateRDSOutput{}, nil
 	}
 
-	logger.Info("Found RDS reference",
-		"reference", rdsResult.Reference,
-		"clusterVersion", rdsResult.ClusterVersion,
-		"rhelVersion", rdsResult.RHELVersion,
-		"validated", rdsResult.Validated,
+	duration := time.Since(start)
+	logger.Info("RDS comparison completed",
+		"duration", duration,
+		"rdsTypes", rdsTypes,
+		"clusterVersion", rdsResults[0].ClusterVersion,
 	)
 
-	logger.Info("Starting cluster comparison", "reference", rdsResult.Reference)
-	compareArgs := &CompareArgs{
-		Reference:    rdsResult.Reference,
-		OutputFormat: input.OutputFormat,
-		AllResources: input.AllResources,
-		Kubeconfig:   kubeconfig,
-		Context:      input.Context,
+	toolResult = newToolResultText(string(jsonOutput))
+	for _, rdsType := range rdsTypes {
+		if metadata := results[rdsType].referenceMetadata; metadata != nil {
+			content, err := metadata.content()
+			if err != nil {
+				return nil, ValidateRDSOutput{}, err
+			}
+			toolResult.Content = append(toolResult.Content, content...)
+		}
+	}
+	return toolResult, ValidateRDSOutput{}, nil
+}
+
+// validateRDSTypes resolves the RDS reference for each of rdsTypes, validates them all
+// up front, and compares the cluster against each. It returns the comparisons keyed by
+// RDS type along with the resolved references, in the order of rdsTypes.
+func validateRDSTypes(ctx context.Context, rdsArgs *ResolveRDSArgs, rdsTypes []string, compareArgs *CompareArgs, logger *slog.Logger) (map[string]*ValidateRDSResult, []*ResolveRDSResult, error) {
+	logger.Info("Finding RDS reference for cluster")
+	rdsResults, err := ResolveRDSTypesInternal(ctx, rdsArgs, rdsTypes)
+	if err != nil {
+		logger.Debug("Failed to find RDS reference", "error", err)
+		return nil, nil, err
+	}
+
+	references := make([]string, 0, len(rdsResults))
+	for _, rdsResult := range rdsResults {
+		logger.Info("Found RDS reference",
+			"rdsType", rdsResult.RDSType,
+			"reference", rdsResult.Reference,
+			"clusterVersion", rdsResult.ClusterVersion,
+			"rhelVersion", rdsResult.RHELVersion,
+			"validated", rdsResult.Validated,
+		)
+		references = append(references, rdsResult.Reference)
 	}
 
-	if err := validateReference(ctx, compareArgs); err != nil {
+	// Validate every reference up front so that all invalid ones are reported together.
+	// Validation and the pulls and extractions that follow share the reference_timeout budget.
+	startReferenceAcquisition(compareArgs)
+	refCtx, cancel := referenceAcquisitionContext(ctx, compareArgs)
+	verifiedDigests, err := validateReferences(refCtx, references)
+	if err != nil && referenceTimedOut(ctx, refCtx) {
+		err = newReferenceTimeoutError(compareArgs.ReferenceTimeout)
+	}
+	cancel()
+	if err != nil {
 		logger.Debug("Reference validation failed", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ValidateRDSOutput{}, nil
+		return nil, nil, err
+	}
+
+	results := make(map[string]*ValidateRDSResult, len(rdsResults))
+	for i, rdsResult := range rdsResults {
+		runArgs := *compareArgs
+		runArgs.verifiedDigest = verifiedDigests[i]
+		result, err := compareRDSReference(ctx, rdsResult, runArgs, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		results[rdsResult.RDSType] = result
+	}
+	return results, rdsResults, nil
+}
+
+// validateRDSResponse shapes the kube_compare_validate_rds response. A single rds_type
+// keeps the original response shape, and summary mode returns only the compliance verdicts.
+func validateRDSResponse(results map[string]*ValidateRDSResult, rdsTypes []string, multi bool, clusterVersion string, summaryOnly bool) any {
+	if !multi {
+		if summaryOnly {
+			return results[rdsTypes[0]].Comparison
+		}
+		return results[rdsTypes[0]]
+	}
+
+	response := ValidateRDSMultiResult{ClusterVersion: clusterVersion}
+	if !summaryOnly {
+		response.Results = results
+		return response
+	}
+
+	response.Summaries = make(map[string]json.RawMessage, len(results))
+	for rdsType, result := range results {
+		response.Summaries[rdsType] = result.Comparison
+	}
+	return response
+}
+
+// selectRDSTypes returns the RDS types requested through either rds_type or rds_types.
+func selectRDSTypes(rdsType string, rdsTypes []string) ([]string, error) {
+	if rdsType != "" && len(rdsTypes) > 0 {
+		return nil, NewValidationError("rds_types",
+			"'rds_type' and 'rds_types' cannot both be provided",
+			"Use rds_type for a single RDS or rds_types for several")
+	}
+	if rdsType != "" {
+		rdsTypes = []string{rdsType}
+	}
+	if len(rdsTypes) == 0 {
+		return nil, NewValidationError("rds_type",
+			"an RDS type is required",
+			"Provide rds_type (core, ran, or hub) or rds_types")
 	}
 
-	comparisonOutput, err := RunCompare(ctx, compareArgs)
+	selected := make([]string, 0, len(rdsTypes))
+	seen := make(map[string]bool, len(rdsTypes))
+	for _, t := range rdsTypes {
+		t, err := normalizeRDSType(t)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[t] {
+			seen[t] = true
+			selected = append(selected, t)
+		}
+	}
+	return selected, nil
+}
+
+// newRDSPathNotFoundError explains a metadata path missing from an RDS image. The
+// configured path can lag behind changes to the image layout, so any metadata.yaml
+// files found near the expected directory are offered as alternatives.
+func newRDSPathNotFoundError(rdsResult *ResolveRDSResult, notFound *TargetNotFoundError) error {
+	var details strings.Builder
+	if len(notFound.Candidates) > 0 {
+		details.WriteString("The RDS image layout may have changed. Files found near the expected path:\n")
+		for _, candidate := range notFound.Candidates {
+			fmt.Fprintf(&details, "  - %s\n", candidate)
+		}
+		fmt.Fprintf(&details, "\nTo compare against one of them, call kube_compare_cluster_diff with reference container://%s:%s",
+			rdsResult.ImageRef, notFound.Candidates[0])
+	} else {
+		fmt.Fprintf(&details, "No %s was found near the expected path. The RDS image layout may have changed; "+
+			"inspect the image and call kube_compare_cluster_diff with reference container://%s:<path to %s>",
+			filepath.Base(notFound.TargetPath), rdsResult.ImageRef, filepath.Base(notFound.TargetPath))
+	}
+
+	return NewCompareError("rds-path",
+		fmt.Errorf("%s RDS metadata not found at %s in image %s: %w",
+			rdsResult.RDSType, notFound.TargetPath, rdsResult.ImageRef, notFound),
+		details.String())
+}
+
+// compareRDSReference runs the cluster comparison against a resolved RDS reference,
+// which the caller has already validated. compareArgs is taken by value so the
+// reference can be set per RDS type.
+func compareRDSReference(ctx context.Context, rdsResult *ResolveRDSResult, compareArgs CompareArgs, logger *slog.Logger) (*ValidateRDSResult, error) {
+	logger.Info("Starting cluster comparison", "reference", rdsResult.Reference)
+	compareArgs.Reference = rdsResult.Reference
+
+	run, err := runCompare(ctx, &compareArgs)
+	logger = withClusterID(logger, compareArgs.clusterID)
 	if err != nil {
 		logger.Debug("Comparison failed", "error", err)
-		return newToolResultError(formatErrorForUser(err)), ValidateRDSOutput{}, nil
+		var notFound *TargetNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, newRDSPathNotFoundError(rdsResult, notFound)
+		}
+		return nil, err
+	}
+
+	comparisonOutput := run.output
+	warning := rdsMismatchWarning(run.summary, rdsResult.RDSType, getRDSMismatchThreshold())
+	if warning != "" {
+		logger.Warn("Comparison suggests the wrong RDS type", "rdsType", rdsResult.RDSType, "warning", warning)
+	}
+	if rdsResult.Floating {
+		warning = strings.TrimSpace(floatingReferenceWarning(rdsResult.Channel) + " " + warning)
+	}
+	if mismatch := referenceVersionWarning(rdsResult); mismatch != "" {
+		warning = strings.TrimSpace(mismatch + " " + warning)
+	}
+	if warning != "" && compareArgs.OutputFormat == OutputFormatSummary {
+		// Summary mode returns only the comparison, so carry the warning in it
+		comparisonOutput = addSummaryWarning(comparisonOutput, warning)
 	}
 
 	var comparisonJSON json.RawMessage
@@ -163,24 +468,69 @@ func HandleValidateRDS(ctx context.Context, req *mcp.CallToolRequest, input Vali
 		comparisonJSON = json.RawMessage(jsonBytes)
 	}
 
-	combinedResult := ValidateRDSResult{
+	return &ValidateRDSResult{
 		RDSReference: rdsResult,
 		Comparison:   comparisonJSON,
+		Warning:      warning,
+
+		CommandEquivalent: run.commandEquivalent,
+		Coverage:          run.coverage,
+
+		referenceMetadata: run.referenceMetadata,
+	}, nil
+}
+
+// floatingReferenceWarning returns the warning for a comparison against the RDS image
+// a channel alias named when it was resolved.
+func floatingReferenceWarning(channel string) string {
+	return fmt.Sprintf("Compared against the RDS image the floating channel alias '%s' names now. "+
+		"The alias can move to another image, so the result is not reproducible; set ocp_version to compare against a pinned version.",
+		channel)
+}
+
+// referenceVersionWarning returns the warning for a comparison against the RDS of
+// another OpenShift version than the cluster runs, or "" when the versions match.
+func referenceVersionWarning(rdsResult *ResolveRDSResult) string {
+	if rdsResult.ReferenceVersion == "" || rdsResult.ReferenceVersion == ExtractMajorMinorVersion(rdsResult.ClusterVersion) {
+		return ""
 	}
+	referenceVersion := strings.TrimPrefix(rdsResult.ReferenceVersion, "v")
+	return fmt.Sprintf("Compared the cluster, which runs OpenShift %s, against the RDS for OpenShift %s selected by reference_ocp_version. "+
+		"The drift previews what differs from the %s RDS, not compliance with the RDS of the running version.",
+		rdsResult.ClusterVersion, referenceVersion, referenceVersion)
+}
 
-	jsonOutput, err := json.MarshalIndent(combinedResult, "", "  ")
-	if err != nil {
-		logger.Error("Failed to marshal result", "error", err)
-		return newToolResultError(fmt.Sprintf("Failed to format result: %v", err)), ValidateRDSOutput{}, nil
+// rdsMismatchWarning returns a warning when the share of reference templates missing
+// from the cluster exceeds threshold. Comparing against another profile's RDS (RAN
+// against a core cluster, for example) reports most of its templates as missing.
+// It returns "" when summary is nil, as it is for the yaml and junit formats.
+func rdsMismatchWarning(summary *compare.Summary, rdsType string, threshold float64) string {
+	if summary == nil || summary.NumMissing == 0 {
+		return ""
+	}
+	expected := summary.NumMissing + summary.TotalCRs
+	ratio := float64(summary.NumMissing) / float64(expected)
+	if ratio <= threshold {
+		return ""
 	}
 
-	duration := time.Since(start)
-	logger.Info("RDS comparison completed",
-		"duration", duration,
-		"rdsType", input.RDSType,
-		"clusterVersion", rdsResult.ClusterVersion,
-		"rhelVersion", rdsResult.RHELVersion,
-	)
+	return fmt.Sprintf("%d reference templates are missing from the cluster while only %d cluster CRs matched "+
+		"(%.0f%% missing). The cluster may not run the %s profile, so rds_type '%s' may be wrong. "+
+		"Compare against several RDS types at once with rds_types to find the best match.",
+		summary.NumMissing, summary.TotalCRs, ratio*100, rdsType, rdsType)
+}
 
-	return newToolResultText(string(jsonOutput)), ValidateRDSOutput{}, nil
+// addSummaryWarning sets warning on a CompareSummary encoded as JSON. Output that is
+// not a CompareSummary is returned unchanged.
+func addSummaryWarning(summaryJSON, warning string) string {
+	var summary CompareSummary
+	if err := json.Unmarshal([]byte(summaryJSON), &summary); err != nil {
+		return summaryJSON
+	}
+	summary.Warning = warning
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return summaryJSON
+	}
+	return string(data)
 }
@@ -3,6 +3,9 @@
 package mcpserver_test
 
 import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -23,6 +26,39 @@ var _ = Describe("RDSCompareHandler", func() {
 		})
 	})
 
+	Describe("HandleValidateRDS RDS type selection", func() {
+		DescribeTable("rejects invalid combinations",
+			func(input mcpserver.ValidateRDSInput, expected string) {
+				result, _, err := mcpserver.HandleValidateRDS(context.Background(), nil, input)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.IsError).To(BeTrue())
+				textContent, ok := result.Content[0].(*mcp.TextContent)
+				Expect(ok).To(BeTrue())
+				Expect(textContent.Text).To(ContainSubstring(expected))
+			},
+			Entry("neither rds_type nor rds_types", mcpserver.ValidateRDSInput{}, "rds_type"),
+			Entry("both rds_type and rds_types",
+				mcpserver.ValidateRDSInput{RDSType: "core", RDSTypes: []string{"ran"}}, "cannot both be provided"),
+			Entry("unknown type in rds_types",
+				mcpserver.ValidateRDSInput{RDSTypes: []string{"core", "edge"}}, "unknown RDS type 'edge'"),
+		)
+
+		It("rejects a malformed ocp_version", func() {
+			result, _, err := mcpserver.HandleValidateRDS(context.Background(), nil,
+				mcpserver.ValidateRDSInput{RDSType: "core", OCPVersion: "latest"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			Expect(ok).To(BeTrue())
+			Expect(textContent.Text).To(ContainSubstring("invalid OpenShift version 'latest'"))
+			Expect(result.Meta).To(HaveKeyWithValue(mcpserver.ToolErrorMetaKey, &mcpserver.ToolErrorData{
+				Code:  mcpserver.ToolErrorCodeValidation,
+				Field: "ocp_version",
+				Hint:  "Use MAJOR.MINOR or MAJOR.MINOR.PATCH, e.g. 4.18 or 4.18.3",
+			}))
+		})
+	})
+
 	Describe("ValidateRDSArgs struct", func() {
 		It("can be created with all fields", func() {
 			args := mcpserver.ValidateRDSArgs{
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// rdsTypeRegex matches an RDS type name as it is given in rds_type.
+var rdsTypeRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// builtinRDSTypes are the built-in RDS types in the order they are listed.
+var builtinRDSTypes = []string{RDSTypeCore, RDSTypeRAN, RDSTypeHub}
+
+var (
+	rdsConfigsMu sync.RWMutex
+	rdsConfigs   = builtinRDSConfigs
+	rdsTypes     = builtinRDSTypes
+)
+
+// RDSConfigEntry is an RDS type defined in the RDS config file.
+type RDSConfigEntry struct {
+	Type          string   `json:"type"`
+	ImageBase     string   `json:"imageBase"`
+	Path          string   `json:"path"`
+	RHELVariants  []string `json:"rhelVariants"`
+	MinOCPVersion string   `json:"minOCPVersion,omitempty"`
+}
+
+// rdsConfigFile is the layout of the file named by --rds-config-file.
+type rdsConfigFile struct {
+	RDSTypes []RDSConfigEntry `json:"rdsTypes"`
+}
+
+// LoadRDSConfigFile reads and validates the RDS types defined in the YAML file at path.
+func LoadRDSConfigFile(path string) ([]RDSConfigEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RDS config file: %w", err)
+	}
+	var file rdsConfigFile
+	if err := sigsyaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse RDS config file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.RDSTypes))
+	for i, entry := range file.RDSTypes {
+		if !rdsTypeRegex.MatchString(entry.Type) {
+			return nil, fmt.Errorf("rdsTypes[%d]: type %q must be a lowercase name such as core or edge", i, entry.Type)
+		}
+		if seen[entry.Type] {
+			return nil, fmt.Errorf("rdsTypes[%d]: type %q is defined more than once", i, entry.Type)
+		}
+		seen[entry.Type] = true
+		if err := validateRDSConfig(entry.Type, entry.config()); err != nil {
+			return nil, fmt.Errorf("rdsTypes[%d]: %w", i, err)
+		}
+	}
+	return file.RDSTypes, nil
+}
+
+// validateRDSConfig checks that cfg has everything needed to build a reference for
+// rdsType, and that the references built from it are valid.
+func validateRDSConfig(rdsType string, cfg RDSConfig) error {
+	switch {
+	case cfg.ImageBase == "":
+		return fmt.Errorf("RDS type %q: imageBase is required", rdsType)
+	case cfg.Path == "":
+		return fmt.Errorf("RDS type %q: path is required", rdsType)
+	case !strings.HasPrefix(cfg.Path, "/"):
+		return fmt.Errorf("RDS type %q: path %q must be absolute", rdsType, cfg.Path)
+	case len(cfg.RHELVariants) == 0:
+		return fmt.Errorf("RDS type %q: rhelVariants must list at least one variant", rdsType)
+	case cfg.MinOCPVersion != "" && !versionTagRegex.MatchString(cfg.MinOCPVersion):
+		return fmt.Errorf("RDS type %q: minOCPVersion %q does not match vMAJOR.MINOR format", rdsType, cfg.MinOCPVersion)
+	}
+	if err := validateReferenceFilePath(cfg.Path); err != nil {
+		return fmt.Errorf("RDS type %q: %w", rdsType, err)
+	}
+	for _, rhel := range cfg.RHELVariants {
+		repoRef := fmt.Sprintf("%s-%s", cfg.ImageBase, rhel)
+		if _, err := name.NewRepository(repoRef); err != nil {
+			return fmt.Errorf("RDS type %q: invalid image repository %q: %w", rdsType, repoRef, err)
+		}
+	}
+	return nil
+}
+
+// config returns the RDSConfig defined by the entry.
+func (e RDSConfigEntry) config() RDSConfig {
+	return RDSConfig{
+		ImageBase:     e.ImageBase,
+		Path:          e.Path,
+		RHELVariants:  e.RHELVariants,
+		MinOCPVersion: e.MinOCPVersion,
+	}
+}
+
+// SetRDSConfigs merges entries, as returned by LoadRDSConfigFile, with the built-in RDS
+// types. An entry for a built-in type replaces it; other entries add types, listed
+// after the built-in ones in the order given.
+func SetRDSConfigs(entries []RDSConfigEntry) {
+	configs := make(map[string]RDSConfig, len(builtinRDSConfigs)+len(entries))
+	for rdsType, cfg := range builtinRDSConfigs {
+		configs[rdsType] = cfg
+	}
+	types := append([]string(nil), builtinRDSTypes...)
+	for _, entry := range entries {
+		if _, ok := configs[entry.Type]; !ok {
+			types = append(types, entry.Type)
+		}
+		configs[entry.Type] = entry.config()
+	}
+
+	rdsConfigsMu.Lock()
+	defer rdsConfigsMu.Unlock()
+	rdsConfigs = configs
+	rdsTypes = types
+}
+
+// getRDSConfig returns the configuration of rdsType set by SetRDSConfigs.
+func getRDSConfig(rdsType string) (RDSConfig, bool) {
+	rdsConfigsMu.RLock()
+	defer rdsConfigsMu.RUnlock()
+	cfg, ok := rdsConfigs[rdsType]
+	return cfg, ok
+}
+
+// getRDSTypes returns the RDS types set by SetRDSConfigs, built-in types first.
+func getRDSTypes() []string {
+	rdsConfigsMu.RLock()
+	defer rdsConfigsMu.RUnlock()
+	return rdsTypes
+}
+
+// rdsTypeEnum returns the RDS types as the enum of an rds_type schema property.
+func rdsTypeEnum() []any {
+	types := getRDSTypes()
+	enum := make([]any, len(types))
+	for i, rdsType := range types {
+		enum[i] = rdsType
+	}
+	return enum
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
+)
+
+const edgeRDSConfig = `rdsTypes:
+  - type: edge
+    imageBase: registry.example.com/telco/openshift-telco-edge-rds
+    path: /usr/share/telco-edge-rds/metadata.yaml
+    rhelVariants: [rhel9]
+`
+
+// writeRDSConfigFile writes content to an RDS config file and returns its path.
+func writeRDSConfigFile(content string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "rds-config.yaml")
+	Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	return path
+}
+
+var _ = Describe("RDS config file", func() {
+	Describe("LoadRDSConfigFile", func() {
+		It("reads the RDS types", func() {
+			entries, err := mcpserver.LoadRDSConfigFile(writeRDSConfigFile(edgeRDSConfig))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(Equal([]mcpserver.RDSConfigEntry{{
+				Type:         "edge",
+				ImageBase:    "registry.example.com/telco/openshift-telco-edge-rds",
+				Path:         "/usr/share/telco-edge-rds/metadata.yaml",
+				RHELVariants: []string{"rhel9"},
+			}}))
+		})
+
+		DescribeTable("rejects invalid RDS types",
+			func(content, expected string) {
+				_, err := mcpserver.LoadRDSConfigFile(writeRDSConfigFile(content))
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("missing type", `rdsTypes: [{imageBase: r.io/a, path: /m.yaml, rhelVariants: [rhel9]}]`,
+				`type "" must be a lowercase name`),
+			Entry("missing imageBase", `rdsTypes: [{type: edge, path: /m.yaml, rhelVariants: [rhel9]}]`,
+				"imageBase is required"),
+			Entry("missing path", `rdsTypes: [{type: edge, imageBase: r.io/a, rhelVariants: [rhel9]}]`,
+				"path is required"),
+			Entry("relative path", `rdsTypes: [{type: edge, imageBase: r.io/a, path: m.yaml, rhelVariants: [rhel9]}]`,
+				"must be absolute"),
+			Entry("no RHEL variants", `rdsTypes: [{type: edge, imageBase: r.io/a, path: /m.yaml}]`,
+				"rhelVariants must list at least one variant"),
+			Entry("bad minOCPVersion", `rdsTypes: [{type: edge, imageBase: r.io/a, path: /m.yaml, rhelVariants: [rhel9], minOCPVersion: "4.20"}]`,
+				"does not match vMAJOR.MINOR format"),
+			Entry("invalid image repository", `rdsTypes: [{type: edge, imageBase: "r.io/A B", path: /m.yaml, rhelVariants: [rhel9]}]`,
+				"invalid image repository"),
+			Entry("duplicate type", edgeRDSConfig+`  - {type: edge, imageBase: r.io/a, path: /m.yaml, rhelVariants: [rhel9]}
+`, "defined more than once"),
+			Entry("unknown field", `rdsTypes: [{type: edge, image: r.io/a, path: /m.yaml, rhelVariants: [rhel9]}]`,
+				"failed to parse RDS config file"),
+		)
+
+		It("reads the RDS types from a JSON file", func() {
+			entries, err := mcpserver.LoadRDSConfigFile(writeRDSConfigFile(`{"rdsTypes": [{
+				"type": "edge",
+				"imageBase": "registry.example.com/telco/openshift-telco-edge-rds",
+				"path": "/usr/share/telco-edge-rds/metadata.yaml",
+				"rhelVariants": ["rhel9", "rhel8"],
+				"minOCPVersion": "v4.20"
+			}]}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].RHELVariants).To(Equal([]string{"rhel9", "rhel8"}))
+			Expect(entries[0].MinOCPVersion).To(Equal("v4.20"))
+		})
+
+		It("fails for a missing file", func() {
+			_, err := mcpserver.LoadRDSConfigFile(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).To(MatchError(ContainSubstring("failed to read RDS config file")))
+		})
+	})
+
+	Context("with a custom RDS type", func() {
+		var (
+			ctrl         *gomock.Controller
+			mockRegistry *MockRegistryClient
+			service      *mcpserver.ReferenceService
+		)
+
+		BeforeEach(func() {
+			entries, err := mcpserver.LoadRDSConfigFile(writeRDSConfigFile(edgeRDSConfig))
+			Expect(err).NotTo(HaveOccurred())
+			mcpserver.SetRDSConfigs(entries)
+			DeferCleanup(mcpserver.SetRDSConfigs, []mcpserver.RDSConfigEntry(nil))
+
+			ctrl = gomock.NewController(GinkgoT())
+			mockRegistry = NewMockRegistryClient(ctrl)
+			service = &mcpserver.ReferenceService{Registry: mockRegistry}
+		})
+
+		It("builds its reference from the configured image and path", func() {
+			Expect(mcpserver.BuildRDSReference("edge", "rhel9", "v4.20")).To(Equal(
+				"container://registry.example.com/telco/openshift-telco-edge-rds-rhel9:v4.20:/usr/share/telco-edge-rds/metadata.yaml"))
+		})
+
+		It("resolves it", func() {
+			mockRegistry.EXPECT().
+				ListTags(gomock.Any(), "registry.example.com/telco/openshift-telco-edge-rds-rhel9").
+				Return([]string{"v4.19", "v4.20"}, nil)
+			mockRegistry.EXPECT().
+				HeadImage(gomock.Any(), "registry.example.com/telco/openshift-telco-edge-rds-rhel9:v4.20").
+				Return(nil)
+
+			result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+				RDSType:    "edge",
+				OCPVersion: "4.20.1",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RDSType).To(Equal("edge"))
+			Expect(result.RHELVersion).To(Equal("rhel9"))
+			Expect(result.ImageRef).To(Equal("registry.example.com/telco/openshift-telco-edge-rds-rhel9:v4.20"))
+			Expect(result.MetadataPath).To(Equal("/usr/share/telco-edge-rds/metadata.yaml"))
+		})
+
+		It("lists it after the built-in types in the rds_type enum", func() {
+			schema := mcpserver.ResolveRDSInputSchema()
+			Expect(schema.Properties["rds_type"].Enum).To(Equal([]any{"core", "ran", "hub", "edge"}))
+		})
+	})
+
+	It("replaces a built-in RDS type with an entry of the same type", func() {
+		mcpserver.SetRDSConfigs([]mcpserver.RDSConfigEntry{{
+			Type:         mcpserver.RDSTypeCore,
+			ImageBase:    "mirror.example.com/openshift4/openshift-telco-core-rds",
+			Path:         "/usr/share/telco-core-rds/configuration/reference-crs-kube-compare/metadata.yaml",
+			RHELVariants: []string{"rhel9"},
+		}})
+		DeferCleanup(mcpserver.SetRDSConfigs, []mcpserver.RDSConfigEntry(nil))
+
+		Expect(mcpserver.BuildRDSReference(mcpserver.RDSTypeCore, "rhel9", "v4.18")).To(HavePrefix(
+			"container://mirror.example.com/openshift4/openshift-telco-core-rds-rhel9:v4.18:"))
+		Expect(mcpserver.ResolveRDSInputSchema().Properties["rds_type"].Enum).To(Equal([]any{"core", "ran", "hub"}))
+	})
+})
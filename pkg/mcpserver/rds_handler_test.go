@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// staticRegistry is a RegistryClient that serves a fixed tag list for every repository.
+type staticRegistry struct {
+	tags []string
+}
+
+func (r *staticRegistry) ListTags(context.Context, string) ([]string, error) { return r.tags, nil }
+func (r *staticRegistry) HeadImage(context.Context, string) error            { return nil }
+func (r *staticRegistry) Digest(context.Context, string) (string, error) {
+	return "sha256:0000000000000000000000000000000000000000000000000000000000000000", nil
+}
+func (r *staticRegistry) GetImageConfig(context.Context, string) (*ImageConfig, error) {
+	return &ImageConfig{}, nil
+}
+
+var _ = Describe("HandleResolveRDS rds_type normalization", func() {
+	BeforeEach(func() {
+		original := defaultReferenceService
+		defaultReferenceService = &ReferenceService{Registry: &staticRegistry{tags: []string{"v4.18"}}}
+		DeferCleanup(func() { defaultReferenceService = original })
+	})
+
+	DescribeTable("accepts differently cased or padded RDS types",
+		func(rdsType string) {
+			result, resolved, err := HandleResolveRDS(context.Background(), nil, ResolveRDSInput{
+				RDSType:    rdsType,
+				OCPVersion: "4.18",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeFalse())
+			Expect(resolved.RDSType).To(Equal(RDSTypeCore))
+			Expect(resolved.Reference).To(ContainSubstring("telco-core-rds"))
+		},
+		Entry("upper case", "CORE"),
+		Entry("mixed case with whitespace", " Core "),
+	)
+
+	It("rejects an unknown RDS type with a validation error", func() {
+		result, resolved, err := HandleResolveRDS(context.Background(), nil, ResolveRDSInput{
+			RDSType:    "edge",
+			OCPVersion: "4.18",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved).To(BeNil())
+		Expect(result.IsError).To(BeTrue())
+		text := result.Content[0].(*mcp.TextContent).Text
+		Expect(text).To(ContainSubstring("validation error for 'rds_type'"))
+		Expect(text).To(ContainSubstring("unknown RDS type 'edge'"))
+	})
+})
+
+var _ = Describe("HandleResolveRDS channel", func() {
+	BeforeEach(func() {
+		original := defaultReferenceService
+		defaultReferenceService = &ReferenceService{Registry: &staticRegistry{tags: []string{"v4.18", "stable"}}}
+		DeferCleanup(func() { defaultReferenceService = original })
+	})
+
+	It("resolves a channel alias and marks the reference floating", func() {
+		result, resolved, err := HandleResolveRDS(context.Background(), nil, ResolveRDSInput{
+			RDSType: RDSTypeCore,
+			Channel: "stable",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsError).To(BeFalse())
+		Expect(resolved.Channel).To(Equal("stable"))
+		Expect(resolved.Floating).To(BeTrue())
+		Expect(resolved.ImageRef).To(HaveSuffix("-rhel9:stable"))
+		Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring(`"floating": true`))
+	})
+
+	DescribeTable("rejects a channel that is not an alias",
+		func(input ResolveRDSInput, message string) {
+			input.RDSType = RDSTypeCore
+			result, resolved, err := HandleResolveRDS(context.Background(), nil, input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved).To(BeNil())
+			Expect(result.IsError).To(BeTrue())
+			text := result.Content[0].(*mcp.TextContent).Text
+			Expect(text).To(ContainSubstring("validation error for 'channel'"))
+			Expect(text).To(ContainSubstring(message))
+		},
+		Entry("a version tag", ResolveRDSInput{Channel: "v4.18"}, "'v4.18' is a version, not a channel alias"),
+		Entry("a version", ResolveRDSInput{Channel: "4.18.3"}, "is a version"),
+		Entry("not an image tag", ResolveRDSInput{Channel: "stable/latest"}, "invalid channel"),
+		Entry("with ocp_version", ResolveRDSInput{Channel: "stable", OCPVersion: "4.18"}, "cannot both be set"),
+	)
+})
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+var _ = Describe("RDS type mismatch detection", func() {
+
+	Describe("rdsMismatchWarning", func() {
+		It("warns when most reference templates are missing", func() {
+			// A RAN reference compared against a core cluster: nearly nothing matches
+			summary := &compare.Summary{NumMissing: 38, TotalCRs: 4, NumDiffCRs: 1}
+
+			warning := rdsMismatchWarning(summary, RDSTypeRAN, DefaultRDSMismatchThreshold)
+			Expect(warning).To(ContainSubstring("38 reference templates are missing"))
+			Expect(warning).To(ContainSubstring("90% missing"))
+			Expect(warning).To(ContainSubstring("rds_type 'ran' may be wrong"))
+			Expect(warning).To(ContainSubstring("rds_types"))
+		})
+
+		It("does not warn for a mostly matching cluster", func() {
+			summary := &compare.Summary{NumMissing: 3, TotalCRs: 40, NumDiffCRs: 5}
+			Expect(rdsMismatchWarning(summary, RDSTypeCore, DefaultRDSMismatchThreshold)).To(BeEmpty())
+		})
+
+		It("does not warn when nothing is missing", func() {
+			Expect(rdsMismatchWarning(&compare.Summary{}, RDSTypeCore, DefaultRDSMismatchThreshold)).To(BeEmpty())
+		})
+
+		It("does not warn without a summary", func() {
+			Expect(rdsMismatchWarning(nil, RDSTypeCore, DefaultRDSMismatchThreshold)).To(BeEmpty())
+		})
+
+		It("honors the threshold", func() {
+			summary := &compare.Summary{NumMissing: 3, TotalCRs: 7}
+			Expect(rdsMismatchWarning(summary, RDSTypeHub, 0.5)).To(BeEmpty())
+			Expect(rdsMismatchWarning(summary, RDSTypeHub, 0.25)).NotTo(BeEmpty())
+		})
+	})
+
+	Describe("getRDSMismatchThreshold", func() {
+		DescribeTable("reads KUBE_COMPARE_MCP_RDS_MISMATCH_THRESHOLD",
+			func(value string, expected float64) {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_RDS_MISMATCH_THRESHOLD", value)
+				Expect(getRDSMismatchThreshold()).To(Equal(expected))
+			},
+			Entry("unset", "", DefaultRDSMismatchThreshold),
+			Entry("valid", "0.8", 0.8),
+			Entry("one disables the warning", "1", 1.0),
+			Entry("zero", "0", DefaultRDSMismatchThreshold),
+			Entry("above one", "1.5", DefaultRDSMismatchThreshold),
+			Entry("not a number", "most", DefaultRDSMismatchThreshold),
+		)
+	})
+
+	Describe("addSummaryWarning", func() {
+		It("adds the warning to a summary", func() {
+			summaryJSON, err := json.Marshal(CompareSummary{NumDiffs: 1, Reference: "ref"})
+			Expect(err).NotTo(HaveOccurred())
+
+			var summary CompareSummary
+			Expect(json.Unmarshal([]byte(addSummaryWarning(string(summaryJSON), "wrong type")), &summary)).To(Succeed())
+			Expect(summary.Warning).To(Equal("wrong type"))
+			Expect(summary.NumDiffs).To(Equal(1))
+			Expect(summary.Reference).To(Equal("ref"))
+		})
+
+		It("leaves output that is not a summary unchanged", func() {
+			Expect(addSummaryWarning("No differences found", "wrong type")).To(Equal("No differences found"))
+		})
+	})
+})
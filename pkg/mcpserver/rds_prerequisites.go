@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// RDSPrerequisitesInput defines the typed input for the check_rds_prerequisites tool.
+type RDSPrerequisitesInput struct {
+	RDSType    string `json:"rds_type" jsonschema:"RDS type whose prerequisites to check: core for Telco Core RDS, ran for Telco RAN DU RDS, or hub for Telco Hub RDS"`
+	Kubeconfig string `json:"kubeconfig,omitempty" jsonschema:"Kubeconfig content (raw YAML or base64-encoded) for the cluster to check. If omitted, uses in-cluster config."`
+	Context    string `json:"context,omitempty" jsonschema:"Kubernetes context name to use from the provided kubeconfig."`
+
+	KubeconfigSecret string `json:"kubeconfig_secret,omitempty" jsonschema:"Secret holding the kubeconfig, as namespace/name or namespace/name/key (key defaults to kubeconfig). Read by the MCP server from its own cluster; must be enabled by the server. Use instead of kubeconfig."`
+}
+
+// PrerequisiteCheck is the outcome of checking one prerequisite. A prerequisite is
+// present when the cluster serves its resource, at any version of its group.
+type PrerequisiteCheck struct {
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	Resource string `json:"resource"`
+	Present  bool   `json:"present"`
+	Version  string `json:"version,omitempty"`
+}
+
+// RDSPrerequisitesResult is the structured response for the check_rds_prerequisites tool.
+type RDSPrerequisitesResult struct {
+	RDSType    string              `json:"rds_type"`
+	AllPresent bool                `json:"all_present"`
+	Missing    []string            `json:"missing"`
+	Checks     []PrerequisiteCheck `json:"checks"`
+}
+
+// rdsPrerequisites are the operators, identified by a resource they install, that the
+// reference CRs of each built-in RDS type configure. Without them a comparison reports
+// their CRs as missing rather than drifted.
+var rdsPrerequisites = map[string][]PrerequisiteCheck{
+	RDSTypeCore: {
+		{Name: "Node Tuning Operator", Group: "performance.openshift.io", Resource: "performanceprofiles"},
+		{Name: "SR-IOV Network Operator", Group: "sriovnetwork.openshift.io", Resource: "sriovnetworknodepolicies"},
+		{Name: "Kubernetes NMState Operator", Group: "nmstate.io", Resource: "nmstates"},
+		{Name: "MetalLB Operator", Group: "metallb.io", Resource: "metallbs"},
+		{Name: "OpenShift Data Foundation", Group: "ocs.openshift.io", Resource: "storageclusters"},
+		{Name: "Cluster Logging Operator", Group: "observability.openshift.io", Resource: "clusterlogforwarders"},
+	},
+	RDSTypeRAN: {
+		{Name: "Node Tuning Operator", Group: "performance.openshift.io", Resource: "performanceprofiles"},
+		{Name: "PTP Operator", Group: "ptp.openshift.io", Resource: "ptpconfigs"},
+		{Name: "SR-IOV Network Operator", Group: "sriovnetwork.openshift.io", Resource: "sriovnetworknodepolicies"},
+		{Name: "Local Storage Operator", Group: "local.storage.openshift.io", Resource: "localvolumes"},
+		{Name: "Cluster Logging Operator", Group: "observability.openshift.io", Resource: "clusterlogforwarders"},
+	},
+	RDSTypeHub: {
+		{Name: "Advanced Cluster Management", Group: "operator.open-cluster-management.io", Resource: "multiclusterhubs"},
+		{Name: "multicluster engine", Group: "multicluster.openshift.io", Resource: "multiclusterengines"},
+		{Name: "Topology Aware Lifecycle Manager", Group: "ran.openshift.io", Resource: "clustergroupupgrades"},
+		{Name: "OpenShift GitOps", Group: "argoproj.io", Resource: "argocds"},
+		{Name: "OpenShift Data Foundation", Group: "ocs.openshift.io", Resource: "storageclusters"},
+		{Name: "Cluster Logging Operator", Group: "observability.openshift.io", Resource: "clusterlogforwarders"},
+	},
+}
+
+// RDSPrerequisitesTool returns the MCP tool definition for checking RDS prerequisites.
+func RDSPrerequisitesTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:  "check_rds_prerequisites",
+		Title: "Check RDS Prerequisites",
+		Description: "Check that a cluster has the operators a Telco RDS configures, before comparing it against the RDS. " +
+			"Reports each prerequisite operator as present or absent; the CRs of an absent operator would " +
+			"otherwise all be reported as missing by kube_compare_validate_rds.",
+		InputSchema:  RDSPrerequisitesInputSchema(),
+		OutputSchema: RDSPrerequisitesOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleRDSPrerequisites is the MCP tool handler for the check_rds_prerequisites tool.
+func HandleRDSPrerequisites(ctx context.Context, req *mcp.CallToolRequest, input RDSPrerequisitesInput) (toolResult *mcp.CallToolResult, result *RDSPrerequisitesResult, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Info("Received tool request",
+		"tool", "check_rds_prerequisites",
+		"rdsType", input.RDSType,
+		"hasKubeconfig", input.Kubeconfig != "",
+		"context", input.Context,
+	)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	rdsType, err := normalizeRDSType(input.RDSType)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+	prerequisites, ok := rdsPrerequisites[rdsType]
+	if !ok {
+		err := NewValidationError("rds_type",
+			fmt.Sprintf("no prerequisites are known for RDS type '%s'", rdsType),
+			"Prerequisites are known for the built-in RDS types core, ran, and hub")
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	resolvedKubeconfig, err := resolveKubeconfigInput(ctx, input.Kubeconfig, input.KubeconfigSecret)
+	if err != nil {
+		logger.Debug("Kubeconfig Secret lookup failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+	input.Kubeconfig = resolvedKubeconfig
+
+	// Validate context requires kubeconfig
+	if input.Context != "" && input.Kubeconfig == "" {
+		err := NewValidationError("context",
+			"'context' parameter requires 'kubeconfig' to also be provided",
+			"Provide a kubeconfig along with the context name")
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	restConfig, err := buildTargetRestConfig(ctx, input.Kubeconfig, input.Context,
+		"No kubeconfig provided: provide a kubeconfig for the cluster to check.", logger)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+	logger = withClusterIdentity(logger, restConfig)
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		err = NewCompareError("cluster-client",
+			fmt.Errorf("failed to create client: %w", err),
+			"Verify the kubeconfig is valid")
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	result, err = checkRDSPrerequisites(discoveryClient, rdsType, prerequisites)
+	if err != nil {
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	logger.Info("RDS prerequisites checked",
+		"duration", time.Since(start),
+		"rdsType", rdsType,
+		"allPresent", result.AllPresent,
+		"missing", len(result.Missing),
+	)
+
+	return newToolResultText(string(outputBytes)), result, nil
+}
+
+// checkRDSPrerequisites checks which of prerequisites the cluster serves. An
+// unreachable cluster is an error; a prerequisite whose group the cluster does not
+// serve is absent.
+func checkRDSPrerequisites(client discovery.DiscoveryInterface, rdsType string, prerequisites []PrerequisiteCheck) (*RDSPrerequisitesResult, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return nil, classifyClusterAuthError(err)
+	}
+
+	result := &RDSPrerequisitesResult{
+		RDSType:    rdsType,
+		AllPresent: true,
+		Missing:    []string{},
+		Checks:     make([]PrerequisiteCheck, 0, len(prerequisites)),
+	}
+	served := make(map[string][]metav1.APIResource)
+	for _, check := range prerequisites {
+		version, err := servedResourceVersion(client, groups, check.Group, check.Resource, served)
+		if err != nil {
+			return nil, NewCompareError("cluster-discovery", err,
+				"Verify the kubeconfig's user may read the cluster's API discovery information")
+		}
+		check.Present = version != ""
+		check.Version = version
+		if !check.Present {
+			result.AllPresent = false
+			result.Missing = append(result.Missing, check.Name)
+		}
+		result.Checks = append(result.Checks, check)
+	}
+	return result, nil
+}
+
+// servedResourceVersion returns the version of group at which the cluster serves
+// resource, preferring the group's preferred version, or "" when it serves none.
+// served caches the resources of each group version already discovered.
+func servedResourceVersion(client discovery.DiscoveryInterface, groups *metav1.APIGroupList, group, resource string, served map[string][]metav1.APIResource) (string, error) {
+	for _, apiGroup := range groups.Groups {
+		if apiGroup.Name != group {
+			continue
+		}
+		versions := []metav1.GroupVersionForDiscovery{apiGroup.PreferredVersion}
+		versions = append(versions, apiGroup.Versions...)
+		for _, version := range versions {
+			resources, ok := served[version.GroupVersion]
+			if !ok {
+				list, err := client.ServerResourcesForGroupVersion(version.GroupVersion)
+				if err != nil && !apierrors.IsNotFound(err) {
+					return "", fmt.Errorf("failed to discover %s resources: %w", version.GroupVersion, err)
+				}
+				if list != nil {
+					resources = list.APIResources
+				}
+				served[version.GroupVersion] = resources
+			}
+			if slices.ContainsFunc(resources, func(r metav1.APIResource) bool { return r.Name == resource }) {
+				return version.Version, nil
+			}
+		}
+	}
+	return "", nil
+}
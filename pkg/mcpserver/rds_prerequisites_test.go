@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newPrerequisitesTestDiscovery returns a discovery client serving the resources of
+// prerequisites at v1, leaving out those named in absent.
+func newPrerequisitesTestDiscovery(prerequisites []PrerequisiteCheck, absent ...string) *discoveryfake.FakeDiscovery {
+	fake := &clienttesting.Fake{}
+	for _, check := range prerequisites {
+		if slices.Contains(absent, check.Name) {
+			continue
+		}
+		fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+			GroupVersion: check.Group + "/v1",
+			APIResources: []metav1.APIResource{{Name: check.Resource, Namespaced: true}},
+		})
+	}
+	return &discoveryfake.FakeDiscovery{Fake: fake}
+}
+
+var _ = Describe("RDS prerequisites", func() {
+
+	Describe("RDSPrerequisitesTool", func() {
+		It("has the correct name and schemas", func() {
+			tool := RDSPrerequisitesTool()
+			Expect(tool.Name).To(Equal("check_rds_prerequisites"))
+			Expect(tool.InputSchema).NotTo(BeNil())
+			Expect(tool.OutputSchema).NotTo(BeNil())
+		})
+	})
+
+	Describe("checkRDSPrerequisites", func() {
+		DescribeTable("reports every prerequisite present on a cluster that has them",
+			func(rdsType string) {
+				prerequisites := rdsPrerequisites[rdsType]
+				Expect(prerequisites).NotTo(BeEmpty())
+
+				result, err := checkRDSPrerequisites(newPrerequisitesTestDiscovery(prerequisites), rdsType, prerequisites)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RDSType).To(Equal(rdsType))
+				Expect(result.AllPresent).To(BeTrue())
+				Expect(result.Missing).To(BeEmpty())
+				Expect(result.Checks).To(HaveLen(len(prerequisites)))
+				for _, check := range result.Checks {
+					Expect(check.Present).To(BeTrue(), check.Name)
+					Expect(check.Version).To(Equal("v1"), check.Name)
+				}
+			},
+			Entry("core", RDSTypeCore),
+			Entry("ran", RDSTypeRAN),
+			Entry("hub", RDSTypeHub),
+		)
+
+		DescribeTable("reports the prerequisites a cluster lacks as absent",
+			func(rdsType string, absent ...string) {
+				prerequisites := rdsPrerequisites[rdsType]
+
+				result, err := checkRDSPrerequisites(newPrerequisitesTestDiscovery(prerequisites, absent...), rdsType, prerequisites)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.AllPresent).To(BeFalse())
+				Expect(result.Missing).To(Equal(absent))
+				for _, check := range result.Checks {
+					Expect(check.Present).To(Equal(!slices.Contains(absent, check.Name)), check.Name)
+					if !check.Present {
+						Expect(check.Version).To(BeEmpty())
+					}
+				}
+			},
+			Entry("core without SR-IOV and MetalLB", RDSTypeCore, "SR-IOV Network Operator", "MetalLB Operator"),
+			Entry("ran without PTP", RDSTypeRAN, "PTP Operator"),
+			Entry("hub without ACM and TALM", RDSTypeHub, "Advanced Cluster Management", "Topology Aware Lifecycle Manager"),
+		)
+
+		It("reports every prerequisite absent on a cluster without operators", func() {
+			prerequisites := rdsPrerequisites[RDSTypeCore]
+
+			result, err := checkRDSPrerequisites(&discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{}}, RDSTypeCore, prerequisites)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.AllPresent).To(BeFalse())
+			Expect(result.Missing).To(HaveLen(len(prerequisites)))
+		})
+
+		It("finds a resource served only at a version other than the preferred one", func() {
+			prerequisites := []PrerequisiteCheck{{Name: "PTP Operator", Group: "ptp.openshift.io", Resource: "ptpconfigs"}}
+			fake := &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+				{GroupVersion: "ptp.openshift.io/v2", APIResources: []metav1.APIResource{{Name: "ptpoperatorconfigs"}}},
+				{GroupVersion: "ptp.openshift.io/v1", APIResources: []metav1.APIResource{{Name: "ptpconfigs"}}},
+			}}
+
+			result, err := checkRDSPrerequisites(&discoveryfake.FakeDiscovery{Fake: fake}, RDSTypeRAN, prerequisites)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Checks[0].Present).To(BeTrue())
+			Expect(result.Checks[0].Version).To(Equal("v1"))
+		})
+
+		It("fails when the cluster cannot be reached", func() {
+			fake := &clienttesting.Fake{}
+			fake.AddReactor("get", "group", func(clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("connection refused")
+			})
+
+			_, err := checkRDSPrerequisites(&discoveryfake.FakeDiscovery{Fake: fake}, RDSTypeCore, rdsPrerequisites[RDSTypeCore])
+			Expect(err).To(MatchError(ErrClusterConnection))
+		})
+	})
+
+	Describe("HandleRDSPrerequisites", func() {
+		It("rejects an unknown RDS type", func() {
+			result, _, err := HandleRDSPrerequisites(context.Background(), &mcp.CallToolRequest{},
+				RDSPrerequisitesInput{RDSType: "edge"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+			Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("unknown RDS type 'edge'"))
+		})
+
+		It("requires a kubeconfig when a context is given", func() {
+			result, _, err := HandleRDSPrerequisites(context.Background(), &mcp.CallToolRequest{},
+				RDSPrerequisitesInput{RDSType: RDSTypeCore, Context: "spoke"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsError).To(BeTrue())
+		})
+	})
+})
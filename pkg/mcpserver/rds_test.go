@@ -5,7 +5,12 @@ package mcpserver_test
 import (
 	"context"
 	"errors"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
@@ -26,6 +31,8 @@ var _ = Describe("ReferenceHandler", func() {
 			Entry("nightly build", "4.18.0-0.nightly-2024-01-15-000000", "v4.18"),
 			Entry("EC build", "4.17.0-ec.1", "v4.17"),
 			Entry("just major.minor", "4.16", "v4.16"),
+			Entry("v prefix", "v4.18", "v4.18"),
+			Entry("v prefix with patch", "v4.18.3", "v4.18"),
 			Entry("single digit minor", "4.9.0", "v4.9"),
 			Entry("invalid version fallback", "invalid", "vinvalid"),
 		)
@@ -56,6 +63,19 @@ var _ = Describe("ReferenceHandler", func() {
 		)
 	})
 
+	Describe("BuildRDSDigestReference", func() {
+		It("names the image by digest, keeping the metadata path", func() {
+			digest := "sha256:3f0c2e1f5a5e3f6b7a9d0c8e4b1a2d3c4e5f60718293a4b5c6d7e8f90a1b2c3d"
+			reference := mcpserver.BuildRDSDigestReference(mcpserver.RDSTypeRAN, "rhel8", digest)
+			Expect(reference).To(ContainSubstring("ztp-site-generate-rhel8@" + digest + ":/"))
+
+			imageRef, metadataPath, err := mcpserver.ParseContainerReference(reference)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imageRef).To(HaveSuffix("ztp-site-generate-rhel8@" + digest))
+			Expect(metadataPath).To(HavePrefix("/"))
+		})
+	})
+
 	Describe("FilterVersionTags", func() {
 		DescribeTable("tag filtering",
 			func(tags []string, expected []string) {
@@ -154,11 +174,291 @@ var _ = Describe("ReferenceHandler", func() {
 		})
 
 		Context("with explicit OCP version", func() {
-			It("skips cluster version detection", func() {
-				// Expect registry calls for finding RHEL variant
+			DescribeTable("skips cluster version detection",
+				func(ocpVersion string) {
+					mockRegistry.EXPECT().
+						ListTags(gomock.Any(), gomock.Any()).
+						Return([]string{"v4.17", "v4.18", "v4.19"}, nil).
+						AnyTimes()
+					mockRegistry.EXPECT().
+						HeadImage(gomock.Any(), "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9:v4.18").
+						Return(nil)
+
+					args := &mcpserver.ResolveRDSArgs{
+						RDSType:    mcpserver.RDSTypeCore,
+						OCPVersion: ocpVersion,
+					}
+
+					result, err := service.ResolveRDS(context.Background(), args)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.ClusterVersion).To(Equal(ocpVersion))
+					Expect(result.Reference).To(ContainSubstring("-rhel9:v4.18:"))
+					Expect(result.Validated).To(BeTrue())
+				},
+				Entry("full version", "4.18.0"),
+				Entry("v-prefixed version", "v4.18"),
+			)
+		})
+
+		Context("with several RHEL variants", func() {
+			const coreBase = "registry.redhat.io/openshift4/openshift-telco-core-rds"
+
+			It("confirms the first matching variant without listing later ones", func() {
+				gomock.InOrder(
+					mockRegistry.EXPECT().
+						ListTags(gomock.Any(), coreBase+"-rhel9").
+						Return([]string{"v4.17", "v4.18"}, nil),
+					mockRegistry.EXPECT().
+						HeadImage(gomock.Any(), coreBase+"-rhel9:v4.18").
+						Return(nil),
+				)
+				// No expectation for the rhel8 repository: listing it fails the test
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeCore,
+					OCPVersion: "4.18",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RHELVersion).To(Equal("rhel9"))
+				Expect(result.Validated).To(BeTrue())
+			})
+
+			It("falls through to a later variant after a listing error", func() {
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel9").
+					Return(nil, errors.New("NAME_UNKNOWN"))
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel8").
+					Return([]string{"v4.14"}, nil)
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), coreBase+"-rhel8:v4.14").
+					Return(nil)
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeCore,
+					OCPVersion: "4.14",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RHELVersion).To(Equal("rhel8"))
+			})
+
+			It("explains choosing rhel8 when rhel9 lacks the version", func() {
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel9").
+					Return([]string{"v4.17", "v4.18"}, nil)
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel8").
+					Return([]string{"v4.14", "v4.15"}, nil)
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), coreBase+"-rhel8:v4.14").
+					Return(nil)
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeCore,
+					OCPVersion: "4.14",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.VariantSelection).To(Equal([]mcpserver.RHELVariantAttempt{
+					{RHELVariant: "rhel9", Repository: coreBase + "-rhel9", Reachable: true},
+					{RHELVariant: "rhel8", Repository: coreBase + "-rhel8", Reachable: true, VersionPresent: true, Chosen: true},
+				}))
+			})
+
+			It("records a variant whose repository could not be listed", func() {
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel9").
+					Return(nil, errors.New("NAME_UNKNOWN"))
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel8").
+					Return([]string{"v4.14"}, nil)
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), coreBase+"-rhel8:v4.14").
+					Return(nil)
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeCore,
+					OCPVersion: "4.14",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.VariantSelection).To(HaveLen(2))
+				Expect(result.VariantSelection[0].Reachable).To(BeFalse())
+				Expect(result.VariantSelection[0].VersionPresent).To(BeFalse())
+				Expect(result.VariantSelection[0].Error).NotTo(BeEmpty())
+				Expect(result.VariantSelection[1].Chosen).To(BeTrue())
+			})
+
+			It("reports an inaccessible matching image without trying later variants", func() {
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel9").
+					Return([]string{"v4.18"}, nil)
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), coreBase+"-rhel9:v4.18").
+					Return(errors.New("UNAUTHORIZED"))
+
+				_, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeCore,
+					OCPVersion: "4.18",
+				})
+				Expect(err).To(MatchError(ContainSubstring("rds image found but not accessible")))
+			})
+		})
+
+		Context("with a channel alias", func() {
+			const coreBase = "registry.redhat.io/openshift4/openshift-telco-core-rds"
+
+			It("accepts the alias tag and validates its image without detecting the cluster version", func() {
+				gomock.InOrder(
+					mockRegistry.EXPECT().
+						ListTags(gomock.Any(), coreBase+"-rhel9").
+						Return([]string{"v4.17", "v4.18", "stable", "sha256-0a1b.sig"}, nil),
+					mockRegistry.EXPECT().
+						HeadImage(gomock.Any(), coreBase+"-rhel9:stable").
+						Return(nil),
+				)
+				// No expectation on the cluster factory: detecting the version fails the test
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					Kubeconfig: EncodeKubeconfig(ValidKubeconfig),
+					RDSType:    mcpserver.RDSTypeCore,
+					Channel:    "stable",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Reference).To(HavePrefix("container://" + coreBase + "-rhel9:stable:/"))
+				Expect(result.Channel).To(Equal("stable"))
+				Expect(result.Floating).To(BeTrue())
+				Expect(result.Validated).To(BeTrue())
+				Expect(result.ClusterVersion).To(BeEmpty())
+				Expect(result.AvailableVersions).To(Equal([]string{"v4.17", "v4.18"}))
+			})
+
+			It("reports an absent alias with the aliases the repository has", func() {
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel9").
+					Return([]string{"v4.18", "stable", "eus-4.18"}, nil)
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel8").
+					Return([]string{"v4.14"}, nil)
+				// No HeadImage expectation: an absent alias is not validated
+
+				_, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType: mcpserver.RDSTypeCore,
+					Channel: "fast",
+				})
+				Expect(err).To(MatchError(ContainSubstring("rds image not found for channel fast")))
+				Expect(err.Error()).To(ContainSubstring("Available channel aliases: eus-4.18, stable"))
+			})
+
+			It("reports an alias whose image is not accessible", func() {
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreBase+"-rhel9").
+					Return([]string{"stable"}, nil)
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), coreBase+"-rhel9:stable").
+					Return(errors.New("MANIFEST_UNKNOWN"))
+
+				_, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType: mcpserver.RDSTypeCore,
+					Channel: "stable",
+				})
+				Expect(err).To(MatchError(ContainSubstring("rds image found but not accessible: stable")))
+			})
+		})
+
+		Context("with pin_digest", func() {
+			const (
+				coreImage = "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9"
+				digest    = "sha256:3f0c2e1f5a5e3f6b7a9d0c8e4b1a2d3c4e5f60718293a4b5c6d7e8f90a1b2c3d"
+			)
+
+			It("pins the reference to the digest of the version tag", func() {
+				gomock.InOrder(
+					mockRegistry.EXPECT().
+						ListTags(gomock.Any(), coreImage).
+						Return([]string{"v4.18"}, nil),
+					mockRegistry.EXPECT().
+						HeadImage(gomock.Any(), coreImage+":v4.18").
+						Return(nil),
+					mockRegistry.EXPECT().
+						Digest(gomock.Any(), coreImage+":v4.18").
+						Return(digest, nil),
+				)
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeCore,
+					OCPVersion: "4.18",
+					PinDigest:  true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Reference).To(Equal(mcpserver.BuildRDSDigestReference(mcpserver.RDSTypeCore, "rhel9", digest)))
+				Expect(result.ImageRef).To(Equal(coreImage + "@" + digest))
+				Expect(result.MetadataPath).To(Equal("/usr/share/telco-core-rds/configuration/reference-crs-kube-compare/metadata.yaml"))
+				Expect(result.Digest).To(Equal(digest))
+				Expect(result.AvailableVersions).To(Equal([]string{"v4.18"}))
+			})
+
+			It("resolves the digest again for a cached reference", func() {
+				service.Cache = mcpserver.NewRDSCache(time.Minute)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), coreImage).Return([]string{"v4.18"}, nil)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), coreImage+":v4.18").Return(nil)
+				mockRegistry.EXPECT().Digest(gomock.Any(), coreImage+":v4.18").Return(digest, nil).Times(2)
+
+				args := &mcpserver.ResolveRDSArgs{RDSType: mcpserver.RDSTypeCore, OCPVersion: "4.18", PinDigest: true}
+				_, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				result, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.ImageRef).To(Equal(coreImage + "@" + digest))
+
+				// The tag reference stays cached as resolved
+				args.PinDigest = false
+				result, err = service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.ImageRef).To(Equal(coreImage + ":v4.18"))
+				Expect(result.Digest).To(BeEmpty())
+			})
+
+			It("pins a channel alias, which is then no longer floating", func() {
+				mockRegistry.EXPECT().ListTags(gomock.Any(), coreImage).Return([]string{"stable", "v4.18"}, nil)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), coreImage+":stable").Return(nil)
+				mockRegistry.EXPECT().Digest(gomock.Any(), coreImage+":stable").Return(digest, nil)
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:   mcpserver.RDSTypeCore,
+					Channel:   "stable",
+					PinDigest: true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Channel).To(Equal("stable"))
+				Expect(result.Floating).To(BeFalse())
+				Expect(result.ImageRef).To(Equal(coreImage + "@" + digest))
+			})
+
+			It("reports a digest that cannot be resolved", func() {
+				mockRegistry.EXPECT().ListTags(gomock.Any(), coreImage).Return([]string{"v4.18"}, nil)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), coreImage+":v4.18").Return(nil)
+				mockRegistry.EXPECT().Digest(gomock.Any(), coreImage+":v4.18").Return("", errors.New("UNAUTHORIZED"))
+
+				_, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeCore,
+					OCPVersion: "4.18",
+					PinDigest:  true,
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to resolve digest of rds image")))
+			})
+		})
+
+		Context("with kubeconfig", func() {
+			It("detects cluster version from API", func() {
+				// Mock factory to return mock cluster client
+				mockFactory.EXPECT().
+					NewClient(gomock.Any()).
+					Return(mockCluster, nil)
+				mockCluster.EXPECT().
+					GetClusterVersion(gomock.Any()).
+					Return(&mcpserver.ClusterVersionInfo{Desired: "4.20.0-rc.1"}, nil)
 				mockRegistry.EXPECT().
 					ListTags(gomock.Any(), gomock.Any()).
-					Return([]string{"v4.17", "v4.18", "v4.19"}, nil).
+					Return([]string{"v4.18", "v4.19", "v4.20"}, nil).
 					AnyTimes()
 				mockRegistry.EXPECT().
 					HeadImage(gomock.Any(), gomock.Any()).
@@ -167,35 +467,84 @@ var _ = Describe("ReferenceHandler", func() {
 
 				args := &mcpserver.ResolveRDSArgs{
 					RDSType:    mcpserver.RDSTypeCore,
-					OCPVersion: "4.18.0",
+					Kubeconfig: EncodeKubeconfig(ValidKubeconfig),
 				}
 
 				result, err := service.ResolveRDS(context.Background(), args)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result.ClusterVersion).To(Equal("4.18.0"))
-				Expect(result.Reference).To(ContainSubstring("v4.18"))
-				Expect(result.Validated).To(BeTrue())
+				Expect(result.ClusterVersion).To(Equal("4.20.0-rc.1"))
+				Expect(result.Reference).To(ContainSubstring("v4.20"))
 			})
 		})
 
-		Context("with kubeconfig", func() {
-			It("detects cluster version from API", func() {
-				// Mock factory to return mock cluster client
+		Context("with a reference OCP version", func() {
+			const coreImage = "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9"
+
+			It("resolves the reference for the target version, not the detected one", func() {
+				mockFactory.EXPECT().
+					NewClient(gomock.Any()).
+					Return(mockCluster, nil)
+				mockCluster.EXPECT().
+					GetClusterVersion(gomock.Any()).
+					Return(&mcpserver.ClusterVersionInfo{Desired: "4.18.7"}, nil)
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreImage).
+					Return([]string{"v4.18", "v4.19", "v4.20"}, nil)
+				// No expectation for the v4.18 image: checking it fails the test
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), coreImage+":v4.20").
+					Return(nil)
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:             mcpserver.RDSTypeCore,
+					Kubeconfig:          EncodeKubeconfig(ValidKubeconfig),
+					ReferenceOCPVersion: "4.20",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.ClusterVersion).To(Equal("4.18.7"))
+				Expect(result.ReferenceVersion).To(Equal("v4.20"))
+				Expect(result.ImageRef).To(Equal(coreImage + ":v4.20"))
+			})
+
+			It("keeps the cluster version given with ocp_version", func() {
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), coreImage).
+					Return([]string{"v4.18", "v4.19"}, nil)
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), coreImage+":v4.19").
+					Return(nil)
+
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:             mcpserver.RDSTypeCore,
+					OCPVersion:          "4.18.2",
+					ReferenceOCPVersion: "4.19.1",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.ClusterVersion).To(Equal("4.18.2"))
+				Expect(result.ReferenceVersion).To(Equal("v4.19"))
+				Expect(result.Reference).To(ContainSubstring(":v4.19:"))
+			})
+		})
+
+		Context("with a cluster mid-upgrade", func() {
+			BeforeEach(func() {
 				mockFactory.EXPECT().
 					NewClient(gomock.Any()).
 					Return(mockCluster, nil)
 				mockCluster.EXPECT().
 					GetClusterVersion(gomock.Any()).
-					Return("4.20.0-rc.1", nil)
+					Return(&mcpserver.ClusterVersionInfo{Desired: "4.19.1", Completed: "4.18.5", Upgrading: true}, nil)
 				mockRegistry.EXPECT().
 					ListTags(gomock.Any(), gomock.Any()).
-					Return([]string{"v4.18", "v4.19", "v4.20"}, nil).
+					Return([]string{"v4.17", "v4.18", "v4.19"}, nil).
 					AnyTimes()
 				mockRegistry.EXPECT().
 					HeadImage(gomock.Any(), gomock.Any()).
 					Return(nil).
 					AnyTimes()
+			})
 
+			It("resolves the RDS for the last completed version", func() {
 				args := &mcpserver.ResolveRDSArgs{
 					RDSType:    mcpserver.RDSTypeCore,
 					Kubeconfig: EncodeKubeconfig(ValidKubeconfig),
@@ -203,8 +552,93 @@ var _ = Describe("ReferenceHandler", func() {
 
 				result, err := service.ResolveRDS(context.Background(), args)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result.ClusterVersion).To(Equal("4.20.0-rc.1"))
-				Expect(result.Reference).To(ContainSubstring("v4.20"))
+				Expect(result.ClusterVersion).To(Equal("4.18.5"))
+				Expect(result.DesiredVersion).To(Equal("4.19.1"))
+				Expect(result.UpgradeInProgress).To(BeTrue())
+				Expect(result.Reference).To(ContainSubstring("v4.18"))
+			})
+
+			It("reports the upgrade for every RDS type", func() {
+				args := &mcpserver.ResolveRDSArgs{
+					Kubeconfig: EncodeKubeconfig(ValidKubeconfig),
+				}
+
+				results, err := service.ResolveRDSTypes(context.Background(), args,
+					[]string{mcpserver.RDSTypeCore, mcpserver.RDSTypeRAN})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(HaveLen(2))
+				for _, result := range results {
+					Expect(result.ClusterVersion).To(Equal("4.18.5"))
+					Expect(result.DesiredVersion).To(Equal("4.19.1"))
+					Expect(result.UpgradeInProgress).To(BeTrue())
+				}
+			})
+		})
+
+		Context("with several RDS types", func() {
+			It("detects the cluster version once and resolves each type", func() {
+				mockFactory.EXPECT().
+					NewClient(gomock.Any()).
+					Return(mockCluster, nil).
+					Times(1)
+				mockCluster.EXPECT().
+					GetClusterVersion(gomock.Any()).
+					Return(&mcpserver.ClusterVersionInfo{Desired: "4.18.5"}, nil).
+					Times(1)
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), gomock.Any()).
+					Return([]string{"v4.17", "v4.18"}, nil).
+					AnyTimes()
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), gomock.Any()).
+					Return(nil).
+					AnyTimes()
+
+				args := &mcpserver.ResolveRDSArgs{
+					Kubeconfig: EncodeKubeconfig(ValidKubeconfig),
+				}
+
+				results, err := service.ResolveRDSTypes(context.Background(), args,
+					[]string{mcpserver.RDSTypeCore, mcpserver.RDSTypeRAN})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(HaveLen(2))
+				Expect(results[0].RDSType).To(Equal(mcpserver.RDSTypeCore))
+				Expect(results[0].Reference).To(ContainSubstring("telco-core-rds"))
+				Expect(results[1].RDSType).To(Equal(mcpserver.RDSTypeRAN))
+				Expect(results[1].Reference).To(ContainSubstring("ztp-site-generate"))
+				for _, result := range results {
+					Expect(result.ClusterVersion).To(Equal("4.18.5"))
+					Expect(result.Reference).To(ContainSubstring("v4.18"))
+				}
+			})
+		})
+
+		Context("with several RDS types and an explicit OCP version", func() {
+			It("skips cluster version detection even when a kubeconfig is provided", func() {
+				mockFactory.EXPECT().NewClient(gomock.Any()).Times(0)
+				mockCluster.EXPECT().GetClusterVersion(gomock.Any()).Times(0)
+				mockRegistry.EXPECT().
+					ListTags(gomock.Any(), gomock.Any()).
+					Return([]string{"v4.17", "v4.18"}, nil).
+					AnyTimes()
+				mockRegistry.EXPECT().
+					HeadImage(gomock.Any(), gomock.Any()).
+					Return(nil).
+					AnyTimes()
+
+				args := &mcpserver.ResolveRDSArgs{
+					Kubeconfig: EncodeKubeconfig(ValidKubeconfig),
+					OCPVersion: "4.17",
+				}
+
+				results, err := service.ResolveRDSTypes(context.Background(), args,
+					[]string{mcpserver.RDSTypeCore, mcpserver.RDSTypeRAN})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(HaveLen(2))
+				for _, result := range results {
+					Expect(result.ClusterVersion).To(Equal("4.17"))
+					Expect(result.Reference).To(ContainSubstring("v4.17"))
+				}
 			})
 		})
 
@@ -244,6 +678,184 @@ var _ = Describe("ReferenceHandler", func() {
 			})
 		})
 
+		Context("with a cache", func() {
+			args := &mcpserver.ResolveRDSArgs{
+				RDSType:    mcpserver.RDSTypeRAN,
+				OCPVersion: "4.18.0",
+			}
+
+			It("does not ask the registry again within the TTL", func() {
+				service.Cache = mcpserver.NewRDSCache(time.Minute)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.17", "v4.18"}, nil).Times(1)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+				first, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				second, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeRAN,
+					OCPVersion: "4.18.3",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(second.Reference).To(Equal(first.Reference))
+				Expect(second.AvailableVersions).To(Equal([]string{"v4.17", "v4.18"}))
+				Expect(second.ClusterVersion).To(Equal("4.18.3"))
+			})
+
+			It("does not report the variant selection of an earlier resolution", func() {
+				service.Cache = mcpserver.NewRDSCache(time.Minute)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.18"}, nil).Times(1)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+				first, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(first.VariantSelection).NotTo(BeEmpty())
+				second, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(second.Reference).To(Equal(first.Reference))
+				Expect(second.VariantSelection).To(BeEmpty())
+			})
+
+			It("does not serve a reference resolved with a previous RDS config", func() {
+				service.Cache = mcpserver.NewRDSCache(time.Minute)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), "registry.redhat.io/openshift4/ztp-site-generate-rhel8").Return([]string{"v4.18"}, nil)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), "mirror.example.com/openshift4/ztp-site-generate-rhel8").Return([]string{"v4.18"}, nil)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+				_, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+
+				mcpserver.SetRDSConfigs([]mcpserver.RDSConfigEntry{{
+					Type:         mcpserver.RDSTypeRAN,
+					ImageBase:    "mirror.example.com/openshift4/ztp-site-generate",
+					Path:         "/home/ztp/reference/metadata.yaml",
+					RHELVariants: []string{"rhel8"},
+				}})
+				DeferCleanup(mcpserver.SetRDSConfigs, []mcpserver.RDSConfigEntry(nil))
+
+				result, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.ImageRef).To(HavePrefix("mirror.example.com/"))
+			})
+
+			It("asks the registry again once the TTL has passed", func() {
+				service.Cache = mcpserver.NewRDSCache(20 * time.Millisecond)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.18"}, nil).Times(2)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+				_, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				time.Sleep(40 * time.Millisecond)
+				_, err = service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not cache a failed resolution", func() {
+				service.Cache = mcpserver.NewRDSCache(time.Minute)
+				gomock.InOrder(
+					mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return(nil, errors.New("NAME_UNKNOWN")),
+					mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.18"}, nil),
+				)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil)
+
+				_, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).To(HaveOccurred())
+				result, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Validated).To(BeTrue())
+			})
+
+			It("keeps each RDS type and version apart", func() {
+				service.Cache = mcpserver.NewRDSCache(time.Minute)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.18", "v4.19"}, nil).Times(2)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+				_, err := service.ResolveRDS(context.Background(), args)
+				Expect(err).NotTo(HaveOccurred())
+				result, err := service.ResolveRDS(context.Background(), &mcpserver.ResolveRDSArgs{
+					RDSType:    mcpserver.RDSTypeRAN,
+					OCPVersion: "4.19",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Reference).To(ContainSubstring(":v4.19:"))
+			})
+
+			It("is safe for concurrent resolutions", func() {
+				service.Cache = mcpserver.NewRDSCache(time.Minute)
+				mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.18"}, nil).MinTimes(1)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil).MinTimes(1)
+
+				var wg sync.WaitGroup
+				for range 8 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						defer GinkgoRecover()
+						_, err := service.ResolveRDS(context.Background(), args)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+				}
+				wg.Wait()
+			})
+		})
+
+		Context("when the registry fails transiently", func() {
+			ranArgs := &mcpserver.ResolveRDSArgs{
+				RDSType:    mcpserver.RDSTypeRAN,
+				OCPVersion: "4.18.0",
+			}
+			unavailable := &transport.Error{StatusCode: http.StatusServiceUnavailable}
+
+			It("retries listing tags until the registry recovers", func() {
+				gomock.InOrder(
+					mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return(nil, unavailable),
+					mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.18"}, nil),
+				)
+				mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil)
+
+				result, err := service.ResolveRDS(context.Background(), ranArgs)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Validated).To(BeTrue())
+			})
+
+			It("retries checking the image after a reset connection", func() {
+				mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return([]string{"v4.18"}, nil)
+				gomock.InOrder(
+					mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(syscall.ECONNRESET),
+					mockRegistry.EXPECT().HeadImage(gomock.Any(), gomock.Any()).Return(nil),
+				)
+
+				_, err := service.ResolveRDS(context.Background(), ranArgs)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("gives up after KUBE_COMPARE_MCP_REGISTRY_RETRIES attempts", func() {
+				GinkgoT().Setenv("KUBE_COMPARE_MCP_REGISTRY_RETRIES", "2")
+				mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return(nil, unavailable).Times(2)
+
+				_, err := service.ResolveRDS(context.Background(), ranArgs)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("could not find RDS image"))
+			})
+
+			DescribeTable("does not retry errors that are not transient",
+				func(registryErr error, expected string) {
+					mockRegistry.EXPECT().ListTags(gomock.Any(), gomock.Any()).Return(nil, registryErr).Times(1)
+
+					_, err := service.ResolveRDS(context.Background(), ranArgs)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring(expected))
+				},
+				Entry("unauthorized", &transport.Error{
+					StatusCode: http.StatusUnauthorized,
+					Errors:     []transport.Diagnostic{{Code: transport.UnauthorizedErrorCode}},
+				}, "authentication"),
+				Entry("name unknown", &transport.Error{
+					StatusCode: http.StatusNotFound,
+					Errors:     []transport.Diagnostic{{Code: transport.NameUnknownErrorCode}},
+				}, "repository not found"),
+			)
+		})
+
 		Context("when image validation fails", func() {
 			It("returns validation error", func() {
 				mockRegistry.EXPECT().
@@ -305,6 +917,40 @@ var _ = Describe("ReferenceHandler", func() {
 				Expect(result.Reference).To(ContainSubstring("telco-hub-rds"))
 			})
 		})
+
+		Context("decomposed reference fields", func() {
+			DescribeTable("match the combined container reference",
+				func(rdsType, ocpVersion string) {
+					mockRegistry.EXPECT().
+						ListTags(gomock.Any(), gomock.Any()).
+						Return([]string{"v4.17", "v4.18", "v4.19"}, nil).
+						AnyTimes()
+					mockRegistry.EXPECT().
+						HeadImage(gomock.Any(), gomock.Any()).
+						Return(nil).
+						AnyTimes()
+
+					args := &mcpserver.ResolveRDSArgs{
+						RDSType:    rdsType,
+						OCPVersion: ocpVersion,
+					}
+
+					result, err := service.ResolveRDS(context.Background(), args)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.ImageRef).NotTo(BeEmpty())
+					Expect(result.MetadataPath).To(HavePrefix("/"))
+					Expect(result.MetadataPath).To(HaveSuffix("metadata.yaml"))
+					Expect(result.Reference).To(Equal("container://" + result.ImageRef + ":" + result.MetadataPath))
+
+					image, path, err := mcpserver.ParseContainerReference(result.Reference)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.ImageRef).To(Equal(image))
+					Expect(result.MetadataPath).To(Equal(path))
+				},
+				Entry("core RDS", mcpserver.RDSTypeCore, "4.18.0"),
+				Entry("RAN RDS", mcpserver.RDSTypeRAN, "4.18.0"),
+			)
+		})
 	})
 
 	Describe("ResolveRDSTool", func() {
@@ -317,6 +963,10 @@ var _ = Describe("ReferenceHandler", func() {
 		It("has a description", func() {
 			Expect(tool.Description).NotTo(BeEmpty())
 		})
+
+		It("has an output schema", func() {
+			Expect(tool.OutputSchema).NotTo(BeNil())
+		})
 	})
 
 	Describe("GetRDSConfigs", func() {
@@ -449,7 +1099,7 @@ var _ = Describe("ReferenceHandler", func() {
 				Return(mockCluster, nil)
 			mockCluster.EXPECT().
 				GetClusterVersion(gomock.Any()).
-				Return("4.19.0", nil)
+				Return(&mcpserver.ClusterVersionInfo{Desired: "4.19.0"}, nil)
 			mockRegistry.EXPECT().
 				ListTags(gomock.Any(), gomock.Any()).
 				Return([]string{"v4.18", "v4.19", "v4.20"}, nil).
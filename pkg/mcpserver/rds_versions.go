@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListRDSVersionsInput defines the typed input for the list_rds_versions tool.
+type ListRDSVersionsInput struct {
+	RDSType string `json:"rds_type" jsonschema:"RDS type whose versions to list: core for Telco Core RDS, ran for Telco RAN DU RDS, or hub for Telco Hub RDS"`
+}
+
+// RDSVariantVersions is the versions published in the repository of one RHEL variant.
+type RDSVariantVersions struct {
+	RHELVariant string   `json:"rhel_variant"`
+	Repository  string   `json:"repository"`
+	Versions    []string `json:"versions"`
+	// Error is why the repository's tags could not be listed
+	Error string `json:"error,omitempty"`
+}
+
+// RDSVersion is one published version and the RHEL variants it is published for.
+type RDSVersion struct {
+	Version string `json:"version"`
+	// RHELVariants are in the order kube_compare_resolve_rds prefers them
+	RHELVariants []string `json:"rhel_variants"`
+}
+
+// ListRDSVersionsResult is the structured response for the list_rds_versions tool.
+type ListRDSVersionsResult struct {
+	RDSType  string               `json:"rds_type"`
+	Versions []RDSVersion         `json:"versions"`
+	Variants []RDSVariantVersions `json:"variants"`
+}
+
+// ListRDSVersionsTool returns the MCP tool definition for listing RDS versions.
+func ListRDSVersionsTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:  "list_rds_versions",
+		Title: "List RDS Versions",
+		Description: "List the OpenShift versions with a published Red Hat Telco RDS image of a type, " +
+			"and the RHEL variants each is published for. Use it to check that an RDS exists for a version " +
+			"before resolving or comparing against it.",
+		InputSchema:  ListRDSVersionsInputSchema(),
+		OutputSchema: ListRDSVersionsOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleListRDSVersions is the MCP tool handler for the list_rds_versions tool.
+func HandleListRDSVersions(ctx context.Context, req *mcp.CallToolRequest, input ListRDSVersionsInput) (toolResult *mcp.CallToolResult, result *ListRDSVersionsResult, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Debug("Received tool request", "tool", "list_rds_versions", "rdsType", input.RDSType)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	// The SDK validates the enum, but direct callers bypass it
+	rdsType, err := normalizeRDSType(input.RDSType)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	result, err = defaultReferenceService.ListRDSVersions(ctx, rdsType)
+	if err != nil {
+		logger.Debug("Failed to list RDS versions", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	jsonOutput, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Error("Failed to marshal result", "error", err)
+		return newToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil, nil
+	}
+
+	logger.Info("RDS versions listed",
+		"duration", time.Since(start),
+		"rdsType", rdsType,
+		"versions", len(result.Versions),
+	)
+
+	return newToolResultText(string(jsonOutput)), result, nil
+}
+
+// ListRDSVersions lists the version tags of every RHEL variant repository of rdsType,
+// leaving out versions below the type's minimum OpenShift version. A variant whose
+// tags cannot be listed is reported with its error; only when no variant can be
+// listed is the call an error.
+func (s *ReferenceService) ListRDSVersions(ctx context.Context, rdsType string) (*ListRDSVersionsResult, error) {
+	logger := slog.Default()
+
+	cfg, ok := getRDSConfig(rdsType)
+	if !ok {
+		return nil, NewValidationError("rds_type",
+			fmt.Sprintf("unknown RDS type '%s'", rdsType),
+			"Supported RDS types are "+strings.Join(getRDSTypes(), ", "))
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+
+	result := &ListRDSVersionsResult{
+		RDSType:  rdsType,
+		Versions: []RDSVersion{},
+		Variants: make([]RDSVariantVersions, 0, len(cfg.RHELVariants)),
+	}
+	variantsOf := make(map[string][]string)
+	var lastErr error
+	listed := 0
+	for _, rhel := range cfg.RHELVariants {
+		repoRef := fmt.Sprintf("%s-%s", cfg.ImageBase, rhel)
+		variant := RDSVariantVersions{RHELVariant: rhel, Repository: repoRef, Versions: []string{}}
+
+		tags, err := s.listTags(listCtx, repoRef)
+		if err != nil {
+			logger.Debug("Failed to list tags for variant", "variant", rhel, "error", err)
+			lastErr = wrapRegistryError(err, repoRef)
+			variant.Error = lastErr.Error()
+			result.Variants = append(result.Variants, variant)
+			continue
+		}
+		listed++
+
+		for _, version := range FilterVersionTags(tags) {
+			if cfg.MinOCPVersion != "" && CompareVersionTags(version, cfg.MinOCPVersion) < 0 {
+				continue
+			}
+			variant.Versions = append(variant.Versions, version)
+			variantsOf[version] = append(variantsOf[version], rhel)
+		}
+		result.Variants = append(result.Variants, variant)
+	}
+
+	if listed == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	versions := make([]string, 0, len(variantsOf))
+	for version := range variantsOf {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareVersionTags(versions[i], versions[j]) < 0
+	})
+	for _, version := range versions {
+		result.Versions = append(result.Versions, RDSVersion{
+			Version:      version,
+			RHELVariants: variantsOf[version],
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
+)
+
+var _ = Describe("ListRDSVersions", func() {
+	var (
+		ctrl         *gomock.Controller
+		mockRegistry *MockRegistryClient
+		service      *mcpserver.ReferenceService
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockRegistry = NewMockRegistryClient(ctrl)
+		service = &mcpserver.ReferenceService{Registry: mockRegistry}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("lists the sorted versions of each RHEL variant and the variants of each version", func() {
+		mockRegistry.EXPECT().
+			ListTags(gomock.Any(), "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9").
+			Return([]string{"v4.19", "latest", "v4.18", "v4.20", "sha256-abc.sig"}, nil)
+		mockRegistry.EXPECT().
+			ListTags(gomock.Any(), "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel8").
+			Return([]string{"v4.16", "v4.18", "v4.17"}, nil)
+
+		result, err := service.ListRDSVersions(context.Background(), mcpserver.RDSTypeCore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RDSType).To(Equal(mcpserver.RDSTypeCore))
+		Expect(result.Variants).To(HaveLen(2))
+		Expect(result.Variants[0].RHELVariant).To(Equal("rhel9"))
+		Expect(result.Variants[0].Versions).To(Equal([]string{"v4.18", "v4.19", "v4.20"}))
+		Expect(result.Variants[1].RHELVariant).To(Equal("rhel8"))
+		Expect(result.Variants[1].Versions).To(Equal([]string{"v4.16", "v4.17", "v4.18"}))
+		Expect(result.Versions).To(Equal([]mcpserver.RDSVersion{
+			{Version: "v4.16", RHELVariants: []string{"rhel8"}},
+			{Version: "v4.17", RHELVariants: []string{"rhel8"}},
+			{Version: "v4.18", RHELVariants: []string{"rhel9", "rhel8"}},
+			{Version: "v4.19", RHELVariants: []string{"rhel9"}},
+			{Version: "v4.20", RHELVariants: []string{"rhel9"}},
+		}))
+	})
+
+	It("leaves out versions below the RDS type's minimum OpenShift version", func() {
+		mockRegistry.EXPECT().
+			ListTags(gomock.Any(), gomock.Any()).
+			Return([]string{"v4.18", "v4.19", "v4.20"}, nil).
+			Times(2)
+
+		result, err := service.ListRDSVersions(context.Background(), mcpserver.RDSTypeHub)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Versions).To(HaveLen(2))
+		Expect(result.Versions[0].Version).To(Equal("v4.19"))
+	})
+
+	It("reports a variant whose tags cannot be listed with its error", func() {
+		mockRegistry.EXPECT().
+			ListTags(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, repo string) ([]string, error) {
+				if strings.HasSuffix(repo, "-rhel9") {
+					return nil, errors.New("NAME_UNKNOWN: repository name not known to registry")
+				}
+				return []string{"v4.16"}, nil
+			}).
+			Times(2)
+
+		result, err := service.ListRDSVersions(context.Background(), mcpserver.RDSTypeCore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Variants[0].Error).To(ContainSubstring("repository not found"))
+		Expect(result.Variants[0].Versions).To(BeEmpty())
+		Expect(result.Versions).To(Equal([]mcpserver.RDSVersion{{Version: "v4.16", RHELVariants: []string{"rhel8"}}}))
+	})
+
+	It("fails when no variant can be listed", func() {
+		mockRegistry.EXPECT().
+			ListTags(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("UNAUTHORIZED: authentication required"))
+
+		_, err := service.ListRDSVersions(context.Background(), mcpserver.RDSTypeRAN)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("authentication"))
+	})
+
+	Describe("ListRDSVersionsTool", func() {
+		It("constrains rds_type to the known RDS types", func() {
+			tool := mcpserver.ListRDSVersionsTool()
+			Expect(tool.Name).To(Equal("list_rds_versions"))
+			Expect(tool.OutputSchema).NotTo(BeNil())
+			Expect(mcpserver.ListRDSVersionsInputSchema().Properties["rds_type"].Enum).To(ContainElements("core", "ran", "hub"))
+		})
+	})
+
+	Describe("HandleListRDSVersions", func() {
+		It("rejects an unknown RDS type", func() {
+			result, versions, err := mcpserver.HandleListRDSVersions(context.Background(), &mcp.CallToolRequest{},
+				mcpserver.ListRDSVersionsInput{RDSType: "edge"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(BeNil())
+			Expect(result.IsError).To(BeTrue())
+			Expect(result.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("unknown RDS type 'edge'"))
+		})
+	})
+})
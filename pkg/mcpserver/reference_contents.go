@@ -0,0 +1,266 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultMaxReferenceEntries is the number of entries listed when max_entries is unset.
+	DefaultMaxReferenceEntries = 200
+	// MaxAllowedReferenceEntries is the largest max_entries a caller may request.
+	MaxAllowedReferenceEntries = 2000
+)
+
+// ListReferenceContentsInput defines the typed input for the kube_compare_list_reference_contents tool.
+type ListReferenceContentsInput struct {
+	Reference  string `json:"reference" jsonschema:"Image reference including the expected path of metadata.yaml, e.g. container://registry/image:tag:/path/to/metadata.yaml"`
+	MaxEntries int    `json:"max_entries,omitempty" jsonschema:"Maximum number of entries to list (default 200, maximum 2000)."`
+}
+
+// ReferenceEntry is one entry of an image filesystem.
+type ReferenceEntry struct {
+	Path       string `json:"path"`
+	Type       string `json:"type"`
+	Size       int64  `json:"size,omitempty"`
+	LinkTarget string `json:"link_target,omitempty"`
+}
+
+// ReferenceContentsResult is the structured response for the kube_compare_list_reference_contents tool.
+type ReferenceContentsResult struct {
+	Reference   string           `json:"reference"`
+	ImageDigest string           `json:"image_digest,omitempty"`
+	TargetPath  string           `json:"target_path"`
+	TargetFound bool             `json:"target_found"`
+	SearchRoot  string           `json:"search_root"`
+	Entries     []ReferenceEntry `json:"entries"`
+	Truncated   bool             `json:"truncated"`
+	// MetadataFiles lists every metadata.yaml under SearchRoot, including those past
+	// max_entries, up to the number a directory reference may compare against
+	MetadataFiles []string `json:"metadata_files,omitempty"`
+}
+
+// ListReferenceContentsTool returns the MCP tool definition for listing reference image contents.
+func ListReferenceContentsTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:  "kube_compare_list_reference_contents",
+		Title: "List Reference Contents",
+		Description: "List the files of a reference image near the expected metadata.yaml path without extracting them. " +
+			"Use this to find the correct path when a comparison fails with 'target file not found'.",
+		InputSchema:  ListReferenceContentsInputSchema(),
+		OutputSchema: ListReferenceContentsOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleListReferenceContents is the MCP tool handler for the kube_compare_list_reference_contents tool.
+func HandleListReferenceContents(ctx context.Context, req *mcp.CallToolRequest, input ListReferenceContentsInput) (toolResult *mcp.CallToolResult, result *ReferenceContentsResult, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Info("Received tool request",
+		"tool", "kube_compare_list_reference_contents",
+		"reference", input.Reference,
+		"maxEntries", input.MaxEntries,
+	)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	if err := validateReferenceNotEmpty(input.Reference); err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	maxEntries, err := normalizeMaxEntries(input.MaxEntries)
+	if err != nil {
+		logger.Debug("Validation failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	result, err = listReferenceContents(ctx, input.Reference, maxEntries)
+	if err != nil {
+		logger.Debug("Listing reference contents failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	logger.Info("Reference contents listed",
+		"duration", time.Since(start),
+		"entries", len(result.Entries),
+		"truncated", result.Truncated,
+		"targetFound", result.TargetFound,
+	)
+
+	return newToolResultText(string(outputBytes)), result, nil
+}
+
+// normalizeMaxEntries applies the default to an unset max_entries and rejects
+// values outside the allowed range.
+func normalizeMaxEntries(maxEntries int) (int, error) {
+	if maxEntries == 0 {
+		return DefaultMaxReferenceEntries, nil
+	}
+	if maxEntries < 0 || maxEntries > MaxAllowedReferenceEntries {
+		return 0, NewValidationError("max_entries",
+			fmt.Sprintf("max_entries must be between 1 and %d, got %d", MaxAllowedReferenceEntries, maxEntries),
+			"Omit max_entries to use the default")
+	}
+	return maxEntries, nil
+}
+
+// listReferenceContents loads the image of a container:// or local image reference
+// and lists the entries near the reference's file path.
+func listReferenceContents(ctx context.Context, reference string, maxEntries int) (*ReferenceContentsResult, error) {
+	var (
+		img        v1.Image
+		digest     string
+		targetPath string
+	)
+
+	switch ClassifyReference(reference) {
+	case ReferenceTypeOCI:
+		verifiedDigest, err := validateOCIReference(ctx, reference)
+		if err != nil {
+			return nil, err
+		}
+		imageRef, filePath, err := ParseContainerReference(reference)
+		if err != nil {
+			return nil, err
+		}
+		var release context.CancelFunc
+		img, digest, release, err = defaultCompareService.pullContainerImage(ctx, imageRef, "", verifiedDigest)
+		if err != nil {
+			return nil, NewCompareError("list",
+				err,
+				"Verify the container image is correct. Check registry authentication if needed.")
+		}
+		defer release()
+		targetPath = filePath
+
+	case ReferenceTypeLocalImage:
+		prefix, imagePath, filePath, err := resolveLocalImageReference(reference)
+		if err != nil {
+			return nil, err
+		}
+		img, err = loadLocalImage(prefix, imagePath, "")
+		if err != nil {
+			return nil, NewCompareError("list", err, "Verify the image layout or archive path is correct.")
+		}
+		if imageDigest, err := img.Digest(); err == nil {
+			digest = imageDigest.String()
+		}
+		targetPath = filePath
+
+	default:
+		return nil, NewValidationError("reference",
+			"only image references can be listed",
+			"Use format: container://registry/image:tag:/path/to/metadata.yaml")
+	}
+
+	result, err := listImageFiles(ctx, img, targetPath, maxEntries)
+	if err != nil {
+		return nil, NewCompareError("list", err, "")
+	}
+	result.Reference = reference
+	result.ImageDigest = digest
+	return result, nil
+}
+
+// listImageFiles lists the entries of the flattened image filesystem under the parent
+// of the directory containing targetPath, the same area searched for alternatives when
+// the target is missing, or under targetPath itself when it names a directory. Nothing
+// is written to disk. At most maxEntries entries are listed; the walk continues past
+// the limit to learn whether the target exists and to find every metadata.yaml.
+func listImageFiles(ctx context.Context, img v1.Image, targetPath string, maxEntries int) (*ReferenceContentsResult, error) {
+	targetPath = strings.TrimPrefix(targetPath, "/")
+	searchRoot := filepath.Dir(filepath.Dir(targetPath))
+	if strings.HasSuffix(targetPath, "/") {
+		searchRoot = filepath.Clean(targetPath)
+	}
+
+	result := &ReferenceContentsResult{
+		TargetPath: "/" + targetPath,
+		SearchRoot: "/" + strings.TrimPrefix(searchRoot, "."),
+		Entries:    []ReferenceEntry{},
+	}
+	err := walkImageFiles(ctx, img, func(header *tar.Header, fileName string, _ *tar.Reader) error {
+		if fileName == targetPath && header.Typeflag == tar.TypeReg {
+			result.TargetFound = true
+		}
+		if !underSearchRoot(fileName, searchRoot) {
+			return nil
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(fileName) == referenceMetadataFileName &&
+			len(result.MetadataFiles) < maxDirectoryMetadataFiles {
+			result.MetadataFiles = append(result.MetadataFiles, "/"+fileName)
+		}
+		if len(result.Entries) >= maxEntries {
+			result.Truncated = true
+			return nil
+		}
+		result.Entries = append(result.Entries, newReferenceEntry(header, fileName))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// newReferenceEntry describes a tar entry.
+func newReferenceEntry(header *tar.Header, fileName string) ReferenceEntry {
+	entry := ReferenceEntry{Path: "/" + strings.TrimSuffix(fileName, "/")}
+	switch header.Typeflag {
+	case tar.TypeReg:
+		entry.Type = "file"
+		entry.Size = header.Size
+	case tar.TypeDir:
+		entry.Type = "directory"
+	case tar.TypeSymlink:
+		entry.Type = "symlink"
+		entry.LinkTarget = header.Linkname
+	case tar.TypeLink:
+		entry.Type = "hardlink"
+		entry.LinkTarget = header.Linkname
+	default:
+		entry.Type = "other"
+	}
+	return entry
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reference contents", func() {
+	const metadataContent = "apiVersion: v2\nparts: []\n"
+	const targetPath = "/usr/share/telco-core-rds/configuration/reference-crs-kube-compare/metadata.yaml"
+
+	entryPaths := func(entries []ReferenceEntry) []string {
+		paths := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			paths = append(paths, entry.Path)
+		}
+		return paths
+	}
+
+	Describe("listImageFiles", func() {
+		It("lists the entries near the target without writing to disk", func() {
+			img := newTestReferenceImage(map[string]string{
+				"usr/share/telco-core-rds/configuration/kube-compare-reference/metadata.yaml": metadataContent,
+				"usr/share/telco-core-rds/configuration/kube-compare-reference/crs/a.yaml":    "kind: A\n",
+				"usr/share/other/metadata.yaml":                                               metadataContent,
+			})
+
+			result, err := listImageFiles(context.Background(), img, targetPath, DefaultMaxReferenceEntries)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.TargetPath).To(Equal(targetPath))
+			Expect(result.TargetFound).To(BeFalse())
+			Expect(result.SearchRoot).To(Equal("/usr/share/telco-core-rds/configuration"))
+			Expect(result.Truncated).To(BeFalse())
+			Expect(entryPaths(result.Entries)).To(ConsistOf(
+				"/usr/share/telco-core-rds/configuration/kube-compare-reference/metadata.yaml",
+				"/usr/share/telco-core-rds/configuration/kube-compare-reference/crs/a.yaml",
+			))
+			Expect(result.Entries).To(ContainElement(ReferenceEntry{
+				Path: "/usr/share/telco-core-rds/configuration/kube-compare-reference/crs/a.yaml",
+				Type: "file",
+				Size: int64(len("kind: A\n")),
+			}))
+		})
+
+		It("reports whether the target exists", func() {
+			img := newTestReferenceImage(map[string]string{
+				"reference/metadata.yaml": metadataContent,
+			})
+
+			result, err := listImageFiles(context.Background(), img, "/reference/metadata.yaml", DefaultMaxReferenceEntries)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.TargetFound).To(BeTrue())
+			Expect(result.SearchRoot).To(Equal("/"))
+			Expect(entryPaths(result.Entries)).To(ConsistOf("/reference/metadata.yaml"))
+		})
+
+		It("stops listing at max entries", func() {
+			files := map[string]string{"reference/metadata.yaml": metadataContent}
+			for i := range 10 {
+				files[fmt.Sprintf("reference/crs/cr-%d.yaml", i)] = "kind: CR\n"
+			}
+			img := newTestReferenceImage(files)
+
+			result, err := listImageFiles(context.Background(), img, "/reference/metadata.yaml", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Entries).To(HaveLen(3))
+			Expect(result.Truncated).To(BeTrue())
+			Expect(result.TargetFound).To(BeTrue())
+		})
+
+		It("stops when the context is canceled", func() {
+			img := newTestReferenceImage(map[string]string{"reference/metadata.yaml": metadataContent})
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := listImageFiles(ctx, img, "/reference/metadata.yaml", DefaultMaxReferenceEntries)
+			Expect(err).To(MatchError(ContainSubstring("extraction canceled")))
+		})
+	})
+
+	Describe("normalizeMaxEntries", func() {
+		It("defaults an unset value", func() {
+			Expect(normalizeMaxEntries(0)).To(Equal(DefaultMaxReferenceEntries))
+		})
+
+		It("rejects values outside the allowed range", func() {
+			_, err := normalizeMaxEntries(-1)
+			Expect(err).To(MatchError(ContainSubstring("max_entries")))
+			_, err = normalizeMaxEntries(MaxAllowedReferenceEntries + 1)
+			Expect(err).To(MatchError(ContainSubstring("max_entries")))
+		})
+	})
+
+	Describe("HandleListReferenceContents", func() {
+		It("lists a local image archive", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_ALLOW_LOCAL_IMAGES", "true")
+			img := newTestReferenceImage(map[string]string{
+				"reference/metadata.yaml":       metadataContent,
+				"reference/templates/node.yaml": "kind: Node\n",
+			})
+			root := GinkgoT().TempDir()
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_LOCAL_IMAGE_ROOT", root)
+			archPath := filepath.Join(root, "image.tar")
+			tag, err := name.NewTag("example.com/reference:latest")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarball.WriteToFile(archPath, tag, img)).To(Succeed())
+
+			toolResult, result, err := HandleListReferenceContents(context.Background(), &mcp.CallToolRequest{},
+				ListReferenceContentsInput{Reference: ociArchivePrefix + archPath + ":/reference/metadata.yaml"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(toolResult.IsError).To(BeFalse())
+			Expect(result.TargetFound).To(BeTrue())
+			Expect(result.ImageDigest).To(HavePrefix("sha256:"))
+			Expect(entryPaths(result.Entries)).To(ConsistOf("/reference/metadata.yaml", "/reference/templates/node.yaml"))
+
+			_, statErr := os.Stat(filepath.Join(filepath.Dir(archPath), "reference"))
+			Expect(os.IsNotExist(statErr)).To(BeTrue())
+		})
+
+		It("rejects an empty reference", func() {
+			toolResult, _, err := HandleListReferenceContents(context.Background(), &mcp.CallToolRequest{},
+				ListReferenceContentsInput{Reference: " "})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(toolResult.IsError).To(BeTrue())
+			Expect(toolResult.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("reference is required"))
+		})
+
+		It("rejects references that are not images", func() {
+			toolResult, _, err := HandleListReferenceContents(context.Background(), &mcp.CallToolRequest{},
+				ListReferenceContentsInput{Reference: "https://example.com/metadata.yaml"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(toolResult.IsError).To(BeTrue())
+			Expect(toolResult.Content[0].(*mcp.TextContent).Text).To(ContainSubstring("only image references can be listed"))
+		})
+	})
+})
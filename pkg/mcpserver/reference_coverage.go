@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// ReferenceCoverage lists the templates a reference declares and the resource kinds
+// they cover. It is returned when include_reference_coverage is set.
+type ReferenceCoverage struct {
+	Reference string `json:"reference"`
+	// Kinds are the distinct apiVersion/kind pairs of the templates, sorted
+	Kinds     []string           `json:"kinds"`
+	Templates []CoverageTemplate `json:"templates"`
+}
+
+// CoverageTemplate is one template declared by a reference. Fields that the template
+// sets through template expressions, rather than literally, are left empty.
+type CoverageTemplate struct {
+	Path       string `json:"path"`
+	Part       string `json:"part"`
+	Component  string `json:"component"`
+	APIVersion string `json:"api_version,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// content returns the MCP content block carrying the coverage as JSON text.
+func (c *ReferenceCoverage) content() (mcp.Content, error) {
+	coverageJSON, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format reference coverage: %w", err)
+	}
+	return &mcp.TextContent{Text: string(coverageJSON)}, nil
+}
+
+// loadReferenceCoverage reads referenceConfig, a local metadata.yaml path or an
+// HTTP/HTTPS URL, and each template it declares, and returns the reference's coverage.
+// reference is the reference as the caller gave it.
+func (s *CompareService) loadReferenceCoverage(ctx context.Context, reference, referenceConfig string) (*ReferenceCoverage, error) {
+	data, err := s.readReferenceMetadata(ctx, referenceConfig)
+	if err != nil {
+		return nil, err
+	}
+	var metadata referenceMetadata
+	if err := sigsyaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse reference metadata: %w", err)
+	}
+
+	coverage := &ReferenceCoverage{Reference: reference, Templates: []CoverageTemplate{}}
+	kinds := make(map[string]bool)
+	for _, part := range metadata.Parts {
+		for _, component := range part.Components {
+			for _, templatePath := range component.templates() {
+				template := CoverageTemplate{Path: templatePath.Path, Part: part.Name, Component: component.Name}
+				// Templates outside the reference directory are listed but not read
+				if location, ok := referenceTemplateLocation(referenceConfig, templatePath.Path); ok {
+					content, err := s.readReferenceFile(ctx, location, "reference template "+templatePath.Path)
+					if err != nil {
+						return nil, err
+					}
+					readTemplateIdentity(content, &template)
+				}
+				if template.Kind != "" {
+					kinds[strings.TrimPrefix(template.APIVersion+"/"+template.Kind, "/")] = true
+				}
+				coverage.Templates = append(coverage.Templates, template)
+			}
+		}
+	}
+	coverage.Kinds = slices.Sorted(maps.Keys(kinds))
+	return coverage, nil
+}
+
+// referenceTemplateLocation returns where the template at templatePath, relative to
+// the directory of referenceConfig, is read from. It reports false for a path that
+// leaves that directory.
+func referenceTemplateLocation(referenceConfig, templatePath string) (string, bool) {
+	if !filepath.IsLocal(templatePath) {
+		return "", false
+	}
+	if ClassifyReference(referenceConfig) != ReferenceTypeHTTP {
+		return filepath.Join(filepath.Dir(referenceConfig), templatePath), true
+	}
+	base, err := url.Parse(referenceConfig)
+	if err != nil {
+		return "", false
+	}
+	return base.ResolveReference(&url.URL{Path: filepath.ToSlash(templatePath)}).String(), true
+}
+
+// readTemplateIdentity sets the apiVersion, kind, name, and namespace of template from
+// the literal values in the first document of a template's content.
+func readTemplateIdentity(content []byte, template *CoverageTemplate) {
+	var tracker yamlPathTracker
+	seenKey := false
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "---") && seenKey {
+			break
+		}
+		path := tracker.add(line)
+		if path == nil {
+			continue
+		}
+		seenKey = true
+
+		var field *string
+		switch strings.Join(path, ".") {
+		case "apiVersion":
+			field = &template.APIVersion
+		case "kind":
+			field = &template.Kind
+		case "metadata.name":
+			field = &template.Name
+		case "metadata.namespace":
+			field = &template.Namespace
+		default:
+			continue
+		}
+		if *field == "" {
+			*field = templateLiteralValue(line)
+		}
+	}
+}
+
+// templateLiteralValue returns the scalar value of a "key: value" line, or "" when the
+// value is empty or set by a template expression.
+func templateLiteralValue(line string) string {
+	_, value, _ := strings.Cut(strings.TrimSpace(line), ":")
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "{{") {
+		return ""
+	}
+	if comment := strings.Index(value, " #"); comment >= 0 {
+		value = strings.TrimSpace(value[:comment])
+	}
+	return strings.Trim(value, `"'`)
+}
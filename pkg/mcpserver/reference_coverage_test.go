@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// coverageTestTemplates are the templates of componentTestMetadataV2.
+var coverageTestTemplates = map[string]string{
+	"networking/sriov-subscription.yaml": `apiVersion: operators.coreos.com/v1alpha1
+kind: Subscription
+metadata:
+  name: sriov-network-operator-subscription
+  namespace: openshift-sriov-network-operator
+spec:
+  name: sriov-network-operator
+`,
+	"networking/sriov-operatorconfig.yaml": `apiVersion: sriovnetwork.openshift.io/v1
+kind: SriovOperatorConfig
+metadata:
+  name: default
+  namespace: openshift-sriov-network-operator
+`,
+	"networking/net-attach-def.yaml": `apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  name: {{ .metadata.name }}
+  namespace: {{ .metadata.namespace }}
+`,
+	"storage/lso-subscription.yaml": `apiVersion: operators.coreos.com/v1alpha1
+kind: Subscription
+metadata:
+  name: local-storage-operator # pinned by the RDS
+  namespace: openshift-local-storage
+`,
+	"storage/localvolume.yaml": `apiVersion: local.storage.openshift.io/v1
+kind: LocalVolume
+metadata:
+  name: local-disks
+  namespace: openshift-local-storage
+spec:
+  storageClassDevices:
+  - storageClassName: general
+    volumeMode: Filesystem
+    devicePaths:
+    - /dev/disk/by-path/pci-0000:00:1f.2-ata-1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second-document
+`,
+}
+
+var _ = Describe("Reference coverage", func() {
+	writeReference := func(metadata string, templates map[string]string) string {
+		dir := GinkgoT().TempDir()
+		metadataPath := filepath.Join(dir, "metadata.yaml")
+		Expect(os.WriteFile(metadataPath, []byte(metadata), 0o600)).To(Succeed())
+		for path, content := range templates {
+			Expect(os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0o700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, path), []byte(content), 0o600)).To(Succeed())
+		}
+		return metadataPath
+	}
+
+	It("enumerates the kinds and templates of a v2 reference", func() {
+		metadataPath := writeReference(componentTestMetadataV2, coverageTestTemplates)
+
+		coverage, err := (&CompareService{}).loadReferenceCoverage(context.Background(), "container://quay.io/org/refs:v1:/metadata.yaml", metadataPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(coverage.Reference).To(Equal("container://quay.io/org/refs:v1:/metadata.yaml"))
+		Expect(coverage.Kinds).To(Equal([]string{
+			"k8s.cni.cncf.io/v1/NetworkAttachmentDefinition",
+			"local.storage.openshift.io/v1/LocalVolume",
+			"operators.coreos.com/v1alpha1/Subscription",
+			"sriovnetwork.openshift.io/v1/SriovOperatorConfig",
+		}))
+		Expect(coverage.Templates).To(Equal([]CoverageTemplate{
+			{Path: "networking/sriov-subscription.yaml", Part: "Networking", Component: "SriovOperator",
+				APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription",
+				Name: "sriov-network-operator-subscription", Namespace: "openshift-sriov-network-operator"},
+			{Path: "networking/sriov-operatorconfig.yaml", Part: "Networking", Component: "SriovOperator",
+				APIVersion: "sriovnetwork.openshift.io/v1", Kind: "SriovOperatorConfig",
+				Name: "default", Namespace: "openshift-sriov-network-operator"},
+			{Path: "networking/net-attach-def.yaml", Part: "Networking", Component: "Multus",
+				APIVersion: "k8s.cni.cncf.io/v1", Kind: "NetworkAttachmentDefinition"},
+			{Path: "storage/lso-subscription.yaml", Part: "Storage", Component: "LocalStorage",
+				APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription",
+				Name: "local-storage-operator", Namespace: "openshift-local-storage"},
+			{Path: "storage/localvolume.yaml", Part: "Storage", Component: "LocalStorage",
+				APIVersion: "local.storage.openshift.io/v1", Kind: "LocalVolume",
+				Name: "local-disks", Namespace: "openshift-local-storage"},
+		}))
+	})
+
+	It("enumerates the templates of a v1 reference", func() {
+		metadataPath := writeReference(componentTestMetadataV1, coverageTestTemplates)
+
+		coverage, err := (&CompareService{}).loadReferenceCoverage(context.Background(), metadataPath, metadataPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(coverage.Kinds).To(Equal([]string{
+			"operators.coreos.com/v1alpha1/Subscription",
+			"sriovnetwork.openshift.io/v1/SriovOperatorConfig",
+		}))
+		Expect(coverage.Templates).To(HaveLen(2))
+	})
+
+	It("lists a template outside the reference directory without reading it", func() {
+		metadataPath := writeReference(`apiVersion: v2
+parts:
+  - name: Escape
+    components:
+      - name: Outside
+        allOf:
+          - path: ../outside.yaml
+`, nil)
+
+		coverage, err := (&CompareService{}).loadReferenceCoverage(context.Background(), metadataPath, metadataPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(coverage.Kinds).To(BeEmpty())
+		Expect(coverage.Templates).To(Equal([]CoverageTemplate{{Path: "../outside.yaml", Part: "Escape", Component: "Outside"}}))
+	})
+
+	It("reports a missing template", func() {
+		metadataPath := writeReference(componentTestMetadataV2, nil)
+
+		_, err := (&CompareService{}).loadReferenceCoverage(context.Background(), metadataPath, metadataPath)
+		Expect(err).To(MatchError(ContainSubstring("reference template networking/sriov-subscription.yaml")))
+	})
+
+	It("fetches the templates of an HTTP reference relative to its metadata", func() {
+		var requested []string
+		service := &CompareService{HTTPClient: doerFunc(func(req *http.Request) (*http.Response, error) {
+			requested = append(requested, req.URL.String())
+			content := componentTestMetadataV1
+			if path, ok := strings.CutPrefix(req.URL.Path, "/ref/"); ok && path != "metadata.yaml" {
+				content = coverageTestTemplates[path]
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(content))}, nil
+		})}
+
+		coverage, err := service.loadReferenceCoverage(context.Background(), "https://example.com/ref/metadata.yaml", "https://example.com/ref/metadata.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requested).To(Equal([]string{
+			"https://example.com/ref/metadata.yaml",
+			"https://example.com/ref/networking/sriov-subscription.yaml",
+			"https://example.com/ref/networking/sriov-operatorconfig.yaml",
+		}))
+		Expect(coverage.Kinds).To(HaveLen(2))
+	})
+})
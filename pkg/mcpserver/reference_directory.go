@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxDirectoryMetadataFiles caps how many metadata.yaml files a directory reference
+// may compare against in one call.
+const maxDirectoryMetadataFiles = 20
+
+// referenceMetadataFileName is the file name of a kube-compare reference's metadata.
+const referenceMetadataFileName = "metadata.yaml"
+
+// ClusterDiffMultiResult is the response for a kube_compare_cluster_diff call whose
+// container:// reference names a directory. Results are keyed by the image path of
+// each metadata.yaml found under the directory.
+type ClusterDiffMultiResult struct {
+	Reference   string                     `json:"reference"`
+	ImageDigest string                     `json:"image_digest"`
+	Results     map[string]json.RawMessage `json:"results,omitempty"`
+	// Errors holds the failure of each comparison that did not complete
+	Errors map[string]string `json:"errors,omitempty"`
+	// SuppressedCRs is the number of CRs dropped by exclude_namespaces per comparison
+	SuppressedCRs map[string]int `json:"suppressed_crs,omitempty"`
+	// CommandEquivalents holds the equivalent kube-compare command per comparison, when requested
+	CommandEquivalents map[string]string `json:"command_equivalents,omitempty"`
+	// Coverage holds the templates and kinds of each reference, when requested
+	Coverage map[string]*ReferenceCoverage `json:"coverage,omitempty"`
+}
+
+// isDirectoryReference reports whether ref is a container:// reference whose path
+// ends in "/", naming a directory of references rather than one metadata.yaml.
+func isDirectoryReference(ref string) bool {
+	if ClassifyReference(ref) != ReferenceTypeOCI {
+		return false
+	}
+	_, filePath, err := ParseContainerReference(ref)
+	return err == nil && strings.HasSuffix(filePath, "/")
+}
+
+// handleClusterDiffDirectory runs kube_compare_cluster_diff for a directory reference.
+func handleClusterDiffDirectory(ctx context.Context, args *CompareArgs, logger *slog.Logger, start time.Time) (*mcp.CallToolResult, ClusterDiffOutput, error) {
+	logger.Info("Starting cluster comparison against reference directory", "reference", args.Reference)
+
+	result, runs, err := runCompareDirectory(ctx, args)
+	logger = withClusterID(logger, args.clusterID)
+	if err != nil {
+		logger.Error("Comparison failed",
+			"error", err,
+			"duration", time.Since(start),
+			"reference", args.Reference,
+		)
+		return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+	}
+
+	logger.Info("Comparison completed",
+		"duration", time.Since(start),
+		"reference", args.Reference,
+		"comparisons", len(result.Results),
+		"failed", len(result.Errors),
+	)
+
+	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, ClusterDiffOutput{}, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	toolResult := newToolResultText(string(outputBytes))
+	for _, run := range runs {
+		if args.FailOnDiff && !*run.verdict().Compliant {
+			toolResult.IsError = true
+		}
+		if run.referenceMetadata == nil {
+			continue
+		}
+		content, err := run.referenceMetadata.content()
+		if err != nil {
+			return nil, ClusterDiffOutput{}, err
+		}
+		toolResult.Content = append(toolResult.Content, content...)
+	}
+	if args.anonymizer != nil {
+		content, err := args.anonymizer.legend().content()
+		if err != nil {
+			err = NewCompareError("anonymize", err, "The comparison completed but its anonymization legend could not be formatted")
+			logger.Error("Formatting anonymization legend failed", "error", err)
+			return newToolResultErrorFor(err), ClusterDiffOutput{}, nil
+		}
+		toolResult.Content = append(toolResult.Content, content)
+	}
+	return toolResult, ClusterDiffOutput{}, nil
+}
+
+// runCompareDirectory pulls the image of a directory reference once and compares the
+// cluster against each metadata.yaml found under the directory.
+func runCompareDirectory(ctx context.Context, args *CompareArgs) (*ClusterDiffMultiResult, []*compareRun, error) {
+	imageRef, dir, err := ParseContainerReference(args.Reference)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	startReferenceAcquisition(args)
+	refCtx, cancel := referenceAcquisitionContext(ctx, args)
+	defer cancel()
+
+	img, digest, release, err := defaultCompareService.pullContainerImage(refCtx, imageRef, args.Platform, args.verifiedDigest)
+	if err != nil {
+		if referenceTimedOut(ctx, refCtx) {
+			return nil, nil, newReferenceTimeoutError(args.ReferenceTimeout)
+		}
+		return nil, nil, NewCompareError("initialize",
+			fmt.Errorf("failed to pull container reference: %w", err),
+			"Verify the container image is correct. Check registry authentication if needed.")
+	}
+	defer release()
+
+	return compareImageDirectory(ctx, args, imageRef, &pulledImage{img: img, digest: digest}, dir)
+}
+
+// compareImageDirectory compares the cluster against each metadata.yaml under dir in
+// image. A comparison that fails is recorded in the result's Errors so that the others
+// are still reported.
+func compareImageDirectory(ctx context.Context, args *CompareArgs, imageRef string, image *pulledImage, dir string) (*ClusterDiffMultiResult, []*compareRun, error) {
+	refCtx, cancel := referenceAcquisitionContext(ctx, args)
+	metadataFiles, err := findImageMetadataFiles(refCtx, image.img, dir)
+	timedOut := referenceTimedOut(ctx, refCtx)
+	cancel()
+	if err != nil {
+		if timedOut {
+			return nil, nil, newReferenceTimeoutError(args.ReferenceTimeout)
+		}
+		return nil, nil, NewCompareError("initialize", err, "")
+	}
+	if len(metadataFiles) == 0 {
+		return nil, nil, NewCompareError("initialize",
+			fmt.Errorf("%w: no %s found under %s in the container image", ErrReferenceNotFound, referenceMetadataFileName, dir),
+			"Use kube_compare_list_reference_contents to see the files in the image")
+	}
+	if len(metadataFiles) > maxDirectoryMetadataFiles {
+		return nil, nil, NewCompareError("initialize",
+			fmt.Errorf("found %d %s files under %s, more than the limit of %d",
+				len(metadataFiles), referenceMetadataFileName, dir, maxDirectoryMetadataFiles),
+			"Use a more specific directory, or the path of a single metadata.yaml")
+	}
+
+	result := &ClusterDiffMultiResult{
+		Reference:   args.Reference,
+		ImageDigest: image.digest,
+		Results:     make(map[string]json.RawMessage, len(metadataFiles)),
+	}
+	runs := make([]*compareRun, 0, len(metadataFiles))
+	for _, metadataFile := range metadataFiles {
+		runArgs := *args
+		runArgs.Reference = "container://" + imageRef + ":" + metadataFile
+		runArgs.image = image
+
+		run, err := runCompare(ctx, &runArgs)
+		// Every comparison connects to the same cluster
+		args.clusterID = runArgs.clusterID
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, ErrReferenceTimeout) {
+				// The remaining comparisons would fail the same way
+				return nil, nil, err
+			}
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[metadataFile] = formatErrorForUser(err)
+			continue
+		}
+
+		result.Results[metadataFile] = comparisonJSON(run.output)
+		if run.suppressedCRs > 0 {
+			if result.SuppressedCRs == nil {
+				result.SuppressedCRs = make(map[string]int)
+			}
+			result.SuppressedCRs[metadataFile] = run.suppressedCRs
+		}
+		if run.commandEquivalent != "" {
+			if result.CommandEquivalents == nil {
+				result.CommandEquivalents = make(map[string]string)
+			}
+			result.CommandEquivalents[metadataFile] = run.commandEquivalent
+		}
+		if run.coverage != nil {
+			if result.Coverage == nil {
+				result.Coverage = make(map[string]*ReferenceCoverage)
+			}
+			result.Coverage[metadataFile] = run.coverage
+		}
+		runs = append(runs, run)
+	}
+	return result, runs, nil
+}
+
+// comparisonJSON embeds comparison output in a JSON result: JSON output as is, and
+// other formats (yaml, junit) as a string.
+func comparisonJSON(output string) json.RawMessage {
+	if json.Valid([]byte(output)) {
+		return json.RawMessage(output)
+	}
+	encoded, _ := json.Marshal(output)
+	return encoded
+}
+
+// findImageMetadataFiles returns the sorted image paths of the metadata.yaml files
+// under dir in the flattened image filesystem.
+func findImageMetadataFiles(ctx context.Context, img v1.Image, dir string) ([]string, error) {
+	searchRoot := strings.Trim(path.Clean("/"+dir), "/")
+	if searchRoot == "" {
+		searchRoot = "."
+	}
+
+	var metadataFiles []string
+	err := walkImageFiles(ctx, img, func(header *tar.Header, fileName string, _ *tar.Reader) error {
+		if header.Typeflag == tar.TypeReg && path.Base(fileName) == referenceMetadataFileName &&
+			underSearchRoot(fileName, searchRoot) {
+			metadataFiles = append(metadataFiles, "/"+fileName)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(metadataFiles)
+	return slices.Compact(metadataFiles), nil
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Directory references", func() {
+	const metadataContent = "apiVersion: v2\nparts: []\n"
+
+	twoProfileImage := func() *pulledImage {
+		return &pulledImage{
+			img: newTestReferenceImage(map[string]string{
+				"usr/share/refs/profile-a/metadata.yaml":      metadataContent,
+				"usr/share/refs/profile-a/crs/node.yaml":      "kind: Node\n",
+				"usr/share/refs/profile-b/metadata.yaml":      metadataContent,
+				"usr/share/other/metadata.yaml":               metadataContent,
+				"usr/share/refs/profile-b/crs/metadata.yml":   "kind: Other\n",
+				"usr/share/refs/profile-b/crs/namespace.yaml": "kind: Namespace\n",
+			}),
+			digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		}
+	}
+
+	DescribeTable("isDirectoryReference",
+		func(ref string, expected bool) {
+			Expect(isDirectoryReference(ref)).To(Equal(expected))
+		},
+		Entry("directory path", "container://quay.io/org/refs:v1:/usr/share/refs/", true),
+		Entry("metadata path", "container://quay.io/org/refs:v1:/usr/share/refs/metadata.yaml", false),
+		Entry("path without trailing slash", "container://quay.io/org/refs:v1:/usr/share/refs", false),
+		Entry("HTTP URL ending in slash", "https://example.com/refs/", false),
+	)
+
+	Describe("findImageMetadataFiles", func() {
+		It("finds each metadata.yaml under the directory", func() {
+			files, err := findImageMetadataFiles(context.Background(), twoProfileImage().img, "/usr/share/refs/")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(Equal([]string{
+				"/usr/share/refs/profile-a/metadata.yaml",
+				"/usr/share/refs/profile-b/metadata.yaml",
+			}))
+		})
+
+		It("searches the whole image for the root directory", func() {
+			files, err := findImageMetadataFiles(context.Background(), twoProfileImage().img, "/")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(3))
+		})
+	})
+
+	Describe("compareImageDirectory", func() {
+		It("compares against each metadata.yaml and reports each outcome by path", func() {
+			// Every comparison fails at the connection preflight, which is enough to show
+			// that each metadata.yaml was extracted and compared separately
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			DeferCleanup(server.Close)
+			kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+users:
+- name: test
+  user:
+    token: expired
+`, server.URL)
+
+			args := &CompareArgs{
+				Reference:    "container://quay.io/org/refs:v1:/usr/share/refs/",
+				OutputFormat: "json",
+				Kubeconfig:   kubeconfig,
+			}
+			image := twoProfileImage()
+			result, runs, err := compareImageDirectory(context.Background(), args, "quay.io/org/refs:v1", image, "/usr/share/refs/")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runs).To(BeEmpty())
+
+			Expect(result.Reference).To(Equal(args.Reference))
+			Expect(result.ImageDigest).To(Equal(image.digest))
+			Expect(result.Results).To(BeEmpty())
+			Expect(result.Errors).To(HaveLen(2))
+			Expect(result.Errors).To(HaveKeyWithValue("/usr/share/refs/profile-a/metadata.yaml", ContainSubstring("credentials rejected")))
+			Expect(result.Errors).To(HaveKeyWithValue("/usr/share/refs/profile-b/metadata.yaml", ContainSubstring("credentials rejected")))
+		})
+
+		It("fails when the directory has no metadata.yaml", func() {
+			_, _, err := compareImageDirectory(context.Background(), &CompareArgs{}, "quay.io/org/refs:v1",
+				twoProfileImage(), "/usr/share/missing/")
+			Expect(errors.Is(err, ErrReferenceNotFound)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("kube_compare_list_reference_contents"))
+		})
+	})
+
+	Describe("comparisonJSON", func() {
+		It("embeds JSON output as is", func() {
+			Expect(string(comparisonJSON(`{"Summary":{"NumDiffCRs":0}}`))).To(Equal(`{"Summary":{"NumDiffCRs":0}}`))
+		})
+
+		It("embeds other output as a string", func() {
+			Expect(string(comparisonJSON("Summary:\n  NumDiffCRs: 0\n"))).To(Equal(`"Summary:\n  NumDiffCRs: 0\n"`))
+		})
+	})
+
+	Describe("listImageFiles", func() {
+		It("enumerates the metadata.yaml files under a directory", func() {
+			result, err := listImageFiles(context.Background(), twoProfileImage().img, "/usr/share/refs/", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.SearchRoot).To(Equal("/usr/share/refs"))
+			Expect(result.Truncated).To(BeTrue())
+			Expect(result.MetadataFiles).To(ConsistOf(
+				"/usr/share/refs/profile-a/metadata.yaml",
+				"/usr/share/refs/profile-b/metadata.yaml",
+			))
+		})
+	})
+})
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// missingReferenceFiles returns the files the local metadata.yaml at referenceConfig
+// declares, its templates and template function files, that do not exist next to it,
+// in metadata order. Paths that leave the reference directory are not checked.
+func missingReferenceFiles(ctx context.Context, referenceConfig string) ([]string, error) {
+	data, err := defaultCompareService.readReferenceMetadata(ctx, referenceConfig)
+	if err != nil {
+		return nil, err
+	}
+	var metadata referenceMetadata
+	if err := sigsyaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse reference metadata: %w", err)
+	}
+
+	var declared []string
+	for _, part := range metadata.Parts {
+		for _, component := range part.Components {
+			for _, template := range component.templates() {
+				declared = append(declared, template.Path)
+			}
+		}
+	}
+	declared = append(declared, metadata.TemplateFunctionFiles...)
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, path := range declared {
+		location, ok := referenceTemplateLocation(referenceConfig, path)
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Stat(location); errors.Is(err, os.ErrNotExist) {
+			missing = append(missing, path)
+		}
+	}
+	return missing, nil
+}
+
+// referenceCompleteError returns the error for kube-compare failing to load the
+// reference, extracted or local at referenceConfig. When the metadata.yaml declares
+// files the reference does not contain, the error names them, since kube-compare's
+// own error does not say which file of the reference it could not open.
+func referenceCompleteError(ctx context.Context, args *CompareArgs, referenceConfig string, err error, errOutput string) error {
+	if ClassifyReference(referenceConfig) == ReferenceTypeHTTP {
+		return NewCompareError("initialize", err, BuildErrorDetails(err, errOutput))
+	}
+	missing, checkErr := missingReferenceFiles(ctx, referenceConfig)
+	if checkErr != nil || len(missing) == 0 {
+		if checkErr != nil {
+			slog.Default().Debug("Could not check the files of the reference", "reference", args.Reference, "error", checkErr)
+		}
+		return NewCompareError("initialize", err, BuildErrorDetails(err, errOutput))
+	}
+
+	where := "the reference directory"
+	switch ClassifyReference(args.Reference) {
+	case ReferenceTypeOCI, ReferenceTypeLocalImage:
+		where = "the image"
+	}
+	verb := "was"
+	if len(missing) > 1 {
+		verb = "were"
+	}
+	return NewCompareError("initialize",
+		fmt.Errorf("%w: %s references %s which %s not found in %s",
+			ErrReferenceNotFound, filepath.Base(referenceConfig), strings.Join(missing, ", "), verb, where),
+		"Add the missing files to the reference, or remove them from "+filepath.Base(referenceConfig)+
+			". kube-compare reported: "+err.Error())
+}
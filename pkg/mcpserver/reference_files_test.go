@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// referenceFilesTestMetadata declares two templates and a template function file.
+const referenceFilesTestMetadata = `apiVersion: v2
+parts:
+  - name: core
+    components:
+      - name: config
+        allOf:
+          - path: config/settings.yaml
+          - path: config/missing.yaml
+templateFunctionFiles:
+  - functions.tmpl
+`
+
+const referenceFilesTestTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: apps
+`
+
+var _ = Describe("Reference files", func() {
+	var metadataPath string
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		metadataPath = filepath.Join(dir, "metadata.yaml")
+		Expect(os.WriteFile(metadataPath, []byte(referenceFilesTestMetadata), 0o600)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "config"), 0o700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "config", "settings.yaml"), []byte(referenceFilesTestTemplate), 0o600)).To(Succeed())
+	})
+
+	It("lists the declared files missing from the reference", func() {
+		missing, err := missingReferenceFiles(context.Background(), metadataPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]string{"config/missing.yaml", "functions.tmpl"}))
+	})
+
+	It("names the missing files when kube-compare fails to load the reference", func() {
+		opts := compare.NewOptions(genericiooptions.NewTestIOStreamsDiscard())
+		opts.ReferenceConfig = metadataPath
+		opts.TmpDir = GinkgoT().TempDir()
+		completeErr := opts.Complete(kcmdutil.NewFactory(genericclioptions.NewConfigFlags(false)), nil, nil)
+		Expect(completeErr).To(HaveOccurred())
+
+		args := &CompareArgs{Reference: "container://quay.io/org/refs:v1:/metadata.yaml"}
+		err := referenceCompleteError(context.Background(), args, metadataPath, completeErr, "")
+		Expect(err).To(MatchError(ErrReferenceNotFound))
+		var compErr *CompareError
+		Expect(errors.As(err, &compErr)).To(BeTrue())
+		Expect(compErr.Op).To(Equal("initialize"))
+		Expect(compErr.Err.Error()).To(Equal("reference configuration not found: " +
+			"metadata.yaml references config/missing.yaml, functions.tmpl which were not found in the image"))
+		Expect(compErr.Details).To(ContainSubstring("kube-compare reported: "))
+	})
+
+	It("says the reference directory for a local reference with one missing file", func() {
+		Expect(os.WriteFile(filepath.Join(filepath.Dir(metadataPath), "functions.tmpl"), []byte(""), 0o600)).To(Succeed())
+
+		err := referenceCompleteError(context.Background(), &CompareArgs{Reference: metadataPath}, metadataPath, errors.New("failed"), "")
+		Expect(err).To(MatchError(ContainSubstring("metadata.yaml references config/missing.yaml which was not found in the reference directory")))
+	})
+
+	It("falls back to kube-compare's error when every declared file exists", func() {
+		Expect(os.WriteFile(filepath.Join(filepath.Dir(metadataPath), "functions.tmpl"), []byte(""), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(filepath.Dir(metadataPath), "config", "missing.yaml"), []byte(referenceFilesTestTemplate), 0o600)).To(Succeed())
+
+		err := referenceCompleteError(context.Background(), &CompareArgs{Reference: metadataPath}, metadataPath, errors.New("invalid reference"), "")
+		Expect(errors.Is(err, ErrReferenceNotFound)).To(BeFalse())
+		Expect(err).To(MatchError(ContainSubstring("The reference configuration appears to be invalid")))
+	})
+})
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ociImageLabelPrefix is the prefix of the OCI pre-defined annotation keys, which
+// image builds also set as labels.
+const ociImageLabelPrefix = "org.opencontainers.image."
+
+// provenanceImageLabels are the labels, besides the OCI ones, that Red Hat and
+// OpenShift builds set to record where an image came from.
+var provenanceImageLabels = map[string]bool{
+	"name":                               true,
+	"version":                            true,
+	"release":                            true,
+	"build-date":                         true,
+	"vcs-ref":                            true,
+	"vcs-type":                           true,
+	"url":                                true,
+	"com.redhat.component":               true,
+	"io.openshift.build.commit.id":       true,
+	"io.openshift.build.source-location": true,
+}
+
+// InspectReferenceImageInput defines the typed input for the kube_compare_inspect_reference_image tool.
+type InspectReferenceImageInput struct {
+	Reference  string `json:"reference,omitempty" jsonschema:"Reference image to inspect, as a container:// reference (e.g. container://registry/image:tag:/path/to/metadata.yaml) or a plain image reference (e.g. registry/image:tag). Use instead of rds_type."`
+	RDSType    string `json:"rds_type,omitempty" jsonschema:"RDS type whose reference image to inspect: core for Telco Core RDS, ran for Telco RAN DU RDS, or hub for Telco Hub RDS. Requires ocp_version."`
+	OCPVersion string `json:"ocp_version,omitempty" jsonschema:"OpenShift version (e.g. 4.18 or 4.20.0) of the RDS reference to inspect. Used with rds_type."`
+}
+
+// ReferenceImageResult is the structured response for the kube_compare_inspect_reference_image tool.
+type ReferenceImageResult struct {
+	Reference    string            `json:"reference,omitempty"`
+	RDSType      string            `json:"rds_type,omitempty"`
+	ImageRef     string            `json:"image_ref"`
+	Digest       string            `json:"digest"`
+	Created      string            `json:"created,omitempty"`
+	Architecture string            `json:"architecture,omitempty"`
+	OS           string            `json:"os,omitempty"`
+	Labels       map[string]string `json:"labels"`
+}
+
+// InspectReferenceImageTool returns the MCP tool definition for inspecting a reference image.
+func InspectReferenceImageTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:  "kube_compare_inspect_reference_image",
+		Title: "Inspect Reference Image",
+		Description: "Show the digest, creation time, platform, and provenance labels of a reference image " +
+			"without pulling its layers. Takes a container:// reference or an RDS type and OpenShift version.",
+		InputSchema:  InspectReferenceImageInputSchema(),
+		OutputSchema: InspectReferenceImageOutputSchema(),
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: ptrBool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   ptrBool(true),
+		},
+	}
+}
+
+// HandleInspectReferenceImage is the MCP tool handler for the kube_compare_inspect_reference_image tool.
+func HandleInspectReferenceImage(ctx context.Context, req *mcp.CallToolRequest, input InspectReferenceImageInput) (toolResult *mcp.CallToolResult, result *ReferenceImageResult, toolErr error) {
+	requestID := generateRequestID()
+	logger := slog.Default().With("requestID", requestID)
+	start := time.Now()
+
+	logger.Info("Received tool request",
+		"tool", "kube_compare_inspect_reference_image",
+		"reference", input.Reference,
+		"rdsType", input.RDSType,
+		"ocpVersion", input.OCPVersion,
+	)
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			stackTrace := string(debug.Stack())
+			logger.Error("Panic recovered in tool handler",
+				"panic", r,
+				"stackTrace", stackTrace,
+			)
+			toolResult = newToolResultError(fmt.Sprintf("Internal error: %v", r))
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Request canceled", "error", err)
+		return newToolResultErrorFor(ErrContextCanceled), nil, nil
+	}
+
+	result, err := defaultReferenceService.InspectReferenceImage(ctx, input)
+	if err != nil {
+		logger.Debug("Inspecting reference image failed", "error", err)
+		return newToolResultErrorFor(err), nil, nil
+	}
+
+	outputBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format result: %w", err)
+	}
+
+	logger.Info("Reference image inspected",
+		"duration", time.Since(start),
+		"imageRef", result.ImageRef,
+		"digest", result.Digest,
+	)
+
+	return newToolResultText(string(outputBytes)), result, nil
+}
+
+// InspectReferenceImage resolves the image named by input, either directly or through
+// the RDS reference for rds_type and ocp_version, and describes it from its config.
+func (s *ReferenceService) InspectReferenceImage(ctx context.Context, input InspectReferenceImageInput) (*ReferenceImageResult, error) {
+	reference := strings.TrimSpace(input.Reference)
+	result := &ReferenceImageResult{}
+
+	switch {
+	case reference != "" && input.RDSType != "":
+		return nil, NewValidationError("reference",
+			"'reference' and 'rds_type' cannot be used together",
+			"Provide either a reference or an rds_type with ocp_version")
+
+	case reference != "":
+		if ClassifyReference(reference) == ReferenceTypeOCI {
+			imageRef, _, err := ParseContainerReference(reference)
+			if err != nil {
+				return nil, err
+			}
+			result.Reference = reference
+			result.ImageRef = imageRef
+		} else {
+			result.ImageRef = reference
+		}
+
+	case input.RDSType != "":
+		rdsType, err := normalizeRDSType(input.RDSType)
+		if err != nil {
+			return nil, err
+		}
+		if input.OCPVersion == "" {
+			return nil, NewValidationError("ocp_version",
+				"'rds_type' requires 'ocp_version'",
+				"Provide the OpenShift version of the RDS reference, e.g. 4.18")
+		}
+		if err := validateOCPVersion(input.OCPVersion); err != nil {
+			return nil, err
+		}
+		resolved, err := s.ResolveRDS(ctx, &ResolveRDSArgs{RDSType: rdsType, OCPVersion: input.OCPVersion})
+		if err != nil {
+			return nil, err
+		}
+		result.Reference = resolved.Reference
+		result.RDSType = rdsType
+		result.ImageRef = resolved.ImageRef
+
+	default:
+		return nil, NewValidationError("reference",
+			"either 'reference' or 'rds_type' is required",
+			"Provide a container:// reference, or an rds_type with ocp_version")
+	}
+
+	if _, err := name.ParseReference(result.ImageRef); err != nil {
+		return nil, NewValidationError("reference",
+			fmt.Sprintf("invalid container image reference '%s': %v", result.ImageRef, err),
+			"Use format: container://registry/image:tag:/path/to/metadata.yaml or registry/image:tag")
+	}
+
+	imageConfig, err := s.Registry.GetImageConfig(ctx, result.ImageRef)
+	if err != nil {
+		return nil, NewCompareError("inspect",
+			err,
+			"Verify the container image is correct. Check registry authentication if needed.")
+	}
+
+	result.Digest = imageConfig.Digest
+	result.Labels = map[string]string{}
+	if config := imageConfig.Config; config != nil {
+		if !config.Created.IsZero() {
+			result.Created = config.Created.UTC().Format(time.RFC3339)
+		}
+		result.Architecture = config.Architecture
+		result.OS = config.OS
+		result.Labels = selectImageLabels(config.Config.Labels)
+	}
+	return result, nil
+}
+
+// selectImageLabels returns the OCI and provenance labels of an image.
+func selectImageLabels(labels map[string]string) map[string]string {
+	selected := map[string]string{}
+	for key, value := range labels {
+		if strings.HasPrefix(key, ociImageLabelPrefix) || provenanceImageLabels[key] {
+			selected[key] = value
+		}
+	}
+	return selected
+}
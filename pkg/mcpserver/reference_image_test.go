@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
+)
+
+var _ = Describe("InspectReferenceImage", func() {
+	const coreImage = "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9:v4.18"
+
+	var (
+		ctrl         *gomock.Controller
+		mockRegistry *MockRegistryClient
+		service      *mcpserver.ReferenceService
+	)
+
+	// syntheticConfig is an image config carrying OCI, provenance, and unrelated labels.
+	syntheticConfig := func() *mcpserver.ImageConfig {
+		return &mcpserver.ImageConfig{
+			Digest: "sha256:0123456789abcdef",
+			Config: &v1.ConfigFile{
+				Architecture: "amd64",
+				OS:           "linux",
+				Created:      v1.Time{Time: time.Date(2025, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))},
+				Config: v1.Config{Labels: map[string]string{
+					"org.opencontainers.image.revision": "abc123",
+					"org.opencontainers.image.source":   "https://github.com/openshift-kni/telco-reference",
+					"version":                           "v4.18.0",
+					"com.redhat.component":              "openshift-telco-core-rds-container",
+					"io.k8s.description":                "Telco Core RDS reference",
+					"maintainer":                        "someone",
+				}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockRegistry = NewMockRegistryClient(ctrl)
+		service = &mcpserver.ReferenceService{Registry: mockRegistry}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("surfaces the digest, creation time, platform, and selected labels", func() {
+		mockRegistry.EXPECT().
+			GetImageConfig(gomock.Any(), "quay.io/org/refs:v1").
+			Return(syntheticConfig(), nil)
+
+		result, err := service.InspectReferenceImage(context.Background(), mcpserver.InspectReferenceImageInput{
+			Reference: "container://quay.io/org/refs:v1:/reference/metadata.yaml",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Reference).To(Equal("container://quay.io/org/refs:v1:/reference/metadata.yaml"))
+		Expect(result.ImageRef).To(Equal("quay.io/org/refs:v1"))
+		Expect(result.Digest).To(Equal("sha256:0123456789abcdef"))
+		Expect(result.Created).To(Equal("2025-03-04T04:06:07Z"))
+		Expect(result.Architecture).To(Equal("amd64"))
+		Expect(result.OS).To(Equal("linux"))
+		Expect(result.Labels).To(Equal(map[string]string{
+			"org.opencontainers.image.revision": "abc123",
+			"org.opencontainers.image.source":   "https://github.com/openshift-kni/telco-reference",
+			"version":                           "v4.18.0",
+			"com.redhat.component":              "openshift-telco-core-rds-container",
+		}))
+	})
+
+	It("accepts a plain image reference", func() {
+		mockRegistry.EXPECT().
+			GetImageConfig(gomock.Any(), "quay.io/org/refs@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef").
+			Return(syntheticConfig(), nil)
+
+		result, err := service.InspectReferenceImage(context.Background(), mcpserver.InspectReferenceImageInput{
+			Reference: "quay.io/org/refs@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Reference).To(BeEmpty())
+		Expect(result.Digest).To(Equal("sha256:0123456789abcdef"))
+	})
+
+	It("inspects the RDS reference for an rds_type and version", func() {
+		mockRegistry.EXPECT().
+			ListTags(gomock.Any(), gomock.Any()).
+			Return([]string{"v4.17", "v4.18"}, nil).
+			AnyTimes()
+		mockRegistry.EXPECT().
+			HeadImage(gomock.Any(), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+		mockRegistry.EXPECT().
+			GetImageConfig(gomock.Any(), coreImage).
+			Return(syntheticConfig(), nil)
+
+		result, err := service.InspectReferenceImage(context.Background(), mcpserver.InspectReferenceImageInput{
+			RDSType:    "Core",
+			OCPVersion: "4.18.3",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RDSType).To(Equal(mcpserver.RDSTypeCore))
+		Expect(result.ImageRef).To(Equal(coreImage))
+		Expect(result.Reference).To(HavePrefix("container://" + coreImage + ":/"))
+	})
+
+	It("returns no labels for an image without a config", func() {
+		mockRegistry.EXPECT().
+			GetImageConfig(gomock.Any(), gomock.Any()).
+			Return(&mcpserver.ImageConfig{Digest: "sha256:0123456789abcdef"}, nil)
+
+		result, err := service.InspectReferenceImage(context.Background(), mcpserver.InspectReferenceImageInput{
+			Reference: "quay.io/org/refs:v1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Labels).To(BeEmpty())
+		Expect(result.Created).To(BeEmpty())
+	})
+
+	It("reports a registry failure as a compare error", func() {
+		mockRegistry.EXPECT().
+			GetImageConfig(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("UNAUTHORIZED"))
+
+		_, err := service.InspectReferenceImage(context.Background(), mcpserver.InspectReferenceImageInput{
+			Reference: "quay.io/org/refs:v1",
+		})
+		var compareErr *mcpserver.CompareError
+		Expect(errors.As(err, &compareErr)).To(BeTrue())
+		Expect(compareErr.Op).To(Equal("inspect"))
+	})
+
+	DescribeTable("rejects invalid input without contacting the registry",
+		func(input mcpserver.InspectReferenceImageInput, field string) {
+			_, err := service.InspectReferenceImage(context.Background(), input)
+			var valErr *mcpserver.ValidationError
+			Expect(errors.As(err, &valErr)).To(BeTrue())
+			Expect(valErr.Field).To(Equal(field))
+		},
+		Entry("neither reference nor rds_type", mcpserver.InspectReferenceImageInput{}, "reference"),
+		Entry("both reference and rds_type",
+			mcpserver.InspectReferenceImageInput{Reference: "quay.io/org/refs:v1", RDSType: "core", OCPVersion: "4.18"}, "reference"),
+		Entry("rds_type without ocp_version", mcpserver.InspectReferenceImageInput{RDSType: "core"}, "ocp_version"),
+		Entry("an unknown rds_type", mcpserver.InspectReferenceImageInput{RDSType: "edge", OCPVersion: "4.18"}, "rds_type"),
+		Entry("an invalid image reference", mcpserver.InspectReferenceImageInput{Reference: "Not A Ref"}, "reference"),
+	)
+})
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// maxInlineReferenceMetadataSize is the largest metadata.yaml returned inline in a
+// tool result. Larger metadata is attached as an embedded resource instead.
+const maxInlineReferenceMetadataSize = 64 << 10
+
+// ReferenceMetadata is the reference metadata.yaml a comparison ran against, with
+// its provenance. It is returned when include_reference_metadata is set.
+type ReferenceMetadata struct {
+	Reference   string `json:"reference"`
+	ImageDigest string `json:"image_digest,omitempty"`
+	SHA256      string `json:"sha256"`
+	Size        int    `json:"size"`
+	// Metadata is the parsed metadata.yaml. It is omitted when the metadata is
+	// larger than maxInlineReferenceMetadataSize and returned as a resource.
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	AsResource bool            `json:"as_resource,omitempty"`
+
+	raw []byte
+}
+
+// newReferenceMetadata records the metadata.yaml content read for reference.
+// imageDigest is the digest of the image the metadata was extracted from, if any.
+func newReferenceMetadata(reference, imageDigest string, data []byte) (*ReferenceMetadata, error) {
+	sum := sha256.Sum256(data)
+	metadata := &ReferenceMetadata{
+		Reference:   reference,
+		ImageDigest: imageDigest,
+		SHA256:      hex.EncodeToString(sum[:]),
+		Size:        len(data),
+		raw:         data,
+	}
+
+	if len(data) > maxInlineReferenceMetadataSize {
+		metadata.AsResource = true
+		return metadata, nil
+	}
+
+	parsed, err := sigsyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reference metadata: %w", err)
+	}
+	metadata.Metadata = parsed
+	return metadata, nil
+}
+
+// content returns the MCP content blocks carrying the metadata: its provenance and,
+// when inline, the parsed metadata as JSON text, followed by the raw metadata.yaml as
+// an embedded resource when it is too large to inline.
+func (m *ReferenceMetadata) content() ([]mcp.Content, error) {
+	metadataJSON, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format reference metadata: %w", err)
+	}
+
+	content := []mcp.Content{&mcp.TextContent{Text: string(metadataJSON)}}
+	if m.AsResource {
+		content = append(content, &mcp.EmbeddedResource{
+			Resource: &mcp.ResourceContents{
+				URI:      m.Reference,
+				MIMEType: "application/yaml",
+				Text:     string(m.raw),
+			},
+		})
+	}
+	return content, nil
+}
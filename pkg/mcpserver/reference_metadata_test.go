@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reference metadata", func() {
+	const reference = "container://registry.example.com/rds/ztp-site-generate:v4.18:/metadata.yaml"
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	Describe("newReferenceMetadata", func() {
+		It("includes the parsed metadata and its provenance", func() {
+			metadata, err := newReferenceMetadata(reference, digest, []byte(componentTestMetadataV2))
+			Expect(err).NotTo(HaveOccurred())
+
+			sum := sha256.Sum256([]byte(componentTestMetadataV2))
+			Expect(metadata.Reference).To(Equal(reference))
+			Expect(metadata.ImageDigest).To(Equal(digest))
+			Expect(metadata.SHA256).To(Equal(hex.EncodeToString(sum[:])))
+			Expect(metadata.Size).To(Equal(len(componentTestMetadataV2)))
+			Expect(metadata.AsResource).To(BeFalse())
+
+			var parsed map[string]any
+			Expect(json.Unmarshal(metadata.Metadata, &parsed)).To(Succeed())
+			Expect(parsed).To(HaveKeyWithValue("apiVersion", "v2"))
+			Expect(parsed).To(HaveKeyWithValue("parts", HaveLen(2)))
+		})
+
+		It("returns large metadata as a resource", func() {
+			data := []byte(componentTestMetadataV2 + "# " + strings.Repeat("x", maxInlineReferenceMetadataSize) + "\n")
+			metadata, err := newReferenceMetadata(reference, digest, data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata.AsResource).To(BeTrue())
+			Expect(metadata.Metadata).To(BeNil())
+			Expect(metadata.Size).To(Equal(len(data)))
+		})
+
+		It("rejects metadata that is not YAML", func() {
+			_, err := newReferenceMetadata(reference, "", []byte("parts: ["))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("content", func() {
+		It("returns inline metadata as a single text block", func() {
+			metadata, err := newReferenceMetadata(reference, digest, []byte(componentTestMetadataV2))
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := metadata.content()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(HaveLen(1))
+
+			text, ok := content[0].(*mcp.TextContent)
+			Expect(ok).To(BeTrue())
+			var decoded ReferenceMetadata
+			Expect(json.Unmarshal([]byte(text.Text), &decoded)).To(Succeed())
+			Expect(decoded.ImageDigest).To(Equal(digest))
+			Expect(decoded.Metadata).NotTo(BeEmpty())
+		})
+
+		It("attaches large metadata as an embedded resource", func() {
+			data := []byte(componentTestMetadataV2 + "# " + strings.Repeat("x", maxInlineReferenceMetadataSize) + "\n")
+			metadata, err := newReferenceMetadata(reference, digest, data)
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := metadata.content()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(HaveLen(2))
+			Expect(content[0].(*mcp.TextContent).Text).To(ContainSubstring(`"as_resource": true`))
+
+			resource, ok := content[1].(*mcp.EmbeddedResource)
+			Expect(ok).To(BeTrue())
+			Expect(resource.Resource.URI).To(Equal(reference))
+			Expect(resource.Resource.MIMEType).To(Equal("application/yaml"))
+			Expect(resource.Resource.Text).To(Equal(string(data)))
+		})
+	})
+
+	Describe("readReferenceMetadata", func() {
+		It("reads extracted metadata", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "metadata.yaml")
+			Expect(os.WriteFile(path, []byte(componentTestMetadataV2), 0o600)).To(Succeed())
+
+			data, err := (&CompareService{}).readReferenceMetadata(context.Background(), path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(componentTestMetadataV2))
+		})
+
+		It("rejects metadata larger than the limit", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "metadata.yaml")
+			Expect(os.WriteFile(path, make([]byte, maxReferenceMetadataSize+1), 0o600)).To(Succeed())
+
+			_, err := (&CompareService{}).readReferenceMetadata(context.Background(), path)
+			Expect(err).To(MatchError(ContainSubstring("exceeds")))
+		})
+	})
+
+	Describe("include_reference_metadata input", func() {
+		It("is accepted by both comparison tools", func() {
+			Expect(ClusterDiffInputSchema().Properties).To(HaveKey("include_reference_metadata"))
+			Expect(ValidateRDSInputSchema().Properties).To(HaveKey("include_reference_metadata"))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxReferenceTimeout is the largest reference_timeout a caller may request.
+const MaxReferenceTimeout = 30 * time.Minute
+
+// parseReferenceTimeout parses a reference_timeout input. An empty value means no
+// per-request limit.
+func parseReferenceTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 || timeout > MaxReferenceTimeout {
+		return 0, NewValidationError("reference_timeout",
+			fmt.Sprintf("invalid reference_timeout %q", value),
+			fmt.Sprintf("Use a positive duration of at most %v, such as '90s' or '5m'", MaxReferenceTimeout))
+	}
+	return timeout, nil
+}
+
+// startReferenceAcquisition starts the reference_timeout budget of args, if it has
+// one. The budget covers validating, pulling, and extracting the reference, and is
+// started only once so that every phase, and every comparison run with a copy of
+// args, draws on the same budget.
+func startReferenceAcquisition(args *CompareArgs) {
+	if args.ReferenceTimeout > 0 && args.referenceDeadline.IsZero() {
+		args.referenceDeadline = time.Now().Add(args.ReferenceTimeout)
+	}
+}
+
+// referenceAcquisitionContext returns the context for a reference acquisition phase:
+// ctx, bounded by the reference_timeout budget of args when one was started.
+func referenceAcquisitionContext(ctx context.Context, args *CompareArgs) (context.Context, context.CancelFunc) {
+	if args.referenceDeadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, args.referenceDeadline)
+}
+
+// referenceTimedOut reports whether the reference_timeout budget of refCtx, derived
+// from ctx by referenceAcquisitionContext, ran out while ctx itself is still live.
+func referenceTimedOut(ctx, refCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(refCtx.Err(), context.DeadlineExceeded)
+}
+
+// newReferenceTimeoutError reports a reference acquisition that ran out of its
+// reference_timeout budget.
+func newReferenceTimeoutError(timeout time.Duration) error {
+	return NewCompareError("reference-timeout",
+		fmt.Errorf("%w: validating, pulling, and extracting the reference took longer than reference_timeout %v",
+			ErrReferenceTimeout, timeout),
+		"Increase reference_timeout, or check that the registry or URL responds promptly from the server")
+}
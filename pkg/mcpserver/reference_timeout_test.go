@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// slowImage is an image whose layers are read slowly, standing in for a registry
+// that serves layer blobs at a crawl.
+type slowImage struct {
+	v1.Image
+}
+
+func (i slowImage) Layers() ([]v1.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for idx, layer := range layers {
+		layers[idx] = slowLayer{Layer: layer}
+	}
+	return layers, nil
+}
+
+type slowLayer struct {
+	v1.Layer
+}
+
+func (l slowLayer) Uncompressed() (io.ReadCloser, error) {
+	rc, err := l.Layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	return &slowReadCloser{ReadCloser: rc}, nil
+}
+
+// slowReadCloser returns at most one tar block per Read, after a delay.
+type slowReadCloser struct {
+	io.ReadCloser
+}
+
+func (r *slowReadCloser) Read(p []byte) (int, error) {
+	time.Sleep(5 * time.Millisecond)
+	if len(p) > 512 {
+		p = p[:512]
+	}
+	return r.ReadCloser.Read(p)
+}
+
+var _ = Describe("reference_timeout", func() {
+	DescribeTable("parseReferenceTimeout",
+		func(value string, expected time.Duration, valid bool) {
+			timeout, err := parseReferenceTimeout(value)
+			if !valid {
+				var valErr *ValidationError
+				Expect(errors.As(err, &valErr)).To(BeTrue())
+				Expect(valErr.Field).To(Equal("reference_timeout"))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeout).To(Equal(expected))
+		},
+		Entry("unset", "", time.Duration(0), true),
+		Entry("seconds", "90s", 90*time.Second, true),
+		Entry("the maximum", "30m", MaxReferenceTimeout, true),
+		Entry("not a duration", "soon", time.Duration(0), false),
+		Entry("zero", "0s", time.Duration(0), false),
+		Entry("negative", "-1m", time.Duration(0), false),
+		Entry("above the maximum", "31m", time.Duration(0), false),
+	)
+
+	It("aborts a slow extraction promptly", func() {
+		files := make(map[string]string, 200)
+		for i := range 200 {
+			files[fmt.Sprintf("reference/templates/cr-%03d.yaml", i)] = "kind: ConfigMap\n"
+		}
+		files["reference/metadata.yaml"] = "apiVersion: v2\nparts: []\n"
+		args := &CompareArgs{
+			Reference:        "container://quay.io/org/refs:v1:/reference/metadata.yaml",
+			OutputFormat:     "json",
+			ReferenceTimeout: 50 * time.Millisecond,
+			image:            &pulledImage{img: slowImage{Image: newTestReferenceImage(files)}, digest: "sha256:test"},
+		}
+
+		start := time.Now()
+		_, err := runCompare(context.Background(), args)
+
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(errors.Is(err, ErrReferenceTimeout)).To(BeTrue())
+		message := formatErrorForUser(err)
+		Expect(message).To(ContainSubstring("reference-timeout"))
+		Expect(message).To(ContainSubstring("reference_timeout 50ms"))
+	})
+
+	It("shares one budget between the phases of a request", func() {
+		args := &CompareArgs{ReferenceTimeout: time.Minute}
+		startReferenceAcquisition(args)
+		deadline := args.referenceDeadline
+		Expect(deadline).NotTo(BeZero())
+
+		copied := *args
+		startReferenceAcquisition(&copied)
+		Expect(copied.referenceDeadline).To(Equal(deadline))
+
+		refCtx, cancel := referenceAcquisitionContext(context.Background(), &copied)
+		defer cancel()
+		ctxDeadline, ok := refCtx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(ctxDeadline).To(Equal(deadline))
+	})
+
+	It("reports a canceled request as canceled rather than timed out", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		args := &CompareArgs{ReferenceTimeout: time.Nanosecond}
+		startReferenceAcquisition(args)
+		refCtx, cancelRef := referenceAcquisitionContext(ctx, args)
+		defer cancelRef()
+		<-refCtx.Done()
+
+		Expect(referenceTimedOut(ctx, refCtx)).To(BeTrue())
+		cancel()
+		Expect(referenceTimedOut(ctx, refCtx)).To(BeFalse())
+	})
+})
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("reference_ocp_version", func() {
+	Describe("validateReferenceOCPVersion", func() {
+		It("accepts an empty or explicit version", func() {
+			Expect(validateReferenceOCPVersion("", "")).To(Succeed())
+			Expect(validateReferenceOCPVersion("4.20", "")).To(Succeed())
+			Expect(validateReferenceOCPVersion("4.20.1", "")).To(Succeed())
+		})
+
+		It("rejects a malformed version", func() {
+			err := validateReferenceOCPVersion("latest", "")
+			Expect(err).To(MatchError(ContainSubstring("invalid OpenShift version 'latest'")))
+			var valErr *ValidationError
+			Expect(errors.As(err, &valErr)).To(BeTrue())
+			Expect(valErr.Field).To(Equal("reference_ocp_version"))
+		})
+
+		It("rejects a version combined with a channel alias", func() {
+			Expect(validateReferenceOCPVersion("4.20", "stable")).To(MatchError(ContainSubstring("cannot both be set")))
+		})
+	})
+
+	Describe("referenceVersionWarning", func() {
+		It("notes a reference selected for another version than the cluster runs", func() {
+			warning := referenceVersionWarning(&ResolveRDSResult{ClusterVersion: "4.18.7", ReferenceVersion: "v4.20"})
+			Expect(warning).To(ContainSubstring("runs OpenShift 4.18.7"))
+			Expect(warning).To(ContainSubstring("against the RDS for OpenShift 4.20"))
+		})
+
+		It("is empty when the versions match or no reference version was set", func() {
+			Expect(referenceVersionWarning(&ResolveRDSResult{ClusterVersion: "4.20.3", ReferenceVersion: "v4.20"})).To(BeEmpty())
+			Expect(referenceVersionWarning(&ResolveRDSResult{ClusterVersion: "4.18.7"})).To(BeEmpty())
+		})
+	})
+})
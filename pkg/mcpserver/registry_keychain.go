@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// getPullSecretPath returns the path of a pull secret holding registry credentials, in
+// the Docker config.json format of an OpenShift pull secret. Can be configured via
+// KUBE_COMPARE_MCP_PULL_SECRET environment variable. Empty (the default) uses only the
+// default keychain.
+func getPullSecretPath() string {
+	return os.Getenv("KUBE_COMPARE_MCP_PULL_SECRET")
+}
+
+var (
+	registryKeychainMu sync.RWMutex
+	registryKeychain   authn.Keychain
+)
+
+// NewRegistryKeychain returns the keychain registry requests authenticate with. The
+// pull secret at pullSecretPath is read once, here, and its credentials take precedence
+// over those of authn.DefaultKeychain, which is used alone when pullSecretPath is empty.
+func NewRegistryKeychain(pullSecretPath string) (authn.Keychain, error) {
+	if pullSecretPath == "" {
+		return authn.DefaultKeychain, nil
+	}
+	keychain, err := loadPullSecretKeychain(pullSecretPath)
+	if err != nil {
+		return nil, err
+	}
+	return authn.NewMultiKeychain(keychain, authn.DefaultKeychain), nil
+}
+
+// LoadRegistryKeychainFromEnv returns the keychain for the pull secret configured
+// through KUBE_COMPARE_MCP_PULL_SECRET, or authn.DefaultKeychain when none is configured.
+func LoadRegistryKeychainFromEnv() (authn.Keychain, error) {
+	return NewRegistryKeychain(getPullSecretPath())
+}
+
+// SetRegistryKeychain sets the keychain registry requests authenticate with, as
+// returned by LoadRegistryKeychainFromEnv. A nil keychain uses authn.DefaultKeychain.
+func SetRegistryKeychain(keychain authn.Keychain) {
+	registryKeychainMu.Lock()
+	defer registryKeychainMu.Unlock()
+	registryKeychain = keychain
+}
+
+// getRegistryKeychain returns the keychain set by SetRegistryKeychain, or
+// authn.DefaultKeychain when none is set.
+func getRegistryKeychain() authn.Keychain {
+	registryKeychainMu.RLock()
+	defer registryKeychainMu.RUnlock()
+	if registryKeychain == nil {
+		return authn.DefaultKeychain
+	}
+	return registryKeychain
+}
+
+// pullSecretKeychain resolves registries to the credentials of a pull secret.
+type pullSecretKeychain struct {
+	// auths maps a registry host, such as registry.redhat.io, to its credentials
+	auths map[string]authn.AuthConfig
+}
+
+// pullSecretFile is the layout of a Docker config.json or OpenShift pull secret.
+type pullSecretFile struct {
+	Auths map[string]authn.AuthConfig `json:"auths"`
+}
+
+// loadPullSecretKeychain reads the pull secret at path.
+func loadPullSecretKeychain(path string) (*pullSecretKeychain, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pull secret: %w", err)
+	}
+	var file pullSecretFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse pull secret %s: %w", path, err)
+	}
+	if len(file.Auths) == 0 {
+		return nil, fmt.Errorf("pull secret %s has no auths entries", path)
+	}
+
+	keychain := &pullSecretKeychain{auths: make(map[string]authn.AuthConfig, len(file.Auths))}
+	for registry, auth := range file.Auths {
+		keychain.auths[pullSecretRegistryHost(registry)] = auth
+	}
+	return keychain, nil
+}
+
+// pullSecretRegistryHost returns the host of a pull secret auths key, which may be
+// given as a URL such as https://index.docker.io/v1/.
+func pullSecretRegistryHost(registry string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	if host == "index.docker.io" {
+		return name.DefaultRegistry
+	}
+	return host
+}
+
+// Resolve returns the credentials of the pull secret for the registry of resource, or
+// anonymous access when the pull secret has none, so the next keychain is consulted.
+func (k *pullSecretKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	auth, ok := k.auths[resource.RegistryStr()]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(auth), nil
+}
+
+// registryOptions returns the options of a registry request authenticating with
+// keychain, or with the keychain set by SetRegistryKeychain when keychain is nil.
+// Retries are left to registryTransport, so that they stay within the request's context.
+func registryOptions(keychain authn.Keychain) []remote.Option {
+	if keychain == nil {
+		keychain = getRegistryKeychain()
+	}
+	return []remote.Option{
+		remote.WithAuthFromKeychain(keychain),
+		remote.WithTransport(registryTransport),
+		remote.WithRetryBackoff(noLibraryRetry),
+	}
+}
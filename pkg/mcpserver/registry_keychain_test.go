@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	keychainTestUser     = "puller"
+	keychainTestPassword = "s3cret"
+)
+
+// recordingKeychain hands out the test credentials and records the registries it
+// was asked to resolve.
+type recordingKeychain struct {
+	mu       sync.Mutex
+	resolved []string
+}
+
+func (k *recordingKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.resolved = append(k.resolved, resource.RegistryStr())
+	return &authn.Basic{Username: keychainTestUser, Password: keychainTestPassword}, nil
+}
+
+// newAuthenticatedTestRegistry starts a registry that requires the test credentials
+// and holds the image org/refs:v1, and returns its host.
+func newAuthenticatedTestRegistry() string {
+	backend := registry.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != keychainTestUser || password != keychainTestPassword {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	DeferCleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	Expect(err).NotTo(HaveOccurred())
+
+	ref, err := name.ParseReference(u.Host + "/org/refs:v1")
+	Expect(err).NotTo(HaveOccurred())
+	img := newTestReferenceImage(map[string]string{"reference/metadata.yaml": "apiVersion: v2\n"})
+	Expect(remote.Write(ref, img, remote.WithAuth(&authn.Basic{Username: keychainTestUser, Password: keychainTestPassword}))).To(Succeed())
+	return u.Host
+}
+
+var _ = Describe("Registry keychain", func() {
+	var registryHost string
+
+	BeforeEach(func() {
+		registryHost = newAuthenticatedTestRegistry()
+	})
+
+	Describe("DefaultRegistryClient", func() {
+		It("passes the credentials of its keychain to remote.List and remote.Head", func() {
+			keychain := &recordingKeychain{}
+			client := &DefaultRegistryClient{Keychain: keychain}
+
+			tags, err := client.ListTags(context.Background(), registryHost+"/org/refs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tags).To(Equal([]string{"v1"}))
+			Expect(client.HeadImage(context.Background(), registryHost+"/org/refs:v1")).To(Succeed())
+
+			Expect(keychain.resolved).To(HaveLen(2))
+			Expect(keychain.resolved).To(HaveEach(registryHost))
+		})
+
+		It("is refused by the registry without the credentials", func() {
+			client := &DefaultRegistryClient{Keychain: authn.NewMultiKeychain()}
+
+			_, err := client.ListTags(context.Background(), registryHost+"/org/refs")
+			Expect(err).To(MatchError(ContainSubstring("401 Unauthorized")))
+			Expect(client.HeadImage(context.Background(), registryHost+"/org/refs:v1")).NotTo(Succeed())
+		})
+	})
+
+	It("pulls and extracts a container reference with the credentials of the keychain", func() {
+		keychain := &recordingKeychain{}
+
+		service := &CompareService{Keychain: keychain}
+		path, digest, err := service.extractContainerReference(context.Background(),
+			registryHost+"/org/refs:v1", "", "", "/reference/metadata.yaml", GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(HavePrefix("sha256:"))
+		Expect(os.ReadFile(path)).To(Equal([]byte("apiVersion: v2\n")))
+		Expect(keychain.resolved).To(ContainElement(registryHost))
+	})
+
+	Describe("NewRegistryKeychain", func() {
+		writePullSecret := func(registry string) string {
+			auth := base64.StdEncoding.EncodeToString([]byte(keychainTestUser + ":" + keychainTestPassword))
+			path := filepath.Join(GinkgoT().TempDir(), "pull-secret.json")
+			Expect(os.WriteFile(path, []byte(`{"auths":{"`+registry+`":{"auth":"`+auth+`"}}}`), 0o600)).To(Succeed())
+			return path
+		}
+
+		newKeychain := func(pullSecretPath string) authn.Keychain {
+			keychain, err := NewRegistryKeychain(pullSecretPath)
+			Expect(err).NotTo(HaveOccurred())
+			return keychain
+		}
+
+		It("uses the default keychain without a pull secret", func() {
+			Expect(newKeychain("")).To(BeIdenticalTo(authn.DefaultKeychain))
+		})
+
+		It("authenticates with the credentials of the pull secret", func() {
+			client := &DefaultRegistryClient{Keychain: newKeychain(writePullSecret(registryHost))}
+
+			tags, err := client.ListTags(context.Background(), registryHost+"/org/refs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tags).To(Equal([]string{"v1"}))
+		})
+
+		It("matches pull secret entries given as URLs", func() {
+			client := &DefaultRegistryClient{Keychain: newKeychain(writePullSecret("https://" + registryHost + "/v2/"))}
+
+			Expect(client.HeadImage(context.Background(), registryHost+"/org/refs:v1")).To(Succeed())
+		})
+
+		It("fails when the pull secret cannot be read", func() {
+			_, err := NewRegistryKeychain(filepath.Join(GinkgoT().TempDir(), "missing.json"))
+			Expect(err).To(MatchError(ContainSubstring("failed to read pull secret")))
+		})
+
+		It("authenticates clients without a keychain of their own with the one set at startup", func() {
+			SetRegistryKeychain(newKeychain(writePullSecret(registryHost)))
+			DeferCleanup(func() { SetRegistryKeychain(nil) })
+
+			client := &DefaultRegistryClient{}
+			Expect(client.HeadImage(context.Background(), registryHost+"/org/refs:v1")).To(Succeed())
+		})
+	})
+
+	Describe("LoadRegistryKeychainFromEnv", func() {
+		It("uses the default keychain when no pull secret is configured", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_PULL_SECRET", "")
+			keychain, err := LoadRegistryKeychainFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keychain).To(BeIdenticalTo(authn.DefaultKeychain))
+		})
+
+		It("fails when the configured pull secret cannot be read", func() {
+			GinkgoT().Setenv("KUBE_COMPARE_MCP_PULL_SECRET", filepath.Join(GinkgoT().TempDir(), "missing.json"))
+			_, err := LoadRegistryKeychainFromEnv()
+			Expect(err).To(MatchError(ContainSubstring("failed to read pull secret")))
+		})
+	})
+
+	DescribeTable("pullSecretRegistryHost",
+		func(registry, expected string) {
+			Expect(pullSecretRegistryHost(registry)).To(Equal(expected))
+		},
+		Entry("a host", "registry.redhat.io", "registry.redhat.io"),
+		Entry("a host and port", "quay.example.com:5000", "quay.example.com:5000"),
+		Entry("a URL", "https://registry.redhat.io/v2/", "registry.redhat.io"),
+		Entry("Docker Hub", "https://index.docker.io/v1/", name.DefaultRegistry),
+	)
+})
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// registryRetryBackoff is the retry budget for registry requests: up to three
+// attempts, waiting one and then three seconds between them. Waits are cut short
+// by the request's context, and a retry whose wait would outlast the context's
+// deadline is not made.
+var registryRetryBackoff = remote.Backoff{
+	Duration: time.Second,
+	Factor:   3.0,
+	Steps:    3,
+}
+
+// noLibraryRetry makes go-containerregistry send each request once. Its own
+// retries sleep without watching the context, so retries are made by
+// registryRetryTransport instead.
+var noLibraryRetry = remote.Backoff{Steps: 1}
+
+// registryRetryStatusCodes are the registry response codes worth retrying, the
+// same ones go-containerregistry retries by default.
+var registryRetryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+	499, // nginx-specific, client closed request
+	522, // Cloudflare-specific, connection timeout
+}
+
+// registryRetryTransport retries requests that fail with a transient error or
+// status code, within the budget of backoff and the deadline of the request's
+// context.
+type registryRetryTransport struct {
+	inner   http.RoundTripper
+	backoff remote.Backoff
+}
+
+// newRegistryRetryTransport wraps inner with retries bounded by backoff.
+func newRegistryRetryTransport(inner http.RoundTripper, backoff remote.Backoff) *registryRetryTransport {
+	return &registryRetryTransport{inner: inner, backoff: backoff}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *registryRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	backoff := t.backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := t.inner.RoundTrip(req)
+		if attempt >= t.backoff.Steps || !retryableRegistryResponse(ctx, resp, err) {
+			return resp, err
+		}
+		// A request with a body can only be resent when the body can be rebuilt
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+		wait := backoff.Step()
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// retryableRegistryResponse reports whether a registry request that returned resp
+// and err failed transiently. Failures caused by ctx ending are not transient.
+func retryableRegistryResponse(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return transientNetworkError(err)
+	}
+	return slices.Contains(registryRetryStatusCodes, resp.StatusCode)
+}
+
+// transientNetworkError reports whether err is a timeout or a connection cut short.
+func transientNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+const defaultRegistryRetries = 3
+
+// getRegistryRetries returns the most attempts made at a registry call that fails
+// transiently. Can be configured via KUBE_COMPARE_MCP_REGISTRY_RETRIES environment variable.
+func getRegistryRetries() int {
+	if val := os.Getenv("KUBE_COMPARE_MCP_REGISTRY_RETRIES"); val != "" {
+		if retries, err := strconv.Atoi(val); err == nil && retries > 0 {
+			return retries
+		}
+	}
+	return defaultRegistryRetries
+}
+
+// registryCallRetryWait is the wait before the second attempt at a registry call.
+// Each later wait is twice the one before.
+var registryCallRetryWait = 500 * time.Millisecond
+
+// retryRegistryCall makes call until it succeeds, fails with an error that is not
+// transient, or has been made getRegistryRetries times. The attempts share a
+// deadline of registryTimeout, and a wait that would outlast it is not started;
+// the error of the last attempt is returned.
+func retryRegistryCall(ctx context.Context, op string, call func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+
+	attempts := getRegistryRetries()
+	wait := registryCallRetryWait
+	for attempt := 1; ; attempt++ {
+		err := call(ctx)
+		if err == nil || attempt >= attempts || !transientRegistryError(ctx, err) {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+		slog.Default().Debug("Retrying registry call after transient error",
+			"operation", op,
+			"attempt", attempt,
+			"wait", wait,
+			"error", err,
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+// transientRegistryError reports whether a registry call failed with err for a
+// reason that may pass: a 5xx or other retryable status, a timeout, or a reset
+// connection. Authentication and not-found errors are not transient, nor are
+// failures caused by ctx ending.
+func transientRegistryError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode >= http.StatusInternalServerError ||
+			slices.Contains(registryRetryStatusCodes, transportErr.StatusCode)
+	}
+	return transientNetworkError(err)
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// failingRoundTripper answers every request with status, or with err when it is
+// set, and counts the requests it was sent.
+type failingRoundTripper struct {
+	status int
+	err    error
+	calls  atomic.Int32
+}
+
+func (t *failingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{
+		StatusCode: t.status,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+// useRegistryTransport makes registry requests go through transport for the
+// rest of the spec.
+func useRegistryTransport(transport http.RoundTripper) {
+	original := registryTransport
+	registryTransport = transport
+	DeferCleanup(func() { registryTransport = original })
+}
+
+var _ = Describe("Registry retries", func() {
+	const testImage = "registry.example.com/org/refs:v1"
+
+	client := &DefaultRegistryClient{}
+	fastBackoff := remote.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+
+	It("retries a transient failure up to the budget", func() {
+		failing := &failingRoundTripper{status: http.StatusServiceUnavailable}
+		useRegistryTransport(newRegistryRetryTransport(failing, fastBackoff))
+
+		err := client.HeadImage(context.Background(), testImage)
+		Expect(err).To(HaveOccurred())
+		Expect(failing.calls.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("retries a reset connection", func() {
+		failing := &failingRoundTripper{err: syscall.ECONNRESET}
+		useRegistryTransport(newRegistryRetryTransport(failing, fastBackoff))
+
+		Expect(client.HeadImage(context.Background(), testImage)).NotTo(Succeed())
+		Expect(failing.calls.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("does not retry a response that is not transient", func() {
+		failing := &failingRoundTripper{status: http.StatusForbidden}
+		useRegistryTransport(newRegistryRetryTransport(failing, fastBackoff))
+
+		Expect(client.HeadImage(context.Background(), testImage)).NotTo(Succeed())
+		Expect(failing.calls.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("returns within the context's deadline against a registry that always fails", func() {
+		failing := &failingRoundTripper{status: http.StatusServiceUnavailable}
+		useRegistryTransport(newRegistryRetryTransport(failing, registryRetryBackoff))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := client.HeadImage(ctx, testImage)
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 250*time.Millisecond))
+	})
+
+	It("stops waiting to retry when the context is canceled", func() {
+		failing := &failingRoundTripper{status: http.StatusServiceUnavailable}
+		useRegistryTransport(newRegistryRetryTransport(failing, registryRetryBackoff))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		Expect(client.HeadImage(ctx, testImage)).NotTo(Succeed())
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		Expect(failing.calls.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("caps OCI reference validation at the validation timeout", func() {
+		GinkgoT().Setenv("KUBE_COMPARE_MCP_OCI_VALIDATION_TIMEOUT", "200ms")
+		failing := &failingRoundTripper{status: http.StatusServiceUnavailable}
+		useRegistryTransport(newRegistryRetryTransport(failing, registryRetryBackoff))
+
+		service := &CompareService{Registry: client}
+		start := time.Now()
+		err := service.ValidateOCIReference(context.Background(), "container://"+testImage+":/metadata.yaml")
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 250*time.Millisecond))
+	})
+})
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// SingleflightRegistryClient wraps a RegistryClient so that concurrent calls for the
+// same repository or image reference share one registry round trip. Bursty sessions
+// often resolve the same RDS image from several requests at once.
+//
+// The shared call runs with the context of the caller that started it. Other callers
+// stop waiting when their own context is done, but do not cancel the shared call. When
+// the shared call fails because the context of the caller that started it was done,
+// callers whose own context is still live start the call again.
+type SingleflightRegistryClient struct {
+	Registry RegistryClient
+
+	tags    singleflight.Group
+	heads   singleflight.Group
+	digests singleflight.Group
+	configs singleflight.Group
+}
+
+// NewSingleflightRegistryClient returns a RegistryClient that coalesces concurrent
+// calls to registry.
+func NewSingleflightRegistryClient(registry RegistryClient) *SingleflightRegistryClient {
+	return &SingleflightRegistryClient{Registry: registry}
+}
+
+// ListTags lists tags for repo, sharing the result with concurrent callers for the same repo.
+func (c *SingleflightRegistryClient) ListTags(ctx context.Context, repo string) ([]string, error) {
+	v, err := waitForFlight(ctx, &c.tags, repo, func(ctx context.Context) (any, error) {
+		return c.Registry.ListTags(ctx, repo)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy so callers cannot modify the shared slice
+	tags, _ := v.([]string)
+	return append([]string(nil), tags...), nil
+}
+
+// HeadImage validates imageRef, sharing the result with concurrent callers for the same reference.
+func (c *SingleflightRegistryClient) HeadImage(ctx context.Context, imageRef string) error {
+	_, err := waitForFlight(ctx, &c.heads, imageRef, func(ctx context.Context) (any, error) {
+		return nil, c.Registry.HeadImage(ctx, imageRef)
+	})
+	return err
+}
+
+// Digest resolves imageRef to its manifest digest, sharing the result with concurrent
+// callers for the same reference.
+func (c *SingleflightRegistryClient) Digest(ctx context.Context, imageRef string) (string, error) {
+	v, err := waitForFlight(ctx, &c.digests, imageRef, func(ctx context.Context) (any, error) {
+		return c.Registry.Digest(ctx, imageRef)
+	})
+	if err != nil {
+		return "", err
+	}
+	digest, _ := v.(string)
+	return digest, nil
+}
+
+// GetImageConfig fetches the config of imageRef, sharing the result with concurrent
+// callers for the same reference.
+func (c *SingleflightRegistryClient) GetImageConfig(ctx context.Context, imageRef string) (*ImageConfig, error) {
+	v, err := waitForFlight(ctx, &c.configs, imageRef, func(ctx context.Context) (any, error) {
+		return c.Registry.GetImageConfig(ctx, imageRef)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy so callers cannot modify the shared config
+	shared, ok := v.(*ImageConfig)
+	if !ok || shared == nil || shared.Config == nil {
+		return nil, fmt.Errorf("no image config returned for %s", imageRef)
+	}
+	return &ImageConfig{Digest: shared.Digest, Config: shared.Config.DeepCopy()}, nil
+}
+
+// waitForFlight runs fn once per key across concurrent callers and waits for the
+// shared result or for ctx to be done. fn is called with the context of the caller
+// that starts the flight. When another caller's flight fails with a context error,
+// the flight is started again while ctx is live.
+func waitForFlight(ctx context.Context, group *singleflight.Group, key string, fn func(ctx context.Context) (any, error)) (any, error) {
+	for {
+		led := false
+		ch := group.DoChan(key, func() (any, error) {
+			led = true
+			return fn(ctx)
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case res := <-ch:
+			if !led && isContextError(res.Err) && ctx.Err() == nil {
+				continue
+			}
+			return res.Val, res.Err
+		}
+	}
+}
+
+// isContextError reports whether err was caused by a canceled or expired context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
+)
+
+var _ = Describe("SingleflightRegistryClient", func() {
+	const (
+		callers  = 10
+		imageRef = "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9:v4.18"
+		repoRef  = "registry.redhat.io/openshift4/openshift-telco-core-rds-rhel9"
+	)
+
+	var (
+		ctrl         *gomock.Controller
+		mockRegistry *MockRegistryClient
+		client       *mcpserver.SingleflightRegistryClient
+		release      chan struct{}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockRegistry = NewMockRegistryClient(ctrl)
+		client = mcpserver.NewSingleflightRegistryClient(mockRegistry)
+		release = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	// runConcurrently starts callers goroutines running fn, releases the blocked
+	// registry call once they have all started, and waits for them to finish.
+	runConcurrently := func(fn func()) {
+		var ready, done sync.WaitGroup
+		ready.Add(callers)
+		done.Add(callers)
+		for range callers {
+			go func() {
+				defer GinkgoRecover()
+				defer done.Done()
+				ready.Done()
+				fn()
+			}()
+		}
+		ready.Wait()
+		// Give every goroutine time to join the in-flight call
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		done.Wait()
+	}
+
+	It("coalesces concurrent HeadImage calls for the same image", func() {
+		mockRegistry.EXPECT().
+			HeadImage(gomock.Any(), imageRef).
+			DoAndReturn(func(context.Context, string) error {
+				<-release
+				return nil
+			}).
+			Times(1)
+
+		runConcurrently(func() {
+			Expect(client.HeadImage(context.Background(), imageRef)).To(Succeed())
+		})
+	})
+
+	It("shares errors with every concurrent caller", func() {
+		mockRegistry.EXPECT().
+			HeadImage(gomock.Any(), imageRef).
+			DoAndReturn(func(context.Context, string) error {
+				<-release
+				return errors.New("MANIFEST_UNKNOWN")
+			}).
+			Times(1)
+
+		runConcurrently(func() {
+			Expect(client.HeadImage(context.Background(), imageRef)).To(MatchError(ContainSubstring("MANIFEST_UNKNOWN")))
+		})
+	})
+
+	It("coalesces concurrent ListTags calls and returns independent slices", func() {
+		mockRegistry.EXPECT().
+			ListTags(gomock.Any(), repoRef).
+			DoAndReturn(func(context.Context, string) ([]string, error) {
+				<-release
+				return []string{"v4.17", "v4.18"}, nil
+			}).
+			Times(1)
+
+		runConcurrently(func() {
+			tags, err := client.ListTags(context.Background(), repoRef)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tags).To(Equal([]string{"v4.17", "v4.18"}))
+			tags[0] = "modified"
+		})
+	})
+
+	It("coalesces concurrent GetImageConfig calls and returns independent configs", func() {
+		mockRegistry.EXPECT().
+			GetImageConfig(gomock.Any(), imageRef).
+			DoAndReturn(func(context.Context, string) (*mcpserver.ImageConfig, error) {
+				<-release
+				return &mcpserver.ImageConfig{
+					Digest: "sha256:0123456789abcdef",
+					Config: &v1.ConfigFile{Config: v1.Config{Labels: map[string]string{"version": "v4.18.0"}}},
+				}, nil
+			}).
+			Times(1)
+
+		runConcurrently(func() {
+			imageConfig, err := client.GetImageConfig(context.Background(), imageRef)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imageConfig.Digest).To(Equal("sha256:0123456789abcdef"))
+			Expect(imageConfig.Config.Config.Labels).To(HaveKeyWithValue("version", "v4.18.0"))
+			imageConfig.Config.Config.Labels["version"] = "modified"
+		})
+	})
+
+	It("calls the registry again once the previous call has completed", func() {
+		mockRegistry.EXPECT().
+			HeadImage(gomock.Any(), imageRef).
+			Return(nil).
+			Times(2)
+
+		Expect(client.HeadImage(context.Background(), imageRef)).To(Succeed())
+		Expect(client.HeadImage(context.Background(), imageRef)).To(Succeed())
+	})
+
+	It("returns when the caller's context is canceled while waiting", func() {
+		mockRegistry.EXPECT().
+			HeadImage(gomock.Any(), imageRef).
+			DoAndReturn(func(context.Context, string) error {
+				<-release
+				return nil
+			}).
+			Times(1)
+
+		firstDone := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(firstDone)
+			_ = client.HeadImage(context.Background(), imageRef)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		Expect(client.HeadImage(ctx, imageRef)).To(MatchError(context.DeadlineExceeded))
+
+		close(release)
+		Eventually(firstDone).Should(BeClosed())
+	})
+
+	It("calls the registry again for waiting callers when the first caller's context is done", func() {
+		leaderCtx, cancelLeader := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		gomock.InOrder(
+			mockRegistry.EXPECT().
+				HeadImage(gomock.Any(), imageRef).
+				DoAndReturn(func(ctx context.Context, _ string) error {
+					close(started)
+					<-ctx.Done()
+					return ctx.Err()
+				}),
+			mockRegistry.EXPECT().
+				HeadImage(gomock.Any(), imageRef).
+				Return(nil),
+		)
+
+		leaderDone := make(chan error, 1)
+		go func() {
+			leaderDone <- client.HeadImage(leaderCtx, imageRef)
+		}()
+		<-started
+
+		followerDone := make(chan error, 1)
+		go func() {
+			followerDone <- client.HeadImage(context.Background(), imageRef)
+		}()
+		// Give the follower time to join the in-flight call
+		time.Sleep(50 * time.Millisecond)
+		cancelLeader()
+
+		Eventually(leaderDone).Should(Receive(MatchError(context.Canceled)))
+		Eventually(followerDone).Should(Receive(BeNil()))
+	})
+
+	It("returns an error when the registry returns no image config", func() {
+		mockRegistry.EXPECT().
+			GetImageConfig(gomock.Any(), imageRef).
+			Return(nil, nil)
+
+		_, err := client.GetImageConfig(context.Background(), imageRef)
+		Expect(err).To(MatchError(ContainSubstring("no image config")))
+	})
+})
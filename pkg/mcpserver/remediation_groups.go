@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"sort"
+
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+// RemediationGroups groups the drift of a comparison by what an operator would do to
+// resolve it. It is returned when group_by_remediation is set.
+type RemediationGroups struct {
+	// Create lists the required reference templates no cluster resource matched, as
+	// part/component/template
+	Create RemediationGroup `json:"create"`
+	// Update lists the CRs whose fields differ from their template
+	Update RemediationGroup `json:"update"`
+	// Delete lists the cluster CRs of the reference's kinds that no template matched.
+	// They are candidates for removal, not necessarily drift.
+	Delete RemediationGroup `json:"delete"`
+}
+
+// RemediationGroup is the resources that share a remediation action.
+type RemediationGroup struct {
+	Count     int      `json:"count"`
+	Resources []string `json:"resources"`
+}
+
+// GroupDiffsByRemediation sorts the drift of output into the resources to create,
+// update, and delete. Missing templates come from the summary's validation issues,
+// drifted CRs from the diffs, and extra CRs from the summary's unmatched CRs.
+func GroupDiffsByRemediation(output *compare.Output) *RemediationGroups {
+	groups := &RemediationGroups{
+		Create: RemediationGroup{Resources: []string{}},
+		Update: RemediationGroup{Resources: []string{}},
+		Delete: RemediationGroup{Resources: []string{}},
+	}
+
+	if output.Summary != nil {
+		parts := make([]string, 0, len(output.Summary.ValidationIssues))
+		for part := range output.Summary.ValidationIssues {
+			parts = append(parts, part)
+		}
+		sort.Strings(parts)
+		for _, part := range parts {
+			components := make([]string, 0, len(output.Summary.ValidationIssues[part]))
+			for component := range output.Summary.ValidationIssues[part] {
+				components = append(components, component)
+			}
+			sort.Strings(components)
+			for _, component := range components {
+				for _, template := range output.Summary.ValidationIssues[part][component].CRs {
+					groups.Create.Resources = append(groups.Create.Resources, part+"/"+component+"/"+template)
+				}
+			}
+		}
+		groups.Delete.Resources = append(groups.Delete.Resources, output.Summary.UnmatchedCRS...)
+	}
+
+	if output.Diffs != nil {
+		for _, diff := range *output.Diffs {
+			if diff.HasDiff() {
+				groups.Update.Resources = append(groups.Update.Resources, diff.CRName)
+			}
+		}
+	}
+
+	groups.Create.Count = len(groups.Create.Resources)
+	groups.Update.Count = len(groups.Update.Resources)
+	groups.Delete.Count = len(groups.Delete.Resources)
+	return groups
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+// newRemediationTestOutput returns kube-compare output with a missing required
+// template, a drifted Deployment, a matching ConfigMap, and an unmatched Secret.
+func newRemediationTestOutput() *compare.Output {
+	return &compare.Output{
+		Summary: &compare.Summary{
+			ValidationIssues: map[string]map[string]compare.ValidationIssue{
+				"core": {"sriov": {
+					Msg: "Missing CRs",
+					CRs: []string{"networking/sriov-policy.yaml"},
+				}},
+			},
+			NumMissing:   1,
+			UnmatchedCRS: []string{"v1_Secret_payments_billing-token"},
+			NumDiffCRs:   1,
+			TotalCRs:     2,
+		},
+		Diffs: &[]compare.DiffSum{
+			{CRName: "apps/v1_Deployment_payments_billing", CorrelatedTemplate: "deployment.yaml", DiffOutput: volatileDiffHeader + driftHunk},
+			{CRName: "v1_ConfigMap_payments_billing-settings", CorrelatedTemplate: "configmap.yaml"},
+		},
+	}
+}
+
+var _ = Describe("group_by_remediation", func() {
+	Describe("GroupDiffsByRemediation", func() {
+		It("groups a missing resource, a drifted resource, and an extra resource by action", func() {
+			groups := GroupDiffsByRemediation(newRemediationTestOutput())
+
+			Expect(groups.Create).To(Equal(RemediationGroup{Count: 1, Resources: []string{"core/sriov/networking/sriov-policy.yaml"}}))
+			Expect(groups.Update).To(Equal(RemediationGroup{Count: 1, Resources: []string{"apps/v1_Deployment_payments_billing"}}))
+			Expect(groups.Delete).To(Equal(RemediationGroup{Count: 1, Resources: []string{"v1_Secret_payments_billing-token"}}))
+		})
+
+		It("orders the missing templates by part and component", func() {
+			output := &compare.Output{Summary: &compare.Summary{
+				ValidationIssues: map[string]map[string]compare.ValidationIssue{
+					"ran": {"ptp": {CRs: []string{"ptp.yaml"}}},
+					"core": {
+						"storage":    {CRs: []string{"odf.yaml"}},
+						"networking": {CRs: []string{"sriov.yaml", "nmstate.yaml"}},
+					},
+				},
+			}}
+
+			Expect(GroupDiffsByRemediation(output).Create.Resources).To(Equal([]string{
+				"core/networking/sriov.yaml",
+				"core/networking/nmstate.yaml",
+				"core/storage/odf.yaml",
+				"ran/ptp/ptp.yaml",
+			}))
+		})
+
+		It("returns empty groups when nothing differs", func() {
+			groups := GroupDiffsByRemediation(&compare.Output{})
+			Expect(groups.Create.Resources).To(BeEmpty())
+			Expect(groups.Update.Count).To(BeZero())
+			Expect(groups.Delete.Resources).NotTo(BeNil())
+		})
+	})
+
+	It("adds the groups to the structured result", func() {
+		groups := GroupDiffsByRemediation(newRemediationTestOutput())
+		run := &compareRun{
+			outcome:     CompareOutcomeDifferencesFound,
+			summary:     &compare.Summary{NumDiffCRs: 1},
+			remediation: groups,
+		}
+		Expect(run.clusterDiffOutput().Remediation).To(Equal(groups))
+	})
+
+	It("anonymizes the cluster resources but not the reference templates", func() {
+		a, err := newAnonymizer(false)
+		Expect(err).NotTo(HaveOccurred())
+		groups := GroupDiffsByRemediation(newRemediationTestOutput())
+
+		a.anonymizeRemediation(groups)
+		Expect(groups.Create.Resources).To(Equal([]string{"core/sriov/networking/sriov-policy.yaml"}))
+		Expect(groups.Update.Resources).To(Equal([]string{"apps/v1_Deployment_" + a.token("payments") + "_" + a.token("billing")}))
+		Expect(groups.Delete.Resources).To(Equal([]string{"v1_Secret_" + a.token("payments") + "_" + a.token("billing-token")}))
+	})
+
+	It("makes kube-compare output JSON", func() {
+		Expect(compareOutputFormat(&CompareArgs{OutputFormat: compare.Yaml, GroupByRemediation: true})).To(Equal(compare.Json))
+	})
+})
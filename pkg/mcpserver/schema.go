@@ -22,10 +22,15 @@ func ClusterDiffInputSchema() *jsonschema.Schema {
 
 	// Add enum constraint for output_format
 	if prop, ok := schema.Properties["output_format"]; ok {
-		prop.Enum = []any{"json", "yaml", "junit"}
+		prop.Enum = []any{"json", "yaml", "junit", OutputFormatSummary}
 		prop.Default = json.RawMessage(`"json"`)
 	}
 
+	if prop, ok := schema.Properties["verbosity"]; ok {
+		prop.Enum = []any{VerbosityTerse, VerbosityNormal, VerbosityFull}
+		prop.Default = json.RawMessage(`"normal"`)
+	}
+
 	makeOptionalFieldsNullable(schema)
 	return schema
 }
@@ -40,13 +45,54 @@ func ResolveRDSInputSchema() *jsonschema.Schema {
 
 	// Add enum constraint for rds_type
 	if prop, ok := schema.Properties["rds_type"]; ok {
-		prop.Enum = []any{"core", "ran", "hub"}
+		prop.Enum = rdsTypeEnum()
+	}
+
+	if prop, ok := schema.Properties["ocp_version"]; ok {
+		prop.Pattern = ocpVersionRegex.String()
+	}
+
+	if prop, ok := schema.Properties["channel"]; ok {
+		prop.Pattern = imageTagRegex.String()
 	}
 
 	makeOptionalFieldsNullable(schema)
 	return schema
 }
 
+// ResolveRDSOutputSchema returns the JSON schema for ResolveRDSResult
+// enabling structured output validation per MCP 2025-06-18 specification.
+func ResolveRDSOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ResolveRDSResult](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["reference"]; ok {
+		prop.Description = "Container reference to pass to kube_compare_cluster_diff"
+	}
+	if prop, ok := schema.Properties["cluster_version"]; ok {
+		prop.Description = "OpenShift version used to select the RDS image, empty when a channel alias selected it"
+	}
+	if prop, ok := schema.Properties["available_versions"]; ok {
+		prop.Description = "RDS image tags available in the registry"
+	}
+	if prop, ok := schema.Properties["reference_version"]; ok {
+		prop.Description = "OpenShift version the reference was selected for, when reference_ocp_version set it apart from cluster_version"
+	}
+	if prop, ok := schema.Properties["variant_selection"]; ok {
+		prop.Description = "RHEL variants tried in order when resolving for a version: whether each repository was reachable, had the version, and was chosen. Unset when the reference came from the cache"
+	}
+	if prop, ok := schema.Properties["validated"]; ok {
+		prop.Description = "Whether the RDS image was confirmed to be accessible"
+	}
+	if prop, ok := schema.Properties["floating"]; ok {
+		prop.Description = "Whether the reference was resolved from a channel alias, whose image can change, so comparisons against it are not reproducible"
+	}
+
+	return schema
+}
+
 // ValidateRDSInputSchema returns the JSON schema for ValidateRDSInput
 // with proper enum constraints for rds_type and output_format.
 func ValidateRDSInputSchema() *jsonschema.Schema {
@@ -57,15 +103,33 @@ func ValidateRDSInputSchema() *jsonschema.Schema {
 
 	// Add enum constraint for rds_type
 	if prop, ok := schema.Properties["rds_type"]; ok {
-		prop.Enum = []any{"core", "ran", "hub"}
+		prop.Enum = rdsTypeEnum()
+	}
+
+	// Add enum constraint for rds_types items
+	if prop, ok := schema.Properties["rds_types"]; ok && prop.Items != nil {
+		prop.Items.Enum = rdsTypeEnum()
 	}
 
 	// Add enum constraint for output_format
 	if prop, ok := schema.Properties["output_format"]; ok {
-		prop.Enum = []any{"json", "yaml", "junit"}
+		prop.Enum = []any{"json", "yaml", "junit", OutputFormatSummary}
 		prop.Default = json.RawMessage(`"json"`)
 	}
 
+	if prop, ok := schema.Properties["verbosity"]; ok {
+		prop.Enum = []any{VerbosityTerse, VerbosityNormal, VerbosityFull}
+		prop.Default = json.RawMessage(`"normal"`)
+	}
+
+	if prop, ok := schema.Properties["ocp_version"]; ok {
+		prop.Pattern = ocpVersionRegex.String()
+	}
+
+	if prop, ok := schema.Properties["channel"]; ok {
+		prop.Pattern = imageTagRegex.String()
+	}
+
 	makeOptionalFieldsNullable(schema)
 	return schema
 }
@@ -73,6 +137,10 @@ func ValidateRDSInputSchema() *jsonschema.Schema {
 // Kubernetes resource name pattern (RFC 1123 DNS subdomain).
 const k8sNamePattern = `^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
 
+// Pattern for a comma-separated list of Kubernetes resource names.
+const k8sNameListPattern = `^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*` +
+	`(,[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*)*$`
+
 // BIOSDiffInputSchema returns the JSON schema for BIOSDiffInput
 // with proper enum constraints, defaults, and validation patterns.
 func BIOSDiffInputSchema() *jsonschema.Schema {
@@ -84,6 +152,13 @@ func BIOSDiffInputSchema() *jsonschema.Schema {
 	// Add pattern validation for Kubernetes resource names
 	if prop, ok := schema.Properties["namespace"]; ok {
 		prop.Pattern = k8sNamePattern
+
+		// namespace is optional, since the kubeconfig context may set it; report the
+		// server-side default the handler falls back to
+		if defaultNamespace := getDefaultBMHNamespace(); defaultNamespace != "" {
+			defaultJSON, _ := json.Marshal(defaultNamespace)
+			prop.Default = json.RawMessage(defaultJSON)
+		}
 	}
 
 	if prop, ok := schema.Properties["host_name"]; ok {
@@ -91,7 +166,7 @@ func BIOSDiffInputSchema() *jsonschema.Schema {
 	}
 
 	if prop, ok := schema.Properties["reference_source"]; ok {
-		prop.Pattern = k8sNamePattern
+		prop.Pattern = k8sNameListPattern
 		prop.Default = json.RawMessage(`"reference-configs"`)
 	}
 
@@ -131,6 +206,321 @@ func BIOSDiffOutputSchema() *jsonschema.Schema {
 	return schema
 }
 
+// BIOSExplainMatchInputSchema returns the JSON schema for BIOSExplainMatchInput
+// with Kubernetes name validation patterns and defaults.
+func BIOSExplainMatchInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[BIOSExplainMatchInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	for _, field := range []string{"namespace", "host_name"} {
+		if prop, ok := schema.Properties[field]; ok {
+			prop.Pattern = k8sNamePattern
+		}
+	}
+
+	if prop, ok := schema.Properties["reference_source"]; ok {
+		prop.Pattern = k8sNameListPattern
+		prop.Default = json.RawMessage(`"reference-configs"`)
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// BIOSExplainMatchOutputSchema returns the JSON schema for BIOSMatchExplanation.
+func BIOSExplainMatchOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[BIOSMatchExplanation](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["Reason"]; ok {
+		prop.Description = "Machine-readable outcome of the matching pipeline"
+		prop.Enum = []any{
+			MatchReasonExactName,
+			MatchReasonLabelMatch,
+			MatchReasonNoVendorRoleMatch,
+			MatchReasonBelowThreshold,
+			MatchReasonListFailed,
+			MatchReasonAmbiguous,
+		}
+	}
+	if prop, ok := schema.Properties["Candidates"]; ok {
+		prop.Description = "Reference ConfigMaps matching the vendor and role labels, with model similarity scores"
+	}
+
+	return schema
+}
+
+// HostFirmwareSettingsInputSchema returns the JSON schema for HostFirmwareSettingsInput
+// with Kubernetes name validation patterns.
+func HostFirmwareSettingsInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[HostFirmwareSettingsInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	for _, field := range []string{"namespace", "host_name"} {
+		if prop, ok := schema.Properties[field]; ok {
+			prop.Pattern = k8sNamePattern
+		}
+	}
+
+	if prop, ok := schema.Properties["include_pending"]; ok {
+		prop.Default = json.RawMessage(`false`)
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// HostFirmwareSettingsOutputSchema returns the JSON schema for HostFirmwareSettingsResult.
+func HostFirmwareSettingsOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[HostFirmwareSettingsResult](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["Settings"]; ok {
+		prop.Description = "Current BIOS settings from status.settings"
+	}
+	if prop, ok := schema.Properties["PendingSettings"]; ok {
+		prop.Description = "Requested BIOS settings from spec.settings, when include_pending is set"
+	}
+	if prop, ok := schema.Properties["YAML"]; ok {
+		prop.Description = "The HostFirmwareSettings object as YAML, without server-managed metadata"
+	}
+
+	return schema
+}
+
+// ClusterAccessInputSchema returns the JSON schema for ClusterAccessInput.
+func ClusterAccessInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ClusterAccessInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// ClusterAccessOutputSchema returns the JSON schema for ClusterAccessResult.
+func ClusterAccessOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ClusterAccessResult](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["server_version"]; ok {
+		prop.Description = "Kubernetes version reported by the API server"
+	}
+	if prop, ok := schema.Properties["all_allowed"]; ok {
+		prop.Description = "Whether every permission check was allowed"
+	}
+
+	return schema
+}
+
+// RDSPrerequisitesInputSchema returns the JSON schema for RDSPrerequisitesInput.
+func RDSPrerequisitesInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[RDSPrerequisitesInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["rds_type"]; ok {
+		prop.Enum = rdsTypeEnum()
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// RDSPrerequisitesOutputSchema returns the JSON schema for RDSPrerequisitesResult.
+func RDSPrerequisitesOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[RDSPrerequisitesResult](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["all_present"]; ok {
+		prop.Description = "Whether the cluster serves every prerequisite of the RDS type"
+	}
+	if prop, ok := schema.Properties["missing"]; ok {
+		prop.Description = "Names of the prerequisite operators the cluster does not have"
+	}
+
+	return schema
+}
+
+// ListRDSVersionsInputSchema returns the JSON schema for ListRDSVersionsInput
+// with proper enum constraints for rds_type.
+func ListRDSVersionsInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ListRDSVersionsInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["rds_type"]; ok {
+		prop.Enum = rdsTypeEnum()
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// ListRDSVersionsOutputSchema returns the JSON schema for ListRDSVersionsResult.
+func ListRDSVersionsOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ListRDSVersionsResult](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["versions"]; ok {
+		prop.Description = "Published versions, oldest first, each with the RHEL variants it is published for"
+	}
+	if prop, ok := schema.Properties["variants"]; ok {
+		prop.Description = "Version tags of each RHEL variant repository, with the error of a repository that could not be listed"
+	}
+
+	return schema
+}
+
+// ListReferenceContentsInputSchema returns the JSON schema for ListReferenceContentsInput.
+func ListReferenceContentsInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ListReferenceContentsInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["max_entries"]; ok {
+		minimum, maximum := 1.0, float64(MaxAllowedReferenceEntries)
+		prop.Minimum = &minimum
+		prop.Maximum = &maximum
+		prop.Default = json.RawMessage(`200`)
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// ListReferenceContentsOutputSchema returns the JSON schema for ReferenceContentsResult.
+func ListReferenceContentsOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ReferenceContentsResult](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["search_root"]; ok {
+		prop.Description = "Image directory whose entries are listed: the parent of the directory expected to contain the target"
+	}
+	if prop, ok := schema.Properties["target_found"]; ok {
+		prop.Description = "Whether the target path exists in the image as a regular file"
+	}
+	if prop, ok := schema.Properties["truncated"]; ok {
+		prop.Description = "Whether entries were omitted because max_entries was reached"
+	}
+
+	return schema
+}
+
+// InspectReferenceImageInputSchema returns the JSON schema for InspectReferenceImageInput.
+func InspectReferenceImageInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[InspectReferenceImageInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["rds_type"]; ok {
+		prop.Enum = rdsTypeEnum()
+	}
+
+	if prop, ok := schema.Properties["ocp_version"]; ok {
+		prop.Pattern = ocpVersionRegex.String()
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// InspectReferenceImageOutputSchema returns the JSON schema for ReferenceImageResult.
+func InspectReferenceImageOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ReferenceImageResult](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["digest"]; ok {
+		prop.Description = "Digest of the image manifest; for a multi-platform image, of the linux/amd64 image"
+	}
+	if prop, ok := schema.Properties["created"]; ok {
+		prop.Description = "Image creation time from the image config, in RFC 3339 format"
+	}
+	if prop, ok := schema.Properties["labels"]; ok {
+		prop.Description = "The image's org.opencontainers.image.* labels and Red Hat build provenance labels"
+	}
+
+	return schema
+}
+
+// ServerBuildInfoOutputSchema returns the JSON schema for BuildInfo.
+func ServerBuildInfoOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[BuildInfo](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["git_commit"]; ok {
+		prop.Description = "Git commit the server was built from"
+	}
+	if prop, ok := schema.Properties["build_date"]; ok {
+		prop.Description = "Build time, or commit time when the build time was not recorded, in RFC 3339 format"
+	}
+	if prop, ok := schema.Properties["kube_compare_version"]; ok {
+		prop.Description = "Version of the kube-compare library the server was built with, or \"unknown\" without build information"
+	}
+
+	return schema
+}
+
+// ComplianceReportInputSchema returns the JSON schema for ComplianceReportInput
+// with proper enum constraints and validation patterns.
+func ComplianceReportInputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ComplianceReportInput](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["rds_type"]; ok {
+		prop.Enum = rdsTypeEnum()
+	}
+
+	if prop, ok := schema.Properties["bios_namespace"]; ok {
+		prop.Pattern = k8sNamePattern
+	}
+
+	makeOptionalFieldsNullable(schema)
+	return schema
+}
+
+// ComplianceReportOutputSchema returns the JSON schema for ComplianceReport.
+func ComplianceReportOutputSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[ComplianceReport](nil)
+	if err != nil {
+		panic(err) // Fails at startup, not during request handling
+	}
+
+	if prop, ok := schema.Properties["verdict"]; ok {
+		prop.Enum = []any{ComplianceStatusCompliant, ComplianceStatusNonCompliant, ComplianceStatusIncomplete}
+		prop.Description = "non_compliant when any domain has drift, incomplete when none does but a domain failed, compliant otherwise"
+	}
+
+	return schema
+}
+
 // makeOptionalFieldsNullable makes non-required fields accept null values in
 // addition to their declared type. LLM clients often send "field": null instead
 // of omitting optional fields, which fails strict JSON schema validation.
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolSchemas is the document DumpToolSchemas writes: every tool the server
+// registers, in name order.
+type ToolSchemas struct {
+	Server  string       `json:"server"`
+	Version string       `json:"version"`
+	Tools   []ToolSchema `json:"tools"`
+}
+
+// ToolSchema is the name, description, and input and output JSON schemas of a tool.
+type ToolSchema struct {
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	InputSchema  any    `json:"inputSchema"`
+	OutputSchema any    `json:"outputSchema,omitempty"`
+}
+
+// DumpToolSchemas writes the schemas of every tool NewServer registers to w as
+// indented JSON. The tools are listed from an in-memory session with the server,
+// so the schemas are exactly the ones clients see, including the output schemas
+// the MCP SDK derives for tools that do not set one.
+func DumpToolSchemas(ctx context.Context, w io.Writer, version string) error {
+	server := NewServer(version)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start in-memory server session: %w", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: ServerName + "-schema-dump", Version: version}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to in-memory server session: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	schemas := ToolSchemas{Server: ServerName, Version: version, Tools: []ToolSchema{}}
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("failed to list tools: %w", err)
+		}
+		schemas.Tools = append(schemas.Tools, ToolSchema{
+			Name:         tool.Name,
+			Description:  tool.Description,
+			InputSchema:  tool.InputSchema,
+			OutputSchema: tool.OutputSchema,
+		})
+	}
+
+	data, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format tool schemas: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sakhoury/kube-compare-mcp/pkg/mcpserver"
+)
+
+var _ = Describe("DumpToolSchemas", func() {
+	It("writes the input and output schemas of every tool as JSON", func() {
+		var buf bytes.Buffer
+		Expect(mcpserver.DumpToolSchemas(context.Background(), &buf, "1.2.3")).To(Succeed())
+
+		var dump struct {
+			Server  string `json:"server"`
+			Version string `json:"version"`
+			Tools   []struct {
+				Name         string         `json:"name"`
+				InputSchema  map[string]any `json:"inputSchema"`
+				OutputSchema map[string]any `json:"outputSchema"`
+			} `json:"tools"`
+		}
+		Expect(json.Unmarshal(buf.Bytes(), &dump)).To(Succeed())
+		Expect(dump.Server).To(Equal(mcpserver.ServerName))
+		Expect(dump.Version).To(Equal("1.2.3"))
+
+		names := make([]string, 0, len(dump.Tools))
+		for _, tool := range dump.Tools {
+			names = append(names, tool.Name)
+			Expect(tool.InputSchema).To(HaveKeyWithValue("type", "object"), tool.Name)
+			Expect(tool.OutputSchema).To(HaveKeyWithValue("type", "object"), tool.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"baremetal_bios_diff",
+			"baremetal_bios_explain_match",
+			"baremetal_host_firmware_settings",
+			"check_rds_prerequisites",
+			"cluster_compliance_report",
+			"kube_compare_check_cluster_access",
+			"kube_compare_cluster_diff",
+			"kube_compare_inspect_reference_image",
+			"kube_compare_list_reference_contents",
+			"kube_compare_resolve_rds",
+			"kube_compare_server_build_info",
+			"kube_compare_validate_rds",
+			"list_rds_versions",
+		}))
+	})
+
+	It("includes the properties the schema builders define", func() {
+		var buf bytes.Buffer
+		Expect(mcpserver.DumpToolSchemas(context.Background(), &buf, "dev")).To(Succeed())
+
+		var dump mcpserver.ToolSchemas
+		Expect(json.Unmarshal(buf.Bytes(), &dump)).To(Succeed())
+		for _, tool := range dump.Tools {
+			if tool.Name != "kube_compare_cluster_diff" {
+				continue
+			}
+			input, ok := tool.InputSchema.(map[string]any)
+			Expect(ok).To(BeTrue())
+			Expect(input["properties"]).To(HaveKey("reference"))
+			Expect(input["properties"]).To(HaveKey("include_owned"))
+			return
+		}
+		Fail("kube_compare_cluster_diff is missing from the dump")
+	})
+})
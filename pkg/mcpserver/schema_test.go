@@ -23,7 +23,7 @@ var _ = Describe("Schema", func() {
 		It("has output_format property with enum constraint", func() {
 			prop, ok := schema.Properties["output_format"]
 			Expect(ok).To(BeTrue(), "output_format property should exist")
-			Expect(prop.Enum).To(ConsistOf("json", "yaml", "junit"))
+			Expect(prop.Enum).To(ConsistOf("json", "yaml", "junit", "summary"))
 		})
 
 		It("has output_format property with default value", func() {
@@ -66,6 +66,21 @@ var _ = Describe("Schema", func() {
 		})
 	})
 
+	Describe("ResolveRDSOutputSchema", func() {
+		var schema = mcpserver.ResolveRDSOutputSchema()
+
+		It("returns non-nil schema", func() {
+			Expect(schema).NotTo(BeNil())
+		})
+
+		It("has the structured result properties", func() {
+			Expect(schema.Properties).To(HaveKey("reference"))
+			Expect(schema.Properties).To(HaveKey("cluster_version"))
+			Expect(schema.Properties).To(HaveKey("available_versions"))
+			Expect(schema.Properties).To(HaveKey("validated"))
+		})
+	})
+
 	Describe("ValidateRDSInputSchema", func() {
 		var schema = mcpserver.ValidateRDSInputSchema()
 
@@ -82,7 +97,7 @@ var _ = Describe("Schema", func() {
 		It("has output_format property with enum constraint", func() {
 			prop, ok := schema.Properties["output_format"]
 			Expect(ok).To(BeTrue(), "output_format property should exist")
-			Expect(prop.Enum).To(ConsistOf("json", "yaml", "junit"))
+			Expect(prop.Enum).To(ConsistOf("json", "yaml", "junit", "summary"))
 		})
 
 		It("has output_format property with default value", func() {
@@ -104,6 +119,12 @@ var _ = Describe("Schema", func() {
 			_, ok := schema.Properties["all_resources"]
 			Expect(ok).To(BeTrue(), "all_resources property should exist")
 		})
+
+		It("has ocp_version property with a version pattern", func() {
+			prop, ok := schema.Properties["ocp_version"]
+			Expect(ok).To(BeTrue(), "ocp_version property should exist")
+			Expect(prop.Pattern).NotTo(BeEmpty())
+		})
 	})
 
 	Describe("Schema generation does not panic", func() {
@@ -119,6 +140,12 @@ var _ = Describe("Schema", func() {
 			}).NotTo(Panic())
 		})
 
+		It("ResolveRDSOutputSchema does not panic", func() {
+			Expect(func() {
+				_ = mcpserver.ResolveRDSOutputSchema()
+			}).NotTo(Panic())
+		})
+
 		It("ValidateRDSInputSchema does not panic", func() {
 			Expect(func() {
 				_ = mcpserver.ValidateRDSInputSchema()
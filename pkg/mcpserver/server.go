@@ -33,11 +33,20 @@ func NewServer(version string) *mcp.Server {
 	mcp.AddTool(s, ResolveRDSTool(), HandleResolveRDS)
 	mcp.AddTool(s, ValidateRDSTool(), HandleValidateRDS)
 	mcp.AddTool(s, BIOSDiffTool(), HandleBIOSDiff)
+	mcp.AddTool(s, BIOSExplainMatchTool(), HandleBIOSExplainMatch)
+	mcp.AddTool(s, HostFirmwareSettingsTool(), HandleHostFirmwareSettings)
+	mcp.AddTool(s, ClusterAccessTool(), HandleClusterAccess)
+	mcp.AddTool(s, RDSPrerequisitesTool(), HandleRDSPrerequisites)
+	mcp.AddTool(s, ListRDSVersionsTool(), HandleListRDSVersions)
+	mcp.AddTool(s, ListReferenceContentsTool(), HandleListReferenceContents)
+	mcp.AddTool(s, InspectReferenceImageTool(), HandleInspectReferenceImage)
+	mcp.AddTool(s, ServerBuildInfoTool(), HandleServerBuildInfo)
+	mcp.AddTool(s, ComplianceReportTool(), HandleComplianceReport)
 
 	logger.Info("MCP server initialized",
 		"name", ServerName,
 		"version", version,
-		"tools", []string{"kube_compare_cluster_diff", "kube_compare_resolve_rds", "kube_compare_validate_rds", "baremetal_bios_diff"},
+		"tools", []string{"kube_compare_cluster_diff", "kube_compare_resolve_rds", "kube_compare_validate_rds", "baremetal_bios_diff", "baremetal_bios_explain_match", "baremetal_host_firmware_settings", "kube_compare_check_cluster_access", "check_rds_prerequisites", "list_rds_versions", "kube_compare_list_reference_contents", "kube_compare_inspect_reference_image", "kube_compare_server_build_info", "cluster_compliance_report"},
 	)
 
 	return s
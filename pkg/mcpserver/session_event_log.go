@@ -0,0 +1,310 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SessionIDHeader carries the ID of an MCP session on the Streamable HTTP transport.
+	SessionIDHeader = "Mcp-Session-Id"
+
+	// DefaultSessionEventLogRetention is how long the events of a session are kept
+	// after its last event.
+	DefaultSessionEventLogRetention = 10 * time.Minute
+
+	// SessionEventLogPath is the admin endpoint the events of a session are read from,
+	// as SessionEventLogPath + "{id}/events".
+	SessionEventLogPath = "/debug/sessions/"
+
+	// maxSessionEventLogSessions bounds how many sessions are kept at once. The
+	// session seen least recently is dropped first.
+	maxSessionEventLogSessions = 100
+	// maxSessionEventDataBytes bounds the data recorded for a single event.
+	maxSessionEventDataBytes = 64 * 1024
+)
+
+// SessionEvent is a server-sent event streamed to an MCP client.
+type SessionEvent struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event,omitempty"`
+	ID    string    `json:"id,omitempty"`
+	Data  string    `json:"data"`
+	// Truncated is set when Data was cut to maxSessionEventDataBytes
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// SessionEventLogResponse is the response of the session event log endpoint.
+type SessionEventLogResponse struct {
+	SessionID string         `json:"session_id"`
+	Events    []SessionEvent `json:"events"`
+	// Dropped is the number of older events that no longer fit in the buffer
+	Dropped int `json:"dropped"`
+}
+
+// SessionEventLog keeps the last server-sent events streamed to each MCP session in
+// memory, so what a client received can be inspected after the fact. Each session
+// holds at most size events, and a session is forgotten once retention has passed
+// since its last event.
+type SessionEventLog struct {
+	size      int
+	retention time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*sessionEvents
+}
+
+// sessionEvents is the ring buffer of one session.
+type sessionEvents struct {
+	events   []SessionEvent
+	next     int
+	dropped  int
+	lastSeen time.Time
+}
+
+// NewSessionEventLog returns a log keeping size events per session for retention
+// after each session's last event. It returns nil, which disables the log, when size
+// is not positive.
+func NewSessionEventLog(size int, retention time.Duration) *SessionEventLog {
+	if size <= 0 {
+		return nil
+	}
+	if retention <= 0 {
+		retention = DefaultSessionEventLogRetention
+	}
+	return &SessionEventLog{
+		size:      size,
+		retention: retention,
+		now:       time.Now,
+		sessions:  make(map[string]*sessionEvents),
+	}
+}
+
+// Record adds event to the buffer of sessionID, dropping the session's oldest event
+// when the buffer is full.
+func (l *SessionEventLog) Record(sessionID string, event SessionEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.expire(now)
+	if event.Time.IsZero() {
+		event.Time = now
+	}
+	if len(event.Data) > maxSessionEventDataBytes {
+		event.Data = event.Data[:maxSessionEventDataBytes]
+		event.Truncated = true
+	}
+
+	session, ok := l.sessions[sessionID]
+	if !ok {
+		if len(l.sessions) >= maxSessionEventLogSessions {
+			l.evictOldest()
+		}
+		session = &sessionEvents{events: make([]SessionEvent, 0, l.size)}
+		l.sessions[sessionID] = session
+	}
+	session.lastSeen = now
+
+	if len(session.events) < l.size {
+		session.events = append(session.events, event)
+		return
+	}
+	session.events[session.next] = event
+	session.next = (session.next + 1) % l.size
+	session.dropped++
+}
+
+// Events returns the recorded events of sessionID, oldest first, and how many older
+// events were dropped. It reports false for a session with no events within the
+// retention window.
+func (l *SessionEventLog) Events(sessionID string) ([]SessionEvent, int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expire(l.now())
+	session, ok := l.sessions[sessionID]
+	if !ok {
+		return nil, 0, false
+	}
+	events := make([]SessionEvent, 0, len(session.events))
+	events = append(events, session.events[session.next:]...)
+	events = append(events, session.events[:session.next]...)
+	return events, session.dropped, true
+}
+
+// expire forgets the sessions whose last event is older than the retention window.
+// The caller holds l.mu.
+func (l *SessionEventLog) expire(now time.Time) {
+	for id, session := range l.sessions {
+		if now.Sub(session.lastSeen) > l.retention {
+			delete(l.sessions, id)
+		}
+	}
+}
+
+// evictOldest forgets the session seen least recently. The caller holds l.mu.
+func (l *SessionEventLog) evictOldest() {
+	var oldestID string
+	var oldest time.Time
+	for id, session := range l.sessions {
+		if oldestID == "" || session.lastSeen.Before(oldest) {
+			oldestID, oldest = id, session.lastSeen
+		}
+	}
+	delete(l.sessions, oldestID)
+}
+
+// Middleware wraps next so the server-sent events of every text/event-stream
+// response are recorded under the response's MCP session ID. A nil log returns next
+// unchanged.
+func (l *SessionEventLog) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &sessionEventResponseWriter{ResponseWriter: w, log: l, sessionID: r.Header.Get(SessionIDHeader)}
+		next.ServeHTTP(recorder, r)
+		recorder.flushEvent()
+	})
+}
+
+// Handler serves the events of a session as a SessionEventLogResponse at
+// SessionEventLogPath + "{id}/events".
+func (l *SessionEventLog) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		sessionID, ok := strings.CutPrefix(r.URL.Path, SessionEventLogPath)
+		if ok {
+			sessionID, ok = strings.CutSuffix(sessionID, "/events")
+		}
+		if !ok || sessionID == "" || strings.Contains(sessionID, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		events, dropped, found := l.Events(sessionID)
+		if !found {
+			http.Error(w, "no events recorded for session "+sessionID, http.StatusNotFound)
+			return
+		}
+		body, err := json.Marshal(SessionEventLogResponse{SessionID: sessionID, Events: events, Dropped: dropped})
+		if err != nil {
+			http.Error(w, "failed to format events", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+}
+
+// sessionEventResponseWriter wraps http.ResponseWriter to parse the server-sent events
+// written to a text/event-stream response and record them in the session event log.
+// It implements http.Flusher to support HTTP streaming.
+type sessionEventResponseWriter struct {
+	http.ResponseWriter
+	log       *SessionEventLog
+	sessionID string
+
+	checked   bool
+	recording bool
+	pending   []byte
+}
+
+func (rw *sessionEventResponseWriter) WriteHeader(code int) {
+	rw.check()
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *sessionEventResponseWriter) Write(b []byte) (int, error) {
+	rw.check()
+	n, err := rw.ResponseWriter.Write(b)
+	if rw.recording {
+		rw.pending = append(rw.pending, b[:n]...)
+		rw.recordEvents()
+	}
+	return n, err
+}
+
+// check decides, once the headers are final, whether the response is recorded: it is
+// when it is an event stream and a session ID is known. The session ID of a new
+// session is only set on the response.
+func (rw *sessionEventResponseWriter) check() {
+	if rw.checked {
+		return
+	}
+	rw.checked = true
+	if id := rw.Header().Get(SessionIDHeader); id != "" {
+		rw.sessionID = id
+	}
+	mediaType, _, _ := mime.ParseMediaType(rw.Header().Get("Content-Type"))
+	rw.recording = mediaType == "text/event-stream" && rw.sessionID != ""
+}
+
+// recordEvents records every complete event in the pending bytes.
+func (rw *sessionEventResponseWriter) recordEvents() {
+	for {
+		end := bytes.Index(rw.pending, []byte("\n\n"))
+		if end < 0 {
+			return
+		}
+		rw.recordEvent(rw.pending[:end])
+		rw.pending = rw.pending[end+2:]
+	}
+}
+
+// flushEvent records an event the handler left unterminated when it returned.
+func (rw *sessionEventResponseWriter) flushEvent() {
+	if rw.recording && len(bytes.TrimSpace(rw.pending)) > 0 {
+		rw.recordEvent(rw.pending)
+	}
+	rw.pending = nil
+}
+
+// recordEvent parses the lines of one event and records it. Comment lines and events
+// without fields are skipped.
+func (rw *sessionEventResponseWriter) recordEvent(raw []byte) {
+	var event SessionEvent
+	var data []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		field, value, _ := strings.Cut(strings.TrimSuffix(line, "\r"), ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if event.Event == "" && event.ID == "" && data == nil {
+		return
+	}
+	event.Data = strings.Join(data, "\n")
+	rw.log.Record(rw.sessionID, event)
+}
+
+// Flush implements http.Flusher interface for HTTP streaming support.
+func (rw *sessionEventResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped http.ResponseWriter for http.ResponseController.
+func (rw *sessionEventResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// sseTestHandler streams the events in body to session "s-1", as the Streamable HTTP
+// handler does, or answers with JSON when the request asks for it.
+func sseTestHandler(body ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(SessionIDHeader, "s-1")
+		if r.URL.Query().Has("json") {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for _, chunk := range body {
+			_, _ = w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+		}
+	})
+}
+
+// getSessionEvents reads the events of sessionID from the log's endpoint.
+func getSessionEvents(eventLog *SessionEventLog, sessionID string) (*httptest.ResponseRecorder, SessionEventLogResponse) {
+	rec := httptest.NewRecorder()
+	eventLog.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, SessionEventLogPath+sessionID+"/events", nil))
+	var response SessionEventLogResponse
+	if rec.Code == http.StatusOK {
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+	}
+	return rec, response
+}
+
+var _ = Describe("Session event log", func() {
+	It("records streamed events and serves them by session ID", func() {
+		eventLog := NewSessionEventLog(10, time.Minute)
+		handler := eventLog.Middleware(sseTestHandler(
+			"event: message\nid: 1_0\ndata: {\"method\":\"notifications/progress\"}\n\n",
+			// An event split across writes is recorded once complete
+			"event: message\nid: 1_1\ndata: {\"result\":",
+			"{\"content\":[]}}\n\n",
+		))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+		Expect(rec.Body.String()).To(HavePrefix("event: message\nid: 1_0\n"))
+
+		rec, response := getSessionEvents(eventLog, "s-1")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(response.SessionID).To(Equal("s-1"))
+		Expect(response.Dropped).To(BeZero())
+		Expect(response.Events).To(HaveLen(2))
+		Expect(response.Events[0].Event).To(Equal("message"))
+		Expect(response.Events[0].ID).To(Equal("1_0"))
+		Expect(response.Events[0].Data).To(Equal(`{"method":"notifications/progress"}`))
+		Expect(response.Events[1].Data).To(Equal(`{"result":{"content":[]}}`))
+	})
+
+	It("keeps only the newest events of a session", func() {
+		eventLog := NewSessionEventLog(2, time.Minute)
+		for i := range 5 {
+			eventLog.Record("s-1", SessionEvent{ID: fmt.Sprint(i), Data: "{}"})
+		}
+
+		events, dropped, found := eventLog.Events("s-1")
+		Expect(found).To(BeTrue())
+		Expect(dropped).To(Equal(3))
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].ID).To(Equal("3"))
+		Expect(events[1].ID).To(Equal("4"))
+	})
+
+	It("truncates large event data", func() {
+		eventLog := NewSessionEventLog(1, time.Minute)
+		eventLog.Record("s-1", SessionEvent{Data: strings.Repeat("x", maxSessionEventDataBytes+10)})
+
+		events, _, _ := eventLog.Events("s-1")
+		Expect(events[0].Data).To(HaveLen(maxSessionEventDataBytes))
+		Expect(events[0].Truncated).To(BeTrue())
+	})
+
+	It("forgets a session once the retention window has passed", func() {
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		eventLog := NewSessionEventLog(10, time.Minute)
+		eventLog.now = func() time.Time { return now }
+		eventLog.Record("s-1", SessionEvent{Data: "{}"})
+
+		now = now.Add(59 * time.Second)
+		_, _, found := eventLog.Events("s-1")
+		Expect(found).To(BeTrue())
+
+		now = now.Add(2 * time.Second)
+		rec, _ := getSessionEvents(eventLog, "s-1")
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("bounds the number of sessions kept", func() {
+		eventLog := NewSessionEventLog(1, time.Minute)
+		for i := range maxSessionEventLogSessions + 1 {
+			eventLog.Record(fmt.Sprintf("s-%d", i), SessionEvent{Data: "{}"})
+		}
+		Expect(eventLog.sessions).To(HaveLen(maxSessionEventLogSessions))
+	})
+
+	It("does not record responses that are not event streams", func() {
+		eventLog := NewSessionEventLog(10, time.Minute)
+		handler := eventLog.Middleware(sseTestHandler())
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mcp?json", nil))
+
+		rec, _ := getSessionEvents(eventLog, "s-1")
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("is disabled when the size is zero", func() {
+		eventLog := NewSessionEventLog(0, time.Minute)
+		Expect(eventLog).To(BeNil())
+
+		next := http.NewServeMux()
+		Expect(eventLog.Middleware(next)).To(BeIdenticalTo(next))
+	})
+})
@@ -35,6 +35,38 @@ contexts:
     user: test-user
 `
 
+	// NamespacedKubeconfig sets a namespace on its current context, hub, and on the
+	// other context, but not on bare.
+	NamespacedKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: hub
+clusters:
+- name: test-cluster
+  cluster:
+    server: https://192.168.1.100:6443
+    certificate-authority-data: dGVzdC1jYS1kYXRh
+users:
+- name: test-user
+  user:
+    token: test-token-12345
+contexts:
+- name: hub
+  context:
+    cluster: test-cluster
+    user: test-user
+    namespace: hub-hosts
+- name: other
+  context:
+    cluster: test-cluster
+    user: test-user
+    namespace: other-hosts
+- name: bare
+  context:
+    cluster: test-cluster
+    user: test-user
+`
+
 	// ExecAuthKubeconfig contains exec-based auth (should be blocked).
 	ExecAuthKubeconfig = `
 apiVersion: v1
@@ -127,6 +159,47 @@ contexts:
     cluster: test-cluster
     user: test-user
 `
+
+	// PlainHTTPKubeconfig points at an http:// API server.
+	PlainHTTPKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: http-context
+clusters:
+- name: http-cluster
+  cluster:
+    server: http://192.168.1.100:6443
+users:
+- name: test-user
+  user:
+    token: test-token
+contexts:
+- name: http-context
+  context:
+    cluster: http-cluster
+    user: test-user
+`
+
+	// InsecureSkipVerifyKubeconfig points at an https:// API server without verifying its certificate.
+	InsecureSkipVerifyKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: insecure-context
+clusters:
+- name: insecure-cluster
+  cluster:
+    server: https://192.168.1.100:6443
+    insecure-skip-tls-verify: true
+users:
+- name: test-user
+  user:
+    token: test-token
+contexts:
+- name: insecure-context
+  context:
+    cluster: insecure-cluster
+    user: test-user
+`
 )
 
 // EncodeKubeconfig base64-encodes a kubeconfig string.
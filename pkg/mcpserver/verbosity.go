@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openshift/kube-compare/pkg/compare"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// VerbosityTerse returns only the identities of the CRs that differ and the counts.
+	VerbosityTerse = "terse"
+	// VerbosityNormal returns kube-compare's field diffs. It is the default.
+	VerbosityNormal = "normal"
+	// VerbosityFull also includes managedFields in the field diffs.
+	VerbosityFull = "full"
+)
+
+// compareVerbosities are the accepted verbosity values.
+var compareVerbosities = []string{VerbosityTerse, VerbosityNormal, VerbosityFull}
+
+// TerseCompareOutput is the comparison output returned for verbosity terse. It keeps
+// kube-compare's summary and lists the CRs that differ, without their field diffs.
+type TerseCompareOutput struct {
+	Summary    *compare.Summary `json:"Summary"`
+	DriftedCRs []TerseDiff      `json:"DriftedCRs"`
+}
+
+// TerseDiff identifies a CR that differs from its reference template.
+type TerseDiff struct {
+	CRName             string `json:"CRName"`
+	CorrelatedTemplate string `json:"CorrelatedTemplate"`
+}
+
+// parseVerbosity checks verbosity and returns it, or VerbosityNormal when it is empty.
+// Terse output has no JUnit rendering, so terse cannot be combined with junit.
+func parseVerbosity(verbosity, outputFormat string) (string, error) {
+	verbosity = strings.ToLower(strings.TrimSpace(verbosity))
+	if verbosity == "" {
+		return VerbosityNormal, nil
+	}
+	if !slices.Contains(compareVerbosities, verbosity) {
+		return "", NewValidationError("verbosity",
+			fmt.Sprintf("unknown verbosity '%s'", verbosity),
+			"Use one of: "+strings.Join(compareVerbosities, ", "))
+	}
+	if verbosity == VerbosityTerse && outputFormat == compare.Junit {
+		return "", NewValidationError("verbosity",
+			"verbosity 'terse' cannot be used with output_format 'junit'",
+			"Use output_format json or yaml with terse, or use verbosity normal for JUnit output")
+	}
+	return verbosity, nil
+}
+
+// terseCompareOutput renders the CRs of parsed kube-compare output that differ,
+// without their field diffs, as YAML when format is yaml and as JSON otherwise.
+func terseCompareOutput(parsed *compare.Output, format string) (string, error) {
+	terse := TerseCompareOutput{Summary: parsed.Summary, DriftedCRs: []TerseDiff{}}
+	if parsed.Diffs != nil {
+		for _, diff := range *parsed.Diffs {
+			if diff.HasDiff() {
+				terse.DriftedCRs = append(terse.DriftedCRs, TerseDiff{
+					CRName:             diff.CRName,
+					CorrelatedTemplate: diff.CorrelatedTemplate,
+				})
+			}
+		}
+	}
+
+	var (
+		content []byte
+		err     error
+	)
+	if format == compare.Yaml {
+		content, err = sigsyaml.Marshal(terse)
+	} else {
+		content, err = json.Marshal(terse)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to format terse output: %w", err)
+	}
+	return string(content), nil
+}
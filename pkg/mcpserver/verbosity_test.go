@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// managedFieldsHunk is the managedFields detail kube-compare adds to a diff with
+// --show-managed-fields.
+const managedFieldsHunk = "@@ -2,4 +2,7 @@\n" +
+	" metadata:\n" +
+	"+  managedFields:\n" +
+	"+  - manager: kubectl-edit\n" +
+	"+    operation: Update\n" +
+	"   name: app\n"
+
+// newVerbosityTestOutput returns kube-compare output, as produced for verbosity full,
+// with one CR that differs and one that matches.
+func newVerbosityTestOutput() *compare.Output {
+	diffs := []compare.DiffSum{
+		{CRName: "apps/v1_Deployment_ns_app", CorrelatedTemplate: "deployment.yaml", DiffOutput: volatileDiffHeader + managedFieldsHunk + driftHunk},
+		{CRName: "v1_ConfigMap_ns_cm", CorrelatedTemplate: "configmap.yaml"},
+	}
+	return &compare.Output{
+		Summary: &compare.Summary{NumDiffCRs: 1, TotalCRs: 2},
+		Diffs:   &diffs,
+	}
+}
+
+var _ = Describe("verbosity", func() {
+	Describe("terseCompareOutput", func() {
+		It("omits the field detail that full output has", func() {
+			full, err := renderCompareOutput(newVerbosityTestOutput(), compare.Json)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(full).To(ContainSubstring("managedFields"))
+			Expect(full).To(ContainSubstring("replicas"))
+
+			terse, err := terseCompareOutput(newVerbosityTestOutput(), compare.Json)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(terse).NotTo(ContainSubstring("managedFields"))
+			Expect(terse).NotTo(ContainSubstring("replicas"))
+			Expect(terse).NotTo(ContainSubstring("DiffOutput"))
+
+			var parsed TerseCompareOutput
+			Expect(json.Unmarshal([]byte(terse), &parsed)).To(Succeed())
+			Expect(parsed.DriftedCRs).To(Equal([]TerseDiff{
+				{CRName: "apps/v1_Deployment_ns_app", CorrelatedTemplate: "deployment.yaml"},
+			}))
+			Expect(parsed.Summary.NumDiffCRs).To(Equal(1))
+			Expect(parsed.Summary.TotalCRs).To(Equal(2))
+		})
+
+		It("renders YAML for output_format yaml", func() {
+			terse, err := terseCompareOutput(newVerbosityTestOutput(), compare.Yaml)
+			Expect(err).NotTo(HaveOccurred())
+
+			var parsed TerseCompareOutput
+			Expect(sigsyaml.UnmarshalStrict([]byte(terse), &parsed)).To(Succeed())
+			Expect(parsed.DriftedCRs).To(HaveLen(1))
+		})
+
+		It("lists no CRs when none differ", func() {
+			diffs := []compare.DiffSum{{CRName: "v1_ConfigMap_ns_cm"}}
+			terse, err := terseCompareOutput(&compare.Output{Summary: &compare.Summary{TotalCRs: 1}, Diffs: &diffs}, compare.Json)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(terse).To(ContainSubstring(`"DriftedCRs":[]`))
+		})
+	})
+
+	DescribeTable("parseVerbosity",
+		func(verbosity, outputFormat, expected, expectedErr string) {
+			parsed, err := parseVerbosity(verbosity, outputFormat)
+			if expectedErr != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(expected))
+		},
+		Entry("defaults to normal", "", "json", VerbosityNormal, ""),
+		Entry("normalizes case", " Terse ", "yaml", VerbosityTerse, ""),
+		Entry("accepts full with junit", "full", "junit", VerbosityFull, ""),
+		Entry("rejects an unknown value", "debug", "json", "", "unknown verbosity 'debug'"),
+		Entry("rejects terse with junit", "terse", "junit", "", "cannot be used with output_format 'junit'"),
+	)
+
+	It("shows managed fields in kube-compare for full", func() {
+		opts, _, err := buildCompareOptions(&CompareArgs{Verbosity: VerbosityFull}, "", "", genericiooptions.NewTestIOStreamsDiscard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.ShowManagedFields).To(BeTrue())
+		Expect(compareCommandEquivalent(&CompareArgs{Reference: "/ref/metadata.yaml", Verbosity: VerbosityFull})).To(
+			HaveSuffix("--show-managed-fields"))
+
+		opts, _, err = buildCompareOptions(&CompareArgs{Verbosity: VerbosityNormal}, "", "", genericiooptions.NewTestIOStreamsDiscard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.ShowManagedFields).To(BeFalse())
+	})
+
+	It("makes kube-compare output JSON for terse", func() {
+		Expect(compareOutputFormat(&CompareArgs{OutputFormat: compare.Yaml, Verbosity: VerbosityTerse})).To(Equal(compare.Json))
+	})
+})
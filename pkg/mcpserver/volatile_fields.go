@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+// DefaultVolatileFields are the field paths ignore_volatile_fields drops diffs in unless
+// the server is configured otherwise.
+const DefaultVolatileFields = "metadata.resourceVersion,metadata.generation,metadata.uid," +
+	"metadata.creationTimestamp,metadata.managedFields,status"
+
+var (
+	volatileFieldsMu sync.RWMutex
+	volatileFields   = ParseVolatileFields(DefaultVolatileFields)
+)
+
+// SetVolatileFields sets the field paths ignore_volatile_fields drops diffs in.
+func SetVolatileFields(patterns []string) {
+	volatileFieldsMu.Lock()
+	defer volatileFieldsMu.Unlock()
+	volatileFields = patterns
+}
+
+// getVolatileFields returns the patterns set by SetVolatileFields.
+func getVolatileFields() []string {
+	volatileFieldsMu.RLock()
+	defer volatileFieldsMu.RUnlock()
+	return volatileFields
+}
+
+// ParseVolatileFields splits a comma-separated list of field paths, dropping empty entries.
+func ParseVolatileFields(value string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// ValidateVolatileFields checks that every pattern is a dotted field path such as
+// metadata.resourceVersion, where "*" matches any single field.
+func ValidateVolatileFields(patterns []string) error {
+	for _, pattern := range patterns {
+		for _, segment := range strings.Split(pattern, ".") {
+			if segment == "" {
+				return fmt.Errorf("invalid volatile field path %q: empty path segment", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// FilterOutputVolatileFields drops the hunks of each diff that only change fields under
+// one of patterns, updates the CR counts in the summary to match, and returns how many
+// CRs no longer differ. A hunk is dropped only when the path of every changed line in it
+// can be read from the hunk itself, so a hunk whose fields cannot be placed is kept.
+func FilterOutputVolatileFields(output *compare.Output, patterns []string) int {
+	if output.Diffs == nil || len(patterns) == 0 {
+		return 0
+	}
+
+	cleared := 0
+	for i := range *output.Diffs {
+		diff := &(*output.Diffs)[i]
+		if !diff.HasDiff() {
+			continue
+		}
+		diff.DiffOutput = filterDiffVolatileFields(diff.DiffOutput, patterns)
+		if !diff.HasDiff() {
+			cleared++
+		}
+	}
+
+	if output.Summary != nil {
+		recountSummaryCRs(output)
+	}
+	return cleared
+}
+
+// filterDiffVolatileFields drops the hunks of a unified diff that only change fields
+// under one of patterns. It returns "" when no hunk is left.
+func filterDiffVolatileFields(diffOutput string, patterns []string) string {
+	header, hunks := splitDiffHunks(diffOutput)
+	if len(hunks) == 0 {
+		// Not a unified diff, so there is nothing to place
+		return diffOutput
+	}
+
+	var kept []string
+	for _, hunk := range hunks {
+		if !hunkOnlyChangesFields(hunk[1:], patterns) {
+			kept = append(kept, hunk...)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(header, "") + strings.Join(kept, "")
+}
+
+// splitDiffHunks splits a unified diff into the lines of its file header and the lines
+// of each hunk, starting with the hunk's @@ header. Lines keep their newlines.
+func splitDiffHunks(diffOutput string) ([]string, [][]string) {
+	var header []string
+	var hunks [][]string
+	for _, line := range strings.SplitAfter(diffOutput, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			hunks = append(hunks, []string{line})
+		case len(hunks) == 0:
+			header = append(header, line)
+		default:
+			hunks[len(hunks)-1] = append(hunks[len(hunks)-1], line)
+		}
+	}
+	return header, hunks
+}
+
+// hunkOnlyChangesFields reports whether every changed line of a hunk, given without its
+// @@ header, lies under one of patterns.
+func hunkOnlyChangesFields(lines []string, patterns []string) bool {
+	return hunkOnlyChangesPaths(lines, func(path []string) bool {
+		return pathUnderPatterns(path, patterns)
+	})
+}
+
+// hunkOnlyChangesPaths reports whether match holds for the field path of every changed
+// line of a hunk, given without its @@ header. The path is nil for a line that cannot be
+// placed. The removed and added lines are placed against the old and new document
+// respectively.
+func hunkOnlyChangesPaths(lines []string, match func(path []string) bool) bool {
+	var oldPath, newPath yamlPathTracker
+	changed := false
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			continue
+		}
+		content := line[1:]
+		switch line[0] {
+		case ' ':
+			oldPath.add(content)
+			newPath.add(content)
+		case '-':
+			changed = true
+			if !match(oldPath.add(content)) {
+				return false
+			}
+		case '+':
+			changed = true
+			if !match(newPath.add(content)) {
+				return false
+			}
+		}
+	}
+	return changed
+}
+
+// pathUnderPatterns reports whether the field path lies under one of patterns. A nil
+// path, one that could not be placed, never does.
+func pathUnderPatterns(path []string, patterns []string) bool {
+	if path == nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		segments := strings.Split(pattern, ".")
+		if len(segments) > len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range segments {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlPathTracker follows the field path of consecutive lines of block-style YAML, as
+// kube-compare renders objects, by their indentation.
+type yamlPathTracker struct {
+	keys []yamlKey
+}
+
+// yamlKey is a mapping key and the column it starts at.
+type yamlKey struct {
+	indent int
+	name   string
+}
+
+// add records the next line and returns its field path: the keys enclosing it, and its
+// own key if it has one. The path is nil while no top-level key has been seen, since
+// the line cannot be placed then.
+func (t *yamlPathTracker) add(line string) []string {
+	trimmed := strings.TrimLeft(line, " ")
+	if trimmed == "" {
+		return t.path()
+	}
+	indent := len(line) - len(trimmed)
+	// A sequence item is written at its parent's indentation, so its fields sit
+	// further in than the "- " marker
+	for strings.HasPrefix(trimmed, "- ") {
+		trimmed = strings.TrimLeft(trimmed[1:], " ")
+		indent = len(line) - len(trimmed)
+	}
+
+	for len(t.keys) > 0 && t.keys[len(t.keys)-1].indent >= indent {
+		t.keys = t.keys[:len(t.keys)-1]
+	}
+	if name, ok := yamlMappingKey(trimmed); ok {
+		t.keys = append(t.keys, yamlKey{indent: indent, name: name})
+	}
+	return t.path()
+}
+
+func (t *yamlPathTracker) path() []string {
+	if len(t.keys) == 0 || t.keys[0].indent != 0 {
+		return nil
+	}
+	path := make([]string, len(t.keys))
+	for i, key := range t.keys {
+		path[i] = key.name
+	}
+	return path
+}
+
+// yamlMappingKey returns the key of a "key: value" or "key:" line.
+func yamlMappingKey(line string) (string, bool) {
+	if strings.HasPrefix(line, `"`) || strings.HasPrefix(line, "'") {
+		quote := line[:1]
+		end := strings.Index(line[1:], quote)
+		if end < 0 || !strings.HasPrefix(line[end+2:], ":") {
+			return "", false
+		}
+		return line[1 : end+1], true
+	}
+	key, rest, found := strings.Cut(line, ":")
+	if !found || key == "" || strings.ContainsAny(key, " \t") {
+		return "", false
+	}
+	if rest != "" && !strings.HasPrefix(rest, " ") {
+		// A colon inside a plain scalar, such as a URL
+		return "", false
+	}
+	return key, true
+}
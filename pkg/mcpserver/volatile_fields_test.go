@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcpserver
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/kube-compare/pkg/compare"
+)
+
+// volatileDiffHeader is the file header kube-compare's diff output starts with.
+const volatileDiffHeader = "diff -u -N /tmp/MERGED-1/v1_configmap_ns_cm /tmp/LIVE-1/v1_configmap_ns_cm\n" +
+	"--- /tmp/MERGED-1/v1_configmap_ns_cm\t2024-01-01 00:00:00.000000000 +0000\n" +
+	"+++ /tmp/LIVE-1/v1_configmap_ns_cm\t2024-01-01 00:00:00.000000000 +0000\n"
+
+// volatileHunk only changes metadata.resourceVersion.
+const volatileHunk = "@@ -4,7 +4,7 @@\n" +
+	" kind: ConfigMap\n" +
+	" metadata:\n" +
+	"   name: cm\n" +
+	"   namespace: ns\n" +
+	"-  resourceVersion: \"100\"\n" +
+	"+  resourceVersion: \"200\"\n" +
+	" spec:\n"
+
+// statusHunk only changes fields under status.
+const statusHunk = "@@ -20,6 +20,7 @@\n" +
+	" status:\n" +
+	"   conditions:\n" +
+	"   - lastTransitionTime: \"2024-01-01T00:00:00Z\"\n" +
+	"-    reason: Pending\n" +
+	"+    reason: Ready\n" +
+	"+    observedGeneration: 3\n" +
+	"     type: Available\n"
+
+// driftHunk changes spec.replicas, real drift.
+const driftHunk = "@@ -10,6 +10,6 @@\n" +
+	" spec:\n" +
+	"   template:\n" +
+	"     name: app\n" +
+	"-  replicas: 3\n" +
+	"+  replicas: 1\n" +
+	"   selector: {}\n"
+
+// unplacedHunk changes a resourceVersion whose enclosing top-level field is not in the hunk.
+const unplacedHunk = "@@ -30,4 +30,4 @@\n" +
+	"     name: ref\n" +
+	"-    resourceVersion: \"1\"\n" +
+	"+    resourceVersion: \"2\"\n" +
+	"     uid: abc\n"
+
+var _ = Describe("ignore_volatile_fields", func() {
+	patterns := ParseVolatileFields(DefaultVolatileFields)
+
+	Describe("filterDiffVolatileFields", func() {
+		It("drops a diff that only changes volatile fields", func() {
+			Expect(filterDiffVolatileFields(volatileDiffHeader+volatileHunk+statusHunk, patterns)).To(BeEmpty())
+		})
+
+		It("keeps the hunks with real drift", func() {
+			filtered := filterDiffVolatileFields(volatileDiffHeader+volatileHunk+driftHunk+statusHunk, patterns)
+			Expect(filtered).To(Equal(volatileDiffHeader + driftHunk))
+		})
+
+		It("keeps a hunk whose changed fields cannot be placed", func() {
+			diff := volatileDiffHeader + unplacedHunk
+			Expect(filterDiffVolatileFields(diff, patterns)).To(Equal(diff))
+		})
+
+		It("matches any single field with *", func() {
+			diff := volatileDiffHeader + driftHunk
+			Expect(filterDiffVolatileFields(diff, []string{"spec.*"})).To(BeEmpty())
+			Expect(filterDiffVolatileFields(diff, []string{"spec.template"})).To(Equal(diff))
+		})
+
+		It("leaves output that is not a unified diff as is", func() {
+			Expect(filterDiffVolatileFields("-a\n+b", patterns)).To(Equal("-a\n+b"))
+		})
+	})
+
+	Describe("FilterOutputVolatileFields", func() {
+		It("reports CRs that only differed in volatile fields as matching", func() {
+			diffs := []compare.DiffSum{
+				{CRName: "v1_ConfigMap_ns_noisy", DiffOutput: volatileDiffHeader + volatileHunk},
+				{CRName: "v1_ConfigMap_ns_drifted", DiffOutput: volatileDiffHeader + volatileHunk + driftHunk},
+				{CRName: "v1_ConfigMap_ns_in-sync"},
+			}
+			output := &compare.Output{
+				Summary: &compare.Summary{NumDiffCRs: 2, TotalCRs: 3},
+				Diffs:   &diffs,
+			}
+
+			Expect(FilterOutputVolatileFields(output, patterns)).To(Equal(1))
+			Expect((*output.Diffs)[0].HasDiff()).To(BeFalse())
+			Expect((*output.Diffs)[1].DiffOutput).To(Equal(volatileDiffHeader + driftHunk))
+			Expect(output.Summary.NumDiffCRs).To(Equal(1))
+			Expect(output.Summary.TotalCRs).To(Equal(3))
+		})
+	})
+
+	DescribeTable("ValidateVolatileFields",
+		func(pattern string, valid bool) {
+			err := ValidateVolatileFields([]string{pattern})
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("a field path", "metadata.resourceVersion", true),
+		Entry("a wildcard", "metadata.annotations.*", true),
+		Entry("an empty segment", "metadata..uid", false),
+		Entry("a trailing dot", "status.", false),
+	)
+})